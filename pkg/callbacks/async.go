@@ -0,0 +1,179 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// QueueFullPolicy decides what an AsyncCallback does with an output when its queue is full
+type QueueFullPolicy int
+
+const (
+	// BlockWhenFull waits for space in the queue so no output is lost
+	BlockWhenFull QueueFullPolicy = iota
+	// DropOldestWhenFull discards the oldest queued output so polling is never delayed
+	DropOldestWhenFull
+)
+
+var queueFullPolicyNames = map[string]QueueFullPolicy{
+	"block":       BlockWhenFull,
+	"drop-oldest": DropOldestWhenFull,
+}
+
+// ParseQueueFullPolicy returns the QueueFullPolicy for a name given by the user
+func ParseQueueFullPolicy(name string) (QueueFullPolicy, error) {
+	policy, ok := queueFullPolicyNames[strings.ToLower(name)]
+	if !ok {
+		return BlockWhenFull, fmt.Errorf("unknown queue full policy %q, valid policies are: block, drop-oldest", name)
+	}
+	return policy, nil
+}
+
+type asyncRecord struct {
+	output OutputType
+	tag    string
+}
+
+// AsyncCallback queues the outputs and passes them on to the wrapped callback from its own
+// goroutine so that a slow output does not delay polling. Errors from the wrapped callback
+// are returned by the next Call or by CleanUp which writes everything still queued.
+type AsyncCallback struct {
+	callback Callback
+	queue    chan asyncRecord
+	done     chan struct{}
+	errs     []error
+	errLock  sync.Mutex
+	// closeLock stops the queue being closed while a Call is sending to it
+	closeLock sync.RWMutex
+	closed    bool
+	dropped   int64
+	policy    QueueFullPolicy
+}
+
+// NewAsyncCallback returns an AsyncCallback which queues up to queueSize outputs for callback
+func NewAsyncCallback(callback Callback, queueSize int, policy QueueFullPolicy) (*AsyncCallback, error) {
+	if queueSize < 1 {
+		return nil, errors.New("async queue size must be at least 1")
+	}
+	c := &AsyncCallback{
+		callback: callback,
+		queue:    make(chan asyncRecord, queueSize),
+		done:     make(chan struct{}),
+		errs:     make([]error, 0),
+		policy:   policy,
+	}
+	go c.dispatch()
+	return c, nil
+}
+
+// dispatch passes the queued outputs on to the wrapped callback until the queue is closed
+func (c *AsyncCallback) dispatch() {
+	defer close(c.done)
+	for record := range c.queue {
+		err := c.callback.Call(record.output, record.tag)
+		if err != nil {
+			c.errLock.Lock()
+			c.errs = append(c.errs, err)
+			c.errLock.Unlock()
+		}
+	}
+}
+
+// takeErrors returns the errors from the wrapped callback since it was last called
+func (c *AsyncCallback) takeErrors() error {
+	c.errLock.Lock()
+	defer c.errLock.Unlock()
+	errs := c.errs
+	c.errs = make([]error, 0)
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return utils.MakeCompositeError("async callback failed", errs)
+	}
+}
+
+// enqueue adds the record to the queue applying the policy if it is full
+func (c *AsyncCallback) enqueue(record asyncRecord) {
+	if c.policy == BlockWhenFull {
+		c.queue <- record
+		return
+	}
+	for {
+		select {
+		case c.queue <- record:
+			return
+		default:
+		}
+		// The dispatcher may take the oldest first in which case there is now space
+		select {
+		case <-c.queue:
+			atomic.AddInt64(&c.dropped, 1)
+		default:
+		}
+	}
+}
+
+// Call queues the output, it returns any errors the wrapped callback has had since the last Call
+func (c *AsyncCallback) Call(output OutputType, tag string) error {
+	c.closeLock.RLock()
+	defer c.closeLock.RUnlock()
+	if c.closed {
+		return fmt.Errorf("async callback has been cleaned up, %s was not written", tag)
+	}
+	c.enqueue(asyncRecord{output: output, tag: tag})
+	return c.takeErrors()
+}
+
+// Dropped returns the number of outputs discarded because the queue was full
+func (c *AsyncCallback) Dropped() int64 {
+	return atomic.LoadInt64(&c.dropped)
+}
+
+// Flush flushes the wrapped callback if it buffers its output
+func (c *AsyncCallback) Flush() error {
+	if flusher, ok := c.callback.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+func (c *AsyncCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+// CleanUp waits for the queued outputs to be written then cleans up the wrapped callback
+func (c *AsyncCallback) CleanUp() error {
+	c.closeLock.Lock()
+	if !c.closed {
+		c.closed = true
+		close(c.queue)
+	}
+	c.closeLock.Unlock()
+	<-c.done
+
+	errs := make([]error, 0)
+	if err := c.takeErrors(); err != nil {
+		errs = append(errs, err)
+	}
+	if err := c.callback.CleanUp(); err != nil {
+		errs = append(errs, err)
+	}
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return utils.MakeCompositeError("failed to clean up async callback", errs)
+	}
+}
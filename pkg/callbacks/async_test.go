@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+// gatedFile holds each write until release is closed so the async queue can be filled
+type gatedFile struct {
+	*testFile
+	writing chan struct{}
+	release chan struct{}
+}
+
+func newGatedFile() *gatedFile {
+	return &gatedFile{
+		testFile: NewTestFile(),
+		writing:  make(chan struct{}, 10), //nolint:gomnd // more than the writes in a test
+		release:  make(chan struct{}),
+	}
+}
+
+func (g *gatedFile) Write(p []byte) (int, error) {
+	g.writing <- struct{}{}
+	<-g.release
+	return g.testFile.Write(p) //nolint:wrapcheck // test helper
+}
+
+var _ = Describe("AsyncCallback", func() {
+	var mockedFile *gatedFile
+
+	BeforeEach(func() {
+		mockedFile = newGatedFile()
+	})
+
+	// fill makes the dispatcher wait writing the first output with the second in the queue of size 1
+	fill := func(callback *callbacks.AsyncCallback) {
+		Expect(callback.Call(&testOutputType{Msg: "1"}, "testOut")).To(Succeed())
+		Eventually(mockedFile.writing).Should(Receive())
+		Expect(callback.Call(&testOutputType{Msg: "2"}, "testOut")).To(Succeed())
+	}
+
+	lines := func() []string {
+		return strings.Split(strings.TrimSuffix(mockedFile.String(), "\n"), "\n")
+	}
+
+	When("the queue is full and the policy is to block", func() {
+		It("should wait for space without losing outputs", func() {
+			callback, err := callbacks.NewAsyncCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.Raw), 1, callbacks.BlockWhenFull,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			fill(callback)
+
+			called := make(chan error, 1)
+			go func() {
+				called <- callback.Call(&testOutputType{Msg: "3"}, "testOut")
+			}()
+			Consistently(called, 100*time.Millisecond).ShouldNot(Receive())
+			close(mockedFile.release)
+			Eventually(called).Should(Receive(BeNil()))
+
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(callback.Dropped()).To(BeZero())
+			Expect(lines()).To(Equal([]string{
+				"*callbacks_test.testOutputType:testOut, {\"msg\":\"1\"}",
+				"*callbacks_test.testOutputType:testOut, {\"msg\":\"2\"}",
+				"*callbacks_test.testOutputType:testOut, {\"msg\":\"3\"}",
+			}))
+		})
+	})
+	When("the queue is full and the policy is to drop the oldest", func() {
+		It("should discard the oldest queued output without waiting", func() {
+			callback, err := callbacks.NewAsyncCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.Raw), 1, callbacks.DropOldestWhenFull,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			fill(callback)
+
+			Expect(callback.Call(&testOutputType{Msg: "3"}, "testOut")).To(Succeed())
+			Expect(callback.Dropped()).To(Equal(int64(1)))
+			close(mockedFile.release)
+
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(lines()).To(Equal([]string{
+				"*callbacks_test.testOutputType:testOut, {\"msg\":\"1\"}",
+				"*callbacks_test.testOutputType:testOut, {\"msg\":\"3\"}",
+			}))
+		})
+	})
+	When("it is cleaned up", func() {
+		It("should write the queued outputs then close the wrapped callback", func() {
+			callback, err := callbacks.NewAsyncCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.Raw), 5, callbacks.BlockWhenFull,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			close(mockedFile.release)
+			for _, msg := range []string{"1", "2", "3"} {
+				Expect(callback.Call(&testOutputType{Msg: msg}, "testOut")).To(Succeed())
+			}
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(lines()).To(HaveLen(3))
+			Expect(mockedFile.open).To(BeFalse())
+			Expect(callback.Call(&testOutputType{Msg: "4"}, "testOut")).To(HaveOccurred())
+		})
+	})
+	It("should reject a queue size below 1", func() {
+		_, err := callbacks.NewAsyncCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw), 0, callbacks.BlockWhenFull)
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// Flusher is implemented by callbacks which buffer their output,
+// Flush should be called periodically so that the output is persisted if the process dies
+type Flusher interface {
+	Flush() error
+}
+
+// BufferedWriteCloser buffers writes to a file handle so that small writes do not each need a syscall,
+// the buffer is written out when it is full, when Flush is called and on Close
+type BufferedWriteCloser struct {
+	fileHandle io.WriteCloser
+	writer     *bufio.Writer
+	lock       sync.Mutex
+}
+
+// NewBufferedWriteCloser returns a BufferedWriteCloser for fileHandle with a buffer of bufferSize bytes
+func NewBufferedWriteCloser(fileHandle io.WriteCloser, bufferSize int) *BufferedWriteCloser {
+	return &BufferedWriteCloser{
+		fileHandle: fileHandle,
+		writer:     bufio.NewWriterSize(fileHandle, bufferSize),
+	}
+}
+
+func (b *BufferedWriteCloser) Write(p []byte) (int, error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	n, err := b.writer.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("failed to write to buffer: %w", err)
+	}
+	return n, nil
+}
+
+// Flush writes any buffered data to the file handle,
+// then flushes the file handle if it buffers as well
+func (b *BufferedWriteCloser) Flush() error {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	err := b.writer.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush buffer: %w", err)
+	}
+	if flusher, ok := b.fileHandle.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+// Close flushes the buffer then closes the file handle, the file handle is closed even if the flush fails
+func (b *BufferedWriteCloser) Close() error {
+	flushErr := b.Flush()
+	err := b.fileHandle.Close()
+	if flushErr != nil {
+		return flushErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close file handle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var _ = Describe("BufferedWriteCloser", func() {
+	var mockedFile *testFile
+
+	BeforeEach(func() {
+		mockedFile = NewTestFile()
+	})
+
+	When("less than the buffer size is written", func() {
+		It("should hold the output until it is flushed", func() {
+			callback := callbacks.NewFileCallback(callbacks.NewBufferedWriteCloser(mockedFile, 1024), callbacks.Raw)
+			Expect(callback.Call(&testOutputType{Msg: "buffered"}, "testOut")).To(Succeed())
+			Expect(mockedFile.Len()).To(BeZero())
+
+			Expect(callback.Flush()).To(Succeed())
+			Expect(mockedFile.String()).To(ContainSubstring(`{"msg":"buffered"}`))
+		})
+	})
+	When("more than the buffer size is written", func() {
+		It("should write out the full buffer", func() {
+			callback := callbacks.NewFileCallback(callbacks.NewBufferedWriteCloser(mockedFile, 16), callbacks.Raw)
+			Expect(callback.Call(&testOutputType{Msg: "longer than the buffer"}, "testOut")).To(Succeed())
+			Expect(mockedFile.Len()).NotTo(BeZero())
+		})
+	})
+	When("the callback is cleaned up", func() {
+		It("should flush the buffer and close the file", func() {
+			callback := callbacks.NewFileCallback(callbacks.NewBufferedWriteCloser(mockedFile, 1024), callbacks.Raw)
+			Expect(callback.Call(&testOutputType{Msg: "buffered"}, "testOut")).To(Succeed())
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(mockedFile.String()).To(ContainSubstring(`{"msg":"buffered"}`))
+			Expect(mockedFile.open).To(BeFalse())
+		})
+	})
+	When("the file handle is not buffered", func() {
+		It("should not fail to flush", func() {
+			callback := callbacks.NewFileCallback(mockedFile, callbacks.Raw)
+			Expect(callback.Flush()).To(Succeed())
+		})
+	})
+})
+
+func benchmarkFileCallback(b *testing.B, wrap func(io.WriteCloser) io.WriteCloser) {
+	b.Helper()
+	fileHandle, err := os.Create(filepath.Join(b.TempDir(), "output.log"))
+	if err != nil {
+		b.Fatal(err)
+	}
+	callback := callbacks.NewFileCallback(wrap(fileHandle), callbacks.AnalyserJSON)
+	output := &testOutputType{Msg: "benchmark"}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if err := callback.Call(output, "testOut"); err != nil {
+			b.Fatal(err)
+		}
+	}
+	if err := callback.CleanUp(); err != nil {
+		b.Fatal(err)
+	}
+}
+
+func BenchmarkFileCallback(b *testing.B) {
+	b.Run("unbuffered", func(b *testing.B) {
+		benchmarkFileCallback(b, func(fileHandle io.WriteCloser) io.WriteCloser {
+			return fileHandle
+		})
+	})
+	b.Run("buffered", func(b *testing.B) {
+		benchmarkFileCallback(b, func(fileHandle io.WriteCloser) io.WriteCloser {
+			return callbacks.NewBufferedWriteCloser(fileHandle, 64*1024)
+		})
+	})
+}
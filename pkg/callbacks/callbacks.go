@@ -8,6 +8,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"sort"
+	"strings"
+	"sync"
 )
 
 const (
@@ -25,45 +28,138 @@ type OutputFormat int
 const (
 	Raw OutputFormat = iota
 	AnalyserJSON
+	NDJSON
 )
 
 type AnalyserFormatType struct {
-	Data any    `json:"data"`
-	ID   string `json:"id"`
+	Data any     `json:"data"`
+	Seq  *uint64 `json:"seq,omitempty"`
+	ID   string  `json:"id"`
+	Node string  `json:"node,omitempty"`
+}
+
+// NDJSONLine is written for each output in the NDJSON format,
+// Seq is the number of the output within its datatype when the collector numbered it
+// and Node is the node it was collected from when the outputs were tagged with it
+type NDJSONLine struct {
+	Data any     `json:"data"`
+	Seq  *uint64 `json:"seq,omitempty"`
+	Type string  `json:"type"`
+	Tag  string  `json:"tag"`
+	Node string  `json:"node,omitempty"`
 }
 
 type OutputType interface {
 	GetAnalyserFormat() ([]*AnalyserFormatType, error)
 }
 
+var outputFormatNames = map[string]OutputFormat{
+	"raw":      Raw,
+	"analyser": AnalyserJSON,
+	"ndjson":   NDJSON,
+}
+
+// OutputFormatNames returns the names which can be passed to ParseOutputFormat
+func OutputFormatNames() []string {
+	names := make([]string, 0, len(outputFormatNames))
+	for name := range outputFormatNames {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// ParseOutputFormat returns the OutputFormat for a name given by the user
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	format, ok := outputFormatNames[strings.ToLower(name)]
+	if !ok {
+		return Raw, fmt.Errorf(
+			"unknown output format %q, valid formats are: %s",
+			name,
+			strings.Join(OutputFormatNames(), ", "),
+		)
+	}
+	return format, nil
+}
+
+// formatRaw returns the output as its go type and tag followed by the json for the type,
+// when the output was tagged with a node the tag is followed by @ and the node name
+func formatRaw(output OutputType, tag string) ([]byte, error) {
+	line, err := json.Marshal(output)
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to marshal %T %w", output, err)
+	}
+	if node, ok := outputNode(output); ok {
+		tag = fmt.Sprintf("%s@%s", tag, node)
+	}
+	return []byte(fmt.Sprintf("%s:%s, %s", outputTypeName(output), tag, line)), nil
+}
+
+// formatNDJSON returns the output as a single line of JSON along with its go type and tag
+func formatNDJSON(output OutputType, tag string) ([]byte, error) {
+	ndjsonLine := &NDJSONLine{Data: output, Type: outputTypeName(output), Tag: tag}
+	if seq, ok := outputSequence(output); ok {
+		ndjsonLine.Seq = &seq
+	}
+	if node, ok := outputNode(output); ok {
+		ndjsonLine.Node = node
+	}
+	line, err := json.Marshal(ndjsonLine)
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to marshal %T %w", output, err)
+	}
+	return line, nil
+}
+
+// fileExtension returns the extension for files written in format
+func fileExtension(format OutputFormat) string {
+	if format == AnalyserJSON || format == NDJSON {
+		return ".jsonl"
+	}
+	return ".log"
+}
+
+// formatAnalyser returns the messages expected by the analysers for the output one per line
+func formatAnalyser(output OutputType, tag string) ([]byte, error) {
+	outputs, err := output.GetAnalyserFormat()
+	if err != nil {
+		return []byte{}, fmt.Errorf("failed to get AnalyserFormat %w", err)
+	}
+	newline := []byte("\n")
+	lines := make([]byte, 0)
+	seq, isSequenced := outputSequence(output)
+	node, isTagged := outputNode(output)
+	for count, obj := range outputs {
+		if isSequenced || isTagged {
+			annotatedObj := *obj
+			if isSequenced {
+				annotatedObj.Seq = &seq
+			}
+			annotatedObj.Node = node
+			obj = &annotatedObj
+		}
+		line, err := json.Marshal(obj)
+		if err != nil {
+			return []byte{}, fmt.Errorf("failed to marshal AnalyserFormat for %s %w", tag, err)
+		}
+		lines = append(lines, line...)
+		// Append a new line between the entries but not a trailing one
+		if count < len(outputs)-1 {
+			lines = append(lines, newline...)
+		}
+	}
+	return lines, nil
+}
+
 // getFormattedOutput returns the output in the format configured by on the callback
 func getFormattedOutput(c Callback, output OutputType, tag string) ([]byte, error) {
 	switch c.getFormat() {
 	case Raw:
-		line, err := json.Marshal(output)
-		if err != nil {
-			return []byte{}, fmt.Errorf("failed to marshal %T %w", output, err)
-		}
-		return []byte(fmt.Sprintf("%T:%s, %s", output, tag, line)), nil
+		return formatRaw(output, tag)
 	case AnalyserJSON:
-		outputs, err := output.GetAnalyserFormat()
-		if err != nil {
-			return []byte{}, fmt.Errorf("failed to get AnalyserFormat %w", err)
-		}
-		newline := []byte("\n")
-		lines := make([]byte, 0)
-		for count, obj := range outputs {
-			line, err := json.Marshal(obj)
-			if err != nil {
-				return []byte{}, fmt.Errorf("failed to marshal AnalyserFormat for %s %w", tag, err)
-			}
-			lines = append(lines, line...)
-			// Append a new line between the entries but not a trailing one
-			if count < len(outputs)-1 {
-				lines = append(lines, newline...)
-			}
-		}
-		return lines, nil
+		return formatAnalyser(output, tag)
+	case NDJSON:
+		return formatNDJSON(output, tag)
 	default:
 		return []byte{}, errors.New("unknown format")
 	}
@@ -71,8 +167,9 @@ func getFormattedOutput(c Callback, output OutputType, tag string) ([]byte, erro
 
 // Returns the filehandle for callback
 // if filename is empty or "-" it will output to stdout otherwise it will
-// write to a file of the given name
-func GetFileHandle(filename string) (io.WriteCloser, error) {
+// write to a file of the given name. An existing file is truncated
+// unless appendToFile is true in which case the output is added to the end of it
+func GetFileHandle(filename string, appendToFile bool) (io.WriteCloser, error) {
 	var (
 		fileHandle io.WriteCloser
 		err        error
@@ -80,7 +177,11 @@ func GetFileHandle(filename string) (io.WriteCloser, error) {
 	if filename == "-" || filename == "" {
 		fileHandle = os.Stdout
 	} else {
-		fileHandle, err = os.OpenFile(filename, os.O_CREATE|os.O_WRONLY, logFilePermissions)
+		flags := os.O_CREATE | os.O_WRONLY | os.O_TRUNC
+		if appendToFile {
+			flags = os.O_CREATE | os.O_WRONLY | os.O_APPEND
+		}
+		fileHandle, err = os.OpenFile(filename, flags, logFilePermissions)
 		if err != nil {
 			return fileHandle, fmt.Errorf("failed to open file: %w", err)
 		}
@@ -92,14 +193,37 @@ func NewFileCallback(fileHandle io.WriteCloser, format OutputFormat) FileCallBac
 	return FileCallBack{fileHandle: fileHandle, format: format}
 }
 
-// SetupCallback returns a FileCallback
+// SetupCallback returns an AnalyserCallback for the AnalyserJSON format otherwise a FileCallback
 // if filename is empty or "-" it will output to stdout otherwise it will
-// write to a file of the given name
-func SetupCallback(filename string, format OutputFormat) (FileCallBack, error) {
-	fileHandle, err := GetFileHandle(filename)
+// write to a file of the given name which is appended to if appendToFile is true.
+// If bufferSize is greater than 0 writes are buffered until that many bytes are waiting or it is flushed.
+// Unless gzipLevel is GzipDisabled the output is compressed at that level
+func SetupCallback(
+	filename string,
+	format OutputFormat,
+	appendToFile bool,
+	bufferSize int,
+	gzipLevel int,
+) (Callback, error) {
+	if gzipLevel != GzipDisabled {
+		if err := ValidateGzipLevel(gzipLevel); err != nil {
+			return FileCallBack{}, err
+		}
+	}
+	fileHandle, err := GetFileHandle(filename, appendToFile)
 	if err != nil {
 		return FileCallBack{}, err
 	}
+	if gzipLevel != GzipDisabled {
+		// The level has been validated so this can not fail
+		fileHandle, _ = NewGzipWriteCloser(fileHandle, gzipLevel)
+	}
+	if bufferSize > 0 {
+		fileHandle = NewBufferedWriteCloser(fileHandle, bufferSize)
+	}
+	if format == AnalyserJSON {
+		return NewAnalyserCallback(fileHandle), nil
+	}
 	return NewFileCallback(fileHandle, format), nil
 }
 
@@ -121,6 +245,14 @@ func (c FileCallBack) Call(output OutputType, tag string) error {
 	return nil
 }
 
+// Flush writes out any output buffered by the file handle
+func (c FileCallBack) Flush() error {
+	if flusher, ok := c.fileHandle.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
 func (c FileCallBack) getFormat() OutputFormat {
 	return c.format
 }
@@ -132,3 +264,72 @@ func (c FileCallBack) CleanUp() error {
 	}
 	return nil
 }
+
+// AnalyserCallback writes the messages expected by the analysers for each output
+type AnalyserCallback struct {
+	FileCallBack
+}
+
+func NewAnalyserCallback(fileHandle io.WriteCloser) AnalyserCallback {
+	return AnalyserCallback{FileCallBack: NewFileCallback(fileHandle, AnalyserJSON)}
+}
+
+func (c AnalyserCallback) Call(output OutputType, tag string) error {
+	lines, err := formatAnalyser(output, tag)
+	if err != nil {
+		return err
+	}
+	lines = append(lines, []byte("\n")...)
+	_, err = c.fileHandle.Write(lines)
+	if err != nil {
+		return fmt.Errorf("failed to write to file in callback: %w", err)
+	}
+	return nil
+}
+
+// CaptureCallback keeps the outputs in memory keyed by tag rather than writing them out,
+// if a tag is called more than once the values are collected into a []any
+type CaptureCallback struct {
+	outputs map[string]any
+	lock    *sync.Mutex
+}
+
+func NewCaptureCallback() CaptureCallback {
+	return CaptureCallback{outputs: make(map[string]any), lock: &sync.Mutex{}}
+}
+
+func (c CaptureCallback) Call(output OutputType, tag string) error {
+	output = unwrapOutput(output)
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	existing, ok := c.outputs[tag]
+	if !ok {
+		c.outputs[tag] = output
+		return nil
+	}
+	if values, isList := existing.([]any); isList {
+		c.outputs[tag] = append(values, output)
+	} else {
+		c.outputs[tag] = []any{existing, output}
+	}
+	return nil
+}
+
+// Outputs returns a copy of the outputs captured so far
+func (c CaptureCallback) Outputs() map[string]any {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	outputs := make(map[string]any, len(c.outputs))
+	for tag, value := range c.outputs {
+		outputs[tag] = value
+	}
+	return outputs
+}
+
+func (c CaptureCallback) getFormat() OutputFormat {
+	return Raw
+}
+
+func (c CaptureCallback) CleanUp() error {
+	return nil
+}
@@ -4,13 +4,21 @@ package callbacks_test
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	log "github.com/sirupsen/logrus"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/logging"
 )
 
 func NewTestFile() *testFile {
@@ -72,6 +80,52 @@ var _ = Describe("Callbacks", func() {
 			Expect(mockedFile.ReadString('\n')).To(Equal("{\"data\":[\"Hello\"],\"id\":\"testOutput\"}\n"))
 		})
 	})
+	When("NDJSON FileCallback is called", func() {
+		It("should write the output as a single line of JSON", func() {
+			callback := callbacks.NewFileCallback(mockedFile, callbacks.NDJSON)
+			err := callback.Call(&testOutputType{Msg: "This is a test line"}, "testOut")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockedFile.String()).To(Equal(
+				"{\"data\":{\"msg\":\"This is a test line\"},\"type\":\"*callbacks_test.testOutputType\",\"tag\":\"testOut\"}\n",
+			))
+		})
+	})
+	When("NDJSON is written to stdout while logging", func() {
+		It("should only write JSON to stdout", func() {
+			stdoutReader, stdoutWriter, err := os.Pipe()
+			Expect(err).NotTo(HaveOccurred())
+			stderrReader, stderrWriter, err := os.Pipe()
+			Expect(err).NotTo(HaveOccurred())
+			originalStdout, originalStderr := os.Stdout, os.Stderr
+			os.Stdout, os.Stderr = stdoutWriter, stderrWriter
+			defer func() {
+				os.Stdout, os.Stderr = originalStdout, originalStderr
+				log.SetOutput(originalStderr)
+			}()
+			logging.SetupLogging("info", os.Stderr)
+
+			callback, err := callbacks.SetupCallback("-", callbacks.NDJSON, false, 0, callbacks.GzipDisabled)
+			Expect(err).NotTo(HaveOccurred())
+			for _, msg := range []string{"first", "second", "third"} {
+				log.Infof("writing %s", msg)
+				Expect(callback.Call(&testOutputType{Msg: msg}, "testOut")).To(Succeed())
+			}
+			// Cleaning up closes the stdout pipe writer
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(stderrWriter.Close()).To(Succeed())
+
+			stdout, err := io.ReadAll(stdoutReader)
+			Expect(err).NotTo(HaveOccurred())
+			lines := strings.Split(strings.TrimSuffix(string(stdout), "\n"), "\n")
+			Expect(lines).To(HaveLen(3))
+			for _, line := range lines {
+				Expect(json.Valid([]byte(line))).To(BeTrue(), "stdout line is not JSON: %s", line)
+			}
+			stderr, err := io.ReadAll(stderrReader)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(stderr)).To(ContainSubstring("writing second"))
+		})
+	})
 	When("A FileCallback is cleaned up", func() {
 		It("should close the file", func() {
 			callback := callbacks.NewFileCallback(mockedFile, callbacks.Raw)
@@ -80,7 +134,108 @@ var _ = Describe("Callbacks", func() {
 			Expect(mockedFile.open).To(BeFalse())
 		})
 	})
+	When("CaptureCallback is called", func() {
+		It("should keep the outputs by tag", func() {
+			callback := callbacks.NewCaptureCallback()
+			first := testOutputType{Msg: "first"}
+			second := testOutputType{Msg: "second"}
+			other := testOutputType{Msg: "other"}
+			Expect(callback.Call(&first, "testOut")).To(Succeed())
+			Expect(callback.Call(&other, "otherOut")).To(Succeed())
+			Expect(callback.Call(&second, "testOut")).To(Succeed())
+
+			outputs := callback.Outputs()
+			Expect(outputs).To(HaveLen(2))
+			Expect(outputs["otherOut"]).To(Equal(&other))
+			Expect(outputs["testOut"]).To(Equal([]any{&first, &second}))
+		})
+	})
+	When("AnalyserCallback is called with GPS details", func() {
+		It("should write a message for the time error and each antenna", func() {
+			callback := callbacks.NewAnalyserCallback(mockedFile)
+			gpsDetails := devices.GPSDetails{
+				NavStatus: devices.GPSNavStatus{Timestamp: "2023-06-16T11:49:47.0584Z", Flags: "0xdd", GPSFix: 3},
+				NavClock:  devices.GPSNavClock{Timestamp: "2023-06-16T11:49:47.0584Z", TimeAcc: 5, FreqAcc: 164},
+				AntennaDetails: []*devices.GPSAntennaDetails{
+					{Timestamp: "2023-06-16T11:49:47.0584Z", BlockID: 0, Status: 2, Power: 1},
+					{Timestamp: "2023-06-16T11:49:47.0584Z", BlockID: 1, Status: 2, Power: 1},
+				},
+			}
+			err := callback.Call(&gpsDetails, "gnss-data")
+			Expect(err).NotTo(HaveOccurred())
 
+			lines := strings.Split(strings.TrimSuffix(mockedFile.String(), "\n"), "\n")
+			Expect(lines).To(HaveLen(3))
+			ids := make([]string, 0, len(lines))
+			for _, line := range lines {
+				msg := callbacks.AnalyserFormatType{}
+				Expect(json.Unmarshal([]byte(line), &msg)).To(Succeed())
+				ids = append(ids, msg.ID)
+			}
+			Expect(ids).To(Equal([]string{"gnss/time-error", "gnss/rf-mon", "gnss/rf-mon"}))
+			Expect(lines[0]).To(ContainSubstring(`"terror":5`))
+			Expect(lines[0]).To(ContainSubstring(`"state":3`))
+		})
+	})
+	When("An AnalyserCallback is cleaned up", func() {
+		It("should close the file", func() {
+			callback := callbacks.NewAnalyserCallback(mockedFile)
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(mockedFile.open).To(BeFalse())
+		})
+	})
+	When("An output format is parsed", func() {
+		It("should accept the known names", func() {
+			format, err := callbacks.ParseOutputFormat("raw")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(format).To(Equal(callbacks.Raw))
+			format, err = callbacks.ParseOutputFormat("Analyser")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(format).To(Equal(callbacks.AnalyserJSON))
+			format, err = callbacks.ParseOutputFormat("ndjson")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(format).To(Equal(callbacks.NDJSON))
+		})
+		It("should reject an unknown name listing the valid ones", func() {
+			_, err := callbacks.ParseOutputFormat("xml")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("analyser, ndjson, raw"))
+		})
+	})
+	When("SetupCallback is given the analyser format", func() {
+		It("should return an AnalyserCallback", func() {
+			callback, err := callbacks.SetupCallback("-", callbacks.AnalyserJSON, false, 0, callbacks.GzipDisabled)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callback).To(BeAssignableToTypeOf(callbacks.AnalyserCallback{}))
+		})
+	})
+	When("SetupCallback is given an existing file", func() {
+		var outputFile string
+		BeforeEach(func() {
+			outputDir, err := os.MkdirTemp("", "callback-output")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(os.RemoveAll, outputDir)
+			outputFile = filepath.Join(outputDir, "output.log")
+			Expect(os.WriteFile(outputFile, []byte("a much longer line from a previous run\n"), 0600)).To(Succeed())
+		})
+		write := func(appendToFile bool) string {
+			callback, err := callbacks.SetupCallback(outputFile, callbacks.AnalyserJSON, appendToFile, 0, callbacks.GzipDisabled)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callback.Call(&testOutputType{Msg: "new"}, "testOut")).To(Succeed())
+			Expect(callback.CleanUp()).To(Succeed())
+			contents, err := os.ReadFile(outputFile)
+			Expect(err).NotTo(HaveOccurred())
+			return string(contents)
+		}
+		It("should truncate the file by default", func() {
+			Expect(write(false)).To(Equal("{\"data\":[\"Hello\"],\"id\":\"testOutput\"}\n"))
+		})
+		It("should append to the file when asked", func() {
+			Expect(write(true)).To(Equal(
+				"a much longer line from a previous run\n{\"data\":[\"Hello\"],\"id\":\"testOutput\"}\n",
+			))
+		})
+	})
 })
 
 func TestCommand(t *testing.T) {
@@ -0,0 +1,55 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import "sync"
+
+// CountingCallback counts the outputs of each tag which the wrapped callback wrote successfully
+type CountingCallback struct {
+	callback Callback
+	counts   map[string]int64
+	lock     sync.Mutex
+}
+
+// NewCountingCallback returns a CountingCallback which writes the outputs through callback
+func NewCountingCallback(callback Callback) *CountingCallback {
+	return &CountingCallback{callback: callback, counts: make(map[string]int64)}
+}
+
+func (c *CountingCallback) Call(output OutputType, tag string) error {
+	err := c.callback.Call(output, tag)
+	if err != nil {
+		return err //nolint:wrapcheck // the wrapped callback wraps its errors
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.counts[tag]++
+	return nil
+}
+
+// Counts returns a copy of the number of outputs written for each tag
+func (c *CountingCallback) Counts() map[string]int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	counts := make(map[string]int64, len(c.counts))
+	for tag, count := range c.counts {
+		counts[tag] = count
+	}
+	return counts
+}
+
+// Flush flushes the wrapped callback if it buffers its output
+func (c *CountingCallback) Flush() error {
+	if flusher, ok := c.callback.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+func (c *CountingCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+func (c *CountingCallback) CleanUp() error {
+	return c.callback.CleanUp() //nolint:wrapcheck // the wrapped callback wraps its errors
+}
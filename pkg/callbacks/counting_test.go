@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var _ = Describe("CountingCallback", func() {
+	It("should count the outputs written for each tag", func() {
+		mockedFile := NewTestFile()
+		callback := callbacks.NewCountingCallback(callbacks.NewFileCallback(mockedFile, callbacks.NDJSON))
+		Expect(callback.Call(&testOutputType{Msg: "first"}, "gpsNav")).To(Succeed())
+		Expect(callback.Call(&testOutputType{Msg: "second"}, "gpsNav")).To(Succeed())
+		Expect(callback.Call(&testOutputType{Msg: "third"}, "dpll")).To(Succeed())
+		Expect(callback.Counts()).To(Equal(map[string]int64{"gpsNav": 2, "dpll": 1}))
+	})
+	It("should not count outputs which failed to be written", func() {
+		mockedFile := NewTestFile()
+		callback := callbacks.NewCountingCallback(callbacks.NewFileCallback(mockedFile, -1))
+		Expect(callback.Call(&testOutputType{Msg: "first"}, "gpsNav")).NotTo(Succeed())
+		Expect(callback.Counts()).To(BeEmpty())
+	})
+})
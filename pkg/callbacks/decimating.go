@@ -0,0 +1,64 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import "sync"
+
+// DecimatingCallback passes one in every sampleEvery outputs of each tag on to the wrapped callback,
+// the first output of each tag is always passed on. Outputs with a pass through tag are never dropped.
+type DecimatingCallback struct {
+	callback        Callback
+	counts          map[string]int
+	passThroughTags map[string]bool
+	lock            sync.Mutex
+	sampleEvery     int
+}
+
+// NewDecimatingCallback returns a DecimatingCallback which writes the sampled outputs through callback
+func NewDecimatingCallback(callback Callback, sampleEvery int, passThroughTags ...string) *DecimatingCallback {
+	tags := make(map[string]bool, len(passThroughTags))
+	for _, tag := range passThroughTags {
+		tags[tag] = true
+	}
+	return &DecimatingCallback{
+		callback:        callback,
+		counts:          make(map[string]int),
+		passThroughTags: tags,
+		sampleEvery:     sampleEvery,
+	}
+}
+
+// shouldPass counts the output for tag and returns true if it is one to pass on
+func (c *DecimatingCallback) shouldPass(tag string) bool {
+	if c.sampleEvery <= 1 || c.passThroughTags[tag] {
+		return true
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	count := c.counts[tag]
+	c.counts[tag] = count + 1
+	return count%c.sampleEvery == 0
+}
+
+func (c *DecimatingCallback) Call(output OutputType, tag string) error {
+	if !c.shouldPass(tag) {
+		return nil
+	}
+	return c.callback.Call(output, tag) //nolint:wrapcheck // the wrapped callback wraps its errors
+}
+
+// Flush flushes the wrapped callback if it buffers its output
+func (c *DecimatingCallback) Flush() error {
+	if flusher, ok := c.callback.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+func (c *DecimatingCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+func (c *DecimatingCallback) CleanUp() error {
+	return c.callback.CleanUp() //nolint:wrapcheck // the wrapped callback wraps its errors
+}
@@ -0,0 +1,59 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var _ = Describe("DecimatingCallback", func() {
+	var mockedFile *testFile
+
+	BeforeEach(func() {
+		mockedFile = NewTestFile()
+	})
+
+	When("outputs of several tags are interleaved", func() {
+		It("should count each tag separately and always pass the first", func() {
+			callback := callbacks.NewDecimatingCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw), 3)
+			for i := 0; i < 7; i++ {
+				Expect(callback.Call(&testOutputType{Msg: "a"}, "dpll")).To(Succeed())
+				if i < 2 {
+					Expect(callback.Call(&testOutputType{Msg: "b"}, "gnss")).To(Succeed())
+				}
+			}
+			lines := strings.Split(strings.TrimSuffix(mockedFile.String(), "\n"), "\n")
+			// dpll outputs 1, 4 and 7 and the first gnss output
+			Expect(lines).To(HaveLen(4))
+			Expect(strings.Count(mockedFile.String(), ":dpll,")).To(Equal(3))
+			Expect(strings.Count(mockedFile.String(), ":gnss,")).To(Equal(1))
+		})
+	})
+	When("an output has a pass through tag", func() {
+		It("should always pass it on", func() {
+			callback := callbacks.NewDecimatingCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.Raw), 5, "device-info",
+			)
+			for i := 0; i < 5; i++ {
+				Expect(callback.Call(&testOutputType{Msg: "a"}, "device-info")).To(Succeed())
+				Expect(callback.Call(&testOutputType{Msg: "b"}, "dpll")).To(Succeed())
+			}
+			Expect(strings.Count(mockedFile.String(), ":device-info,")).To(Equal(5))
+			Expect(strings.Count(mockedFile.String(), ":dpll,")).To(Equal(1))
+		})
+	})
+	When("every output is sampled", func() {
+		It("should pass on every output", func() {
+			callback := callbacks.NewDecimatingCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw), 1)
+			for i := 0; i < 3; i++ {
+				Expect(callback.Call(&testOutputType{Msg: "a"}, "dpll")).To(Succeed())
+			}
+			Expect(strings.Count(mockedFile.String(), ":dpll,")).To(Equal(3))
+		})
+	})
+})
@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// GzipDisabled is passed as the gzip level when the output should not be compressed
+const GzipDisabled = -1
+
+// ValidateGzipLevel returns an error if level is not between
+// gzip.NoCompression (0) and gzip.BestCompression (9)
+func ValidateGzipLevel(level int) error {
+	if level < gzip.NoCompression || level > gzip.BestCompression {
+		return fmt.Errorf(
+			"gzip level %d is not between %d (fastest) and %d (smallest)",
+			level, gzip.NoCompression, gzip.BestCompression,
+		)
+	}
+	return nil
+}
+
+// GzipWriteCloser compresses writes to a file handle, Flush writes out what has been
+// compressed so far so the output can be read back before it is closed
+type GzipWriteCloser struct {
+	fileHandle io.WriteCloser
+	writer     *gzip.Writer
+	lock       sync.Mutex
+}
+
+// NewGzipWriteCloser returns a GzipWriteCloser for fileHandle compressing at level
+func NewGzipWriteCloser(fileHandle io.WriteCloser, level int) (*GzipWriteCloser, error) {
+	err := ValidateGzipLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	writer, err := gzip.NewWriterLevel(fileHandle, level)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create gzip writer: %w", err)
+	}
+	return &GzipWriteCloser{fileHandle: fileHandle, writer: writer}, nil
+}
+
+func (g *GzipWriteCloser) Write(p []byte) (int, error) {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	n, err := g.writer.Write(p)
+	if err != nil {
+		return n, fmt.Errorf("failed to write to gzip writer: %w", err)
+	}
+	return n, nil
+}
+
+// Flush writes any pending compressed data to the file handle
+func (g *GzipWriteCloser) Flush() error {
+	g.lock.Lock()
+	defer g.lock.Unlock()
+	err := g.writer.Flush()
+	if err != nil {
+		return fmt.Errorf("failed to flush gzip writer: %w", err)
+	}
+	return nil
+}
+
+// Close writes the gzip footer then closes the file handle, the file handle is closed even if that fails
+func (g *GzipWriteCloser) Close() error {
+	g.lock.Lock()
+	gzipErr := g.writer.Close()
+	g.lock.Unlock()
+	err := g.fileHandle.Close()
+	if gzipErr != nil {
+		return fmt.Errorf("failed to close gzip writer: %w", gzipErr)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to close file handle: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+// gzipXFLOffset is the offset of the extra flags in the gzip header which record
+// whether the fastest (4) or smallest (2) compression was used
+const gzipXFLOffset = 8
+
+func gunzip(compressed []byte) string {
+	reader, err := gzip.NewReader(bytes.NewReader(compressed))
+	Expect(err).NotTo(HaveOccurred())
+	contents, err := io.ReadAll(reader)
+	Expect(err).NotTo(HaveOccurred())
+	return string(contents)
+}
+
+var _ = Describe("GzipWriteCloser", func() {
+	var mockedFile *testFile
+
+	BeforeEach(func() {
+		mockedFile = NewTestFile()
+	})
+
+	When("the level is invalid", func() {
+		It("should be rejected", func() {
+			for _, level := range []int{-2, 10} {
+				_, err := callbacks.NewGzipWriteCloser(mockedFile, level)
+				Expect(err).To(MatchError(ContainSubstring("is not between 0 (fastest) and 9 (smallest)")))
+			}
+		})
+	})
+	When("the level is valid", func() {
+		It("should be applied", func() {
+			for level, xfl := range map[int]byte{gzip.BestSpeed: 4, gzip.BestCompression: 2} {
+				file := NewTestFile()
+				gzipHandle, err := callbacks.NewGzipWriteCloser(file, level)
+				Expect(err).NotTo(HaveOccurred())
+				callback := callbacks.NewFileCallback(gzipHandle, callbacks.Raw)
+				Expect(callback.Call(&testOutputType{Msg: "compressed"}, "testOut")).To(Succeed())
+				Expect(callback.CleanUp()).To(Succeed())
+				Expect(file.Bytes()[gzipXFLOffset]).To(Equal(xfl))
+				Expect(gunzip(file.Bytes())).To(ContainSubstring(`{"msg":"compressed"}`))
+			}
+		})
+	})
+	When("it is flushed", func() {
+		It("should write out what has been compressed so far", func() {
+			gzipHandle, err := callbacks.NewGzipWriteCloser(mockedFile, 6)
+			Expect(err).NotTo(HaveOccurred())
+			callback := callbacks.NewFileCallback(callbacks.NewBufferedWriteCloser(gzipHandle, 1024), callbacks.Raw)
+			Expect(callback.Call(&testOutputType{Msg: "flushed"}, "testOut")).To(Succeed())
+			Expect(mockedFile.Len()).To(BeZero())
+
+			Expect(callback.Flush()).To(Succeed())
+			reader, err := gzip.NewReader(bytes.NewReader(mockedFile.Bytes()))
+			Expect(err).NotTo(HaveOccurred())
+			// The stream is not finished until it is closed
+			contents, err := io.ReadAll(reader)
+			Expect(err).To(MatchError(io.ErrUnexpectedEOF))
+			Expect(string(contents)).To(ContainSubstring(`{"msg":"flushed"}`))
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(mockedFile.open).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("SetupCallback with a gzip level", func() {
+	var outputFile string
+	BeforeEach(func() {
+		outputDir, err := os.MkdirTemp("", "callback-output")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, outputDir)
+		outputFile = filepath.Join(outputDir, "output.log.gz")
+	})
+	It("should compress the output", func() {
+		callback, err := callbacks.SetupCallback(outputFile, callbacks.AnalyserJSON, false, 0, gzip.BestSpeed)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(callback.Call(&testOutputType{Msg: "new"}, "testOut")).To(Succeed())
+		Expect(callback.CleanUp()).To(Succeed())
+		contents, err := os.ReadFile(outputFile)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(gunzip(contents)).To(Equal("{\"data\":[\"Hello\"],\"id\":\"testOutput\"}\n"))
+	})
+	It("should reject an invalid level without creating the file", func() {
+		_, err := callbacks.SetupCallback(outputFile, callbacks.AnalyserJSON, false, 0, 10)
+		Expect(err).To(HaveOccurred())
+		_, err = os.Stat(outputFile)
+		Expect(os.IsNotExist(err)).To(BeTrue())
+	})
+})
+
+// gzipBenchmarkOutput is similar to the DPLL and GNSS outputs written on every poll
+type gzipBenchmarkOutput struct {
+	Timestamp string  `json:"timestamp"`
+	EECState  string  `json:"eecState"`
+	PPSState  string  `json:"ppsState"`
+	Offset    float64 `json:"offset"`
+	Poll      int     `json:"poll"`
+}
+
+func (o *gzipBenchmarkOutput) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{ID: "dpll/time-error", Data: o}}, nil
+}
+
+// BenchmarkGzipLevels compares the CPU time and compressed bytes per output of each level
+func BenchmarkGzipLevels(b *testing.B) {
+	for _, level := range []int{gzip.NoCompression, gzip.BestSpeed, 6, gzip.BestCompression} {
+		b.Run(fmt.Sprintf("level=%d", level), func(b *testing.B) {
+			fileHandle, err := os.Create(filepath.Join(b.TempDir(), "output.log.gz"))
+			if err != nil {
+				b.Fatal(err)
+			}
+			gzipHandle, err := callbacks.NewGzipWriteCloser(fileHandle, level)
+			if err != nil {
+				b.Fatal(err)
+			}
+			callback := callbacks.NewAnalyserCallback(callbacks.NewBufferedWriteCloser(gzipHandle, 64*1024))
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				output := &gzipBenchmarkOutput{
+					Timestamp: "2023-06-16T11:49:47.0584Z",
+					EECState:  "locked_ho_ack",
+					PPSState:  "locked_ho_ack",
+					Offset:    float64(i%50) - 25.5,
+					Poll:      i,
+				}
+				if err := callback.Call(output, "dpll-info"); err != nil {
+					b.Fatal(err)
+				}
+			}
+			if err := callback.CleanUp(); err != nil {
+				b.Fatal(err)
+			}
+			b.StopTimer()
+			info, err := os.Stat(fileHandle.Name())
+			if err != nil {
+				b.Fatal(err)
+			}
+			b.ReportMetric(float64(info.Size())/float64(b.N), "bytes/output")
+		})
+	}
+}
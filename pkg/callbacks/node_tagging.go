@@ -0,0 +1,69 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import "encoding/json"
+
+// nodeOutput carries the name of the node an output was collected from,
+// the name is written alongside the output by each format
+type nodeOutput struct {
+	source OutputType
+	node   string
+}
+
+// MarshalJSON returns the JSON of the source, the node name is not part of the data
+func (n *nodeOutput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(n.source) //nolint:wrapcheck // the error is wrapped by the formatter
+}
+
+func (n *nodeOutput) GetAnalyserFormat() ([]*AnalyserFormatType, error) {
+	return n.source.GetAnalyserFormat() //nolint:wrapcheck // the error is wrapped by the formatter
+}
+
+func (n *nodeOutput) unwrap() OutputType {
+	return n.source
+}
+
+// outputNode returns the name of the node the output was collected from, looking through any wrapping by callbacks
+func outputNode(output OutputType) (string, bool) {
+	if tagged, ok := output.(*nodeOutput); ok {
+		return tagged.node, true
+	}
+	if wrapped, ok := output.(interface{ unwrap() OutputType }); ok {
+		return outputNode(wrapped.unwrap())
+	}
+	return "", false
+}
+
+// NodeTaggingCallback tags each output with the node it was collected from before passing it on
+// to the wrapped callback, so the outputs of the collections run on several nodes can be told apart
+type NodeTaggingCallback struct {
+	callback Callback
+	node     string
+}
+
+// NewNodeTaggingCallback returns a NodeTaggingCallback which tags the outputs with node
+func NewNodeTaggingCallback(callback Callback, node string) *NodeTaggingCallback {
+	return &NodeTaggingCallback{callback: callback, node: node}
+}
+
+func (c *NodeTaggingCallback) Call(output OutputType, tag string) error {
+	//nolint:wrapcheck // the wrapped callback wraps its errors
+	return c.callback.Call(&nodeOutput{source: output, node: c.node}, tag)
+}
+
+// Flush flushes the wrapped callback if it buffers its output
+func (c *NodeTaggingCallback) Flush() error {
+	if flusher, ok := c.callback.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+func (c *NodeTaggingCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+func (c *NodeTaggingCallback) CleanUp() error {
+	return c.callback.CleanUp() //nolint:wrapcheck // the wrapped callback wraps its errors
+}
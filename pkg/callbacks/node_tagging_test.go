@@ -0,0 +1,43 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var _ = Describe("NodeTaggingCallback", func() {
+	var mockedFile *testFile
+
+	BeforeEach(func() {
+		mockedFile = NewTestFile()
+	})
+
+	It("should write the node alongside the NDJSON line", func() {
+		callback := callbacks.NewNodeTaggingCallback(callbacks.NewFileCallback(mockedFile, callbacks.NDJSON), "node-1")
+		Expect(callback.Call(&testOutputType{Msg: "hello"}, "testOut")).To(Succeed())
+		Expect(mockedFile.String()).To(Equal(
+			"{\"data\":{\"msg\":\"hello\"},\"type\":\"*callbacks_test.testOutputType\"," +
+				"\"tag\":\"testOut\",\"node\":\"node-1\"}\n",
+		))
+	})
+	It("should write the node in each analyser message along with the sequence number", func() {
+		callback := callbacks.NewNodeTaggingCallback(callbacks.NewFileCallback(mockedFile, callbacks.AnalyserJSON), "node-1")
+		output := callbacks.NewSequencedOutput(&testOutputType{Msg: "hello"}, 3)
+		Expect(callback.Call(output, "testOut")).To(Succeed())
+		Expect(mockedFile.String()).To(Equal("{\"data\":[\"Hello\"],\"seq\":3,\"id\":\"testOutput\",\"node\":\"node-1\"}\n"))
+	})
+	It("should add the node to the tag in the raw format", func() {
+		callback := callbacks.NewNodeTaggingCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw), "node-1")
+		Expect(callback.Call(&testOutputType{Msg: "hello"}, "testOut")).To(Succeed())
+		Expect(mockedFile.String()).To(Equal("*callbacks_test.testOutputType:testOut@node-1, {\"msg\":\"hello\"}\n"))
+	})
+	It("should clean up the wrapped callback", func() {
+		callback := callbacks.NewNodeTaggingCallback(callbacks.NewFileCallback(mockedFile, callbacks.NDJSON), "node-1")
+		Expect(callback.CleanUp()).To(Succeed())
+		Expect(mockedFile.open).To(BeFalse())
+	})
+})
@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+const (
+	outputNameTimeFormat  = "2006-01-02T15-04-05"
+	defaultOutputNamePart = "unknown"
+)
+
+var repeatedSeparators = regexp.MustCompile(`-{2,}`)
+
+// sanitizeOutputNamePart lower cases value and replaces anything which is not safe in a
+// file name with a -, underscores are replaced too as they separate the parts of the name
+func sanitizeOutputNamePart(value string) string {
+	part := strings.ToLower(unsafeFileNameChars.ReplaceAllString(value, "-"))
+	part = strings.ReplaceAll(part, "_", "-")
+	part = repeatedSeparators.ReplaceAllString(part, "-")
+	part = strings.Trim(part, "-.")
+	if part == "" {
+		return defaultOutputNamePart
+	}
+	return part
+}
+
+// GetOutputFileName returns a file name for a collection run made up of when it started,
+// the cluster and the collectors e.g. 2024-05-01T12-00-00_ocp_ptp-dpll.jsonl
+func GetOutputFileName(startedAt time.Time, cluster string, collectorNames []string, format OutputFormat) string {
+	collectorParts := make([]string, 0, len(collectorNames))
+	for _, name := range collectorNames {
+		collectorParts = append(collectorParts, sanitizeOutputNamePart(name))
+	}
+	return fmt.Sprintf(
+		"%s_%s_%s%s",
+		startedAt.UTC().Format(outputNameTimeFormat),
+		sanitizeOutputNamePart(cluster),
+		sanitizeOutputNamePart(strings.Join(collectorParts, "-")),
+		fileExtension(format),
+	)
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var _ = Describe("GetOutputFileName", func() {
+	startedAt := time.Date(2024, time.May, 1, 12, 0, 0, 0, time.UTC)
+
+	When("given fixed inputs", func() {
+		It("should embed the time, cluster and collectors", func() {
+			name := callbacks.GetOutputFileName(startedAt, "ocp", []string{"PTP", "DPLL"}, callbacks.Raw)
+			Expect(name).To(Equal("2024-05-01T12-00-00_ocp_ptp-dpll.log"))
+		})
+		It("should use the jsonl extension for the analyser format", func() {
+			name := callbacks.GetOutputFileName(startedAt, "ocp", []string{"GNSS"}, callbacks.AnalyserJSON)
+			Expect(name).To(Equal("2024-05-01T12-00-00_ocp_gnss.jsonl"))
+		})
+		It("should use UTC for the time", func() {
+			zone := time.FixedZone("UTC+2", 2*60*60)
+			name := callbacks.GetOutputFileName(startedAt.In(zone), "ocp", []string{"PTP"}, callbacks.Raw)
+			Expect(name).To(Equal("2024-05-01T12-00-00_ocp_ptp.log"))
+		})
+	})
+	When("the inputs contain characters which are not safe in file names", func() {
+		It("should replace them", func() {
+			name := callbacks.GetOutputFileName(
+				startedAt,
+				"api.My_Cluster.example.com:6443/../",
+				[]string{"Dev Info", "GNSS"},
+				callbacks.Raw,
+			)
+			Expect(name).To(Equal("2024-05-01T12-00-00_api.my-cluster.example.com-6443_dev-info-gnss.log"))
+		})
+		It("should use a placeholder for empty parts", func() {
+			name := callbacks.GetOutputFileName(startedAt, "", []string{}, callbacks.Raw)
+			Expect(name).To(Equal("2024-05-01T12-00-00_unknown_unknown.log"))
+		})
+	})
+})
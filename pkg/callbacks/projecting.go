@@ -0,0 +1,205 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// fieldTree holds the selected paths split on "." e.g. navClock.timeAcc,
+// a node without children selects the whole value
+type fieldTree map[string]fieldTree
+
+func newFieldTree(paths []string) fieldTree {
+	tree := make(fieldTree)
+	for _, path := range paths {
+		node := tree
+		for _, key := range strings.Split(path, ".") {
+			child, ok := node[key]
+			if !ok {
+				child = make(fieldTree)
+				node[key] = child
+			}
+			node = child
+		}
+	}
+	return tree
+}
+
+// project returns the parts of the decoded JSON value selected by the tree
+// and false if nothing was selected. A path is applied to each item of an array.
+func (tree fieldTree) project(value any) (any, bool) {
+	if len(tree) == 0 {
+		return value, true
+	}
+	switch v := value.(type) {
+	case map[string]any:
+		projected := make(map[string]any)
+		for key, child := range tree {
+			if field, ok := v[key]; ok {
+				if selected, ok := child.project(field); ok {
+					projected[key] = selected
+				}
+			}
+		}
+		return projected, len(projected) > 0
+	case []any:
+		projected := make([]any, 0, len(v))
+		for _, item := range v {
+			if selected, ok := tree.project(item); ok {
+				projected = append(projected, selected)
+			}
+		}
+		return projected, len(projected) > 0
+	default:
+		return nil, false
+	}
+}
+
+// decodeJSON returns the JSON encoding of value decoded into maps, slices and json.Numbers
+func decodeJSON(value any) (any, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal %T %w", value, err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	// Keep numbers as they were written rather than converting them to floats
+	decoder.UseNumber()
+	var decoded any
+	err = decoder.Decode(&decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %T %w", value, err)
+	}
+	return decoded, nil
+}
+
+// projectJSON returns the fields selected by the tree from the JSON encoding of value
+func (tree fieldTree) projectJSON(value any) (map[string]any, error) {
+	decoded, err := decodeJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("projection failed: %w", err)
+	}
+	projected, ok := tree.project(decoded)
+	if !ok {
+		return map[string]any{}, nil
+	}
+	if fields, isObject := projected.(map[string]any); isObject {
+		return fields, nil
+	}
+	return map[string]any{}, nil
+}
+
+// ParseFieldSelections parses selections in the form datatype=path,path e.g. gnss/time-error=terror,ferror
+// into the paths selected for each datatype. A datatype can be given more than once.
+func ParseFieldSelections(selections []string) (map[string][]string, error) {
+	fields := make(map[string][]string)
+	for _, selection := range selections {
+		datatype, paths, found := strings.Cut(selection, "=")
+		if !found || datatype == "" || paths == "" {
+			return fields, fmt.Errorf("field selection %q is not in the form datatype=path,path", selection)
+		}
+		for _, path := range strings.Split(paths, ",") {
+			if path == "" || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") || strings.Contains(path, "..") {
+				return fields, fmt.Errorf("field selection %q has an invalid path %q", selection, path)
+			}
+			fields[datatype] = append(fields[datatype], path)
+		}
+	}
+	return fields, nil
+}
+
+// projectedOutput is written in place of an output which has selected fields
+type projectedOutput struct {
+	source OutputType
+	fields map[string]fieldTree
+	tag    string
+}
+
+// MarshalJSON returns the fields selected for the tag
+func (p *projectedOutput) MarshalJSON() ([]byte, error) {
+	tree, ok := p.fields[p.tag]
+	if !ok {
+		return json.Marshal(p.source) //nolint:wrapcheck // the error is wrapped by the formatter
+	}
+	projected, err := tree.projectJSON(p.source)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(projected) //nolint:wrapcheck // the error is wrapped by the formatter
+}
+
+// GetAnalyserFormat returns the messages of the source with the data reduced to the fields selected for their ID
+func (p *projectedOutput) GetAnalyserFormat() ([]*AnalyserFormatType, error) {
+	messages, err := p.source.GetAnalyserFormat()
+	if err != nil {
+		return messages, err //nolint:wrapcheck // the error is wrapped by the formatter
+	}
+	projectedMessages := make([]*AnalyserFormatType, 0, len(messages))
+	for _, message := range messages {
+		tree, ok := p.fields[message.ID]
+		if !ok {
+			projectedMessages = append(projectedMessages, message)
+			continue
+		}
+		data, err := tree.projectJSON(message.Data)
+		if err != nil {
+			return projectedMessages, err
+		}
+		projectedMessages = append(projectedMessages, &AnalyserFormatType{ID: message.ID, Data: data})
+	}
+	return projectedMessages, nil
+}
+
+func (p *projectedOutput) unwrap() OutputType {
+	return p.source
+}
+
+// outputTypeName returns the go type of the output, looking through any wrapping by callbacks
+func outputTypeName(output OutputType) string {
+	if wrapped, ok := output.(interface{ unwrap() OutputType }); ok {
+		return outputTypeName(wrapped.unwrap())
+	}
+	return fmt.Sprintf("%T", output)
+}
+
+// ProjectingCallback reduces each output to the fields selected for its datatype before
+// passing it on to the wrapped callback. The datatype is the tag of the output or for the
+// analyser format the ID of each message. Fields which are not selected are dropped and
+// selected fields which are missing are omitted. Datatypes without a selection are unchanged.
+type ProjectingCallback struct {
+	callback Callback
+	fields   map[string]fieldTree
+}
+
+// NewProjectingCallback returns a ProjectingCallback selecting the paths in fields for each datatype
+func NewProjectingCallback(callback Callback, fields map[string][]string) *ProjectingCallback {
+	trees := make(map[string]fieldTree, len(fields))
+	for datatype, paths := range fields {
+		trees[datatype] = newFieldTree(paths)
+	}
+	return &ProjectingCallback{callback: callback, fields: trees}
+}
+
+func (c *ProjectingCallback) Call(output OutputType, tag string) error {
+	//nolint:wrapcheck // the wrapped callback wraps its errors
+	return c.callback.Call(&projectedOutput{source: output, fields: c.fields, tag: tag}, tag)
+}
+
+// Flush flushes the wrapped callback if it buffers its output
+func (c *ProjectingCallback) Flush() error {
+	if flusher, ok := c.callback.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+func (c *ProjectingCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+func (c *ProjectingCallback) CleanUp() error {
+	return c.callback.CleanUp() //nolint:wrapcheck // the wrapped callback wraps its errors
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+var _ = Describe("ProjectingCallback", func() {
+	var (
+		mockedFile *testFile
+		gpsDetails *devices.GPSDetails
+	)
+
+	BeforeEach(func() {
+		mockedFile = NewTestFile()
+		gpsDetails = &devices.GPSDetails{
+			NavStatus: devices.GPSNavStatus{Timestamp: "2023-06-16T11:49:47.0584Z", Flags: "0xdd", GPSFix: 3},
+			NavClock:  devices.GPSNavClock{Timestamp: "2023-06-16T11:49:47.0584Z", TimeAcc: 5, FreqAcc: 164},
+			AntennaDetails: []*devices.GPSAntennaDetails{
+				{Timestamp: "2023-06-16T11:49:47.0584Z", BlockID: 0, Status: 2, Power: 1},
+			},
+		}
+	})
+
+	When("fields are selected for the tag", func() {
+		It("should only write the selected fields", func() {
+			callback := callbacks.NewProjectingCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.NDJSON),
+				map[string][]string{"gpsNav": {"navClock.timeAcc", "navClock.freqAcc", "navClock.missing"}},
+			)
+			Expect(callback.Call(gpsDetails, "gpsNav")).To(Succeed())
+
+			line := struct {
+				Data any    `json:"data"`
+				Type string `json:"type"`
+			}{}
+			Expect(json.Unmarshal(mockedFile.Bytes(), &line)).To(Succeed())
+			Expect(line.Type).To(Equal("*devices.GPSDetails"))
+			Expect(line.Data).To(Equal(map[string]any{
+				"navClock": map[string]any{"timeAcc": float64(5), "freqAcc": float64(164)},
+			}))
+		})
+		It("should apply the path to each item of an array", func() {
+			callback := callbacks.NewProjectingCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.Raw),
+				map[string][]string{"gpsNav": {"antennaDetails.power"}},
+			)
+			Expect(callback.Call(gpsDetails, "gpsNav")).To(Succeed())
+			Expect(mockedFile.String()).To(Equal(
+				"*devices.GPSDetails:gpsNav, {\"antennaDetails\":[{\"power\":1}]}\n",
+			))
+		})
+	})
+	When("no fields are selected for the tag", func() {
+		It("should write the whole output", func() {
+			callback := callbacks.NewProjectingCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.Raw),
+				map[string][]string{"other": {"timestamp"}},
+			)
+			Expect(callback.Call(&testOutputType{Msg: "Hello"}, "testOut")).To(Succeed())
+			Expect(mockedFile.String()).To(Equal("*callbacks_test.testOutputType:testOut, {\"msg\":\"Hello\"}\n"))
+		})
+	})
+	When("fields are selected for an analyser message", func() {
+		It("should reduce the data of that message", func() {
+			callback := callbacks.NewProjectingCallback(
+				callbacks.NewAnalyserCallback(mockedFile),
+				map[string][]string{"gnss/time-error": {"terror", "ferror"}},
+			)
+			Expect(callback.Call(gpsDetails, "gpsNav")).To(Succeed())
+
+			lines := strings.Split(strings.TrimSuffix(mockedFile.String(), "\n"), "\n")
+			Expect(lines).To(HaveLen(2))
+			Expect(lines[0]).To(Equal(`{"data":{"ferror":164,"terror":5},"id":"gnss/time-error"}`))
+			Expect(lines[1]).To(ContainSubstring(`"id":"gnss/rf-mon"`))
+			Expect(lines[1]).To(ContainSubstring(`"blockId":0`))
+		})
+	})
+})
+
+var _ = Describe("ParseFieldSelections", func() {
+	It("should collect the paths for each datatype", func() {
+		fields, err := callbacks.ParseFieldSelections([]string{
+			"gnss/time-error=terror,ferror",
+			"dpll-info-fs=terror",
+			"gnss/time-error=timestamp",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(fields).To(Equal(map[string][]string{
+			"gnss/time-error": {"terror", "ferror", "timestamp"},
+			"dpll-info-fs":    {"terror"},
+		}))
+	})
+	It("should reject malformed selections", func() {
+		for _, selection := range []string{"terror", "=terror", "gpsNav=", "gpsNav=navClock..timeAcc", "gpsNav=a,,b"} {
+			_, err := callbacks.ParseFieldSelections([]string{selection})
+			Expect(err).To(HaveOccurred(), selection)
+		}
+	})
+})
@@ -0,0 +1,157 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+const (
+	// redactedPrefix marks a value which has been replaced by its hash
+	redactedPrefix = "redacted:"
+	// redactedHashBytes is how much of the hash is kept, enough to tell values apart
+	redactedHashBytes = 8
+)
+
+// redactValue returns a hash of the JSON encoding of value, the same value
+// always gives the same hash so redacted values can still be compared
+func redactValue(value any) (string, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal %T for redaction %w", value, err)
+	}
+	sum := sha256.Sum256(encoded)
+	return redactedPrefix + hex.EncodeToString(sum[:redactedHashBytes]), nil
+}
+
+// redact replaces the values selected by the tree in the decoded JSON value with their hash,
+// a path is applied to each item of an array and paths which are missing are ignored
+func (tree fieldTree) redact(value any) (any, error) {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, child := range tree {
+			field, ok := v[key]
+			if !ok {
+				continue
+			}
+			var err error
+			if len(child) == 0 {
+				v[key], err = redactValue(field)
+			} else {
+				v[key], err = child.redact(field)
+			}
+			if err != nil {
+				return v, err
+			}
+		}
+		return v, nil
+	case []any:
+		for i, item := range v {
+			redacted, err := tree.redact(item)
+			if err != nil {
+				return v, err
+			}
+			v[i] = redacted
+		}
+		return v, nil
+	default:
+		return value, nil
+	}
+}
+
+// redactJSON returns the JSON encoding of value with the fields selected by the tree redacted
+func (tree fieldTree) redactJSON(value any) (any, error) {
+	decoded, err := decodeJSON(value)
+	if err != nil {
+		return nil, fmt.Errorf("redaction failed: %w", err)
+	}
+	return tree.redact(decoded)
+}
+
+// redactedOutput is written in place of an output which has redacted fields
+type redactedOutput struct {
+	source OutputType
+	fields map[string]fieldTree
+	tag    string
+}
+
+// MarshalJSON returns the source with the fields redacted for the tag replaced by their hash
+func (r *redactedOutput) MarshalJSON() ([]byte, error) {
+	tree, ok := r.fields[r.tag]
+	if !ok {
+		return json.Marshal(r.source) //nolint:wrapcheck // the error is wrapped by the formatter
+	}
+	redacted, err := tree.redactJSON(r.source)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(redacted) //nolint:wrapcheck // the error is wrapped by the formatter
+}
+
+// GetAnalyserFormat returns the messages of the source with the fields redacted for their ID replaced by their hash
+func (r *redactedOutput) GetAnalyserFormat() ([]*AnalyserFormatType, error) {
+	messages, err := r.source.GetAnalyserFormat()
+	if err != nil {
+		return messages, err //nolint:wrapcheck // the error is wrapped by the formatter
+	}
+	redactedMessages := make([]*AnalyserFormatType, 0, len(messages))
+	for _, message := range messages {
+		tree, ok := r.fields[message.ID]
+		if !ok {
+			redactedMessages = append(redactedMessages, message)
+			continue
+		}
+		data, err := tree.redactJSON(message.Data)
+		if err != nil {
+			return redactedMessages, err
+		}
+		redactedMessages = append(redactedMessages, &AnalyserFormatType{ID: message.ID, Data: data})
+	}
+	return redactedMessages, nil
+}
+
+func (r *redactedOutput) unwrap() OutputType {
+	return r.source
+}
+
+// RedactingCallback replaces the values of the fields selected for the datatype of each output
+// with a hash before passing it on to the wrapped callback so that captures can be shared.
+// The datatype is the tag of the output or for the analyser format the ID of each message.
+// A value always redacts to the same hash so it can still be compared across lines.
+type RedactingCallback struct {
+	callback Callback
+	fields   map[string]fieldTree
+}
+
+// NewRedactingCallback returns a RedactingCallback redacting the paths in fields for each datatype
+func NewRedactingCallback(callback Callback, fields map[string][]string) *RedactingCallback {
+	trees := make(map[string]fieldTree, len(fields))
+	for datatype, paths := range fields {
+		trees[datatype] = newFieldTree(paths)
+	}
+	return &RedactingCallback{callback: callback, fields: trees}
+}
+
+func (c *RedactingCallback) Call(output OutputType, tag string) error {
+	//nolint:wrapcheck // the wrapped callback wraps its errors
+	return c.callback.Call(&redactedOutput{source: output, fields: c.fields, tag: tag}, tag)
+}
+
+// Flush flushes the wrapped callback if it buffers its output
+func (c *RedactingCallback) Flush() error {
+	if flusher, ok := c.callback.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+func (c *RedactingCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+func (c *RedactingCallback) CleanUp() error {
+	return c.callback.CleanUp() //nolint:wrapcheck // the wrapped callback wraps its errors
+}
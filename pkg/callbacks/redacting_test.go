@@ -0,0 +1,93 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"encoding/json"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+var _ = Describe("RedactingCallback", func() {
+	var mockedFile *testFile
+
+	BeforeEach(func() {
+		mockedFile = NewTestFile()
+	})
+
+	// firmwareVersions returns the firmwareVersion written on each NDJSON line
+	firmwareVersions := func() []string {
+		versions := make([]string, 0)
+		for _, line := range strings.Split(strings.TrimSuffix(mockedFile.String(), "\n"), "\n") {
+			decoded := struct {
+				Data map[string]any `json:"data"`
+			}{}
+			Expect(json.Unmarshal([]byte(line), &decoded)).To(Succeed())
+			version, ok := decoded.Data["firmwareVersion"].(string)
+			Expect(ok).To(BeTrue())
+			versions = append(versions, version)
+		}
+		return versions
+	}
+
+	When("a field is redacted for the tag", func() {
+		It("should hash the value consistently across lines", func() {
+			callback := callbacks.NewRedactingCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.NDJSON),
+				map[string][]string{"device-info": {"firmwareVersion"}},
+			)
+			for _, firmware := range []string{"4.20 0x8001778b 1.3346.0", "4.20 0x8001778b 1.3346.0", "4.30 0x80019da5 1.3415.0"} {
+				devInfo := &devices.PTPDeviceInfo{VendorID: "0x8086", FirmwareVersion: firmware}
+				Expect(callback.Call(devInfo, "device-info")).To(Succeed())
+			}
+			Expect(mockedFile.String()).NotTo(ContainSubstring("0x8001778b"))
+			Expect(mockedFile.String()).To(ContainSubstring(`"vendorId":"0x8086"`))
+
+			versions := firmwareVersions()
+			Expect(versions).To(HaveLen(3))
+			Expect(versions[0]).To(HavePrefix("redacted:"))
+			Expect(versions[1]).To(Equal(versions[0]))
+			Expect(versions[2]).To(HavePrefix("redacted:"))
+			Expect(versions[2]).NotTo(Equal(versions[0]))
+		})
+		It("should apply the path to each item of an array", func() {
+			callback := callbacks.NewRedactingCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.Raw),
+				map[string][]string{"gpsNav": {"antennaDetails.power", "missing"}},
+			)
+			gpsDetails := &devices.GPSDetails{
+				AntennaDetails: []*devices.GPSAntennaDetails{{BlockID: 0, Power: 1}, {BlockID: 1, Power: 1}},
+			}
+			Expect(callback.Call(gpsDetails, "gpsNav")).To(Succeed())
+			Expect(mockedFile.String()).To(ContainSubstring(`"blockId":1`))
+			Expect(strings.Count(mockedFile.String(), `"power":"redacted:`)).To(Equal(2))
+		})
+	})
+	When("a field is redacted for an analyser message", func() {
+		It("should hash the value in the data of that message", func() {
+			callback := callbacks.NewRedactingCallback(
+				callbacks.NewAnalyserCallback(mockedFile),
+				map[string][]string{devices.DeviceInfoID: {"firmwareVersion"}},
+			)
+			devInfo := &devices.PTPDeviceInfo{VendorID: "0x8086", FirmwareVersion: "4.20 0x8001778b 1.3346.0"}
+			Expect(callback.Call(devInfo, "device-info")).To(Succeed())
+			Expect(mockedFile.String()).NotTo(ContainSubstring("0x8001778b"))
+			Expect(firmwareVersions()[0]).To(HavePrefix("redacted:"))
+		})
+	})
+	When("nothing is redacted for the tag", func() {
+		It("should write the whole output", func() {
+			callback := callbacks.NewRedactingCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.Raw),
+				map[string][]string{"other": {"msg"}},
+			)
+			Expect(callback.Call(&testOutputType{Msg: "Hello"}, "testOut")).To(Succeed())
+			Expect(mockedFile.String()).To(Equal("*callbacks_test.testOutputType:testOut, {\"msg\":\"Hello\"}\n"))
+		})
+	})
+})
@@ -0,0 +1,98 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"errors"
+	"strings"
+	"sync"
+)
+
+// ringBuffer holds the most recent lines written for a single tag
+type ringBuffer struct {
+	lines []string
+	next  int
+	full  bool
+}
+
+func (r *ringBuffer) push(line string) {
+	r.lines[r.next] = line
+	r.next = (r.next + 1) % len(r.lines)
+	if r.next == 0 {
+		r.full = true
+	}
+}
+
+// contents returns a copy of the lines held ordered from oldest to newest
+func (r *ringBuffer) contents() []string {
+	if !r.full {
+		return append([]string{}, r.lines[:r.next]...)
+	}
+	lines := make([]string, 0, len(r.lines))
+	lines = append(lines, r.lines[r.next:]...)
+	return append(lines, r.lines[:r.next]...)
+}
+
+// RingBufferCallback keeps the most recent lines for each tag in memory
+// so they can be read by a program embedding the collectors
+type RingBufferCallback struct {
+	buffers map[string]*ringBuffer
+	lock    sync.RWMutex
+	size    int
+	format  OutputFormat
+}
+
+// NewRingBufferCallback returns a RingBufferCallback which retains up to size lines per tag
+func NewRingBufferCallback(size int, format OutputFormat) (*RingBufferCallback, error) {
+	if size < 1 {
+		return nil, errors.New("ring buffer size must be at least 1")
+	}
+	return &RingBufferCallback{
+		buffers: make(map[string]*ringBuffer),
+		size:    size,
+		format:  format,
+	}, nil
+}
+
+func (c *RingBufferCallback) Call(output OutputType, tag string) error {
+	formattedOutput, err := getFormattedOutput(c, output, tag)
+	if err != nil {
+		return err
+	}
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	buffer, ok := c.buffers[tag]
+	if !ok {
+		buffer = &ringBuffer{lines: make([]string, c.size)}
+		c.buffers[tag] = buffer
+	}
+	// The analyser format can produce more than one line for each output
+	for _, line := range strings.Split(string(formattedOutput), "\n") {
+		buffer.push(line)
+	}
+	return nil
+}
+
+func (c *RingBufferCallback) getFormat() OutputFormat {
+	return c.format
+}
+
+// Snapshot returns a copy of the lines held for each tag ordered from oldest to newest
+func (c *RingBufferCallback) Snapshot() map[string][]string {
+	c.lock.RLock()
+	defer c.lock.RUnlock()
+	snapshot := make(map[string][]string, len(c.buffers))
+	for tag, buffer := range c.buffers {
+		snapshot[tag] = buffer.contents()
+	}
+	return snapshot
+}
+
+// CleanUp discards the lines which have been retained
+func (c *RingBufferCallback) CleanUp() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.buffers = make(map[string]*ringBuffer)
+	return nil
+}
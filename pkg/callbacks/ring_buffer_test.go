@@ -0,0 +1,112 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"fmt"
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var _ = Describe("RingBufferCallback", func() {
+	When("created with a size less than one", func() {
+		It("should return an error", func() {
+			_, err := callbacks.NewRingBufferCallback(0, callbacks.Raw)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
+	When("more lines than the size are written", func() {
+		It("should keep only the most recent lines for each tag", func() {
+			callback, err := callbacks.NewRingBufferCallback(2, callbacks.Raw)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(callback.Call(&testOutputType{Msg: "first"}, "gnss-data")).To(Succeed())
+			Expect(callback.Call(&testOutputType{Msg: "second"}, "gnss-data")).To(Succeed())
+			Expect(callback.Call(&testOutputType{Msg: "third"}, "gnss-data")).To(Succeed())
+			Expect(callback.Call(&testOutputType{Msg: "other"}, "dpll-info")).To(Succeed())
+
+			snapshot := callback.Snapshot()
+			Expect(snapshot).To(HaveLen(2))
+			Expect(snapshot["gnss-data"]).To(HaveLen(2))
+			Expect(snapshot["gnss-data"][0]).To(ContainSubstring("second"))
+			Expect(snapshot["gnss-data"][1]).To(ContainSubstring("third"))
+			Expect(snapshot["dpll-info"]).To(HaveLen(1))
+			Expect(snapshot["dpll-info"][0]).To(ContainSubstring("other"))
+		})
+	})
+
+	When("a snapshot is modified", func() {
+		It("should not change the retained lines", func() {
+			callback, err := callbacks.NewRingBufferCallback(1, callbacks.AnalyserJSON)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callback.Call(&testOutputType{Msg: "first"}, "gnss-data")).To(Succeed())
+
+			snapshot := callback.Snapshot()
+			snapshot["gnss-data"][0] = "changed"
+			Expect(callback.Snapshot()["gnss-data"]).To(Equal([]string{"{\"data\":[\"Hello\"],\"id\":\"testOutput\"}"}))
+		})
+	})
+
+	When("cleaned up", func() {
+		It("should discard the retained lines", func() {
+			callback, err := callbacks.NewRingBufferCallback(1, callbacks.Raw)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callback.Call(&testOutputType{Msg: "first"}, "gnss-data")).To(Succeed())
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(callback.Snapshot()).To(BeEmpty())
+		})
+	})
+
+	When("Call and Snapshot are used concurrently", func() {
+		It("should never return more than size lines per tag", func() {
+			const (
+				size    = 8
+				writers = 8
+				calls   = 200
+			)
+			callback, err := callbacks.NewRingBufferCallback(size, callbacks.Raw)
+			Expect(err).NotTo(HaveOccurred())
+
+			var wg sync.WaitGroup
+			for i := 0; i < writers; i++ {
+				wg.Add(1)
+				go func(writer int) {
+					defer GinkgoRecover()
+					defer wg.Done()
+					tag := fmt.Sprintf("tag-%d", writer%2)
+					for j := 0; j < calls; j++ {
+						Expect(callback.Call(&testOutputType{Msg: fmt.Sprintf("%d-%d", writer, j)}, tag)).To(Succeed())
+					}
+				}(i)
+			}
+			done := make(chan struct{})
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+
+		readLoop:
+			for {
+				select {
+				case <-done:
+					break readLoop
+				default:
+					for _, lines := range callback.Snapshot() {
+						Expect(len(lines)).To(BeNumerically("<=", size))
+					}
+				}
+			}
+
+			snapshot := callback.Snapshot()
+			Expect(snapshot).To(HaveLen(2))
+			for _, lines := range snapshot {
+				Expect(lines).To(HaveLen(size))
+			}
+		})
+	})
+})
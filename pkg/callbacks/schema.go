@@ -0,0 +1,282 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Schema is a JSON schema, only the type, properties, required,
+// additionalProperties and items keywords are generated and validated
+type Schema map[string]any
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+	timeType          = reflect.TypeOf(time.Time{})
+
+	schemaCache     = make(map[reflect.Type]Schema)
+	schemaCacheLock sync.Mutex
+)
+
+// GetSchema returns the schema for the JSON encoding of the type of value,
+// it is generated from the type and its json tags the first time it is requested.
+// A MarshalJSON method on the type of value itself is ignored so that
+// an encoding which has drifted from the struct tags does not match
+func GetSchema(value any) Schema {
+	valueType := reflect.TypeOf(value)
+	schemaCacheLock.Lock()
+	defer schemaCacheLock.Unlock()
+	schema, ok := schemaCache[valueType]
+	if !ok {
+		visiting := make(map[reflect.Type]bool)
+		if valueType != nil && valueType.Kind() == reflect.Pointer {
+			schema = nullable(generateKindSchema(valueType.Elem(), visiting))
+		} else {
+			schema = generateKindSchema(valueType, visiting)
+		}
+		schemaCache[valueType] = schema
+	}
+	return schema
+}
+
+func nullable(schema Schema) Schema {
+	if jsonType, ok := schema["type"].(string); ok {
+		schema["type"] = []string{jsonType, "null"}
+	}
+	return schema
+}
+
+// generateSchema returns the schema for t, visiting holds the struct types
+// currently being generated so that recursive types are not expanded forever
+func generateSchema(t reflect.Type, visiting map[reflect.Type]bool) Schema {
+	switch {
+	case t == nil:
+		return Schema{}
+	case t == timeType:
+		return Schema{"type": "string"}
+	case t.Implements(jsonMarshalerType), reflect.PointerTo(t).Implements(jsonMarshalerType):
+		// The encoding is not known from the type
+		return Schema{}
+	case t.Implements(textMarshalerType), reflect.PointerTo(t).Implements(textMarshalerType):
+		return Schema{"type": "string"}
+	}
+	return generateKindSchema(t, visiting)
+}
+
+// generateKindSchema returns the schema for t from its kind, nil slices and maps are encoded as null
+func generateKindSchema(t reflect.Type, visiting map[reflect.Type]bool) Schema {
+	if t == nil {
+		return Schema{}
+	}
+	switch t.Kind() { //nolint:exhaustive // the remaining kinds can not be encoded as JSON
+	case reflect.Bool:
+		return Schema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return Schema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return Schema{"type": "number"}
+	case reflect.String:
+		return Schema{"type": "string"}
+	case reflect.Pointer:
+		return nullable(generateSchema(t.Elem(), visiting))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return Schema{"type": "string"}
+		}
+		return nullable(Schema{"type": "array", "items": generateSchema(t.Elem(), visiting)})
+	case reflect.Array:
+		return Schema{"type": "array", "items": generateSchema(t.Elem(), visiting)}
+	case reflect.Map:
+		return nullable(Schema{"type": "object", "additionalProperties": generateSchema(t.Elem(), visiting)})
+	case reflect.Struct:
+		if visiting[t] {
+			return Schema{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+		return generateStructSchema(t, visiting)
+	default:
+		return Schema{}
+	}
+}
+
+// generateStructSchema follows the rules used by encoding/json for field names,
+// omitempty fields are not required and embedded structs are flattened
+func generateStructSchema(t reflect.Type, visiting map[reflect.Type]bool) Schema {
+	properties := make(map[string]any)
+	required := make([]string, 0)
+	addStructFields(t, visiting, properties, &required)
+	sort.Strings(required)
+	return Schema{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+func addStructFields(t reflect.Type, visiting map[reflect.Type]bool, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options, _ := strings.Cut(tag, ",")
+		fieldType := field.Type
+		if field.Anonymous && name == "" {
+			if fieldType.Kind() == reflect.Pointer {
+				fieldType = fieldType.Elem()
+			}
+			if fieldType.Kind() == reflect.Struct {
+				addStructFields(fieldType, visiting, properties, required)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		schema := generateSchema(fieldType, visiting)
+		if hasTagOption(options, "string") {
+			schema = Schema{"type": "string"}
+		}
+		properties[name] = schema
+		if !hasTagOption(options, "omitempty") {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func hasTagOption(options, option string) bool {
+	for _, o := range strings.Split(options, ",") {
+		if o == option {
+			return true
+		}
+	}
+	return false
+}
+
+// ValidateJSON checks the encoded value against schema,
+// the error names the path of the first value which does not match
+func ValidateJSON(schema Schema, encoded []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var value any
+	err := decoder.Decode(&value)
+	if err != nil {
+		return fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	return validateValue(schema, value, "$")
+}
+
+func jsonTypeOf(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return "integer"
+		}
+		return "number"
+	case string:
+		return "string"
+	case []any:
+		return "array"
+	default:
+		return "object"
+	}
+}
+
+func schemaTypes(schema Schema) []string {
+	switch jsonType := schema["type"].(type) {
+	case string:
+		return []string{jsonType}
+	case []string:
+		return jsonType
+	default:
+		return nil
+	}
+}
+
+func typeMatches(allowed []string, actual string) bool {
+	for _, jsonType := range allowed {
+		if jsonType == actual || (jsonType == "number" && actual == "integer") {
+			return true
+		}
+	}
+	return false
+}
+
+func validateValue(schema Schema, value any, path string) error {
+	allowed := schemaTypes(schema)
+	if allowed == nil {
+		return nil
+	}
+	actual := jsonTypeOf(value)
+	if !typeMatches(allowed, actual) {
+		return fmt.Errorf("%s: expected %s but got %s", path, strings.Join(allowed, " or "), actual)
+	}
+	switch v := value.(type) {
+	case []any:
+		if items, ok := schema["items"].(Schema); ok {
+			for i, item := range v {
+				if err := validateValue(items, item, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+		}
+	case map[string]any:
+		return validateObject(schema, v, path)
+	}
+	return nil
+}
+
+func validateObject(schema Schema, object map[string]any, path string) error {
+	properties, _ := schema["properties"].(map[string]any)
+	required, _ := schema["required"].([]string)
+	for _, name := range required {
+		if _, ok := object[name]; !ok {
+			return fmt.Errorf("%s: missing required property %q", path, name)
+		}
+	}
+	names := make([]string, 0, len(object))
+	for name := range object {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		propertySchema, isProperty := properties[name].(Schema)
+		if !isProperty {
+			switch additional := schema["additionalProperties"].(type) {
+			case bool:
+				if !additional {
+					return fmt.Errorf("%s: unexpected property %q", path, name)
+				}
+				continue
+			case Schema:
+				propertySchema = additional
+			default:
+				continue
+			}
+		}
+		if err := validateValue(propertySchema, object[name], path+"."+name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
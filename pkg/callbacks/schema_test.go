@@ -0,0 +1,99 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+// malformedOutputType has a MarshalJSON which has drifted from its struct tags
+type malformedOutputType struct {
+	Msg   string `json:"msg"`
+	Count int    `json:"count"`
+}
+
+func (t *malformedOutputType) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]any{"msg": t.Msg, "count": "not a number"}) //nolint:wrapcheck // test only
+}
+
+func (t *malformedOutputType) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{ID: "malformed", Data: t.Msg}}, nil
+}
+
+var _ = Describe("Schema", func() {
+	When("a record conforms to its schema", func() {
+		It("should validate", func() {
+			offset := 1.5
+			status := &devices.DevFilesystemDPLLInfo{
+				Timestamp:         "2023-06-16T11:49:47.0584Z",
+				EECState:          "locked",
+				PPSState:          "locked",
+				PPSOffset:         -0.39,
+				PPSOffsetSmoothed: &offset,
+			}
+			encoded, err := json.Marshal(status)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callbacks.ValidateJSON(callbacks.GetSchema(status), encoded)).To(Succeed())
+		})
+	})
+	When("a record does not conform to its schema", func() {
+		It("should name the mismatched value", func() {
+			schema := callbacks.GetSchema(&devices.DevFilesystemDPLLInfo{})
+			err := callbacks.ValidateJSON(schema, []byte(
+				`{"timestamp":"2023-06-16T11:49:47.0584Z","eecstate":"locked","state":"locked",`+
+					`"terrorRaw":"","terror":"x","terrorNs":0}`,
+			))
+			Expect(err).To(MatchError(`$.terror: expected number but got string`))
+		})
+		It("should report missing and unexpected properties", func() {
+			schema := callbacks.GetSchema(&devices.DevFilesystemDPLLInfo{})
+			err := callbacks.ValidateJSON(schema, []byte(`{"timestamp":"","eecstate":"","state":"","terror":0,"terrorNs":0}`))
+			Expect(err).To(MatchError(`$: missing required property "terrorRaw"`))
+			err = callbacks.ValidateJSON(schema, []byte(
+				`{"timestamp":"","eecstate":"","state":"","terrorRaw":"","terror":0,"terrorNs":0,"extra":1}`,
+			))
+			Expect(err).To(MatchError(`$: unexpected property "extra"`))
+		})
+	})
+})
+
+var _ = Describe("ValidatingCallback", func() {
+	var mockedFile *testFile
+
+	BeforeEach(func() {
+		mockedFile = NewTestFile()
+	})
+
+	When("the output conforms to its schema", func() {
+		It("should write the output", func() {
+			callback := callbacks.NewValidatingCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw))
+			err := callback.Call(&testOutputType{Msg: "Hello"}, "test")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(mockedFile.String()).To(Equal("*callbacks_test.testOutputType:test, {\"msg\":\"Hello\"}\n"))
+		})
+	})
+	When("the output does not conform to its schema", func() {
+		It("should fail without writing the output", func() {
+			callback := callbacks.NewValidatingCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw))
+			err := callback.Call(&malformedOutputType{Msg: "Hello", Count: 1}, "test")
+			Expect(err).To(MatchError(
+				"*callbacks_test.malformedOutputType for test does not match its schema: " +
+					"$.count: expected integer but got string",
+			))
+			Expect(mockedFile.Len()).To(Equal(0))
+		})
+	})
+	When("the callback is cleaned up", func() {
+		It("should clean up the wrapped callback", func() {
+			callback := callbacks.NewValidatingCallback(callbacks.NewFileCallback(mockedFile, callbacks.Raw))
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(mockedFile.open).To(BeFalse())
+		})
+	})
+})
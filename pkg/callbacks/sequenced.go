@@ -0,0 +1,78 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// SequenceCounter numbers the outputs of each datatype from 1 so gaps show where outputs were
+// dropped e.g. by the async queue or sampling. It is safe to use from several goroutines
+// and the zero value is ready to use.
+type SequenceCounter struct {
+	counts map[string]uint64
+	lock   sync.Mutex
+}
+
+// Next returns the sequence number of the next output of datatype
+func (s *SequenceCounter) Next(datatype string) uint64 {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	if s.counts == nil {
+		s.counts = make(map[string]uint64)
+	}
+	s.counts[datatype]++
+	return s.counts[datatype]
+}
+
+// Reset restarts the numbering of every datatype
+func (s *SequenceCounter) Reset() {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.counts = make(map[string]uint64)
+}
+
+// sequencedOutput carries the sequence number of an output within its datatype,
+// the number is written alongside the output by the NDJSON and analyser formats
+type sequencedOutput struct {
+	source OutputType
+	seq    uint64
+}
+
+// NewSequencedOutput returns output numbered seq within its datatype
+func NewSequencedOutput(output OutputType, seq uint64) OutputType {
+	return &sequencedOutput{source: output, seq: seq}
+}
+
+// MarshalJSON returns the JSON of the source, the sequence number is not part of the data
+func (s *sequencedOutput) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.source) //nolint:wrapcheck // the error is wrapped by the formatter
+}
+
+func (s *sequencedOutput) GetAnalyserFormat() ([]*AnalyserFormatType, error) {
+	return s.source.GetAnalyserFormat() //nolint:wrapcheck // the error is wrapped by the formatter
+}
+
+func (s *sequencedOutput) unwrap() OutputType {
+	return s.source
+}
+
+// outputSequence returns the sequence number of the output, looking through any wrapping by callbacks
+func outputSequence(output OutputType) (uint64, bool) {
+	if sequenced, ok := output.(*sequencedOutput); ok {
+		return sequenced.seq, true
+	}
+	if wrapped, ok := output.(interface{ unwrap() OutputType }); ok {
+		return outputSequence(wrapped.unwrap())
+	}
+	return 0, false
+}
+
+// unwrapOutput returns the output as it was passed by the collector
+func unwrapOutput(output OutputType) OutputType {
+	if wrapped, ok := output.(interface{ unwrap() OutputType }); ok {
+		return unwrapOutput(wrapped.unwrap())
+	}
+	return output
+}
@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"sync"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var _ = Describe("SequenceCounter", func() {
+	It("should number each datatype independently", func() {
+		counter := callbacks.SequenceCounter{}
+		Expect(counter.Next("gpsNav")).To(Equal(uint64(1)))
+		Expect(counter.Next("gpsNav")).To(Equal(uint64(2)))
+		Expect(counter.Next("pmc-info")).To(Equal(uint64(1)))
+		Expect(counter.Next("gpsNav")).To(Equal(uint64(3)))
+		Expect(counter.Next("pmc-info")).To(Equal(uint64(2)))
+	})
+	It("should restart the numbering when reset", func() {
+		counter := callbacks.SequenceCounter{}
+		counter.Next("gpsNav")
+		counter.Next("gpsNav")
+		counter.Reset()
+		Expect(counter.Next("gpsNav")).To(Equal(uint64(1)))
+	})
+	It("should not repeat a number when used from several goroutines", func() {
+		const goroutines, perGoroutine = 8, 100
+		counter := callbacks.SequenceCounter{}
+		seen := make(chan uint64, goroutines*perGoroutine)
+		var wg sync.WaitGroup
+		for i := 0; i < goroutines; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				for j := 0; j < perGoroutine; j++ {
+					seen <- counter.Next("gpsNav")
+				}
+			}()
+		}
+		wg.Wait()
+		close(seen)
+		numbers := make(map[uint64]bool)
+		for seq := range seen {
+			Expect(numbers).NotTo(HaveKey(seq))
+			numbers[seq] = true
+		}
+		Expect(numbers).To(HaveLen(goroutines * perGoroutine))
+		Expect(numbers).To(HaveKey(uint64(goroutines * perGoroutine)))
+	})
+})
+
+var _ = Describe("SequencedOutput", func() {
+	var mockedFile *testFile
+
+	BeforeEach(func() {
+		mockedFile = NewTestFile()
+	})
+
+	It("should write the sequence number alongside the NDJSON data", func() {
+		callback := callbacks.NewFileCallback(mockedFile, callbacks.NDJSON)
+		Expect(callback.Call(callbacks.NewSequencedOutput(&testOutputType{Msg: "Hello"}, 3), "testOut")).To(Succeed())
+		Expect(mockedFile.String()).To(Equal(
+			"{\"data\":{\"msg\":\"Hello\"},\"seq\":3,\"type\":\"*callbacks_test.testOutputType\",\"tag\":\"testOut\"}\n",
+		))
+	})
+	It("should write the sequence number in each analyser message", func() {
+		callback := callbacks.NewAnalyserCallback(mockedFile)
+		Expect(callback.Call(callbacks.NewSequencedOutput(&testOutputType{Msg: "Hello"}, 3), "testOut")).To(Succeed())
+		Expect(mockedFile.String()).To(Equal("{\"data\":[\"Hello\"],\"seq\":3,\"id\":\"testOutput\"}\n"))
+	})
+	It("should leave the raw format unchanged", func() {
+		callback := callbacks.NewFileCallback(mockedFile, callbacks.Raw)
+		Expect(callback.Call(callbacks.NewSequencedOutput(&testOutputType{Msg: "Hello"}, 3), "testOut")).To(Succeed())
+		Expect(mockedFile.String()).To(Equal("*callbacks_test.testOutputType:testOut, {\"msg\":\"Hello\"}\n"))
+	})
+	It("should capture the output as it was passed by the collector", func() {
+		capture := callbacks.NewCaptureCallback()
+		output := &testOutputType{Msg: "Hello"}
+		Expect(capture.Call(callbacks.NewSequencedOutput(output, 1), "testOut")).To(Succeed())
+		Expect(capture.Outputs()["testOut"]).To(BeIdenticalTo(output))
+	})
+})
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sync"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	outputDirPermissions = 0755
+	defaultSplitFileName = "output"
+)
+
+var unsafeFileNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
+// SplitFileCallBack writes the output for each tag to its own file within a directory,
+// the files are created the first time a tag is seen
+type SplitFileCallBack struct {
+	fileHandles map[string]io.WriteCloser
+	lock        sync.Mutex
+	outputDir   string
+	format      OutputFormat
+	append      bool
+}
+
+// NewSplitFileCallback returns a SplitFileCallBack which writes into outputDir,
+// creating it if it does not exist. Existing files are appended to if appendToFiles is true
+func NewSplitFileCallback(outputDir string, format OutputFormat, appendToFiles bool) (*SplitFileCallBack, error) {
+	err := os.MkdirAll(outputDir, outputDirPermissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create output directory: %w", err)
+	}
+	return &SplitFileCallBack{
+		fileHandles: make(map[string]io.WriteCloser),
+		outputDir:   outputDir,
+		format:      format,
+		append:      appendToFiles,
+	}, nil
+}
+
+// GetFileName returns the file name used for the output of a tag,
+// characters which are not safe in file names are replaced
+func (c *SplitFileCallBack) GetFileName(tag string) string {
+	name := unsafeFileNameChars.ReplaceAllString(tag, "_")
+	if name == "" || name == "." || name == ".." {
+		name = defaultSplitFileName
+	}
+	return name + fileExtension(c.format)
+}
+
+func (c *SplitFileCallBack) getFileHandle(tag string) (io.WriteCloser, error) {
+	fileName := c.GetFileName(tag)
+	if fileHandle, ok := c.fileHandles[fileName]; ok {
+		return fileHandle, nil
+	}
+	fileHandle, err := GetFileHandle(filepath.Join(c.outputDir, fileName), c.append)
+	if err != nil {
+		return nil, err
+	}
+	c.fileHandles[fileName] = fileHandle
+	return fileHandle, nil
+}
+
+func (c *SplitFileCallBack) Call(output OutputType, tag string) error {
+	formattedOutput, err := getFormattedOutput(c, output, tag)
+	if err != nil {
+		return err
+	}
+	formattedOutput = append(formattedOutput, []byte("\n")...)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	fileHandle, err := c.getFileHandle(tag)
+	if err != nil {
+		return err
+	}
+	_, err = fileHandle.Write(formattedOutput)
+	if err != nil {
+		return fmt.Errorf("failed to write to file in callback: %w", err)
+	}
+	return nil
+}
+
+func (c *SplitFileCallBack) getFormat() OutputFormat {
+	return c.format
+}
+
+// CleanUp closes all of the files which have been written to
+func (c *SplitFileCallBack) CleanUp() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	errs := make([]error, 0)
+	for fileName, fileHandle := range c.fileHandles {
+		err := fileHandle.Close()
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to close %s: %w", fileName, err))
+		}
+	}
+	c.fileHandles = make(map[string]io.WriteCloser)
+	if len(errs) > 0 {
+		return utils.MakeCompositeError("failed to close file handles in callback", errs)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+var _ = Describe("SplitFileCallback", func() {
+	var outputDir string
+
+	BeforeEach(func() {
+		var err error
+		outputDir, err = os.MkdirTemp("", "split-output")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, outputDir)
+	})
+
+	When("called with different tags", func() {
+		It("should route each tag to its own file", func() {
+			callback, err := callbacks.NewSplitFileCallback(filepath.Join(outputDir, "out"), callbacks.AnalyserJSON, false)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(callback.Call(&testOutputType{Msg: "first"}, "gnss-data")).To(Succeed())
+			Expect(callback.Call(&testOutputType{Msg: "second"}, "dpll-info")).To(Succeed())
+			Expect(callback.Call(&testOutputType{Msg: "third"}, "gnss-data")).To(Succeed())
+			Expect(callback.CleanUp()).To(Succeed())
+
+			gnss, err := os.ReadFile(filepath.Join(outputDir, "out", "gnss-data.jsonl"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(gnss)).To(Equal(
+				"{\"data\":[\"Hello\"],\"id\":\"testOutput\"}\n{\"data\":[\"Hello\"],\"id\":\"testOutput\"}\n",
+			))
+			dpll, err := os.ReadFile(filepath.Join(outputDir, "out", "dpll-info.jsonl"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(dpll)).To(Equal("{\"data\":[\"Hello\"],\"id\":\"testOutput\"}\n"))
+		})
+	})
+
+	When("a tag is not safe to use as a file name", func() {
+		It("should replace the unsafe characters", func() {
+			callback, err := callbacks.NewSplitFileCallback(outputDir, callbacks.Raw, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callback.GetFileName("../dpll/info nl")).To(Equal(".._dpll_info_nl.log"))
+			Expect(callback.GetFileName("..")).To(Equal("output.log"))
+
+			Expect(callback.Call(&testOutputType{Msg: "first"}, "../dpll/info nl")).To(Succeed())
+			Expect(callback.CleanUp()).To(Succeed())
+			Expect(filepath.Join(outputDir, ".._dpll_info_nl.log")).To(BeAnExistingFile())
+		})
+	})
+
+	When("a file already exists for a tag", func() {
+		It("should truncate it unless appending", func() {
+			existing := filepath.Join(outputDir, "testOut.log")
+			for _, appendToFiles := range []bool{false, true} {
+				Expect(os.WriteFile(existing, []byte("old\n"), 0600)).To(Succeed())
+				callback, err := callbacks.NewSplitFileCallback(outputDir, callbacks.Raw, appendToFiles)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(callback.Call(&testOutputType{Msg: "new"}, "testOut")).To(Succeed())
+				Expect(callback.CleanUp()).To(Succeed())
+
+				contents, err := os.ReadFile(existing)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(strings.HasPrefix(string(contents), "old\n")).To(Equal(appendToFiles))
+				Expect(string(contents)).To(ContainSubstring(`{"msg":"new"}`))
+			}
+		})
+	})
+
+	When("nothing is written", func() {
+		It("should not create any files", func() {
+			callback, err := callbacks.NewSplitFileCallback(outputDir, callbacks.Raw, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(callback.CleanUp()).To(Succeed())
+			entries, err := os.ReadDir(outputDir)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
+})
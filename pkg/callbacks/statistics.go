@@ -0,0 +1,169 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// numericValue returns the value as a float, the second value is false if it is not a number
+func numericValue(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case float32:
+		return float64(v), true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case int32:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+// StatisticsCallback accumulates statistics of the numeric fields selected for each analyser ID
+// from every output before passing it on to the wrapped callback. Only the statistics are kept
+// so the memory used does not grow with the length of the run. Outputs passed during the warmup
+// are still written but are left out of the statistics.
+type StatisticsCallback struct {
+	warmupEnd time.Time
+	callback  Callback
+	fields    map[string][]string
+	stats     map[string]map[string]*utils.StreamingStatistics
+	warmup    time.Duration
+	warmedUp  int64
+	lock      sync.Mutex
+}
+
+// NewStatisticsCallback returns a StatisticsCallback which summarises the fields of the analyser
+// messages keyed by their ID e.g. {"dpll/time-error": {"terror"}}. If warmup is not zero the outputs
+// passed before StartWarmup is called and for warmup afterwards are excluded.
+func NewStatisticsCallback(callback Callback, fields map[string][]string, warmup time.Duration) *StatisticsCallback {
+	return &StatisticsCallback{
+		callback: callback,
+		fields:   fields,
+		stats:    make(map[string]map[string]*utils.StreamingStatistics),
+		warmup:   warmup,
+	}
+}
+
+// StartWarmup starts the warmup period, it is called once polling begins
+// so the time taken to start the collectors does not count towards it
+func (c *StatisticsCallback) StartWarmup() {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.warmupEnd = time.Now().Add(c.warmup)
+}
+
+// inWarmup returns true if the output passed now should be left out of the statistics,
+// it counts the output as excluded if so
+func (c *StatisticsCallback) inWarmup() bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	if c.warmup == 0 || (!c.warmupEnd.IsZero() && !time.Now().Before(c.warmupEnd)) {
+		return false
+	}
+	c.warmedUp++
+	return true
+}
+
+// add adds the value to the statistics of the field, the caller must hold the lock
+func (c *StatisticsCallback) add(id, field string, value float64) {
+	idStats, found := c.stats[id]
+	if !found {
+		idStats = make(map[string]*utils.StreamingStatistics)
+		c.stats[id] = idStats
+	}
+	fieldStats, found := idStats[field]
+	if !found {
+		fieldStats = utils.NewStreamingStatistics()
+		idStats[field] = fieldStats
+	}
+	fieldStats.Add(value)
+}
+
+// record adds the selected fields of the output's analyser messages to the statistics,
+// outputs which can not be formatted are left to the wrapped callback to report
+func (c *StatisticsCallback) record(output OutputType) {
+	messages, err := output.GetAnalyserFormat()
+	if err != nil {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	for _, message := range messages {
+		fields, ok := c.fields[message.ID]
+		if !ok {
+			continue
+		}
+		data, ok := message.Data.(map[string]any)
+		if !ok {
+			decoded, decodeErr := decodeJSON(message.Data)
+			if decodeErr != nil {
+				continue
+			}
+			data, _ = decoded.(map[string]any)
+		}
+		for _, field := range fields {
+			value, isNumber := numericValue(data[field])
+			if !isNumber {
+				continue
+			}
+			c.add(message.ID, field, value)
+		}
+	}
+}
+
+func (c *StatisticsCallback) Call(output OutputType, tag string) error {
+	if !c.inWarmup() {
+		c.record(output)
+	}
+	return c.callback.Call(output, tag) //nolint:wrapcheck // the wrapped callback wraps its errors
+}
+
+// Summaries returns the summary of each field seen so far keyed by analyser ID then field
+func (c *StatisticsCallback) Summaries() map[string]map[string]utils.StatisticsSummary {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	summaries := make(map[string]map[string]utils.StatisticsSummary, len(c.stats))
+	for id, fields := range c.stats {
+		summaries[id] = make(map[string]utils.StatisticsSummary, len(fields))
+		for field, stats := range fields {
+			summaries[id][field] = stats.Summary()
+		}
+	}
+	return summaries
+}
+
+// WarmupExcluded returns the number of outputs left out of the statistics because they were passed in the warmup
+func (c *StatisticsCallback) WarmupExcluded() int64 {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.warmedUp
+}
+
+// Flush flushes the wrapped callback if it buffers its output
+func (c *StatisticsCallback) Flush() error {
+	if flusher, ok := c.callback.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+func (c *StatisticsCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+func (c *StatisticsCallback) CleanUp() error {
+	return c.callback.CleanUp() //nolint:wrapcheck // the wrapped callback wraps its errors
+}
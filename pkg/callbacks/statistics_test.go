@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks_test
+
+import (
+	"math"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var _ = Describe("StatisticsCallback", func() {
+	It("should summarise the selected fields of each analyser ID", func() {
+		mockedFile := NewTestFile()
+		callback := callbacks.NewStatisticsCallback(
+			callbacks.NewFileCallback(mockedFile, callbacks.NDJSON),
+			map[string][]string{
+				devices.DPLLTimeErrorID: {"terror"},
+				devices.GNSSTimeErrorID: {"terror", "ferror"},
+			},
+			0,
+		)
+		// terror is reported by the driver in hundredths of a nanosecond
+		for _, offset := range []float64{-400, 200, -200, 400} {
+			Expect(callback.Call(&devices.DevFilesystemDPLLInfo{PPSOffset: offset}, "dpll-info-fs")).To(Succeed())
+		}
+		gpsDetails := &devices.GPSDetails{NavClock: devices.GPSNavClock{TimeAcc: 5, FreqAcc: 40}}
+		Expect(callback.Call(gpsDetails, "gnss-data")).To(Succeed())
+		Expect(callback.Call(&testOutputType{Msg: "ignored"}, "other")).To(Succeed())
+
+		summaries := callback.Summaries()
+		Expect(summaries).To(HaveLen(2))
+		dpll := summaries[devices.DPLLTimeErrorID]["terror"]
+		Expect(dpll.Count).To(Equal(int64(4)))
+		Expect(dpll.Min).To(Equal(-4.0))
+		Expect(dpll.Max).To(Equal(4.0))
+		Expect(dpll.Mean).To(BeNumerically("~", 0, 1e-9))
+		Expect(dpll.StdDev).To(BeNumerically("~", math.Sqrt(10), 1e-9))
+		Expect(dpll.AbsP99).To(Equal(4.0))
+		Expect(summaries[devices.GNSSTimeErrorID]).To(Equal(map[string]utils.StatisticsSummary{
+			"terror": {Count: 1, Min: 5, Max: 5, Mean: 5, AbsP99: 5},
+			"ferror": {Count: 1, Min: 40, Max: 40, Mean: 40, AbsP99: 40},
+		}))
+	})
+	When("a warmup is given", func() {
+		fields := map[string][]string{devices.DPLLTimeErrorID: {"terror"}}
+
+		It("should exclude the outputs in the warmup from the statistics but still write them", func() {
+			mockedFile := NewTestFile()
+			callback := callbacks.NewStatisticsCallback(
+				callbacks.NewFileCallback(mockedFile, callbacks.NDJSON), fields, time.Hour,
+			)
+			Expect(callback.Call(&devices.DevFilesystemDPLLInfo{PPSOffset: 100000}, "dpll-info-fs")).To(Succeed())
+			Expect(callback.Summaries()).To(BeEmpty())
+			Expect(callback.WarmupExcluded()).To(Equal(int64(1)))
+			Expect(mockedFile.Buffer.String()).NotTo(BeEmpty())
+		})
+		It("should exclude the outputs passed before the warmup starts", func() {
+			warmup := time.Millisecond
+			callback := callbacks.NewStatisticsCallback(
+				callbacks.NewFileCallback(NewTestFile(), callbacks.NDJSON), fields, warmup,
+			)
+			time.Sleep(2 * warmup)
+			Expect(callback.Call(&devices.DevFilesystemDPLLInfo{PPSOffset: 100000}, "dpll-info-fs")).To(Succeed())
+			Expect(callback.Summaries()).To(BeEmpty())
+			Expect(callback.WarmupExcluded()).To(Equal(int64(1)))
+		})
+		It("should include the outputs after the warmup", func() {
+			warmup := 50 * time.Millisecond
+			callback := callbacks.NewStatisticsCallback(
+				callbacks.NewFileCallback(NewTestFile(), callbacks.NDJSON), fields, warmup,
+			)
+			callback.StartWarmup()
+			for _, offset := range []float64{100000, -100000} {
+				Expect(callback.Call(&devices.DevFilesystemDPLLInfo{PPSOffset: offset}, "dpll-info-fs")).To(Succeed())
+			}
+			time.Sleep(2 * warmup)
+			for _, offset := range []float64{100, -100} {
+				Expect(callback.Call(&devices.DevFilesystemDPLLInfo{PPSOffset: offset}, "dpll-info-fs")).To(Succeed())
+			}
+			Expect(callback.WarmupExcluded()).To(Equal(int64(2)))
+			Expect(callback.Summaries()[devices.DPLLTimeErrorID]["terror"]).To(Equal(utils.StatisticsSummary{
+				Count: 2, Min: -1, Max: 1, Mean: 0, StdDev: 1, AbsP99: 1,
+			}))
+		})
+	})
+})
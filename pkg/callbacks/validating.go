@@ -0,0 +1,48 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package callbacks
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ValidatingCallback checks the JSON encoding of each output against the schema
+// generated for its type before passing it on to the wrapped callback
+type ValidatingCallback struct {
+	callback Callback
+}
+
+// NewValidatingCallback returns a ValidatingCallback which writes the outputs through callback
+func NewValidatingCallback(callback Callback) *ValidatingCallback {
+	return &ValidatingCallback{callback: callback}
+}
+
+// Call returns an error without writing the output if it does not match its schema
+func (c *ValidatingCallback) Call(output OutputType, tag string) error {
+	encoded, err := json.Marshal(output)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %T for validation %w", output, err)
+	}
+	err = ValidateJSON(GetSchema(unwrapOutput(output)), encoded)
+	if err != nil {
+		return fmt.Errorf("%T for %s does not match its schema: %w", output, tag, err)
+	}
+	return c.callback.Call(output, tag) //nolint:wrapcheck // the wrapped callback wraps its errors
+}
+
+// Flush flushes the wrapped callback if it buffers its output
+func (c *ValidatingCallback) Flush() error {
+	if flusher, ok := c.callback.(Flusher); ok {
+		return flusher.Flush() //nolint:wrapcheck // the error is already wrapped by the flusher
+	}
+	return nil
+}
+
+func (c *ValidatingCallback) getFormat() OutputFormat {
+	return c.callback.getFormat()
+}
+
+func (c *ValidatingCallback) CleanUp() error {
+	return c.callback.CleanUp() //nolint:wrapcheck // the wrapped callback wraps its errors
+}
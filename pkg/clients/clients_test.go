@@ -3,12 +3,22 @@
 package clients_test
 
 import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
 )
 
 const (
@@ -46,6 +56,227 @@ var _ = Describe("Client", func() {
 	})
 })
 
+// execPluginScript prints an already expired ExecCredential with a token numbered by the times it has run
+const execPluginScript = `count=$(( $(cat "$COUNT_FILE" 2>/dev/null || echo 0) + 1 ))
+echo "$count" > "$COUNT_FILE"
+printf '{"apiVersion":"client.authentication.k8s.io/v1","kind":"ExecCredential",'
+printf '"status":{"token":"token-%d","expirationTimestamp":"2000-01-01T00:00:00Z"}}' "$count"
+`
+
+const execPluginKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster:
+    server: %s
+    insecure-skip-tls-verify: true
+  name: exec
+contexts:
+- context:
+    cluster: exec
+    user: exec
+  name: exec
+current-context: exec
+users:
+- name: exec
+  user:
+    exec:
+      apiVersion: client.authentication.k8s.io/v1
+      command: /bin/sh
+      args:
+      - %s
+      env:
+      - name: COUNT_FILE
+        value: %s
+      interactiveMode: Never
+`
+
+var _ = Describe("Client with an exec credential plugin", func() {
+	var authHeaders chan string
+	var server *httptest.Server
+	var kubeconfigFile string
+
+	BeforeEach(func() {
+		clients.ClearClientSet()
+		authHeaders = make(chan string, 10)
+		// The exec plugin is only used for clusters served over TLS
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			authHeaders <- r.Header.Get("Authorization")
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"kind":"PodList","apiVersion":"v1","items":[]}`)
+		}))
+		DeferCleanup(server.Close)
+
+		dir := GinkgoT().TempDir()
+		pluginFile := filepath.Join(dir, "plugin.sh")
+		Expect(os.WriteFile(pluginFile, []byte(execPluginScript), 0600)).To(Succeed())
+		kubeconfigFile = filepath.Join(dir, "kubeconfig")
+		kubeconfig := fmt.Sprintf(execPluginKubeconfig, server.URL, pluginFile, filepath.Join(dir, "count"))
+		Expect(os.WriteFile(kubeconfigFile, []byte(kubeconfig), 0600)).To(Succeed())
+	})
+
+	When("the credential from the plugin expires", func() {
+		It("should run the plugin again and use the new token", func() {
+			clientset, err := clients.GetClientset(kubeconfigFile)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(clientset.RestConfig.ExecProvider).NotTo(BeNil())
+
+			for i := 0; i < 2; i++ {
+				_, err = clientset.K8sClient.CoreV1().Pods("TestNamespace").List(context.TODO(), metav1.ListOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(<-authHeaders).To(Equal("Bearer token-1"))
+			Expect(<-authHeaders).To(Equal("Bearer token-2"))
+		})
+	})
+})
+
+func newPodOnNode(name, nodeName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: "TestNamespace",
+		},
+		Spec: v1.PodSpec{NodeName: nodeName},
+	}
+}
+
+var _ = Describe("FindPodNameFromPrefixOnNode", func() {
+	var clientset *clients.Clientset
+	BeforeEach(func() {
+		clientset = testutils.GetMockedClientSet(
+			newPodOnNode("linuxptp-daemon-aaaaa", "node-1"),
+			newPodOnNode("linuxptp-daemon-bbbbb", "node-2"),
+			newPodOnNode("linuxptp-daemon-ccccc", "node-3"),
+			newPodOnNode("linuxptp-daemon-ddddd-debug", "node-2"),
+			newPodOnNode("other-pod-eeeee", "node-2"),
+		)
+	})
+
+	When("several pods match the prefix and no node is given", func() {
+		It("should return an error", func() {
+			_, err := clientset.FindPodNameFromPrefix("TestNamespace", "linuxptp-daemon-")
+			Expect(err).To(HaveOccurred())
+			_, err = clientset.FindPodNameFromPrefixOnNode("TestNamespace", "linuxptp-daemon-", "")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	When("several pods match the prefix and a node is given", func() {
+		It("should return the pod on that node", func() {
+			podName, err := clientset.FindPodNameFromPrefixOnNode("TestNamespace", "linuxptp-daemon-", "node-2")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(podName).To(Equal("linuxptp-daemon-bbbbb"))
+		})
+	})
+	When("no pod matching the prefix is on the given node", func() {
+		It("should return an error", func() {
+			_, err := clientset.FindPodNameFromPrefixOnNode("TestNamespace", "linuxptp-daemon-", "node-4")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("node-4"))
+		})
+	})
+	When("a container context is requested on a node", func() {
+		It("should use the pod on that node", func() {
+			ctx, err := clients.NewContainerContextOnNode(
+				clientset, "TestNamespace", "linuxptp-daemon-", "TestContainer", "node-3",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.GetPodName()).To(Equal("linuxptp-daemon-ccccc"))
+			Expect(ctx.GetNodeName()).To(Equal("node-3"))
+		})
+	})
+})
+
+var _ = Describe("FindNodesWithPodPrefix", func() {
+	When("pods matching the prefix are on several nodes", func() {
+		It("should return each node once in order ignoring debug and other pods", func() {
+			clientset := testutils.GetMockedClientSet(
+				newPodOnNode("linuxptp-daemon-ccccc", "node-3"),
+				newPodOnNode("linuxptp-daemon-aaaaa", "node-1"),
+				newPodOnNode("linuxptp-daemon-bbbbb", "node-2"),
+				newPodOnNode("linuxptp-daemon-xxxxx", "node-2"),
+				newPodOnNode("linuxptp-daemon-ddddd-debug", "node-4"),
+				newPodOnNode("other-pod-eeeee", "node-5"),
+			)
+			nodes, err := clientset.FindNodesWithPodPrefix("TestNamespace", "linuxptp-daemon-")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nodes).To(Equal([]string{"node-1", "node-2", "node-3"}))
+		})
+	})
+	When("no pod matches the prefix", func() {
+		It("should return an error", func() {
+			clientset := testutils.GetMockedClientSet(newPodOnNode("other-pod-eeeee", "node-1"))
+			_, err := clientset.FindNodesWithPodPrefix("TestNamespace", "linuxptp-daemon-")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+func newPodWithContainers(name string, containerNames ...string) *v1.Pod {
+	pod := newPodOnNode(name, "node-1")
+	for _, containerName := range containerNames {
+		pod.Spec.Containers = append(pod.Spec.Containers, v1.Container{Name: containerName})
+	}
+	return pod
+}
+
+var _ = Describe("FindContainerNameFromPrefix", func() {
+	var clientset *clients.Clientset
+	BeforeEach(func() {
+		clientset = testutils.GetMockedClientSet(
+			newPodWithContainers(
+				"linuxptp-daemon-aaaaa", "kube-rbac-proxy", "linuxptp-daemon-container-v2", "cloud-event-proxy",
+			),
+			newPodWithContainers("linuxptp-daemon-bbbbb", "linuxptp-daemon-container", "linuxptp-daemon-container-sidecar"),
+			newPodWithContainers("linuxptp-daemon-ccccc", "linuxptp-daemon-a", "linuxptp-daemon-b"),
+		)
+	})
+
+	When("one container matches the prefix", func() {
+		It("should return its name", func() {
+			name, err := clientset.FindContainerNameFromPrefix(
+				"TestNamespace", "linuxptp-daemon-aaaaa", "linuxptp-daemon-container",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("linuxptp-daemon-container-v2"))
+		})
+	})
+	When("a container is named exactly the prefix", func() {
+		It("should return it rather than the others which match", func() {
+			name, err := clientset.FindContainerNameFromPrefix(
+				"TestNamespace", "linuxptp-daemon-bbbbb", "linuxptp-daemon-container",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(name).To(Equal("linuxptp-daemon-container"))
+		})
+	})
+	When("several containers match the prefix", func() {
+		It("should return an error naming them", func() {
+			_, err := clientset.FindContainerNameFromPrefix(
+				"TestNamespace", "linuxptp-daemon-ccccc", "linuxptp-daemon-",
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("linuxptp-daemon-a, linuxptp-daemon-b"))
+		})
+	})
+	When("no container matches the prefix", func() {
+		It("should return an error", func() {
+			_, err := clientset.FindContainerNameFromPrefix("TestNamespace", "linuxptp-daemon-aaaaa", "gpsd")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("no container with prefix gpsd"))
+		})
+	})
+	When("a container context is requested by prefixes", func() {
+		It("should use the matching container", func() {
+			ctx, err := clients.NewContainerContextFromPrefixes(
+				clientset, "TestNamespace", "linuxptp-daemon-a", "linuxptp-daemon-container", "",
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.GetPodName()).To(Equal("linuxptp-daemon-aaaaa"))
+			Expect(ctx.GetContainerName()).To(Equal("linuxptp-daemon-container-v2"))
+		})
+	})
+})
+
 func TestCommand(t *testing.T) {
 	RegisterFailHandler(Fail)
 	RunSpecs(t, "Clients Suite")
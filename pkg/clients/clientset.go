@@ -4,13 +4,17 @@ package clients
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"sort"
 	"strings"
+	"sync"
 	"time"
 
 	ocpconfig "github.com/openshift/client-go/config/clientset/versioned"
 	log "github.com/sirupsen/logrus"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
@@ -19,18 +23,43 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
+// DefaultExecTimeout is the default time allowed for a command run
+// in a container before it is abandoned
+const DefaultExecTimeout = 30 * time.Second
+
+// minRebuildInterval is the shortest time between rebuilds of the clients
+const minRebuildInterval = 10 * time.Second
+
+const (
+	// DefaultPodLookupAttempts is the default number of times a pod is looked up before giving up,
+	// this allows for the pod being replaced during a rollout of the operator
+	DefaultPodLookupAttempts = 5
+	// DefaultPodLookupBackoff is the default delay before the first retry of a pod lookup,
+	// this doubles with each subsequent retry
+	DefaultPodLookupBackoff = 2 * time.Second
+)
+
 // A Clientset contains clients for the different k8s API groups in one place
 type Clientset struct {
-	RestConfig      *rest.Config
-	DynamicClient   dynamic.Interface
-	OcpClient       ocpconfig.Interface
-	K8sClient       kubernetes.Interface
-	K8sRestClient   rest.Interface
-	KubeConfigPaths []string
-	ready           bool
+	RestConfig        *rest.Config
+	DynamicClient     dynamic.Interface
+	OcpClient         ocpconfig.Interface
+	K8sClient         kubernetes.Interface
+	K8sRestClient     rest.Interface
+	rebuiltAt         time.Time
+	KubeConfigPaths   []string
+	ExecTimeout       time.Duration
+	PodLookupBackoff  time.Duration
+	PodLookupAttempts int
+	ready             bool
 }
 
-var clientset = Clientset{}
+var (
+	clientset = Clientset{}
+	// clientsetLock guards the clients of the clientset which are replaced by Rebuild
+	clientsetLock sync.RWMutex
+	rebuildLock   sync.Mutex
+)
 
 // GetClientset returns the singleton clientset object.
 func GetClientset(kubeconfigPaths ...string) (*Clientset, error) {
@@ -56,6 +85,19 @@ func GetClientset(kubeconfigPaths ...string) (*Clientset, error) {
 func newClientset(kubeconfigPaths ...string) (*Clientset, error) {
 	log.Infof("creating new Clientset from %v", kubeconfigPaths)
 	clientset.KubeConfigPaths = kubeconfigPaths
+	clientset.ExecTimeout = DefaultExecTimeout
+	clientset.PodLookupAttempts = DefaultPodLookupAttempts
+	clientset.PodLookupBackoff = DefaultPodLookupBackoff
+	err := clientset.buildClients()
+	if err != nil {
+		return nil, err
+	}
+	clientset.ready = true
+	return &clientset, nil
+}
+
+// loadRestConfig loads the kubeconfigs and returns the rest config for the current context
+func loadRestConfig(kubeconfigPaths []string) (*rest.Config, error) {
 	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
 
 	loadingRules.Precedence = kubeconfigPaths // This means it will not load the value from $KUBECONFIG
@@ -66,40 +108,111 @@ func newClientset(kubeconfigPaths ...string) (*Clientset, error) {
 	)
 	// Get a rest.Config from the kubeconfig file.  This will be passed into all
 	// the client objects we create.
-	var err error
-	clientset.RestConfig, err = kubeconfig.ClientConfig()
+	restConfig, err := kubeconfig.ClientConfig()
 	if err != nil {
 		return nil, fmt.Errorf("cannot instantiate rest config: %w", err)
 	}
+	if restConfig.ExecProvider != nil {
+		// client-go caches the credential from the plugin and runs it again when the credential
+		// expires or is rejected so the clients keep working for runs longer than its lifetime
+		log.Infof("using exec credential plugin %s", restConfig.ExecProvider.Command)
+	}
 
 	DefaultTimeout := 10 * time.Second
-	clientset.RestConfig.Timeout = DefaultTimeout
+	restConfig.Timeout = DefaultTimeout
+	return restConfig, nil
+}
 
-	clientset.DynamicClient, err = dynamic.NewForConfig(clientset.RestConfig)
+// buildClients creates the rest config and the clients from the kubeconfigs
+func (clientsholder *Clientset) buildClients() error {
+	restConfig, err := loadRestConfig(clientsholder.KubeConfigPaths)
+	if err != nil {
+		return err
+	}
+	dynamicClient, err := dynamic.NewForConfig(restConfig)
 	if err != nil {
-		return nil, fmt.Errorf("cannot instantiate dynamic client (unstructured/dynamic): %w", err)
+		return fmt.Errorf("cannot instantiate dynamic client (unstructured/dynamic): %w", err)
 	}
-	clientset.K8sClient, err = kubernetes.NewForConfig(clientset.RestConfig)
+	k8sClient, err := kubernetes.NewForConfig(restConfig)
 	if err != nil {
-		return nil, fmt.Errorf("cannot instantiate k8sclient: %w", err)
+		return fmt.Errorf("cannot instantiate k8sclient: %w", err)
 	}
 	// create the oc client
-	clientset.OcpClient, err = ocpconfig.NewForConfig(clientset.RestConfig)
+	ocpClient, err := ocpconfig.NewForConfig(restConfig)
 	if err != nil {
-		return nil, fmt.Errorf("cannot instantiate ocClient: %w", err)
+		return fmt.Errorf("cannot instantiate ocClient: %w", err)
 	}
 
-	clientset.K8sRestClient = clientset.K8sClient.CoreV1().RESTClient()
-	clientset.ready = true
-	return &clientset, nil
+	clientsetLock.Lock()
+	defer clientsetLock.Unlock()
+	clientsholder.RestConfig = restConfig
+	clientsholder.DynamicClient = dynamicClient
+	clientsholder.K8sClient = k8sClient
+	clientsholder.OcpClient = ocpClient
+	clientsholder.K8sRestClient = k8sClient.CoreV1().RESTClient()
+	return nil
+}
+
+// Rebuild reloads the kubeconfigs and recreates the clients so that a credential which has been
+// renewed since they were created is used, client-go runs exec credential plugins again when their
+// credential is rejected. When several commands are rejected at once only the first rebuilds the clients.
+func (clientsholder *Clientset) Rebuild() error {
+	rebuildLock.Lock()
+	defer rebuildLock.Unlock()
+	if !clientsholder.rebuiltAt.IsZero() && time.Since(clientsholder.rebuiltAt) < minRebuildInterval {
+		return nil
+	}
+	log.Infof("rebuilding the clients from %v", clientsholder.KubeConfigPaths)
+	err := clientsholder.buildClients()
+	if err != nil {
+		return fmt.Errorf("failed to rebuild the clients: %w", err)
+	}
+	clientsholder.rebuiltAt = time.Now()
+	return nil
+}
+
+// getExecClients returns the clients used to exec commands in containers,
+// they are read under the lock as they are replaced by Rebuild
+func (clientsholder *Clientset) getExecClients() (rest.Interface, *rest.Config) {
+	clientsetLock.RLock()
+	defer clientsetLock.RUnlock()
+	return clientsholder.K8sRestClient, clientsholder.RestConfig
+}
+
+// GetK8sClient returns the kubernetes client, it is read under the lock as it is replaced by Rebuild
+func (clientsholder *Clientset) GetK8sClient() kubernetes.Interface { //nolint:ireturn // tests use the fake client
+	clientsetLock.RLock()
+	defer clientsetLock.RUnlock()
+	return clientsholder.K8sClient
 }
 
 func ClearClientSet() {
 	clientset = Clientset{}
 }
 
+// PodNotFoundError is returned when no pod has the prefix, unlike other lookup failures
+// the pod may appear if it is being replaced so the lookup can be retried
+type PodNotFoundError struct {
+	Prefix   string
+	Location string
+}
+
+func (err *PodNotFoundError) Error() string {
+	return fmt.Sprintf("no pod with prefix %v found in %s", err.Prefix, err.Location)
+}
+
 func (clientsholder *Clientset) FindPodNameFromPrefix(namespace, prefix string) (string, error) {
-	podList, err := clientsholder.K8sClient.CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	return clientsholder.FindPodNameFromPrefixOnNode(namespace, prefix, "")
+}
+
+// FindPodNameFromPrefixOnNode returns the name of the pod with the given prefix,
+// if nodeName is not empty only pods scheduled to that node are considered.
+func (clientsholder *Clientset) FindPodNameFromPrefixOnNode(namespace, prefix, nodeName string) (string, error) {
+	listOptions := metav1.ListOptions{}
+	if nodeName != "" {
+		listOptions.FieldSelector = fields.OneTermEqualSelector("spec.nodeName", nodeName).String()
+	}
+	podList, err := clientsholder.GetK8sClient().CoreV1().Pods(namespace).List(context.TODO(), listOptions)
 	if err != nil {
 		return "", fmt.Errorf("failed to getting pod list: %w", err)
 	}
@@ -108,17 +221,103 @@ func (clientsholder *Clientset) FindPodNameFromPrefix(namespace, prefix string)
 	for i := range podList.Items {
 		hasPrefix := strings.HasPrefix(podList.Items[i].Name, prefix)
 		isDebug := strings.HasSuffix(podList.Items[i].Name, "-debug")
-		if hasPrefix && !isDebug {
+		onNode := nodeName == "" || podList.Items[i].Spec.NodeName == nodeName
+		if hasPrefix && !isDebug && onNode {
 			podNames = append(podNames, podList.Items[i].Name)
 		}
 	}
 
+	location := fmt.Sprintf("namespace %v", namespace)
+	if nodeName != "" {
+		location += fmt.Sprintf(" on node %v", nodeName)
+	}
+
 	switch len(podNames) {
 	case 0:
-		return "", fmt.Errorf("no pod with prefix %v found in namespace %v", prefix, namespace)
+		return "", &PodNotFoundError{Prefix: prefix, Location: location}
 	case 1:
 		return podNames[0], nil
 	default:
-		return "", fmt.Errorf("too many (%v) pods with prefix %v found in namespace %v", len(podNames), prefix, namespace)
+		return "", fmt.Errorf(
+			"too many (%v) pods with prefix %v found in %s, a node name is required to select one",
+			len(podNames), prefix, location,
+		)
+	}
+}
+
+// FindNodesWithPodPrefix returns the sorted names of the nodes running a pod with the given prefix,
+// debug pods are ignored as they are with FindPodNameFromPrefixOnNode.
+func (clientsholder *Clientset) FindNodesWithPodPrefix(namespace, prefix string) ([]string, error) {
+	podList, err := clientsholder.GetK8sClient().CoreV1().Pods(namespace).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to getting pod list: %w", err)
+	}
+	seen := make(map[string]bool)
+	nodeNames := make([]string, 0)
+	for i := range podList.Items {
+		pod := &podList.Items[i]
+		if !strings.HasPrefix(pod.Name, prefix) || strings.HasSuffix(pod.Name, "-debug") {
+			continue
+		}
+		if pod.Spec.NodeName == "" || seen[pod.Spec.NodeName] {
+			continue
+		}
+		seen[pod.Spec.NodeName] = true
+		nodeNames = append(nodeNames, pod.Spec.NodeName)
+	}
+	if len(nodeNames) == 0 {
+		return nil, fmt.Errorf("no node is running a pod with prefix %v in namespace %v", prefix, namespace)
+	}
+	sort.Strings(nodeNames)
+	return nodeNames, nil
+}
+
+// WaitForPodNameFromPrefixOnNode returns the name of the pod with the given prefix like
+// FindPodNameFromPrefixOnNode, the lookup is retried with a backoff up to PodLookupAttempts times
+// so that a pod which is briefly missing while it is being replaced is tolerated. Other errors,
+// such as several pods matching when no node is given, are returned without retrying.
+func (clientsholder *Clientset) WaitForPodNameFromPrefixOnNode(namespace, prefix, nodeName string) (string, error) {
+	backoff := clientsholder.PodLookupBackoff
+	for attempt := 1; ; attempt++ {
+		podName, err := clientsholder.FindPodNameFromPrefixOnNode(namespace, prefix, nodeName)
+		var notFound *PodNotFoundError
+		if !errors.As(err, &notFound) || attempt >= clientsholder.PodLookupAttempts {
+			return podName, err
+		}
+		log.Infof("pod lookup attempt %d of %d failed, retrying in %s: %s",
+			attempt, clientsholder.PodLookupAttempts, backoff, err.Error())
+		time.Sleep(backoff)
+		backoff *= 2
+	}
+}
+
+// FindContainerNameFromPrefix returns the name of the container in the pod with the given prefix,
+// a container named exactly prefix is used even if others start with it.
+func (clientsholder *Clientset) FindContainerNameFromPrefix(namespace, podName, prefix string) (string, error) {
+	pod, err := clientsholder.GetK8sClient().CoreV1().Pods(namespace).Get(context.TODO(), podName, metav1.GetOptions{})
+	if err != nil {
+		return "", fmt.Errorf("failed to get pod %v: %w", podName, err)
+	}
+	containerNames := make([]string, 0)
+	for i := range pod.Spec.Containers {
+		name := pod.Spec.Containers[i].Name
+		if name == prefix {
+			return name, nil
+		}
+		if strings.HasPrefix(name, prefix) {
+			containerNames = append(containerNames, name)
+		}
+	}
+
+	switch len(containerNames) {
+	case 0:
+		return "", fmt.Errorf("no container with prefix %v found in pod %v", prefix, podName)
+	case 1:
+		return containerNames[0], nil
+	default:
+		return "", fmt.Errorf(
+			"too many (%v) containers with prefix %v found in pod %v: %s",
+			len(containerNames), prefix, podName, strings.Join(containerNames, ", "),
+		)
 	}
 }
@@ -0,0 +1,68 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"fmt"
+	"net/url"
+
+	"k8s.io/client-go/tools/clientcmd"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+const redactedServer = "<redacted>"
+
+// ClusterInfo records which cluster the kubeconfig pointed at so that
+// the output of a run can be traced back to it. It never contains credentials.
+type ClusterInfo struct {
+	Context   string `json:"context"`
+	Cluster   string `json:"cluster"`
+	Server    string `json:"server"`
+	Namespace string `json:"namespace,omitempty"`
+}
+
+func (info *ClusterInfo) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   "cluster-info",
+		Data: info,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+// redactServer removes any user info, query or fragment from the API server URL
+// as they can hold credentials
+func redactServer(server string) string {
+	serverURL, err := url.Parse(server)
+	if err != nil {
+		return redactedServer
+	}
+	serverURL.User = nil
+	serverURL.RawQuery = ""
+	serverURL.Fragment = ""
+	return serverURL.String()
+}
+
+// GetClusterInfo returns the current context of the kubeconfig used to create
+// the clientset along with the cluster and API server it refers to
+func (clientsholder *Clientset) GetClusterInfo() (*ClusterInfo, error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	loadingRules.Precedence = clientsholder.KubeConfigPaths
+	config, err := loadingRules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load kubeconfig: %w", err)
+	}
+	kubeContext, ok := config.Contexts[config.CurrentContext]
+	if !ok {
+		return nil, fmt.Errorf("current context %q not found in kubeconfig", config.CurrentContext)
+	}
+	info := &ClusterInfo{
+		Context:   config.CurrentContext,
+		Cluster:   kubeContext.Cluster,
+		Namespace: kubeContext.Namespace,
+	}
+	if cluster, found := config.Clusters[kubeContext.Cluster]; found {
+		info.Server = redactServer(cluster.Server)
+	}
+	return info, nil
+}
@@ -0,0 +1,49 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients_test
+
+import (
+	"encoding/json"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+)
+
+var _ = Describe("GetClusterInfo", func() {
+	BeforeEach(func() {
+		clients.ClearClientSet()
+	})
+
+	When("the kubeconfig has a current context", func() {
+		It("should return the context, cluster and server", func() {
+			clientset, err := clients.GetClientset(kubeconfigPath)
+			Expect(err).NotTo(HaveOccurred())
+			info, err := clientset.GetClusterInfo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info).To(Equal(&clients.ClusterInfo{
+				Context:   "openshift-ptp/api-cormorant-wpc-test:6443/system:admin",
+				Cluster:   "api-cormorant-wpc-test:6443",
+				Server:    "https://api.cormorant.wpc.test:6443",
+				Namespace: "openshift-ptp",
+			}))
+		})
+	})
+
+	When("the kubeconfig contains credentials", func() {
+		It("should not include them", func() {
+			clientset, err := clients.GetClientset("test_files/kubeconfig_credentials")
+			Expect(err).NotTo(HaveOccurred())
+			info, err := clientset.GetClusterInfo()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.Context).To(Equal("kubeadmin/heron"))
+			Expect(info.Server).To(Equal("https://api.heron.wpc.test:6443/"))
+
+			output, err := json.Marshal(info)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(output)).NotTo(ContainSubstring("hunter2"))
+			Expect(string(output)).NotTo(ContainSubstring("sha256~"))
+		})
+	})
+})
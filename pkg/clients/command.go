@@ -9,6 +9,16 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// ExtractError is returned when the result for a command can not be extracted from the output
+type ExtractError struct {
+	Key string
+	msg string
+}
+
+func (err *ExtractError) Error() string {
+	return err.msg
+}
+
 type Cmder interface {
 	GetCommand() string
 	ExtractResult(string) (map[string]string, error)
@@ -39,7 +49,9 @@ func NewCmd(key, cmd string) (*Cmd, error) {
 	}
 	cmdInstance.fullCmd += fmt.Sprintf("%s;", cmdInstance.suffix)
 
-	compiledRegex, err := regexp.Compile(`(?s)<` + key + `>\n` + `(.*)` + `\n</` + key + `>`)
+	// The output is optional so that commands which print nothing (e.g. ls of an empty directory)
+	// are passed to the output processor rather than reported as missing
+	compiledRegex, err := regexp.Compile(`(?s)<` + key + `>\n` + `(?:(.*)\n)?` + `</` + key + `>`)
 	if err != nil {
 		return nil, fmt.Errorf("failed to compile regex for key %s: %w", key, err)
 	}
@@ -67,7 +79,10 @@ func (c *Cmd) ExtractResult(s string) (map[string]string, error) {
 		if c.outputProcessor != nil {
 			cleanValue, err := c.outputProcessor(match[1])
 			if err != nil {
-				return result, fmt.Errorf("failed to cleanup value %s of key %s", match[1], c.key)
+				return result, &ExtractError{
+					Key: c.key,
+					msg: fmt.Sprintf("failed to cleanup value %s of key %s: %s", match[1], c.key, err.Error()),
+				}
 			}
 			value = cleanValue
 		}
@@ -75,7 +90,7 @@ func (c *Cmd) ExtractResult(s string) (map[string]string, error) {
 		result[c.key] = value
 		return result, nil
 	}
-	return result, fmt.Errorf("failed to find result for key: %s", c.key)
+	return result, &ExtractError{Key: c.key, msg: fmt.Sprintf("failed to find result for key: %s", c.key)}
 }
 
 type CmdGroup struct {
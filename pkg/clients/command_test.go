@@ -38,6 +38,22 @@ var _ = Describe("Cmd", func() {
 		})
 	})
 
+	When("Cmd is passed an empty result", func() {
+		It("should pass the empty value to the output processor", func() {
+			key := "TestKey"
+			cmd, _ := clients.NewCmd(key, "Hello This is a test")
+			processed := false
+			cmd.SetOutputProcessor(func(s string) (string, error) {
+				processed = true
+				return "was " + s + "empty", nil
+			})
+			result, err := cmd.ExtractResult(fmt.Sprintf("<%s>\n</%s>\n", key, key))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(processed).To(BeTrue())
+			Expect(result[key]).To(Equal("was empty"))
+		})
+	})
+
 	When("Cmd when supplied a clean up function", func() {
 		It("should use it", func() {
 			part1 := "I am part"
@@ -93,3 +109,20 @@ var _ = Describe("CmdGrp", func() {
 		})
 	})
 })
+
+var _ = Describe("Shell", func() {
+	AfterEach(func() {
+		Expect(clients.SetShell(clients.DefaultShell)).To(Succeed())
+	})
+	It("should default to /usr/bin/sh", func() {
+		Expect(clients.Shell()).To(Equal("/usr/bin/sh"))
+	})
+	It("should use the shell which is set", func() {
+		Expect(clients.SetShell("/bin/ash")).To(Succeed())
+		Expect(clients.Shell()).To(Equal("/bin/ash"))
+	})
+	It("should reject a shell which is not an absolute path", func() {
+		Expect(clients.SetShell("sh")).To(MatchError(ContainSubstring("absolute path")))
+		Expect(clients.Shell()).To(Equal(clients.DefaultShell))
+	})
+})
@@ -7,8 +7,11 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
+	"net"
 	"os"
 	"strings"
+	"syscall"
 	"time"
 
 	log "github.com/sirupsen/logrus"
@@ -17,36 +20,53 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 	"k8s.io/kubectl/pkg/scheme"
 )
 
 const (
-	startTimeoutDefault    = 5 * time.Second
-	deletionTimeoutDefault = 10 * time.Minute
+	// startTimeoutDefault allows for the image being pulled when the pod is first created
+	startTimeoutDefault     = 2 * time.Minute
+	deletionTimeoutDefault  = 10 * time.Minute
+	execMaxAttemptsDefault  = 3
+	execRetryBackoffDefault = 100 * time.Millisecond
 )
 
 type ExecContext interface {
 	ExecCommand([]string) (string, string, error)
 	ExecCommandStdIn([]string, bytes.Buffer) (string, string, error)
+	ExecCommandEnv([]string, map[string]string) (string, string, error)
 }
 
 var NewSPDYExecutor = remotecommand.NewSPDYExecutor
 
 // ContainerExecContext encapsulates the context in which a command is run; the namespace, pod, and container.
 type ContainerExecContext struct {
-	clientset     *Clientset
-	namespace     string
-	podName       string
-	containerName string
-	podNamePrefix string
+	clientset           *Clientset
+	namespace           string
+	podName             string
+	containerName       string
+	podNamePrefix       string
+	containerNamePrefix string
+	nodeName            string
+	maxAttempts         int
+	retryBackoff        time.Duration
 }
 
 func (c *ContainerExecContext) refresh() error {
-	newPodname, err := c.clientset.FindPodNameFromPrefix(c.namespace, c.podNamePrefix)
+	newPodname, err := c.clientset.FindPodNameFromPrefixOnNode(c.namespace, c.podNamePrefix, c.nodeName)
 	if err != nil {
 		return err
 	}
 	c.podName = newPodname
+	if c.containerNamePrefix == "" {
+		return nil
+	}
+	newContainerName, err := c.clientset.FindContainerNameFromPrefix(c.namespace, c.podName, c.containerNamePrefix)
+	if err != nil {
+		return err
+	}
+	c.containerName = newContainerName
 	return nil
 }
 
@@ -54,7 +74,16 @@ func NewContainerContext(
 	clientset *Clientset,
 	namespace, podNamePrefix, containerName string,
 ) (*ContainerExecContext, error) {
-	podName, err := clientset.FindPodNameFromPrefix(namespace, podNamePrefix)
+	return NewContainerContextOnNode(clientset, namespace, podNamePrefix, containerName, "")
+}
+
+// NewContainerContextOnNode returns a ContainerExecContext for the pod with the prefix
+// which is running on nodeName, if nodeName is empty the pod can be on any node.
+func NewContainerContextOnNode(
+	clientset *Clientset,
+	namespace, podNamePrefix, containerName, nodeName string,
+) (*ContainerExecContext, error) {
+	podName, err := clientset.WaitForPodNameFromPrefixOnNode(namespace, podNamePrefix, nodeName)
 	if err != nil {
 		return &ContainerExecContext{}, err
 	}
@@ -63,11 +92,34 @@ func NewContainerContext(
 		podName:       podName,
 		containerName: containerName,
 		podNamePrefix: podNamePrefix,
+		nodeName:      nodeName,
 		clientset:     clientset,
+		maxAttempts:   execMaxAttemptsDefault,
+		retryBackoff:  execRetryBackoffDefault,
 	}
 	return &ctx, nil
 }
 
+// NewContainerContextFromPrefixes returns a ContainerExecContext for the container with containerNamePrefix
+// in the pod with podNamePrefix which is running on nodeName. The container name is found from the pod spec
+// so the context still works when the container has been renamed, for example in deployments with sidecars.
+func NewContainerContextFromPrefixes(
+	clientset *Clientset,
+	namespace, podNamePrefix, containerNamePrefix, nodeName string,
+) (*ContainerExecContext, error) {
+	ctx, err := NewContainerContextOnNode(clientset, namespace, podNamePrefix, containerNamePrefix, nodeName)
+	if err != nil {
+		return ctx, err
+	}
+	containerName, err := clientset.FindContainerNameFromPrefix(namespace, ctx.podName, containerNamePrefix)
+	if err != nil {
+		return &ContainerExecContext{}, err
+	}
+	ctx.containerName = containerName
+	ctx.containerNamePrefix = containerNamePrefix
+	return ctx, nil
+}
+
 func (c *ContainerExecContext) GetNamespace() string {
 	return c.namespace
 }
@@ -80,8 +132,12 @@ func (c *ContainerExecContext) GetContainerName() string {
 	return c.containerName
 }
 
+func (c *ContainerExecContext) GetNodeName() string {
+	return c.nodeName
+}
+
 //nolint:lll,funlen // allow slightly long function definition and function length
-func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Buffer) (stdout, stderr string, err error) {
+func (c *ContainerExecContext) execCommandOnce(command []string, buffInPtr *bytes.Buffer) (stdout, stderr string, err error) {
 	commandStr := command
 	var buffOut bytes.Buffer
 	var buffErr bytes.Buffer
@@ -95,7 +151,8 @@ func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Bu
 		c.GetContainerName(),
 		strings.Join(commandStr, " "),
 	)
-	req := c.clientset.K8sRestClient.Post().
+	restClient, restConfig := c.clientset.getExecClients()
+	req := restClient.Post().
 		Namespace(c.GetNamespace()).
 		Resource("pods").
 		Name(c.GetPodName()).
@@ -109,7 +166,7 @@ func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Bu
 			TTY:       false,
 		}, scheme.ParameterCodec)
 
-	exec, err := NewSPDYExecutor(c.clientset.RestConfig, "POST", req.URL())
+	exec, err := NewSPDYExecutor(restConfig, "POST", req.URL())
 	if err != nil {
 		log.Debug(err)
 		return stdout, stderr, fmt.Errorf("error setting up remote command: %w", err)
@@ -130,17 +187,25 @@ func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Bu
 		}
 	}
 
-	err = exec.StreamWithContext(context.TODO(), streamOptions)
+	streamCtx := context.Background()
+	if timeout := c.clientset.ExecTimeout; timeout > 0 {
+		var cancel context.CancelFunc
+		streamCtx, cancel = context.WithTimeout(streamCtx, timeout)
+		defer cancel()
+	}
+
+	err = exec.StreamWithContext(streamCtx, streamOptions)
 	stdout, stderr = buffOut.String(), buffErr.String()
+	if errors.Is(streamCtx.Err(), context.DeadlineExceeded) {
+		log.Debugf("command %s timed out after %s", strings.Join(commandStr, " "), c.clientset.ExecTimeout)
+		return stdout, stderr, &ExecTimeoutError{Command: command, Timeout: c.clientset.ExecTimeout}
+	}
 	if err != nil {
-		if k8sErrors.IsNotFound(err) {
-			log.Debugf("Pod %s was not found, likely restarted so refreshing context", c.GetPodName())
-			refreshErr := c.refresh()
-			if refreshErr != nil {
-				log.Debug("Failed to refresh container context", refreshErr)
-			}
+		var exitErr utilexec.ExitError
+		if errors.As(err, &exitErr) && exitErr.Exited() {
+			log.Debugf("command %s exited with code %d", strings.Join(commandStr, " "), exitErr.ExitStatus())
+			return stdout, stderr, &ExitCodeError{ExitCode: exitErr.ExitStatus(), err: err}
 		}
-
 		log.Debug(err)
 		log.Debug(req.URL())
 		log.Debug("command: ", command)
@@ -149,11 +214,169 @@ func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Bu
 		}
 		log.Debug("stderr: ", stderr)
 		log.Debug("stdout: ", stdout)
-		return stdout, stderr, fmt.Errorf("error running remote command: %w", err)
+		return stdout, stderr, &streamError{err: err}
 	}
 	return stdout, stderr, nil
 }
 
+// ExecTimeoutError is returned when a command does not complete within the exec timeout
+type ExecTimeoutError struct {
+	Command []string
+	Timeout time.Duration
+}
+
+func (err *ExecTimeoutError) Error() string {
+	return fmt.Sprintf("command '%s' timed out after %s", strings.Join(err.Command, " "), err.Timeout)
+}
+
+func (err *ExecTimeoutError) Unwrap() error {
+	return context.DeadlineExceeded
+}
+
+// ExitCodeError is returned when the command was run but exited with a non-zero exit code,
+// this allows callers to tell a failing command apart from a failure to run the command
+type ExitCodeError struct {
+	err      error
+	ExitCode int
+}
+
+func (err *ExitCodeError) Error() string {
+	return fmt.Sprintf("remote command exited with code %d: %s", err.ExitCode, err.err.Error())
+}
+
+func (err *ExitCodeError) Unwrap() error {
+	return err.err
+}
+
+// GetExitCode returns the exit code of the remote command if err was caused by it exiting non-zero
+func GetExitCode(err error) (int, bool) {
+	var exitCodeErr *ExitCodeError
+	if errors.As(err, &exitCodeErr) {
+		return exitCodeErr.ExitCode, true
+	}
+	return 0, false
+}
+
+// streamError marks a failure which happened while streaming the remote
+// command as opposed to while setting it up.
+type streamError struct {
+	err error
+}
+
+func (err *streamError) Error() string {
+	return fmt.Sprintf("error running remote command: %s", err.err.Error())
+}
+
+func (err *streamError) Unwrap() error {
+	return err.err
+}
+
+// isRetryableExecError returns true for errors which are likely to be
+// caused by the pod or the connection to it going away temporarily,
+// for example the daemon being restarted.
+func isRetryableExecError(err error) bool {
+	var streamErr *streamError
+	if !errors.As(err, &streamErr) {
+		return false
+	}
+	switch {
+	case k8sErrors.IsNotFound(err),
+		k8sErrors.IsServiceUnavailable(err),
+		k8sErrors.IsServerTimeout(err),
+		k8sErrors.IsTimeout(err),
+		k8sErrors.IsTooManyRequests(err),
+		errors.Is(err, io.EOF),
+		errors.Is(err, io.ErrUnexpectedEOF),
+		errors.Is(err, syscall.ECONNRESET),
+		errors.Is(err, syscall.ECONNREFUSED),
+		errors.Is(err, syscall.EPIPE):
+		return true
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+// isAuthExecError returns true when the API server rejected the credentials used to run the command
+func isAuthExecError(err error) bool {
+	var streamErr *streamError
+	return errors.As(err, &streamErr) && k8sErrors.IsUnauthorized(err)
+}
+
+// rebuildOnAuthError rebuilds the clients if err shows the credentials were rejected,
+// it returns true if they were rebuilt so that the command can be tried again
+func (c *ContainerExecContext) rebuildOnAuthError(err error) bool {
+	if !isAuthExecError(err) {
+		return false
+	}
+	log.Warnf("command was rejected as unauthorized, rebuilding the clients: %s", err.Error())
+	rebuildErr := c.clientset.Rebuild()
+	if rebuildErr != nil {
+		log.Error(rebuildErr)
+		return false
+	}
+	return true
+}
+
+// execCommand runs the command retrying with an increasing backoff on transient errors,
+// the pod name is re-resolved between attempts in case the pod has been replaced.
+// If the credentials are rejected the clients are rebuilt from the kubeconfig and the command
+// is tried once more without counting as an attempt.
+// The duration including any retries and the final failure are recorded in the exec metrics.
+func (c *ContainerExecContext) execCommand(command []string, buffInPtr *bytes.Buffer) (stdout, stderr string, err error) {
+	start := time.Now()
+	defer func() {
+		execStats.record(time.Since(start), err)
+	}()
+	var stdin []byte
+	if buffInPtr != nil {
+		stdin = buffInPtr.Bytes()
+	}
+	backoff := c.retryBackoff
+	rebuilt := false
+	for attempt := 1; ; attempt++ {
+		var attemptBuffIn *bytes.Buffer
+		if buffInPtr != nil {
+			attemptBuffIn = bytes.NewBuffer(stdin)
+		}
+		stdout, stderr, err = c.execCommandOnce(command, attemptBuffIn)
+		if !rebuilt && c.rebuildOnAuthError(err) {
+			rebuilt = true
+			attempt--
+			continue
+		}
+		if err == nil || attempt >= c.maxAttempts || !isRetryableExecError(err) {
+			if k8sErrors.IsNotFound(err) {
+				// The pod was likely restarted so refresh the context for the next command
+				log.Debugf("Pod %s was not found, likely restarted so refreshing context", c.GetPodName())
+				if refreshErr := c.refresh(); refreshErr != nil {
+					log.Debug("Failed to refresh container context", refreshErr)
+				}
+			}
+			return stdout, stderr, err
+		}
+		log.Debugf("attempt %d of %d failed with a retryable error: %s", attempt, c.maxAttempts, err.Error())
+		time.Sleep(backoff)
+		backoff *= 2
+
+		if refreshErr := c.refresh(); refreshErr != nil {
+			log.Debug("Failed to refresh container context", refreshErr)
+		}
+	}
+}
+
+// SetMaxAttempts sets the number of times a command will be tried when it fails with a transient error
+func (c *ContainerExecContext) SetMaxAttempts(maxAttempts int) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	c.maxAttempts = maxAttempts
+}
+
+// SetRetryBackoff sets the delay before the first retry, this doubles with each subsequent retry
+func (c *ContainerExecContext) SetRetryBackoff(backoff time.Duration) {
+	c.retryBackoff = backoff
+}
+
 // ExecCommand runs command in a container and returns output buffers
 //
 //nolint:lll,funlen // allow slightly long function definition and allow a slightly long function
@@ -166,6 +389,17 @@ func (c *ContainerExecContext) ExecCommandStdIn(command []string, buffIn bytes.B
 	return c.execCommand(command, &buffIn)
 }
 
+// ExecCommandEnv runs command in a container with the variables in env set
+//
+//nolint:lll // allow slightly long function definition
+func (c *ContainerExecContext) ExecCommandEnv(command []string, env map[string]string) (stdout, stderr string, err error) {
+	envCommand, err := CommandWithEnv(command, env)
+	if err != nil {
+		return "", "", err
+	}
+	return c.execCommand(envCommand, nil)
+}
+
 // ContainerExecContext encapsulates the context in which a command is run; the namespace, pod, and container.
 type ContainerCreationExecContext struct {
 	*ContainerExecContext
@@ -173,6 +407,7 @@ type ContainerCreationExecContext struct {
 	pod                      *corev1.Pod
 	containerSecurityContext *corev1.SecurityContext
 	containerImage           string
+	serviceAccountName       string
 	command                  []string
 	volumes                  []*Volume
 	hostNetwork              bool
@@ -186,6 +421,12 @@ type Volume struct {
 	MountPath    string
 }
 
+// SetServiceAccountName sets the service account the created pod runs as,
+// this allows it to be bound to an SCC which admits its security context
+func (c *ContainerCreationExecContext) SetServiceAccountName(name string) {
+	c.serviceAccountName = name
+}
+
 func (c *ContainerCreationExecContext) createPod() error {
 	pod := &corev1.Pod{
 		ObjectMeta: metav1.ObjectMeta{
@@ -202,11 +443,15 @@ func (c *ContainerCreationExecContext) createPod() error {
 				},
 			},
 			HostNetwork: c.hostNetwork,
+			NodeName:    c.nodeName,
 		},
 	}
 	if len(c.command) > 0 {
 		pod.Spec.Containers[0].Command = c.command
 	}
+	if c.serviceAccountName != "" {
+		pod.Spec.ServiceAccountName = c.serviceAccountName
+	}
 	if c.containerSecurityContext != nil {
 		pod.Spec.Containers[0].SecurityContext = c.containerSecurityContext
 	}
@@ -222,7 +467,7 @@ func (c *ContainerCreationExecContext) createPod() error {
 		}
 	}
 
-	pod, err := c.clientset.K8sClient.CoreV1().Pods(pod.Namespace).Create(
+	pod, err := c.clientset.GetK8sClient().CoreV1().Pods(pod.Namespace).Create(
 		context.TODO(),
 		pod,
 		metav1.CreateOptions{},
@@ -235,7 +480,7 @@ func (c *ContainerCreationExecContext) createPod() error {
 }
 
 func (c *ContainerCreationExecContext) listPods(options *metav1.ListOptions) (*corev1.PodList, error) {
-	pods, err := c.clientset.K8sClient.CoreV1().Pods(c.pod.Namespace).List(
+	pods, err := c.clientset.GetK8sClient().CoreV1().Pods(c.pod.Namespace).List(
 		context.TODO(),
 		*options,
 	)
@@ -287,6 +532,50 @@ func (c *ContainerCreationExecContext) waitForPodToStart() error {
 	return errors.New("timed out waiting for pod to start")
 }
 
+// isPodHealthy returns true if the pod is running, not being deleted and its containers are ready
+func isPodHealthy(pod *corev1.Pod) bool {
+	if pod.DeletionTimestamp != nil || pod.Status.Phase != corev1.PodRunning {
+		return false
+	}
+	for i := range pod.Status.ContainerStatuses {
+		if !pod.Status.ContainerStatuses[i].Ready {
+			return false
+		}
+	}
+	return true
+}
+
+// adoptExistingPod looks for a pod with the same name left behind by a previous run.
+// A healthy pod is adopted otherwise it is deleted so that it can be recreated,
+// it returns true if a pod was adopted. A pod with the same name without
+// the ManagedByLabel was not created by a collector so is left alone and an error returned.
+func (c *ContainerCreationExecContext) adoptExistingPod() (bool, error) {
+	pod, err := c.clientset.GetK8sClient().CoreV1().Pods(c.namespace).Get(context.TODO(), c.podName, metav1.GetOptions{})
+	if k8sErrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check for an existing pod: %w", err)
+	}
+	if !isManagedPod(pod.Labels) {
+		return false, fmt.Errorf("pod %s already exists but was not created by the collector", c.podName)
+	}
+	c.pod = pod
+	if isPodHealthy(pod) {
+		log.Infof("adopting pod %s left by a previous run", c.podName)
+		return true, nil
+	}
+	log.Infof("recreating pod %s left by a previous run as it is not healthy", c.podName)
+	err = c.DeletePodAndWait()
+	if err != nil {
+		return false, err
+	}
+	c.pod = nil
+	return false, nil
+}
+
+// CreatePodAndWait creates the pod and waits for it to start,
+// a pod left behind by a previous run is adopted if it is healthy or recreated if not
 func (c *ContainerCreationExecContext) CreatePodAndWait() error {
 	var err error
 	running := false
@@ -295,6 +584,11 @@ func (c *ContainerCreationExecContext) CreatePodAndWait() error {
 		if err != nil {
 			return err
 		}
+	} else {
+		running, err = c.adoptExistingPod()
+		if err != nil {
+			return err
+		}
 	}
 	if !running {
 		err := c.createPod()
@@ -302,12 +596,21 @@ func (c *ContainerCreationExecContext) CreatePodAndWait() error {
 			return err
 		}
 	}
-	return c.waitForPodToStart()
+	err = c.waitForPodToStart()
+	if err != nil {
+		// Don't leave a pod which never started behind in the cluster
+		deleteErr := c.DeletePodAndWait()
+		if deleteErr != nil {
+			log.Errorf("failed to delete pod %s which did not start: %s", c.podName, deleteErr.Error())
+		}
+		return err
+	}
+	return nil
 }
 
 func (c *ContainerCreationExecContext) deletePod() error {
 	deletePolicy := metav1.DeletePropagationForeground
-	err := c.clientset.K8sClient.CoreV1().Pods(c.pod.Namespace).Delete(
+	err := c.clientset.GetK8sClient().CoreV1().Pods(c.pod.Namespace).Delete(
 		context.TODO(),
 		c.pod.Name,
 		metav1.DeleteOptions{
@@ -360,9 +663,11 @@ func fetchDurationEnv(key string, defaultValue time.Duration) (time.Duration, er
 	return defaultValue, nil
 }
 
+// NewContainerCreationExecContext returns a context which creates a pod to run commands in,
+// the ManagedByLabel is added to the labels of the pod
 func NewContainerCreationExecContext(
 	clientset *Clientset,
-	namespace, podName, containerName, containerImage string,
+	namespace, podName, containerName, containerImage, nodeName string,
 	labels map[string]string,
 	command []string,
 	containerSecurityContext *corev1.SecurityContext,
@@ -374,7 +679,10 @@ func NewContainerCreationExecContext(
 		podNamePrefix: podName,
 		podName:       podName,
 		containerName: containerName,
+		nodeName:      nodeName,
 		clientset:     clientset,
+		maxAttempts:   execMaxAttemptsDefault,
+		retryBackoff:  execRetryBackoffDefault,
 	}
 
 	startTimeout, err := fetchDurationEnv("COLLECTOR_POD_START_TIMEOUT", startTimeoutDefault)
@@ -387,10 +695,16 @@ func NewContainerCreationExecContext(
 		return nil, err
 	}
 
+	podLabels := make(map[string]string, len(labels)+1)
+	for key, value := range labels {
+		podLabels[key] = value
+	}
+	podLabels[ManagedByLabel] = ManagedByValue
+
 	containerCTX := ContainerCreationExecContext{
 		ContainerExecContext:     &ctx,
 		containerImage:           containerImage,
-		labels:                   labels,
+		labels:                   podLabels,
 		command:                  command,
 		containerSecurityContext: containerSecurityContext,
 		hostNetwork:              hostNetwork,
@@ -3,16 +3,26 @@
 package clients_test
 
 import (
+	"bytes"
+	"context"
 	"errors"
+	"io"
 	"net/url"
+	"os"
+	"syscall"
+	"time"
 
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
 	v1 "k8s.io/api/core/v1"
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	fakeK8s "k8s.io/client-go/kubernetes/fake"
+	k8sTesting "k8s.io/client-go/testing"
 	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
@@ -42,6 +52,7 @@ var _ = Describe("NewContainerContext", func() {
 		if err != nil {
 			panic("failed to get clientset")
 		}
+		clientset.PodLookupBackoff = 0
 	})
 
 	When("A ContainerContext is requested for a pod which DOES NOT exist", func() {
@@ -65,6 +76,63 @@ var _ = Describe("NewContainerContext", func() {
 			Expect(ctx.GetPodName()).To(Equal("TestPod-8292"))
 		})
 	})
+	When("A ContainerContext is requested for a pod which appears on the second lookup", func() {
+		It("should retry the lookup and return the context for that pod", func() {
+			fakeK8sClient := fakeK8s.NewSimpleClientset(notATestPod, testPod)
+			lookups := 0
+			fakeK8sClient.PrependReactor("list", "pods", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+				lookups++
+				if lookups == 1 {
+					return true, &v1.PodList{}, nil
+				}
+				return false, nil, nil
+			})
+			clientset.K8sClient = fakeK8sClient
+			clientset.PodLookupAttempts = 3
+			clientset.PodLookupBackoff = time.Millisecond
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.GetPodName()).To(Equal("TestPod-8292"))
+			Expect(lookups).To(Equal(2))
+		})
+	})
+	When("A ContainerContext is requested for a pod which never appears", func() {
+		It("should give up after the configured number of lookups", func() {
+			fakeK8sClient := fakeK8s.NewSimpleClientset(notATestPod)
+			lookups := 0
+			fakeK8sClient.PrependReactor("list", "pods", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+				lookups++
+				return false, nil, nil
+			})
+			clientset.K8sClient = fakeK8sClient
+			clientset.PodLookupAttempts = 3
+			clientset.PodLookupBackoff = time.Millisecond
+
+			_, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).To(HaveOccurred())
+			Expect(lookups).To(Equal(3))
+		})
+	})
+	When("A ContainerContext is requested for a prefix several pods have", func() {
+		It("should return the error without retrying", func() {
+			secondTestPod := testPod.DeepCopy()
+			secondTestPod.Name = "TestPod-1234"
+			fakeK8sClient := fakeK8s.NewSimpleClientset(testPod, secondTestPod)
+			lookups := 0
+			fakeK8sClient.PrependReactor("list", "pods", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+				lookups++
+				return false, nil, nil
+			})
+			clientset.K8sClient = fakeK8sClient
+			clientset.PodLookupAttempts = 3
+			clientset.PodLookupBackoff = time.Millisecond
+
+			_, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).To(MatchError(ContainSubstring("a node name is required")))
+			Expect(lookups).To(Equal(1))
+		})
+	})
 })
 
 var _ = Describe("ExecCommandContainer", func() {
@@ -128,4 +196,475 @@ var _ = Describe("ExecCommandContainer", func() {
 			Expect(stderr).To(Equal(expectedStdErr))
 		})
 	})
+
+	When("SteamWithContext is rejected as unauthorized", func() {
+		It("should rebuild the clients and succeed on the next call", func() {
+			calls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				calls++
+				if calls == 1 {
+					return []byte(""), []byte(""), k8sErrors.NewUnauthorized("token has expired")
+				}
+				return []byte("my test command stdout"), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			ctx.SetMaxAttempts(1)
+			restConfig := clientset.RestConfig
+			stdout, _, err := ctx.ExecCommand([]string{"my", "test", "command"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal(2))
+			Expect(stdout).To(Equal("my test command stdout"))
+			Expect(clientset.RestConfig).NotTo(BeIdenticalTo(restConfig))
+		})
+		It("should only rebuild the clients once", func() {
+			calls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				calls++
+				return []byte(""), []byte(""), k8sErrors.NewUnauthorized("token has expired")
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			_, _, err := ctx.ExecCommand([]string{"my", "test", "command"})
+			Expect(k8sErrors.IsUnauthorized(err)).To(BeTrue())
+			Expect(calls).To(Equal(2))
+		})
+	})
+
+	When("SteamWithContext fails with a transient error", func() {
+		It("should retry and return the result of the successful attempt", func() {
+			expectedStdOut := "my test command stdout"
+			expectedStdErr := "my test command stderr"
+			calls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				calls++
+				if calls == 1 {
+					return []byte(""), []byte(""), syscall.ECONNRESET
+				}
+				stdin, _ := io.ReadAll(options.Stdin)
+				return []byte(expectedStdOut + string(stdin)), []byte(expectedStdErr), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			ctx.SetRetryBackoff(time.Millisecond)
+			cmd := []string{"my", "test", "command"}
+			stdout, stderr, err := ctx.ExecCommandStdIn(cmd, *bytes.NewBufferString(" with stdin"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal(2))
+			Expect(stdout).To(Equal(expectedStdOut + " with stdin"))
+			Expect(stderr).To(Equal(expectedStdErr))
+		})
+	})
+	When("SteamWithContext keeps failing with a transient error", func() {
+		It("should give up after the max attempts", func() {
+			calls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				calls++
+				return []byte(""), []byte(""), io.ErrUnexpectedEOF
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			ctx.SetRetryBackoff(time.Millisecond)
+			ctx.SetMaxAttempts(4)
+			_, _, err := ctx.ExecCommand([]string{"my", "test", "command"})
+			Expect(err).To(HaveOccurred())
+			Expect(calls).To(Equal(4))
+		})
+	})
+	When("SteamWithContext fails because the pod is not found on the last attempt", func() {
+		It("should refresh the pod for the next command", func() {
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte(""), []byte(""), k8sErrors.NewNotFound(v1.Resource("pods"), "TestPod-8292")
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			ctx.SetMaxAttempts(1)
+			pods := clientset.K8sClient.CoreV1().Pods("TestNamespace")
+			Expect(pods.Delete(context.TODO(), testPod.Name, metav1.DeleteOptions{})).To(Succeed())
+			restartedPod := testPod.DeepCopy()
+			restartedPod.Name = "TestPod-1111"
+			_, err := pods.Create(context.TODO(), restartedPod, metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, _, err = ctx.ExecCommand([]string{"my", "test", "command"})
+			Expect(k8sErrors.IsNotFound(err)).To(BeTrue())
+			Expect(ctx.GetPodName()).To(Equal("TestPod-1111"))
+		})
+	})
+	When("SteamWithContext fails with a non-transient error", func() {
+		It("should not retry", func() {
+			calls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				calls++
+				return []byte(""), []byte(""), errors.New("Something went horribly wrong with the stream")
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			ctx.SetRetryBackoff(time.Millisecond)
+			_, _, err := ctx.ExecCommand([]string{"my", "test", "command"})
+			Expect(err).To(HaveOccurred())
+			Expect(calls).To(Equal(1))
+		})
+	})
+	When("the command does not complete within the exec timeout", func() {
+		It("should return an ExecTimeoutError", func() {
+			unblock := make(chan bool)
+			defer close(unblock)
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				<-unblock
+				return []byte(""), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			clientset.ExecTimeout = 10 * time.Millisecond
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			ctx.SetRetryBackoff(time.Millisecond)
+			start := time.Now()
+			_, _, err := ctx.ExecCommand([]string{"timeout", "head", "/dev/gnss0"})
+			Expect(time.Since(start)).To(BeNumerically("<", time.Second))
+			Expect(err).To(HaveOccurred())
+			var timeoutErr *clients.ExecTimeoutError
+			Expect(errors.As(err, &timeoutErr)).To(BeTrue())
+			Expect(timeoutErr.Timeout).To(Equal(10 * time.Millisecond))
+			Expect(err.Error()).To(ContainSubstring("timed out"))
+		})
+	})
+	When("the command exits with a non-zero exit code", func() {
+		It("should return an ExitCodeError with the code and the std buffers", func() {
+			expectedStdErr := "cat: /sys/class/net/ens7f0/device/dpll_1_offset: No such file or directory"
+			calls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				calls++
+				return []byte(""), []byte(expectedStdErr), utilexec.CodeExitError{
+					Err:  errors.New("command terminated with exit code 1"),
+					Code: 1,
+				}
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			ctx.SetRetryBackoff(time.Millisecond)
+			_, stderr, err := ctx.ExecCommand([]string{"cat", "/sys/class/net/ens7f0/device/dpll_1_offset"})
+			Expect(err).To(HaveOccurred())
+			Expect(calls).To(Equal(1))
+			Expect(stderr).To(Equal(expectedStdErr))
+			var exitCodeErr *clients.ExitCodeError
+			Expect(errors.As(err, &exitCodeErr)).To(BeTrue())
+			Expect(exitCodeErr.ExitCode).To(Equal(1))
+			code, ok := clients.GetExitCode(err)
+			Expect(ok).To(BeTrue())
+			Expect(code).To(Equal(1))
+		})
+	})
+	When("an env is given", func() {
+		It("should run the command with the env set", func() {
+			var executed []string
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				executed = url.Query()["command"]
+				return []byte("1686916187"), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			stdout, _, err := ctx.ExecCommandEnv(
+				[]string{"date", "+%s"},
+				map[string]string{"TZ": "UTC", "LD_LIBRARY_PATH": "/usr/local/lib"},
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("1686916187"))
+			Expect(executed).To(Equal([]string{"env", "LD_LIBRARY_PATH=/usr/local/lib", "TZ=UTC", "date", "+%s"}))
+		})
+		It("should not run the command when a variable name is invalid", func() {
+			calls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				calls++
+				return []byte(""), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			_, _, err := ctx.ExecCommandEnv([]string{"date"}, map[string]string{"TZ; rm": "UTC"})
+			Expect(err).To(HaveOccurred())
+			Expect(calls).To(BeZero())
+		})
+	})
+	When("the command can not be run", func() {
+		It("should not return an ExitCodeError", func() {
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte(""), []byte(""), errors.New("Something went horribly wrong with the stream")
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			_, _, err := ctx.ExecCommand([]string{"my", "test", "command"})
+			Expect(err).To(HaveOccurred())
+			_, ok := clients.GetExitCode(err)
+			Expect(ok).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("GetExecMetrics", func() {
+	var clientset *clients.Clientset
+	var before clients.ExecMetrics
+	BeforeEach(func() {
+		clientset = testutils.GetMockedClientSet(testPod)
+		// The metrics are shared by every test so only the change is checked
+		before = clients.GetExecMetrics()
+	})
+
+	execWith := func(
+		responder func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error),
+		creationErr error,
+	) error {
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, creationErr)
+		ctx, _ := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+		ctx.SetRetryBackoff(time.Millisecond)
+		_, _, err := ctx.ExecCommand([]string{"my", "test", "command"})
+		return err
+	}
+	failuresSince := func(failureType clients.ExecFailureType) int64 {
+		return clients.GetExecMetrics().Failures[failureType] - before.Failures[failureType]
+	}
+
+	When("a command succeeds", func() {
+		It("should count it in every bucket above its duration without a failure", func() {
+			err := execWith(func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte("ok"), []byte(""), nil
+			}, nil)
+			Expect(err).NotTo(HaveOccurred())
+			after := clients.GetExecMetrics()
+			Expect(after.Count - before.Count).To(Equal(int64(1)))
+			Expect(after.Sum).To(BeNumerically(">=", before.Sum))
+			buckets := clients.ExecLatencyBuckets()
+			Expect(after.BucketCounts).To(HaveLen(len(buckets)))
+			last := len(buckets) - 1
+			Expect(after.BucketCounts[last] - before.BucketCounts[last]).To(Equal(int64(1)))
+			for i := 1; i < len(buckets); i++ {
+				Expect(after.BucketCounts[i]).To(BeNumerically(">=", after.BucketCounts[i-1]))
+			}
+			for failureType, count := range after.Failures {
+				Expect(count).To(Equal(before.Failures[failureType]), failureType.String())
+			}
+		})
+	})
+	When("a command exits with a non-zero exit code", func() {
+		It("should count an exit-code failure", func() {
+			err := execWith(func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte(""), []byte(""), utilexec.CodeExitError{
+					Err:  errors.New("command terminated with exit code 2"),
+					Code: 2,
+				}
+			}, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(failuresSince(clients.ExecExitedNonZero)).To(Equal(int64(1)))
+			Expect(failuresSince(clients.ExecStreamFailed)).To(BeZero())
+		})
+	})
+	When("the stream fails", func() {
+		It("should count a stream failure", func() {
+			err := execWith(func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte(""), []byte(""), errors.New("Something went horribly wrong with the stream")
+			}, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(failuresSince(clients.ExecStreamFailed)).To(Equal(int64(1)))
+		})
+	})
+	When("the executor can not be created", func() {
+		It("should count a setup failure", func() {
+			err := execWith(func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte(""), []byte(""), nil
+			}, errors.New("Something went horribly wrong when creating the executor"))
+			Expect(err).To(HaveOccurred())
+			Expect(failuresSince(clients.ExecSetupFailed)).To(Equal(int64(1)))
+		})
+	})
+	When("the command does not complete within the exec timeout", func() {
+		It("should count a timeout failure", func() {
+			unblock := make(chan bool)
+			defer close(unblock)
+			clientset.ExecTimeout = 10 * time.Millisecond
+			err := execWith(func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				<-unblock
+				return []byte(""), []byte(""), nil
+			}, nil)
+			Expect(err).To(HaveOccurred())
+			Expect(failuresSince(clients.ExecTimedOut)).To(Equal(int64(1)))
+			Expect(clients.GetExecMetrics().Sum - before.Sum).To(BeNumerically(">=", 10*time.Millisecond))
+		})
+	})
+})
+
+var testCreationLabels = map[string]string{"app": "vse-sync-collector"}
+
+func newTestCreationContext(clientset *clients.Clientset) (*clients.ContainerCreationExecContext, error) {
+	return clients.NewContainerCreationExecContext(
+		clientset,
+		"TestNamespace",
+		"TestPod",
+		"TestContainer",
+		"TestImage",
+		"TestNode",
+		testCreationLabels,
+		[]string{"sleep", "inf"},
+		nil,
+		false,
+		nil,
+	)
+}
+
+// startCreatedPods makes the fake cluster report created pods as running
+func startCreatedPods(fakeK8sClient *fakeK8s.Clientset) {
+	fakeK8sClient.PrependReactor("create", "pods", func(action k8sTesting.Action) (bool, runtime.Object, error) {
+		createAction, ok := action.(k8sTesting.CreateAction)
+		if ok {
+			if pod, isPod := createAction.GetObject().(*v1.Pod); isPod {
+				pod.Status.Phase = v1.PodRunning
+			}
+		}
+		// Let the default reactor store the pod
+		return false, nil, nil
+	})
+}
+
+// leftoverPod returns a pod with the name of the test creation context as if left behind by a previous run
+func leftoverPod(labels map[string]string, phase v1.PodPhase) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "TestPod",
+			Namespace: "TestNamespace",
+			Labels:    labels,
+		},
+		Status: v1.PodStatus{Phase: phase},
+	}
+}
+
+func countPodActions(fakeK8sClient *fakeK8s.Clientset, verb string) int {
+	count := 0
+	for _, action := range fakeK8sClient.Actions() {
+		if action.GetVerb() == verb && action.GetResource().Resource == "pods" {
+			count++
+		}
+	}
+	return count
+}
+
+var _ = Describe("ContainerCreationExecContext", func() {
+	When("the start timeout is not a valid duration", func() {
+		It("should return an error", func() {
+			Expect(os.Setenv("COLLECTOR_POD_START_TIMEOUT", "soon")).To(Succeed())
+			DeferCleanup(os.Unsetenv, "COLLECTOR_POD_START_TIMEOUT")
+
+			_, err := newTestCreationContext(testutils.GetMockedClientSet())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("COLLECTOR_POD_START_TIMEOUT"))
+		})
+	})
+
+	When("the created pod starts", func() {
+		It("should create the pod and be able to exec in it", func() {
+			clientset := testutils.GetMockedClientSet()
+			fakeK8sClient, ok := clientset.K8sClient.(*fakeK8s.Clientset)
+			Expect(ok).To(BeTrue())
+			startCreatedPods(fakeK8sClient)
+
+			ctx, err := newTestCreationContext(clientset)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.CreatePodAndWait()).To(Succeed())
+			Expect(countPodActions(fakeK8sClient, "create")).To(Equal(1))
+			Expect(ctx.GetPodName()).To(Equal("TestPod"))
+			pod, err := fakeK8sClient.CoreV1().Pods("TestNamespace").Get(context.TODO(), "TestPod", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pod.Labels).To(Equal(map[string]string{
+				"app":                  "vse-sync-collector",
+				clients.ManagedByLabel: clients.ManagedByValue,
+			}))
+
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte("my cool response"), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			stdout, _, err := ctx.ExecCommand([]string{"my", "command"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("my cool response"))
+		})
+	})
+
+	When("the pod has already been created and is running", func() {
+		It("should reuse the pod rather than create another", func() {
+			clientset := testutils.GetMockedClientSet()
+			fakeK8sClient, ok := clientset.K8sClient.(*fakeK8s.Clientset)
+			Expect(ok).To(BeTrue())
+			startCreatedPods(fakeK8sClient)
+
+			ctx, err := newTestCreationContext(clientset)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.CreatePodAndWait()).To(Succeed())
+			Expect(ctx.CreatePodAndWait()).To(Succeed())
+			Expect(countPodActions(fakeK8sClient, "create")).To(Equal(1))
+		})
+	})
+
+	When("a healthy pod was left behind by a previous run", func() {
+		It("should adopt the pod rather than create another", func() {
+			clientset := testutils.GetMockedClientSet(leftoverPod(map[string]string{clients.ManagedByLabel: clients.ManagedByValue}, v1.PodRunning))
+			fakeK8sClient, ok := clientset.K8sClient.(*fakeK8s.Clientset)
+			Expect(ok).To(BeTrue())
+
+			ctx, err := newTestCreationContext(clientset)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.CreatePodAndWait()).To(Succeed())
+			Expect(countPodActions(fakeK8sClient, "create")).To(Equal(0))
+			Expect(countPodActions(fakeK8sClient, "delete")).To(Equal(0))
+		})
+	})
+
+	When("a pod which is not running was left behind by a previous run", func() {
+		It("should delete the pod and create a new one", func() {
+			clientset := testutils.GetMockedClientSet(leftoverPod(map[string]string{clients.ManagedByLabel: clients.ManagedByValue}, v1.PodFailed))
+			fakeK8sClient, ok := clientset.K8sClient.(*fakeK8s.Clientset)
+			Expect(ok).To(BeTrue())
+			startCreatedPods(fakeK8sClient)
+
+			ctx, err := newTestCreationContext(clientset)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ctx.CreatePodAndWait()).To(Succeed())
+			Expect(countPodActions(fakeK8sClient, "delete")).To(Equal(1))
+			Expect(countPodActions(fakeK8sClient, "create")).To(Equal(1))
+			pod, err := fakeK8sClient.CoreV1().Pods("TestNamespace").Get(context.TODO(), "TestPod", metav1.GetOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pod.Status.Phase).To(Equal(v1.PodRunning))
+		})
+	})
+
+	When("a pod with the same name was not created by a collector", func() {
+		It("should return an error and leave the pod alone", func() {
+			clientset := testutils.GetMockedClientSet(leftoverPod(map[string]string{"app": "other"}, v1.PodRunning))
+			fakeK8sClient, ok := clientset.K8sClient.(*fakeK8s.Clientset)
+			Expect(ok).To(BeTrue())
+
+			ctx, err := newTestCreationContext(clientset)
+			Expect(err).NotTo(HaveOccurred())
+			err = ctx.CreatePodAndWait()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("not created by the collector"))
+			Expect(countPodActions(fakeK8sClient, "create")).To(Equal(0))
+			Expect(countPodActions(fakeK8sClient, "delete")).To(Equal(0))
+		})
+	})
+
+	When("the created pod does not start", func() {
+		It("should delete the pod and return an error", func() {
+			Expect(os.Setenv("COLLECTOR_POD_START_TIMEOUT", "10ms")).To(Succeed())
+			DeferCleanup(os.Unsetenv, "COLLECTOR_POD_START_TIMEOUT")
+
+			clientset := testutils.GetMockedClientSet()
+			ctx, err := newTestCreationContext(clientset)
+			Expect(err).NotTo(HaveOccurred())
+			err = ctx.CreatePodAndWait()
+			Expect(err).To(HaveOccurred())
+
+			fakeK8sClient, ok := clientset.K8sClient.(*fakeK8s.Clientset)
+			Expect(ok).To(BeTrue())
+			Expect(countPodActions(fakeK8sClient, "delete")).To(BeNumerically(">", 0))
+			pods, err := fakeK8sClient.CoreV1().Pods("TestNamespace").List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pods.Items).To(BeEmpty())
+		})
+	})
 })
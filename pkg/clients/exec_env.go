@@ -0,0 +1,35 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+)
+
+var envNameRegex = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// CommandWithEnv returns command run by env with the variables in env set,
+// the pod exec API has no way to pass environment so it is set by the command itself.
+// The variables are sorted so the same env always gives the same command.
+func CommandWithEnv(command []string, env map[string]string) ([]string, error) {
+	if len(env) == 0 {
+		return command, nil
+	}
+	names := make([]string, 0, len(env))
+	for name := range env {
+		if !envNameRegex.MatchString(name) {
+			return nil, fmt.Errorf("invalid environment variable name %q", name)
+		}
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	envCommand := make([]string, 0, 1+len(names)+len(command))
+	envCommand = append(envCommand, "env")
+	for _, name := range names {
+		envCommand = append(envCommand, name+"="+env[name])
+	}
+	return append(envCommand, command...), nil
+}
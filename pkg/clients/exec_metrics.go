@@ -0,0 +1,124 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"errors"
+	"sync/atomic"
+	"time"
+
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// ExecFailureType classifies why a command executed in a container failed
+type ExecFailureType int
+
+const (
+	// ExecNotFound is a command whose pod or container could not be found
+	ExecNotFound ExecFailureType = iota
+	// ExecTimedOut is a command which did not complete within the exec timeout
+	ExecTimedOut
+	// ExecStreamFailed is a command whose stream failed e.g. the connection was reset
+	ExecStreamFailed
+	// ExecExitedNonZero is a command which ran but exited with a non-zero exit code
+	ExecExitedNonZero
+	// ExecSetupFailed is a command which could not be set up to run
+	ExecSetupFailed
+	execFailureTypes
+)
+
+var execFailureTypeNames = [execFailureTypes]string{"not-found", "timeout", "stream", "exit-code", "setup"}
+
+func (failureType ExecFailureType) String() string {
+	return execFailureTypeNames[failureType]
+}
+
+// execLatencyBuckets are the upper bounds of the buckets of the exec duration histogram
+var execLatencyBuckets = [...]time.Duration{
+	50 * time.Millisecond,
+	100 * time.Millisecond,
+	250 * time.Millisecond,
+	500 * time.Millisecond,
+	time.Second,
+	2500 * time.Millisecond,
+	5 * time.Second,
+	10 * time.Second,
+}
+
+// execMetrics counts with atomics so that recording does not allocate or lock,
+// the last bucket counts the commands slower than every bound
+type execMetrics struct {
+	buckets     [len(execLatencyBuckets) + 1]int64
+	failures    [execFailureTypes]int64
+	count       int64
+	sumDuration int64
+}
+
+var execStats = execMetrics{}
+
+// classifyExecError returns the type of failure of a command run by execCommand
+func classifyExecError(err error) ExecFailureType {
+	var timeoutErr *ExecTimeoutError
+	var streamErr *streamError
+	switch {
+	case errors.As(err, &timeoutErr):
+		return ExecTimedOut
+	case k8sErrors.IsNotFound(err):
+		return ExecNotFound
+	case errors.As(err, new(*ExitCodeError)):
+		return ExecExitedNonZero
+	case errors.As(err, &streamErr):
+		return ExecStreamFailed
+	default:
+		return ExecSetupFailed
+	}
+}
+
+func (m *execMetrics) record(duration time.Duration, err error) {
+	bucket := len(execLatencyBuckets)
+	for i, bound := range execLatencyBuckets {
+		if duration <= bound {
+			bucket = i
+			break
+		}
+	}
+	atomic.AddInt64(&m.buckets[bucket], 1)
+	atomic.AddInt64(&m.count, 1)
+	atomic.AddInt64(&m.sumDuration, int64(duration))
+	if err != nil {
+		atomic.AddInt64(&m.failures[classifyExecError(err)], 1)
+	}
+}
+
+// ExecLatencyBuckets returns the upper bounds of the buckets of the exec duration histogram
+func ExecLatencyBuckets() []time.Duration {
+	return append([]time.Duration{}, execLatencyBuckets[:]...)
+}
+
+// ExecMetrics is a snapshot of the latency and failures of the commands executed in containers
+type ExecMetrics struct {
+	// BucketCounts holds the cumulative count for each of the ExecLatencyBuckets()
+	BucketCounts []int64
+	Failures     map[ExecFailureType]int64
+	Count        int64
+	Sum          time.Duration
+}
+
+// GetExecMetrics returns the metrics of every command executed in a container by this process
+func GetExecMetrics() ExecMetrics {
+	metrics := ExecMetrics{
+		BucketCounts: make([]int64, len(execLatencyBuckets)),
+		Failures:     make(map[ExecFailureType]int64, execFailureTypes),
+		Count:        atomic.LoadInt64(&execStats.count),
+		Sum:          time.Duration(atomic.LoadInt64(&execStats.sumDuration)),
+	}
+	var cumulative int64
+	for i := range execLatencyBuckets {
+		cumulative += atomic.LoadInt64(&execStats.buckets[i])
+		metrics.BucketCounts[i] = cumulative
+	}
+	for failureType := ExecFailureType(0); failureType < execFailureTypes; failureType++ {
+		metrics.Failures[failureType] = atomic.LoadInt64(&execStats.failures[failureType])
+	}
+	return metrics
+}
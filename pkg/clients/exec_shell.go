@@ -0,0 +1,36 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"fmt"
+	"path"
+	"sync"
+)
+
+// DefaultShell is the shell which the scripts built from Cmds are piped to
+const DefaultShell = "/usr/bin/sh"
+
+var (
+	shell     = DefaultShell
+	shellLock sync.RWMutex
+)
+
+// SetShell sets the shell which the scripts built from Cmds are piped to, containers
+// which do not have /usr/bin/sh may still have another POSIX shell such as /bin/sh or /bin/ash
+func SetShell(shellPath string) error {
+	if !path.IsAbs(shellPath) {
+		return fmt.Errorf("the shell must be an absolute path in the container, got %q", shellPath)
+	}
+	shellLock.Lock()
+	defer shellLock.Unlock()
+	shell = shellPath
+	return nil
+}
+
+// Shell returns the shell which the scripts built from Cmds are piped to
+func Shell() string {
+	shellLock.RLock()
+	defer shellLock.RUnlock()
+	return shell
+}
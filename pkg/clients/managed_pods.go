@@ -0,0 +1,56 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients
+
+import (
+	"context"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ManagedByLabel is set on every pod created by the collector
+	// so that pods left behind can be identified and cleaned up
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+	ManagedByValue = "vse-sync-collection-tools"
+)
+
+// ManagedPodSelector returns the label selector for the pods created by the collector
+func ManagedPodSelector() string {
+	return ManagedByLabel + "=" + ManagedByValue
+}
+
+// isManagedPod returns true if the labels are those of a pod created by the collector
+func isManagedPod(labels map[string]string) bool {
+	return labels[ManagedByLabel] == ManagedByValue
+}
+
+// DeleteManagedPods deletes every pod created by the collector in the namespace
+// and returns the names of the pods which were deleted
+func DeleteManagedPods(clientset *Clientset, namespace string) ([]string, error) {
+	pods, err := clientset.GetK8sClient().CoreV1().Pods(namespace).List(
+		context.TODO(),
+		metav1.ListOptions{LabelSelector: ManagedPodSelector()},
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list collector pods: %w", err)
+	}
+	deletePolicy := metav1.DeletePropagationForeground
+	deleted := make([]string, 0, len(pods.Items))
+	for i := range pods.Items {
+		name := pods.Items[i].Name
+		err = clientset.GetK8sClient().CoreV1().Pods(namespace).Delete(
+			context.TODO(),
+			name,
+			metav1.DeleteOptions{PropagationPolicy: &deletePolicy},
+		)
+		if err != nil {
+			return deleted, fmt.Errorf("failed to delete pod %s: %w", name, err)
+		}
+		log.Infof("deleted collector pod %s/%s", namespace, name)
+		deleted = append(deleted, name)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package clients_test
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	fakeK8s "k8s.io/client-go/kubernetes/fake"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+func namedPod(namespace, name string, labels map[string]string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+			Labels:    labels,
+		},
+	}
+}
+
+var _ = Describe("DeleteManagedPods", func() {
+	When("there are collector pods in the namespace", func() {
+		It("should delete only the pods with the ownership label", func() {
+			managed := map[string]string{clients.ManagedByLabel: clients.ManagedByValue}
+			clientset := testutils.GetMockedClientSet(
+				namedPod("TestNamespace", "collector-pod", managed),
+				namedPod("TestNamespace", "linuxptp-daemon-abcde", map[string]string{"app": "linuxptp-daemon"}),
+				namedPod("OtherNamespace", "other-collector-pod", managed),
+			)
+
+			deleted, err := clients.DeleteManagedPods(clientset, "TestNamespace")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(Equal([]string{"collector-pod"}))
+
+			fakeK8sClient, ok := clientset.K8sClient.(*fakeK8s.Clientset)
+			Expect(ok).To(BeTrue())
+			pods, err := fakeK8sClient.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			names := make([]string, 0, len(pods.Items))
+			for i := range pods.Items {
+				names = append(names, pods.Items[i].Name)
+			}
+			Expect(names).To(ConsistOf("linuxptp-daemon-abcde", "other-collector-pod"))
+		})
+	})
+	When("there are no collector pods in the namespace", func() {
+		It("should delete nothing", func() {
+			clientset := testutils.GetMockedClientSet(namedPod("TestNamespace", "TestPod-8292", nil))
+			deleted, err := clients.DeleteManagedPods(clientset, "TestNamespace")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deleted).To(BeEmpty())
+		})
+	})
+})
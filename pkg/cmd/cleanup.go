@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var cleanupNamespace string
+
+// cleanupCmd represents the collect cleanup command
+var cleanupCmd = &cobra.Command{
+	Use:   "cleanup",
+	Short: "Delete the pods left behind by the collectors",
+	Long: `Delete every pod in the namespace which was created by the collectors,
+these are found by their ` + clients.ManagedByLabel + ` label`,
+	Run: func(cmd *cobra.Command, args []string) {
+		clientset, err := clients.GetClientset(kubeConfig)
+		utils.IfErrorExitOrPanic(err)
+		deleted, err := clients.DeleteManagedPods(clientset, cleanupNamespace)
+		for _, name := range deleted {
+			fmt.Fprintf(cmd.OutOrStdout(), "deleted pod %s/%s\n", cleanupNamespace, name)
+		}
+		utils.IfErrorExitOrPanic(err)
+	},
+}
+
+func init() {
+	collectCmd.AddCommand(cleanupCmd)
+
+	AddKubeconfigFlag(cleanupCmd)
+	cleanupCmd.Flags().StringVarP(
+		&cleanupNamespace,
+		"namespace",
+		"n",
+		contexts.PTPNamespace,
+		"Namespace to delete the collector pods from",
+	)
+}
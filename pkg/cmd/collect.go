@@ -9,12 +9,18 @@ import (
 	"os/user"
 	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
 
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/runner"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
@@ -26,27 +32,190 @@ const (
 	defaultIncludeLogTimestamps bool   = false
 	defaultTempDir              string = "."
 	defaultKeepDebugFiles       bool   = false
+	defaultStrictCollectors     bool   = true
 	tempdirPerm                        = 0755
+	unsetRunAsUser              int64  = -1
+	// runIDFormat is a time layout which is a valid label value
+	runIDFormat = "20060102-150405"
 )
 
+// interfaceIndependentCollectors collect from the node rather than an interface
+// so are only run once when collecting from discovered interfaces
+var interfaceIndependentCollectors = []string{
+	collectors.LogsCollectorName,
+	collectors.PMCCollectorName,
+	collectors.PMCTimeStatusCollectorName,
+	collectors.PTPConfigCollectorName,
+	collectors.SysfsCollectorName,
+}
+
 var (
 	requestedDurationStr   string
+	pollCount              int
 	pollInterval           int
 	devInfoAnnouceInterval int
+	announceOnce           bool
+	flushOnAnnounce        bool
 	collectorNames         []string
+	strictCollectors       bool
 	logsOutputFile         string
 	includeLogTimestamps   bool
 	tempDir                string
 	keepDebugFiles         bool
+	execTimeout            time.Duration
+	podLookupAttempts      int
+	podLookupBackoff       time.Duration
+	deadline               time.Duration
+	podRunAsUser           int64
+	podPrivileged          bool
+	podCapabilities        []string
+	podServiceAccount      string
+	podVolumes             []string
+	podLabels              []string
+	splitOutputDir         string
+	outputDir              string
+	appendOutput           bool
+	outputBufferSize       int
+	gzipLevel              int
+	flushInterval          time.Duration
+	validateOutput         bool
+	sampleEvery            int
+	fieldSelections        []string
+	outputFields           map[string][]string
+	redactSelections       []string
+	redactedFields         map[string][]string
+	asyncQueueSize         int
+	asyncQueuePolicyName   string
+	asyncQueuePolicy       callbacks.QueueFullPolicy
+	outputToStdout         bool
+	expectedRFBlocks       int
+	strictRFBlocks         bool
+	dpllSmoothingAlpha     float64
+	dpllChangesOnly        bool
+	gnssSource             string
+	gnssExtraMessages      []string
+	listenAddress          string
+	pprofAddress           string
+	maxConcurrency         int
+	writeRunManifest       bool
+	warmup                 time.Duration
+	interfaceAutoDiscover  bool
+	allNodes               bool
+	skipDeviceCheck        bool
+	strictParse            bool
+	noCoreutils            bool
+	execShell              string
+	includeRaw             bool
+	sysfsSpecs             []string
+	sysfsAttributes        map[string]string
+	gnssDeviceIndex        int
 )
 
+func isValidGNSSSource(source string) bool {
+	for _, validSource := range devices.GPSSources {
+		if source == validSource {
+			return true
+		}
+	}
+	return false
+}
+
+// checkGNSSFlags returns an error if the GNSS source or extra messages are invalid
+func checkGNSSFlags() error {
+	if !isValidGNSSSource(gnssSource) {
+		return utils.NewMissingInputError(
+			fmt.Errorf("--gnss-source must be one of: %s", strings.Join(devices.GPSSources, ", ")),
+		)
+	}
+	if gnssDeviceIndex < 0 {
+		return utils.NewMissingInputError(errors.New("--gnss-device-index must not be negative"))
+	}
+	if len(gnssExtraMessages) == 0 {
+		return nil
+	}
+	if gnssSource != devices.GPSSourceUBXTool {
+		return utils.NewMissingInputError(
+			fmt.Errorf("--gnss-extra-messages can only be used with --gnss-source %s", devices.GPSSourceUBXTool),
+		)
+	}
+	if _, err := devices.UBXCommand(gnssExtraMessages); err != nil {
+		return utils.NewMissingInputError(fmt.Errorf("invalid --gnss-extra-messages: %w", err))
+	}
+	return nil
+}
+
+// checkCompressionFlags returns an error if the gzip level or flush interval are invalid
+func checkCompressionFlags() error {
+	if flushInterval < 0 {
+		return utils.NewMissingInputError(errors.New("--flush-interval must not be negative"))
+	}
+	if gzipLevel == callbacks.GzipDisabled {
+		return nil
+	}
+	if err := callbacks.ValidateGzipLevel(gzipLevel); err != nil {
+		return utils.NewMissingInputError(fmt.Errorf("invalid --gzip-level: %w", err))
+	}
+	if splitOutputDir != "" {
+		return utils.NewMissingInputError(errors.New("--gzip-level can not be used with --split-output"))
+	}
+	return nil
+}
+
+// getPodOptions builds the options for pods created by the collectors from the flags,
+// the run as user and privileged settings are only set if they were passed
+func getPodOptions(cmd *cobra.Command) (*contexts.PodOptions, error) {
+	volumes, err := contexts.ParseVolumes(podVolumes)
+	if err != nil {
+		return nil, utils.NewMissingInputError(err)
+	}
+	labels, err := contexts.ParseLabels(podLabels)
+	if err != nil {
+		return nil, utils.NewMissingInputError(err)
+	}
+	podOptions := &contexts.PodOptions{
+		Capabilities:   podCapabilities,
+		ServiceAccount: podServiceAccount,
+		Volumes:        volumes,
+		Labels:         labels,
+		RunID:          time.Now().UTC().Format(runIDFormat),
+	}
+	if podRunAsUser != unsetRunAsUser {
+		runAsUser := podRunAsUser
+		podOptions.RunAsUser = &runAsUser
+	}
+	if cmd.Flags().Changed("pod-privileged") {
+		privileged := podPrivileged
+		podOptions.Privileged = &privileged
+	}
+	return podOptions, nil
+}
+
+// checkPollCount returns an error if the poll count is invalid or is requested with a duration
+func checkPollCount(cmd *cobra.Command) error {
+	if !cmd.Flags().Changed("count") {
+		return nil
+	}
+	if cmd.Flags().Changed("duration") {
+		return utils.NewMissingInputError(errors.New("--count and --duration can not be used together"))
+	}
+	if pollCount < runner.InfinitePolls || pollCount == runner.UntilDuration {
+		return utils.NewMissingInputError(
+			fmt.Errorf("--count must be a positive number of polls or %d to poll until stopped", runner.InfinitePolls),
+		)
+	}
+	return nil
+}
+
 // collectCmd represents the collect command
 var collectCmd = &cobra.Command{
 	Use:   "collect",
 	Short: "Run the collector tool",
 	Long:  `Run the collector tool to gather data from your target cluster`,
 	Run: func(cmd *cobra.Command, args []string) {
-		collectionRunner := runner.NewCollectorRunner(collectorNames)
+		collectionRunner, err := runner.NewCollectorRunner(collectorNames, strictCollectors)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(err))
+		}
 
 		requestedDuration, err := time.ParseDuration(requestedDurationStr)
 		if requestedDuration.Nanoseconds() < 0 {
@@ -54,12 +223,23 @@ var collectCmd = &cobra.Command{
 		}
 		utils.IfErrorExitOrPanic(err)
 
-		for _, c := range collectorNames {
-			if (c == collectors.LogsCollectorName || c == runner.All) && logsOutputFile == "" {
-				utils.IfErrorExitOrPanic(utils.NewMissingInputError(
-					errors.New("if Logs collector is selected you must also provide a log output file")),
-				)
-			}
+		err = checkPollCount(cmd)
+		utils.IfErrorExitOrPanic(err)
+
+		if collectionRunner.IsSelected(collectors.LogsCollectorName) && logsOutputFile == "" {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("if Logs collector is selected you must also provide a log output file")),
+			)
+		}
+
+		sysfsAttributes, err = devices.ParseSysfsAttributes(sysfsSpecs)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("invalid --sysfs-attribute: %w", err)))
+		}
+		if collectionRunner.IsSelected(collectors.SysfsCollectorName) && len(sysfsAttributes) == 0 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("if Sysfs collector is selected you must also provide at least one --sysfs-attribute")),
+			)
 		}
 
 		if strings.Contains(tempDir, "~") {
@@ -78,30 +258,384 @@ var collectCmd = &cobra.Command{
 			log.Fatal(err)
 		}
 
-		collectionRunner.Run(
-			kubeConfig,
-			outputFile,
-			requestedDuration,
-			pollInterval,
-			devInfoAnnouceInterval,
-			ptpInterface,
-			useAnalyserJSON,
-			logsOutputFile,
-			includeLogTimestamps,
-			tempDir,
-			keepDebugFiles,
-		)
+		if dpllSmoothingAlpha < 0 || dpllSmoothingAlpha > 1 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--dpll-smoothing-alpha must be between 0 and 1")),
+			)
+		}
+
+		if deadline < 0 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--deadline must not be negative")),
+			)
+		}
+
+		if sampleEvery < 1 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--sample-every must be at least 1")),
+			)
+		}
+
+		outputFields, err = callbacks.ParseFieldSelections(fieldSelections)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("invalid --fields: %w", err)))
+		}
+
+		redactedFields, err = callbacks.ParseFieldSelections(redactSelections)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("invalid --redact: %w", err)))
+		}
+
+		if asyncQueueSize < 0 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--async-queue-size must not be negative")),
+			)
+		}
+		if podLookupAttempts < 1 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--pod-lookup-attempts must be at least 1")),
+			)
+		}
+		if podLookupBackoff < 0 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--pod-lookup-backoff must not be negative")),
+			)
+		}
+		err = clients.SetShell(execShell)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("invalid --exec-shell: %w", err)))
+		}
+		fetcher.SetIncludeRaw(includeRaw)
+
+		asyncQueuePolicy, err = callbacks.ParseQueueFullPolicy(asyncQueuePolicyName)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("invalid --async-queue-policy: %w", err)))
+		}
+
+		if maxConcurrency < 0 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--max-concurrency must not be negative")),
+			)
+		}
+		runner.SetMaxConcurrency(maxConcurrency)
+
+		if warmup < 0 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--warmup must not be negative")),
+			)
+		}
+
+		if outputBufferSize < 0 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--output-buffer-size must not be negative")),
+			)
+		}
+
+		err = checkCompressionFlags()
+		utils.IfErrorExitOrPanic(err)
+
+		if outputToStdout {
+			outputFile = "-"
+		}
+		if writeRunManifest && (outputFile == "" || outputFile == "-") && outputDir == "" && splitOutputDir == "" {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("--manifest is written next to the output so needs --output, --output-dir or --split-output")),
+			)
+		}
+
+		err = checkGNSSFlags()
+		utils.IfErrorExitOrPanic(err)
+
+		podOptions, err := getPodOptions(cmd)
+		utils.IfErrorExitOrPanic(err)
+
+		format, err := getOutputFormat()
+		utils.IfErrorExitOrPanic(err)
+
+		// The clientset is shared by the collections so is configured before any of them start
+		err = runner.ConfigureClientset(kubeConfig, execTimeout, podLookupAttempts, podLookupBackoff)
+		utils.IfErrorExitOrPanic(err)
+
+		collections, err := getCollections(collectionRunner)
+		utils.IfErrorExitOrPanic(err)
+		pprofServer, err := runner.StartPprofServer(pprofAddress)
+		utils.IfErrorExitOrPanic(err)
+		err = runCollections(collections, requestedDuration, podOptions, format)
+		runner.StopPprofServer(pprofServer)
+		utils.IfErrorExitOrPanic(err)
 	},
 }
 
+// runCollections runs the collections concurrently and returns the errors of those which failed,
+// a collection which fails does not stop the others so they still clean up and flush their output
+func runCollections(
+	collections []collection,
+	requestedDuration time.Duration,
+	podOptions *contexts.PodOptions,
+	format callbacks.OutputFormat,
+) error {
+	if len(collections) == 1 {
+		return collections[0].run(requestedDuration, podOptions, format)
+	}
+	errs := make([]error, len(collections))
+	var wg sync.WaitGroup
+	for i, c := range collections {
+		wg.Add(1)
+		go func(i int, c collection) {
+			defer wg.Done()
+			errs[i] = c.run(requestedDuration, podOptions, format)
+		}(i, c)
+	}
+	wg.Wait()
+	failed := make([]error, 0)
+	for i, err := range errs {
+		if err != nil {
+			failed = append(failed, fmt.Errorf("collection from %s failed: %w", collections[i].target(), err))
+		}
+	}
+	switch len(failed) {
+	case 0:
+		return nil
+	case 1:
+		return failed[0]
+	default:
+		return utils.MakeCompositeError("several collections failed", failed)
+	}
+}
+
+// collection holds the settings which differ between the collections
+// run for each interface when the interfaces are discovered and for each node with --all-nodes
+type collection struct {
+	runner         *runner.CollectorRunner
+	nodeName       string
+	ptpInterface   string
+	outputFile     string
+	splitOutputDir string
+	outputDir      string
+	listenAddress  string
+	tagNode        bool
+}
+
+// target returns the node and interface the collection is from for reporting errors
+func (c collection) target() string {
+	target := fmt.Sprintf("interface %q", c.ptpInterface)
+	if c.nodeName != "" {
+		target = fmt.Sprintf("node %q %s", c.nodeName, target)
+	}
+	return target
+}
+
+func (c collection) run(
+	requestedDuration time.Duration,
+	podOptions *contexts.PodOptions,
+	format callbacks.OutputFormat,
+) error {
+	return c.runner.Run(&runner.RunOptions{
+		KubeConfig:             kubeConfig,
+		OutputFile:             c.outputFile,
+		RequestedDuration:      requestedDuration,
+		Deadline:               deadline,
+		PollCount:              pollCount,
+		PollInterval:           pollInterval,
+		DevInfoAnnouceInterval: devInfoAnnouceInterval,
+		AnnounceOnce:           announceOnce,
+		FlushOnAnnounce:        flushOnAnnounce,
+		PTPInterface:           c.ptpInterface,
+		NodeName:               c.nodeName,
+		TagNode:                c.tagNode,
+		OutputFormat:           format,
+		LogsOutputFile:         logsOutputFile,
+		IncludeLogTimestamps:   includeLogTimestamps,
+		TempDir:                tempDir,
+		KeepDebugFiles:         keepDebugFiles,
+		PodOptions:             podOptions,
+		SplitOutputDir:         c.splitOutputDir,
+		OutputDir:              c.outputDir,
+		AppendOutput:           appendOutput,
+		OutputBufferSize:       outputBufferSize,
+		GzipLevel:              gzipLevel,
+		FlushInterval:          flushInterval,
+		ValidateOutput:         validateOutput,
+		SampleEvery:            sampleEvery,
+		OutputFields:           outputFields,
+		RedactedFields:         redactedFields,
+		AsyncQueueSize:         asyncQueueSize,
+		AsyncQueuePolicy:       asyncQueuePolicy,
+		ExpectedRFBlocks:       expectedRFBlocks,
+		StrictRFBlocks:         strictRFBlocks,
+		DPLLSmoothingAlpha:     dpllSmoothingAlpha,
+		DPLLChangesOnly:        dpllChangesOnly,
+		GNSSSource:             gnssSource,
+		GNSSExtraMessages:      gnssExtraMessages,
+		GNSSDeviceIndex:        gnssDeviceIndex,
+		SkipDeviceCheck:        skipDeviceCheck,
+		StrictParse:            strictParse,
+		NoCoreutils:            noCoreutils,
+		SysfsAttributes:        sysfsAttributes,
+		ListenAddress:          c.listenAddress,
+		WriteRunManifest:       writeRunManifest,
+		Warmup:                 warmup,
+	})
+}
+
+// getInterfaces returns the interface given by the user or the interfaces discovered on node
+func getInterfaces(node string) ([]string, error) {
+	switch {
+	case interfaceAutoDiscover && ptpInterface != "":
+		return nil, utils.NewMissingInputError(
+			errors.New("--interface and --interface-auto-discover can not be used together"),
+		)
+	case interfaceAutoDiscover:
+		interfaces, err := runner.DiscoverInterfaces(kubeConfig, node)
+		if err != nil {
+			return nil, utils.NewInvalidEnvError(err)
+		}
+		return interfaces, nil
+	case ptpInterface == "":
+		return nil, utils.NewMissingInputError(
+			errors.New("either --interface or --interface-auto-discover must be set"),
+		)
+	default:
+		return []string{ptpInterface}, nil
+	}
+}
+
+// getCollections returns a collection for each node and interface which is collected from
+func getCollections(firstRunner *runner.CollectorRunner) ([]collection, error) {
+	if allNodes {
+		return getNodeCollections(firstRunner)
+	}
+	base := collection{
+		runner:         firstRunner,
+		nodeName:       nodeName,
+		outputFile:     outputFile,
+		splitOutputDir: splitOutputDir,
+		outputDir:      outputDir,
+		listenAddress:  listenAddress,
+	}
+	return getNodeInterfaceCollections(base)
+}
+
+// getNodeCollections returns the collections for each node running the PTP daemon, each node
+// writes to its own directory within --output-dir or --split-output and its outputs are tagged with its name.
+// Only the runner of the first node serves --listen as the address can only be listened on once.
+func getNodeCollections(firstRunner *runner.CollectorRunner) ([]collection, error) {
+	if nodeName != "" {
+		return nil, utils.NewMissingInputError(errors.New("--node and --all-nodes can not be used together"))
+	}
+	if outputDir == "" && splitOutputDir == "" {
+		return nil, utils.NewMissingInputError(
+			errors.New("--all-nodes needs --output-dir or --split-output so each node has its own output"),
+		)
+	}
+	nodes, err := runner.DiscoverNodes(kubeConfig)
+	if err != nil {
+		return nil, utils.NewInvalidEnvError(err)
+	}
+	if listenAddress != "" && len(nodes) > 1 {
+		log.Warnf("--listen only reports the collectors of node %s", nodes[0])
+	}
+	collections := make([]collection, 0, len(nodes))
+	for i, node := range nodes {
+		base := collection{runner: firstRunner, nodeName: node, tagNode: true}
+		if i == 0 {
+			base.listenAddress = listenAddress
+		} else {
+			nodeRunner, err := runner.NewCollectorRunner(collectorNames, strictCollectors)
+			if err != nil {
+				return nil, utils.NewMissingInputError(err)
+			}
+			base.runner = nodeRunner
+		}
+		if outputDir != "" {
+			base.outputDir = filepath.Join(outputDir, node)
+		}
+		if splitOutputDir != "" {
+			base.splitOutputDir = filepath.Join(splitOutputDir, node)
+		}
+		nodeCollections, err := getNodeInterfaceCollections(base)
+		if err != nil {
+			return nil, err
+		}
+		collections = append(collections, nodeCollections...)
+	}
+	return collections, nil
+}
+
+// getNodeInterfaceCollections returns the collections for the interfaces on the node of base
+func getNodeInterfaceCollections(base collection) ([]collection, error) {
+	interfaces, err := getInterfaces(base.nodeName)
+	if err != nil {
+		return nil, err
+	}
+	if len(interfaces) == 1 {
+		base.ptpInterface = interfaces[0]
+		return []collection{base}, nil
+	}
+	return getInterfaceCollections(base, interfaces)
+}
+
+// getInterfaceCollections returns a collection for each interface based on base writing to its own
+// directory within the output directory of base. The collectors which do not depend on
+// the interface are only run by the first collection.
+func getInterfaceCollections(base collection, interfaces []string) ([]collection, error) {
+	if base.outputDir == "" && base.splitOutputDir == "" {
+		return nil, utils.NewMissingInputError(fmt.Errorf(
+			"%d interfaces were discovered, use --output-dir or --split-output so each has its own output",
+			len(interfaces),
+		))
+	}
+	collections := make([]collection, 0, len(interfaces))
+	for i, interfaceName := range interfaces {
+		c := base
+		c.ptpInterface = interfaceName
+		if i > 0 {
+			c.listenAddress = ""
+			names := append([]string{}, collectorNames...)
+			for _, name := range interfaceIndependentCollectors {
+				names = append(names, "-"+name)
+			}
+			interfaceRunner, err := runner.NewCollectorRunner(names, strictCollectors)
+			if err != nil {
+				return nil, utils.NewMissingInputError(err)
+			}
+			c.runner = interfaceRunner
+		}
+		if base.outputDir != "" {
+			c.outputDir = filepath.Join(base.outputDir, interfaceName)
+		}
+		if base.splitOutputDir != "" {
+			c.splitOutputDir = filepath.Join(base.splitOutputDir, interfaceName)
+		}
+		collections = append(collections, c)
+	}
+	return collections, nil
+}
+
 func init() { //nolint:funlen // Allow this to get a little long
 	rootCmd.AddCommand(collectCmd)
 
 	AddKubeconfigFlag(collectCmd)
 	AddOutputFlag(collectCmd)
 	AddFormatFlag(collectCmd)
-	AddInterfaceFlag(collectCmd)
+	AddOptionalInterfaceFlag(collectCmd)
+	AddNodeFlag(collectCmd)
 
+	collectCmd.Flags().BoolVar(
+		&interfaceAutoDiscover,
+		"interface-auto-discover",
+		false,
+		"Collect from every interface with a supported NIC instead of --interface. When more than one "+
+			"is found each gets its own directory in --output-dir or --split-output, "+
+			"the Logs, PMC and PTPConfig collectors only run for the first",
+	)
+	collectCmd.Flags().BoolVar(
+		&allNodes,
+		"all-nodes",
+		false,
+		"Collect from every node running the PTP daemon instead of --node, the collections run concurrently. "+
+			"Each node gets its own directory in --output-dir or --split-output and its outputs are tagged with its name",
+	)
 	collectCmd.Flags().StringVarP(
 		&requestedDurationStr,
 		"duration",
@@ -110,6 +644,25 @@ func init() { //nolint:funlen // Allow this to get a little long
 		"A positive duration string sequence of decimal numbers and a unit suffix, such as \"300ms\", \"1.5h\" or \"2h45m\"."+
 			" Valid time units are \"s\", \"m\", \"h\".",
 	)
+	collectCmd.Flags().DurationVar(
+		&deadline,
+		"deadline",
+		0,
+		"Stop collecting and cleaning up this long after starting even if --duration has not passed e.g. 30m. "+
+			"Once reached at most 10s is spent stopping the collectors and deleting their pods. "+
+			"A value of 0 disables the deadline",
+	)
+	collectCmd.Flags().IntVarP(
+		&pollCount,
+		"count",
+		"c",
+		runner.UntilDuration,
+		fmt.Sprintf(
+			"Number of times to poll each collector, use %d to poll until stopped. "+
+				"Can not be used with --duration",
+			runner.InfinitePolls,
+		),
+	)
 	collectCmd.Flags().IntVarP(
 		&pollInterval,
 		"rate",
@@ -125,6 +678,19 @@ func init() { //nolint:funlen // Allow this to get a little long
 		defaultDevInfoInterval,
 		"interval at which to emit the device info summary to the targeted output.",
 	)
+	collectCmd.Flags().BoolVar(
+		&announceOnce,
+		"announce-once",
+		false,
+		"Emit the device info summary once at the start of the collection rather than every --announce interval",
+	)
+	collectCmd.Flags().BoolVar(
+		&flushOnAnnounce,
+		"flush-on-announce",
+		false,
+		"Flush the buffered output each time the device info is announced so that e.g. tail -f "+
+			"shows the data collected up to the announcement",
+	)
 	defaultCollectorNames := make([]string, 0)
 	defaultCollectorNames = append(defaultCollectorNames, runner.All)
 	collectCmd.Flags().StringSliceVarP(
@@ -135,12 +701,20 @@ func init() { //nolint:funlen // Allow this to get a little long
 		fmt.Sprintf(
 			"the collectors you wish to run (case-insensitive):\n"+
 				"\trequired collectors: %s (will be automatically added)\n"+
-				"\toptional collectors: %s",
+				"\toptional collectors: %s\n"+
+				"\tprefix a name with - to exclude it e.g. all,-GNSS",
 			strings.Join(runner.RequiredCollectorNames, ", "),
 			strings.Join(runner.OptionalCollectorNames, ", "),
 		),
 	)
 
+	collectCmd.Flags().BoolVar(
+		&strictCollectors,
+		"strict-collectors",
+		defaultStrictCollectors,
+		"Fail if an unknown collector is selected. When false unknown collectors are logged and ignored",
+	)
+
 	collectCmd.Flags().StringVarP(
 		&logsOutputFile,
 		"logs-output", "l", "",
@@ -155,4 +729,289 @@ func init() { //nolint:funlen // Allow this to get a little long
 	collectCmd.Flags().StringVarP(&tempDir, "tempdir", "t", defaultTempDir,
 		"Directory for storing temp/debug files. Must exist.")
 	collectCmd.Flags().BoolVar(&keepDebugFiles, "keep", defaultKeepDebugFiles, "Keep debug files")
+	collectCmd.Flags().DurationVar(
+		&execTimeout,
+		"exec-timeout",
+		clients.DefaultExecTimeout,
+		"Maximum time a single command run in the cluster may take before the poll is failed. "+
+			"A value of 0 disables the timeout",
+	)
+	collectCmd.Flags().IntVar(
+		&podLookupAttempts,
+		"pod-lookup-attempts",
+		clients.DefaultPodLookupAttempts,
+		"Number of times the daemon pod is looked up at startup before giving up, "+
+			"this allows for the pod being replaced during a rollout of the operator",
+	)
+	collectCmd.Flags().DurationVar(
+		&podLookupBackoff,
+		"pod-lookup-backoff",
+		clients.DefaultPodLookupBackoff,
+		"Delay before the first retry of the daemon pod lookup, this doubles with each subsequent retry",
+	)
+	collectCmd.Flags().StringVar(
+		&execShell,
+		"exec-shell",
+		clients.DefaultShell,
+		"Absolute path of the shell in the containers which the collectors' commands are run with",
+	)
+	collectCmd.Flags().BoolVar(
+		&includeRaw,
+		"include-raw",
+		false,
+		"Include the raw output of the commands alongside the parsed values in each record, "+
+			"this greatly increases the size of the output so is intended for the raw and ndjson formats",
+	)
+	collectCmd.Flags().BoolVar(
+		&noCoreutils,
+		"no-coreutils",
+		false,
+		"Do not use coreutils such as timeout and head in the daemon container, "+
+			"the gpsd GNSS source reads the socket with bash builtins instead. "+
+			"When not set missing coreutils are detected and the builtins are used",
+	)
+
+	collectCmd.Flags().Int64Var(
+		&podRunAsUser,
+		"pod-run-as-user",
+		unsetRunAsUser,
+		"User ID the pods created by the collectors run as. If not set the cluster default is used",
+	)
+	collectCmd.Flags().BoolVar(
+		&podPrivileged,
+		"pod-privileged",
+		false,
+		"Run the pods created by the collectors as privileged. If not set the cluster default is used",
+	)
+	collectCmd.Flags().StringSliceVar(
+		&podCapabilities,
+		"pod-capabilities",
+		contexts.DefaultPodCapabilities,
+		"Capabilities added to the pods created by the collectors",
+	)
+	collectCmd.Flags().StringVar(
+		&podServiceAccount,
+		"pod-service-account",
+		"",
+		"Service account the pods created by the collectors run as, "+
+			"use this to bind the pods to an SCC which admits them on restricted clusters",
+	)
+	collectCmd.Flags().StringArrayVar(
+		&podVolumes,
+		"volume",
+		[]string{},
+		"Mount a host path into the pods created by the collectors in the form host:container "+
+			"e.g. --volume /dev:/dev. Can be passed multiple times",
+	)
+	collectCmd.Flags().StringArrayVar(
+		&podLabels,
+		"pod-label",
+		[]string{},
+		"Add a label to the pods created by the collectors in the form key=value. "+
+			"The pods are always labelled with the tool, run and interface. Can be passed multiple times",
+	)
+	collectCmd.Flags().StringArrayVar(
+		&sysfsSpecs,
+		"sysfs-attribute",
+		[]string{},
+		"Attribute for the Sysfs collector to output on each poll in the form name=/sys/path "+
+			"e.g. --sysfs-attribute carrier_changes=/sys/class/net/ens7f0/carrier_changes. Can be passed multiple times",
+	)
+	collectCmd.Flags().IntVar(
+		&expectedRFBlocks,
+		"gnss-rf-blocks",
+		0,
+		"Expected number of UBX MON-RF blocks, one per antenna. A warning is logged when a different "+
+			"number is reported. A value of 0 disables the check",
+	)
+	collectCmd.Flags().BoolVar(
+		&strictRFBlocks,
+		"gnss-rf-blocks-strict",
+		false,
+		"Fail the GNSS poll instead of warning when the number of MON-RF blocks differs from --gnss-rf-blocks",
+	)
+	collectCmd.Flags().StringVar(
+		&gnssSource,
+		"gnss-source",
+		devices.GPSSourceUBXTool,
+		fmt.Sprintf(
+			"Where the GNSS collector reads from, one of: %s. "+
+				"gpsd reads the gpsd JSON socket on port 2947 but does not report antenna status",
+			strings.Join(devices.GPSSources, ", "),
+		),
+	)
+	collectCmd.Flags().StringSliceVar(
+		&gnssExtraMessages,
+		"gnss-extra-messages",
+		[]string{},
+		"Additional UBX messages for ubxtool to poll e.g. TIM-TP,NAV-TIMEUTC. "+
+			"Messages without a registered parser are output as the raw block",
+	)
+	collectCmd.Flags().IntVar(
+		&gnssDeviceIndex,
+		"gnss-device-index",
+		0,
+		"Which GNSS device to use when the interface has more than one, "+
+			"an index into the sorted entries of /sys/class/net/<interface>/device/gnss/",
+	)
+	collectCmd.Flags().Float64Var(
+		&dpllSmoothingAlpha,
+		"dpll-smoothing-alpha",
+		0,
+		"Also output an exponentially weighted moving average of the DPLL offset using this alpha (0 to 1]. "+
+			"Smaller values smooth more. A value of 0 disables smoothing. "+
+			"Only applies to DPLL info read from the filesystem as netlink does not report the offset",
+	)
+	collectCmd.Flags().BoolVar(
+		&dpllChangesOnly,
+		"dpll-changes-only",
+		false,
+		"Only output the DPLL states when they change rather than the DPLL info on every poll. "+
+			"Each change includes the previous and new states",
+	)
+	collectCmd.Flags().BoolVar(
+		&skipDeviceCheck,
+		"skip-device-check",
+		false,
+		"Log a warning instead of failing when the NIC is not an E810. "+
+			"Intended for experimenting with pre-production hardware, the output may not be valid",
+	)
+	collectCmd.Flags().BoolVar(
+		&strictParse,
+		"strict-parse",
+		false,
+		"Fail the run when any collector fails to parse its output, including partially parsed GNSS output, "+
+			"rather than logging it and continuing with the data which did parse",
+	)
+	collectCmd.Flags().StringVar(
+		&listenAddress,
+		"listen",
+		"",
+		"Serve /healthz and /metrics on this address while collecting e.g. --listen :8080. "+
+			"/healthz fails when a collector has not had a successful poll recently. "+
+			"With --all-nodes or several discovered interfaces only the first node and interface are reported",
+	)
+	collectCmd.Flags().BoolVar(
+		&writeRunManifest,
+		"manifest",
+		false,
+		"At the end of the run write a JSON manifest of the run next to the output with the suffix "+
+			".manifest.json, or as manifest.json in --split-output. It records the start and end times, "+
+			"the target, the collectors, the records written for each datatype, the poll and error counts "+
+			"and the tool version",
+	)
+	collectCmd.Flags().IntVar(
+		&maxConcurrency,
+		"max-concurrency",
+		0,
+		"Maximum number of polls which run at once across all collectors and interfaces, "+
+			"this limits the commands run in the cluster at the same time. A value of 0 disables the limit",
+	)
+	collectCmd.Flags().DurationVar(
+		&warmup,
+		"warmup",
+		0,
+		"Exclude the outputs of the first part of the run, from when the collectors have started, "+
+			"from the statistics logged at the end of the run "+
+			"and written to the manifest e.g. --warmup 5m. The outputs are still written",
+	)
+	collectCmd.Flags().StringVar(
+		&pprofAddress,
+		"pprof",
+		"",
+		"Serve the Go profiling endpoints under /debug/pprof/ on this address while collecting e.g. --pprof :6060. "+
+			"This is intended for diagnosing the performance of the tool and is separate from --listen",
+	)
+	collectCmd.Flags().StringVar(
+		&splitOutputDir,
+		"split-output",
+		"",
+		"Write the output of each datatype to its own file in this directory instead of to --output",
+	)
+	collectCmd.Flags().StringVar(
+		&outputDir,
+		"output-dir",
+		"",
+		"Write the output to a new file in this directory instead of to --output. The file is named from "+
+			"the start time, cluster and collectors e.g. 2024-05-01T12-00-00_ocp_ptp-dpll.log",
+	)
+	collectCmd.Flags().BoolVar(
+		&outputToStdout,
+		"stdout",
+		false,
+		"Write the output to stdout, the same as --output -. Logs are always written to stderr "+
+			"so with --format ndjson the output can be piped into e.g. jq",
+	)
+	collectCmd.MarkFlagsMutuallyExclusive("output", "output-dir", "split-output", "stdout")
+	collectCmd.Flags().BoolVar(
+		&appendOutput,
+		"append",
+		false,
+		"Append to existing output files instead of truncating them",
+	)
+	collectCmd.Flags().IntVar(
+		&outputBufferSize,
+		"output-buffer-size",
+		0,
+		"Buffer up to this many bytes of output before writing it to --output e.g. 65536. "+
+			"The buffer is also flushed every --flush-interval and on exit. A value of 0 disables buffering",
+	)
+	collectCmd.Flags().IntVar(
+		&gzipLevel,
+		"gzip-level",
+		callbacks.GzipDisabled,
+		"Compress the output with gzip at this level from 0 (fastest) to 9 (smallest), "+
+			"lower levels use less CPU at high sample rates. By default the output is not compressed",
+	)
+	collectCmd.Flags().DurationVar(
+		&flushInterval,
+		"flush-interval",
+		0,
+		"How often buffered or compressed output is flushed to --output. "+
+			"A value of 0 flushes every --announce interval",
+	)
+	collectCmd.Flags().IntVar(
+		&sampleEvery,
+		"sample-every",
+		1,
+		"Only write every Nth output of each datatype to reduce the output of long runs, the first is always written. "+
+			"Device info, device summaries, PTP config and GNSS events are always written",
+	)
+	collectCmd.Flags().StringArrayVar(
+		&fieldSelections,
+		"fields",
+		[]string{},
+		"Only write the selected fields of a datatype in the form datatype=path,path "+
+			"e.g. --fields gnss/time-error=terror,ferror or --fields gpsNav=navClock.timeAcc. "+
+			"The datatype is the tag of the output or the ID of an analyser message. Can be passed multiple times",
+	)
+	collectCmd.Flags().StringArrayVar(
+		&redactSelections,
+		"redact",
+		[]string{},
+		"Replace the selected fields of a datatype with a hash before they are written so captures can be shared, "+
+			"in the same form as --fields e.g. --redact devInfo=firmwareVersion. "+
+			"A value always gives the same hash so it can still be compared. Can be passed multiple times",
+	)
+	collectCmd.Flags().IntVar(
+		&asyncQueueSize,
+		"async-queue-size",
+		0,
+		"Queue up to this many outputs and write them from a separate goroutine so a slow output does not "+
+			"delay polling. The queue is written out on exit. A value of 0 writes each output as it is polled",
+	)
+	collectCmd.Flags().StringVar(
+		&asyncQueuePolicyName,
+		"async-queue-policy",
+		"block",
+		"What to do when the --async-queue-size queue is full: block waits for space, "+
+			"drop-oldest discards the oldest queued output so polling is never delayed",
+	)
+	collectCmd.Flags().BoolVar(
+		&validateOutput,
+		"validate-output",
+		false,
+		"Check the JSON of each output against a schema generated from its datatype before it is written. "+
+			"An output which does not match fails the poll with an error naming the mismatched field",
+	)
 }
@@ -0,0 +1,107 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/runner"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+func newDaemonPod(name, node string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: contexts.PTPNamespace},
+		Spec:       v1.PodSpec{NodeName: node},
+	}
+}
+
+// setAllNodesFlags sets the flags of a --all-nodes collection restoring them when the test ends
+func setAllNodesFlags(output, listen string) {
+	savedAllNodes, savedNodeName, savedInterface := allNodes, nodeName, ptpInterface
+	savedOutputDir, savedSplitOutputDir := outputDir, splitOutputDir
+	savedListenAddress, savedAutoDiscover := listenAddress, interfaceAutoDiscover
+	DeferCleanup(func() {
+		allNodes, nodeName, ptpInterface = savedAllNodes, savedNodeName, savedInterface
+		outputDir, splitOutputDir = savedOutputDir, savedSplitOutputDir
+		listenAddress, interfaceAutoDiscover = savedListenAddress, savedAutoDiscover
+	})
+	allNodes = true
+	nodeName = ""
+	outputDir = output
+	splitOutputDir = ""
+	listenAddress = listen
+	ptpInterface = "ens1f0"
+	interfaceAutoDiscover = false
+}
+
+var _ = Describe("getNodeCollections", func() {
+	BeforeEach(func() {
+		testutils.GetMockedClientSet(
+			newDaemonPod("linuxptp-daemon-aaaaa", "node-1"),
+			newDaemonPod("linuxptp-daemon-bbbbb", "node-2"),
+			newDaemonPod("linuxptp-daemon-ccccc", "node-3"),
+		)
+	})
+
+	When("the daemon is running on several nodes", func() {
+		It("should return a tagged collection with its own runner and output for each node", func() {
+			setAllNodesFlags("output", ":8080")
+			firstRunner, err := runner.NewCollectorRunner(nil, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			collections, err := getCollections(firstRunner)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(collections).To(HaveLen(3))
+			runners := make(map[*runner.CollectorRunner]bool)
+			for i, node := range []string{"node-1", "node-2", "node-3"} {
+				c := collections[i]
+				Expect(c.nodeName).To(Equal(node))
+				Expect(c.ptpInterface).To(Equal("ens1f0"))
+				Expect(c.tagNode).To(BeTrue())
+				Expect(c.outputDir).To(Equal(filepath.Join("output", node)))
+				runners[c.runner] = true
+			}
+			Expect(runners).To(HaveLen(3))
+			Expect(collections[0].runner).To(BeIdenticalTo(firstRunner))
+			Expect(collections[0].listenAddress).To(Equal(":8080"))
+			Expect(collections[1].listenAddress).To(BeEmpty())
+			Expect(collections[2].listenAddress).To(BeEmpty())
+		})
+	})
+	When("there is nowhere to write the output of each node", func() {
+		It("should return an error", func() {
+			setAllNodesFlags("", "")
+			firstRunner, err := runner.NewCollectorRunner(nil, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = getCollections(firstRunner)
+			Expect(err).To(MatchError(ContainSubstring("--all-nodes needs --output-dir or --split-output")))
+		})
+	})
+	When("a node is also given", func() {
+		It("should return an error", func() {
+			setAllNodesFlags("output", "")
+			nodeName = "node-1"
+			firstRunner, err := runner.NewCollectorRunner(nil, true)
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = getCollections(firstRunner)
+			Expect(err).To(MatchError(ContainSubstring("--node and --all-nodes can not be used together")))
+		})
+	})
+})
+
+func TestCmd(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Cmd Suite")
+}
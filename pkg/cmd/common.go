@@ -3,8 +3,12 @@
 package cmd
 
 import (
+	"fmt"
+	"strings"
+
 	"github.com/spf13/cobra"
 
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
@@ -12,7 +16,9 @@ var (
 	kubeConfig      string
 	outputFile      string
 	useAnalyserJSON bool
+	outputFormat    string
 	ptpInterface    string
+	nodeName        string
 )
 
 func AddKubeconfigFlag(targetCmd *cobra.Command) {
@@ -26,6 +32,12 @@ func AddOutputFlag(targetCmd *cobra.Command) {
 }
 
 func AddFormatFlag(targetCmd *cobra.Command) {
+	targetCmd.Flags().StringVar(
+		&outputFormat,
+		"format",
+		"raw",
+		fmt.Sprintf("Output format, one of: %s", strings.Join(callbacks.OutputFormatNames(), ", ")),
+	)
 	targetCmd.Flags().BoolVarP(
 		&useAnalyserJSON,
 		"use-analyser-format",
@@ -33,10 +45,39 @@ func AddFormatFlag(targetCmd *cobra.Command) {
 		false,
 		"Output in a format to be used by analysers from vse-sync-pp",
 	)
+	err := targetCmd.Flags().MarkDeprecated("use-analyser-format", "use --format=analyser instead")
+	utils.IfErrorExitOrPanic(err)
+}
+
+// getOutputFormat returns the format requested by the user,
+// the deprecated --use-analyser-format flag takes precedence over --format
+func getOutputFormat() (callbacks.OutputFormat, error) {
+	if useAnalyserJSON {
+		return callbacks.AnalyserJSON, nil
+	}
+	format, err := callbacks.ParseOutputFormat(outputFormat)
+	if err != nil {
+		return format, utils.NewMissingInputError(err)
+	}
+	return format, nil
 }
 
 func AddInterfaceFlag(targetCmd *cobra.Command) {
-	targetCmd.Flags().StringVarP(&ptpInterface, "interface", "i", "", "Name of the PTP interface")
+	AddOptionalInterfaceFlag(targetCmd)
 	err := targetCmd.MarkFlagRequired("interface")
 	utils.IfErrorExitOrPanic(err)
 }
+
+// AddOptionalInterfaceFlag adds --interface for commands which can find the interface themselves
+func AddOptionalInterfaceFlag(targetCmd *cobra.Command) {
+	targetCmd.Flags().StringVarP(&ptpInterface, "interface", "i", "", "Name of the PTP interface")
+}
+
+func AddNodeFlag(targetCmd *cobra.Command) {
+	targetCmd.Flags().StringVar(
+		&nodeName,
+		"node",
+		"",
+		"Name of the node to collect from. Required when the PTP daemon is running on more than one node",
+	)
+}
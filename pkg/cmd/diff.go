@@ -0,0 +1,62 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/diff"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// the baseline and compared captures
+const diffCaptureCount = 2
+
+var diffWindow time.Duration
+
+func readCapture(path string) *diff.Capture {
+	inputFile, err := os.Open(path)
+	if err != nil {
+		utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("failed to open capture: %w", err)))
+	}
+	defer inputFile.Close()
+	capture, err := diff.ReadCapture(inputFile)
+	if err != nil {
+		utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("failed to read capture %s: %w", path, err)))
+	}
+	return capture
+}
+
+// diffCmd represents the diff command
+var diffCmd = &cobra.Command{
+	Use:   "diff <baseline> <compared>",
+	Short: "Compare two previously collected files",
+	Long: `Compare a known good capture against another capture, both written by collect
+with --format=ndjson or --format=raw, without connecting to a cluster.
+The device info and versions are compared along with the mean, max and stddev
+of the offsets of each datatype overall and in windows from the start of each capture`,
+	Args: cobra.ExactArgs(diffCaptureCount),
+	Run: func(cmd *cobra.Command, args []string) {
+		if diffWindow <= 0 {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(errors.New("--window must be positive")))
+		}
+		baseline := readCapture(args[0])
+		compared := readCapture(args[1])
+		err := diff.Compare(baseline, compared, diffWindow).Write(cmd.OutOrStdout())
+		utils.IfErrorExitOrPanic(err)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(diffCmd)
+
+	diffCmd.Flags().DurationVar(
+		&diffWindow, "window", diff.DefaultWindow,
+		"Length of the windows the offsets are compared over",
+	)
+}
@@ -0,0 +1,54 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/runner"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// inventoryCmd represents the inventory command
+var inventoryCmd = &cobra.Command{
+	Use:   "inventory",
+	Short: "Print the timing hardware of the node as JSON",
+	Long: `Fetch the vendor, device, firmware, GNSS device and DPLL states of each timing NIC
+along with the GNSS versions once and print them as a single JSON document.
+If --interface is not given every interface with a supported NIC is included`,
+	Run: func(cmd *cobra.Command, args []string) {
+		interfaces := []string{ptpInterface}
+		if ptpInterface == "" {
+			var err error
+			interfaces, err = runner.DiscoverInterfaces(kubeConfig, nodeName)
+			utils.IfErrorExitOrPanic(err)
+		}
+		clientset, err := clients.GetClientset(kubeConfig)
+		utils.IfErrorExitOrPanic(err)
+		ctx, err := contexts.GetPTPDaemonContext(clientset, nodeName)
+		utils.IfErrorExitOrPanic(err)
+
+		inventory, err := devices.GetInventory(ctx, interfaces)
+		if err != nil {
+			log.Warning(err.Error())
+		}
+		output, err := json.MarshalIndent(inventory, "", "  ")
+		utils.IfErrorExitOrPanic(err)
+		fmt.Fprintln(cmd.OutOrStdout(), string(output))
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(inventoryCmd)
+
+	AddKubeconfigFlag(inventoryCmd)
+	AddOptionalInterfaceFlag(inventoryCmd)
+	AddNodeFlag(inventoryCmd)
+}
@@ -0,0 +1,32 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/runner"
+)
+
+// listCmd represents the list command
+var listCmd = &cobra.Command{
+	Use:   "list",
+	Short: "list the collectors and the analyser ids they emit",
+	Long: `list the collectors and the ids of the analyser messages each can emit,
+the ids are the contract between the collectors and the analysers`,
+	Run: func(cmd *cobra.Command, args []string) {
+		registry := collectors.GetRegistry()
+		names := append(append([]string{}, runner.RequiredCollectorNames...), runner.OptionalCollectorNames...)
+		for _, name := range names {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s: %s\n", name, strings.Join(registry.GetAnalyserIDs(name), ", "))
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(listCmd)
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/replay"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var replayInputFile string
+
+// replayCmd represents the replay command
+var replayCmd = &cobra.Command{
+	Use:   "replay",
+	Short: "Convert a previously collected file into the analyser format",
+	Long: `Read a file previously written by collect with --format=raw and
+write it out in the format used by the analysers, without connecting to a cluster`,
+	Run: func(cmd *cobra.Command, args []string) {
+		inputFile, err := os.Open(replayInputFile)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("failed to open input file: %w", err)))
+		}
+		defer inputFile.Close()
+
+		callback, err := callbacks.SetupCallback(outputFile, callbacks.AnalyserJSON, false, 0, callbacks.GzipDisabled)
+		utils.IfErrorExitOrPanic(err)
+		err = replay.Replay(inputFile, callback)
+		cleanUpErr := callback.CleanUp()
+		utils.IfErrorExitOrPanic(err)
+		utils.IfErrorExitOrPanic(cleanUpErr)
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(replayCmd)
+
+	AddOutputFlag(replayCmd)
+	replayCmd.Flags().StringVarP(&replayInputFile, "input", "f", "", "Path to the previously collected file")
+	err := replayCmd.MarkFlagRequired("input")
+	utils.IfErrorExitOrPanic(err)
+}
@@ -20,7 +20,8 @@ var (
 		Short: "A monitoring tool for PTP related metrics",
 		Long:  `A monitoring tool for PTP related metrics.`,
 		PersistentPreRun: func(cmd *cobra.Command, args []string) {
-			logging.SetupLogging(logLevel, os.Stdout)
+			// Logs go to stderr so they do not mix with output written to stdout
+			logging.SetupLogging(logLevel, os.Stderr)
 		},
 	}
 )
@@ -3,11 +3,23 @@
 package cmd
 
 import (
+	"errors"
+	"fmt"
+	"strconv"
+
 	"github.com/spf13/cobra"
 
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/validations"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/verify"
 )
 
+var (
+	maxClockAccuracy      string
+	firmwareAllowListPath string
+)
+
 var envCmd = &cobra.Command{
 	Use:   "env",
 	Short: "environment based actions",
@@ -20,7 +32,35 @@ var verifyEnvCmd = &cobra.Command{
 	Short: "verify the environment is ready for collection",
 	Long:  `verify the environment is ready for collection`,
 	Run: func(cmd *cobra.Command, args []string) {
-		verify.Verify(ptpInterface, kubeConfig, useAnalyserJSON)
+		format, err := getOutputFormat()
+		utils.IfErrorExitOrPanic(err)
+		// The report is either for the user or for the analysers so there is no NDJSON form
+		if format == callbacks.NDJSON {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				errors.New("env verify does not support --format=ndjson, use raw or analyser")),
+			)
+		}
+		maxAccuracy, err := strconv.ParseUint(maxClockAccuracy, 0, 8)
+		if err != nil {
+			utils.IfErrorExitOrPanic(utils.NewMissingInputError(
+				fmt.Errorf("--max-clock-accuracy must be a clockAccuracy value e.g. 0x21: %w", err)),
+			)
+		}
+		var firmwareAllowList []validations.FirmwareCombination
+		if firmwareAllowListPath != "" {
+			firmwareAllowList, err = validations.LoadFirmwareAllowList(firmwareAllowListPath)
+			if err != nil {
+				utils.IfErrorExitOrPanic(utils.NewMissingInputError(fmt.Errorf("invalid --firmware-allow-list: %w", err)))
+			}
+		}
+		verify.Verify(
+			ptpInterface,
+			kubeConfig,
+			nodeName,
+			format == callbacks.AnalyserJSON,
+			uint8(maxAccuracy),
+			firmwareAllowList,
+		)
 	},
 }
 
@@ -31,4 +71,20 @@ func init() {
 	AddOutputFlag(verifyEnvCmd)
 	AddFormatFlag(verifyEnvCmd)
 	AddInterfaceFlag(verifyEnvCmd)
+	AddNodeFlag(verifyEnvCmd)
+	verifyEnvCmd.Flags().StringVar(
+		&maxClockAccuracy,
+		"max-clock-accuracy",
+		fmt.Sprintf("0x%02x", validations.DefaultMaxClockAccuracy),
+		"The worst clockAccuracy reported by pmc which passes, smaller values are more accurate "+
+			"e.g. 0x21 is within 100ns and 0x22 within 250ns",
+	)
+	verifyEnvCmd.Flags().StringVar(
+		&firmwareAllowListPath,
+		"firmware-allow-list",
+		"",
+		"JSON file listing the validated combinations of GNSS firmware and protocol versions "+
+			`e.g. [{"firmwareVersion": "TIM 2.20", "protocolVersion": "29.20"}]. `+
+			"When given the collected versions must be one of the combinations",
+	)
 }
@@ -7,6 +7,7 @@ import (
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
@@ -18,28 +19,57 @@ type Collector interface {
 	IsAnnouncer() bool
 }
 
+// OnDemandCollector is a Collector which can also be polled synchronously
+// by programs using collectors as a library rather than through the runner
+type OnDemandCollector interface {
+	Collector
+	PollOnce() (map[string]any, error) // Polls once returning the outputs keyed by tag
+}
+
+// UndecimatedTags returns the tags of the outputs which are announced or are events,
+// they should be written every time even when the other outputs are sampled
+func UndecimatedTags() []string {
+	return []string{DeviceInfo, DeviceSummaryInfo, PTPConfigInfo, ICEPinConfigInfo, gpsEventKey, DPLLStateChangeKey}
+}
+
 // A union of all values required to be passed into all constructions
 type CollectionConstructor struct {
 	Callback               callbacks.Callback
 	Clientset              *clients.Clientset
+	PodOptions             *contexts.PodOptions
+	SysfsAttributes        map[string]string
+	GNSSExtraMessages      []string
 	ErroredPolls           chan PollResult
 	PTPInterface           string
+	NodeName               string
+	GNSSSource             string
 	Msg                    string
 	LogsOutputFile         string
 	TempDir                string
 	PollInterval           int
 	DevInfoAnnouceInterval int
+	DPLLSmoothingAlpha     float64
+	ExpectedRFBlocks       int
+	GNSSDeviceIndex        int
 	IncludeLogTimestamps   bool
 	KeepDebugFiles         bool
+	StrictRFBlocks         bool
+	SkipDeviceCheck        bool
+	DPLLChangesOnly        bool
+	StrictParse            bool
+	NoCoreutils            bool
 }
 
 type PollResult struct {
+	StartedAt     time.Time
 	CollectorName string
 	Errors        []error
+	Duration      time.Duration
 }
 
 type baseCollector struct {
 	callback     callbacks.Callback
+	sequences    callbacks.SequenceCounter
 	isAnnouncer  bool
 	running      bool
 	pollInterval time.Duration
@@ -54,10 +84,18 @@ func (base *baseCollector) IsAnnouncer() bool {
 }
 
 func (base *baseCollector) Start() error {
+	base.sequences.Reset()
 	base.running = true
 	return nil
 }
 
+// call numbers the output within its tag so dropped outputs can be detected
+// then passes it to the callback
+func (base *baseCollector) call(output callbacks.OutputType, tag string) error {
+	//nolint:wrapcheck // the callers wrap the error
+	return base.callback.Call(callbacks.NewSequencedOutput(output, base.sequences.Next(tag)), tag)
+}
+
 func (base *baseCollector) CleanUp() error {
 	base.running = false
 	return nil
@@ -75,3 +113,30 @@ func newBaseCollector(
 		pollInterval: time.Duration(pollInterval) * time.Second,
 	}
 }
+
+// pollOnce runs a single poll with the callback replaced by one which captures the outputs,
+// any outputs gathered before an error are returned along with it.
+// It must not be called while the collector is being polled by a runner.
+func (base *baseCollector) pollOnce(poll func(chan PollResult, *utils.WaitGroupCount)) (map[string]any, error) {
+	capture := callbacks.NewCaptureCallback()
+	callback := base.callback
+	base.callback = capture
+	defer func() {
+		base.callback = callback
+	}()
+
+	resultsChan := make(chan PollResult, 1)
+	wg := utils.WaitGroupCount{}
+	wg.Add(1)
+	poll(resultsChan, &wg)
+	result := <-resultsChan
+
+	switch len(result.Errors) {
+	case 0:
+		return capture.Outputs(), nil
+	case 1:
+		return capture.Outputs(), result.Errors[0]
+	default:
+		return capture.Outputs(), utils.MakeCompositeError(result.CollectorName+" poll failed", result.Errors)
+	}
+}
@@ -0,0 +1,579 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/remotecommand"
+	utilexec "k8s.io/client-go/util/exec"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var ptpPod = &v1.Pod{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:        "linuxptp-daemon-8292",
+		Namespace:   "openshift-ptp",
+		Annotations: map[string]string{},
+	},
+	Spec: v1.PodSpec{
+		Containers: []v1.Container{{Name: "linuxptp-daemon-container"}, {Name: "cloud-event-proxy"}},
+	},
+}
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error {
+	return nil
+}
+
+var _ = Describe("PollResult", func() {
+	var constructor *collectors.CollectionConstructor
+	BeforeEach(func() {
+		constructor = &collectors.CollectionConstructor{
+			Callback:               callbacks.NewFileCallback(&bufferCloser{}, callbacks.Raw),
+			Clientset:              testutils.GetMockedClientSet(ptpPod),
+			PollInterval:           1,
+			DevInfoAnnouceInterval: 1,
+		}
+	})
+
+	When("a collector is polled", func() {
+		It("should report when the poll started and how long it took", func() {
+			delay := 10 * time.Millisecond
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				time.Sleep(delay)
+				return []byte(""), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+
+			collector, err := collectors.NewPMCCollector(constructor)
+			Expect(err).NotTo(HaveOccurred())
+
+			resultsChan := make(chan collectors.PollResult, 1)
+			wg := utils.WaitGroupCount{}
+			wg.Add(1)
+			before := time.Now()
+			collector.Poll(resultsChan, &wg)
+			result := <-resultsChan
+
+			Expect(result.CollectorName).To(Equal(collectors.PMCCollectorName))
+			Expect(result.StartedAt).To(BeTemporally(">=", before))
+			Expect(result.StartedAt).To(BeTemporally("<=", time.Now()))
+			Expect(result.Duration).To(BeNumerically(">=", delay))
+		})
+		It("should report fetch failures with the collector name", func() {
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte("unexpected output"), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+
+			collector, err := collectors.NewPMCCollector(constructor)
+			Expect(err).NotTo(HaveOccurred())
+
+			resultsChan := make(chan collectors.PollResult, 1)
+			wg := utils.WaitGroupCount{}
+			wg.Add(1)
+			collector.Poll(resultsChan, &wg)
+			result := <-resultsChan
+
+			Expect(result.Errors).To(HaveLen(1))
+			var fetchErr *fetcher.FetchError
+			Expect(errors.As(result.Errors[0], &fetchErr)).To(BeTrue())
+			Expect(fetchErr.Collector).To(Equal(collectors.PMCCollectorName))
+			Expect(fetchErr.Stage).To(Equal(fetcher.ExtractStage))
+			Expect(fetchErr.RawOutput).To(Equal("unexpected output"))
+		})
+	})
+})
+
+var _ = Describe("GPSCollector", func() {
+	var constructor *collectors.CollectionConstructor
+	BeforeEach(func() {
+		constructor = &collectors.CollectionConstructor{
+			Callback:         callbacks.NewFileCallback(&bufferCloser{}, callbacks.Raw),
+			Clientset:        testutils.GetMockedClientSet(ptpPod),
+			PollInterval:     1,
+			ExpectedRFBlocks: 2,
+		}
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			return []byte(strings.Join([]string{
+				"<GPS>",
+				"1686916187.0584",
+				"UBX-MON-RF:",
+				" version 0 nBlocks 1 reserved1 0 0",
+				"   blockId 0 flags x0 antStatus 2 antPower 1 postStatus 0 reserved2 0 0 0 0",
+				"    noisePerMS 82 agcCnt 6318 jamInd 3 ofsI 15 magI 154 ofsQ 2 magQ 145",
+				"    reserved3 0 0 0",
+				"",
+				"</GPS>",
+			}, "\n")), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	pollOnce := func() collectors.PollResult {
+		collector, err := collectors.NewGPSCollector(constructor)
+		Expect(err).NotTo(HaveOccurred())
+		resultsChan := make(chan collectors.PollResult, 1)
+		wg := utils.WaitGroupCount{}
+		wg.Add(1)
+		collector.Poll(resultsChan, &wg)
+		return <-resultsChan
+	}
+
+	When("the fix goes 3 -> 0 -> 3 across polls", func() {
+		It("should emit fix lost and fix acquired events", func() {
+			fixes := []int{3, 0, 3}
+			polls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				// The check for ubxtool is the only command run without stdin
+				if options.Stdin == nil {
+					return []byte("/usr/bin/ubxtool"), []byte(""), nil
+				}
+				output := strings.Join([]string{
+					"<GPS>",
+					"1686916187.0584",
+					"UBX-NAV-STATUS:",
+					fmt.Sprintf("  iTOW 474605000 gpsFix %d flags 0xdd fixStat 0x0 flags2 0x8", fixes[polls]),
+					"  ttff 25030, msss 4294967295",
+					"",
+					"1686916187.0586",
+					"UBX-NAV-CLOCK:",
+					"  iTOW 474605000 clkB -61594 clkD -56 tAcc 5 fAcc 164",
+					"</GPS>",
+				}, "\n")
+				polls++
+				return []byte(output), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			callback, err := callbacks.NewRingBufferCallback(len(fixes), callbacks.AnalyserJSON)
+			Expect(err).NotTo(HaveOccurred())
+			constructor.Callback = callback
+
+			collector, err := collectors.NewGPSCollector(constructor)
+			Expect(err).NotTo(HaveOccurred())
+			resultsChan := make(chan collectors.PollResult, 1)
+			for range fixes {
+				wg := utils.WaitGroupCount{}
+				wg.Add(1)
+				collector.Poll(resultsChan, &wg)
+				Expect((<-resultsChan).Errors).To(BeEmpty())
+			}
+
+			events := callback.Snapshot()["gnss-event"]
+			Expect(events).To(HaveLen(2))
+			Expect(events[0]).To(ContainSubstring(`"event":"fix-lost"`))
+			Expect(events[1]).To(ContainSubstring(`"event":"fix-acquired"`))
+		})
+	})
+
+	When("ubxtool is not installed in the container", func() {
+		It("should return a RequirementsNotMetError naming ubxtool", func() {
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte(""), []byte(""), utilexec.CodeExitError{
+					Err:  errors.New("command terminated with exit code 1"),
+					Code: 1,
+				}
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+			_, err := collectors.NewGPSCollector(constructor)
+			Expect(err).To(HaveOccurred())
+			var requirementsErr *utils.RequirementsNotMetError
+			Expect(errors.As(err, &requirementsErr)).To(BeTrue())
+			Expect(errors.Is(err, devices.ErrUBXToolNotFound)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("ubxtool"))
+		})
+	})
+
+	When("fewer MON-RF blocks than expected are reported", func() {
+		It("should only warn when not strict", func() {
+			result := pollOnce()
+			Expect(result.Errors).To(BeEmpty())
+		})
+		It("should fail the poll when strict", func() {
+			constructor.StrictRFBlocks = true
+			result := pollOnce()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(result.Errors[0].Error()).To(ContainSubstring("expected 2 UBX MON-RF blocks but 1"))
+		})
+	})
+
+	When("some sections fail to parse", func() {
+		It("should report the other sections when not strict", func() {
+			result := pollOnce()
+			Expect(result.Errors).To(BeEmpty())
+		})
+		It("should fail the poll with a parse error when strict", func() {
+			constructor.StrictParse = true
+			result := pollOnce()
+			Expect(result.Errors).To(HaveLen(1))
+			Expect(fetcher.IsParseError(result.Errors[0])).To(BeTrue())
+			Expect(result.Errors[0].Error()).To(ContainSubstring(devices.UBXNavStatus))
+		})
+	})
+})
+
+var _ = Describe("DPLLFilesystemCollector", func() {
+	When("smoothing is enabled", func() {
+		It("should emit the raw and smoothed offsets", func() {
+			offsets := []int{0, 100, 100, 100}
+			polls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				output := "<date>\n1686916187.0584\n</date>\n" +
+					"<dpll_0_state>\n2\n</dpll_0_state>\n" +
+					"<dpll_1_state>\n10\n</dpll_1_state>\n" +
+					fmt.Sprintf("<dpll_1_offset>\n%d\n</dpll_1_offset>\n", offsets[polls])
+				polls++
+				return []byte(output), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+
+			callback, err := callbacks.NewRingBufferCallback(len(offsets), callbacks.Raw)
+			Expect(err).NotTo(HaveOccurred())
+			collector, err := collectors.NewDPLLFilesystemCollector(&collectors.CollectionConstructor{
+				Callback:           callback,
+				Clientset:          testutils.GetMockedClientSet(ptpPod),
+				PTPInterface:       "aFakeInterface",
+				PollInterval:       1,
+				DPLLSmoothingAlpha: 0.5,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resultsChan := make(chan collectors.PollResult, 1)
+			for range offsets {
+				wg := utils.WaitGroupCount{}
+				wg.Add(1)
+				collector.Poll(resultsChan, &wg)
+				Expect((<-resultsChan).Errors).To(BeEmpty())
+			}
+
+			smoothed := make([]float64, 0)
+			for _, line := range callback.Snapshot()[collectors.DPLLInfo] {
+				info := devices.DevFilesystemDPLLInfo{}
+				_, body, _ := strings.Cut(line, ", ")
+				Expect(json.Unmarshal([]byte(body), &info)).To(Succeed())
+				Expect(info.PPSOffsetSmoothed).NotTo(BeNil())
+				smoothed = append(smoothed, *info.PPSOffsetSmoothed)
+			}
+			Expect(smoothed).To(Equal([]float64{0, 50, 75, 87.5}))
+		})
+	})
+
+	When("only changes are requested", func() {
+		pollStates := func(ppsStates []string) map[string][]string {
+			polls := 0
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				output := fmt.Sprintf("<date>\n1686916187.%d\n</date>\n", polls) +
+					"<dpll_0_state>\n2\n</dpll_0_state>\n" +
+					fmt.Sprintf("<dpll_1_state>\n%s\n</dpll_1_state>\n", ppsStates[polls]) +
+					"<dpll_1_offset>\n0\n</dpll_1_offset>\n"
+				polls++
+				return []byte(output), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+
+			callback, err := callbacks.NewRingBufferCallback(len(ppsStates), callbacks.Raw)
+			Expect(err).NotTo(HaveOccurred())
+			collector, err := collectors.NewDPLLFilesystemCollector(&collectors.CollectionConstructor{
+				Callback:        callback,
+				Clientset:       testutils.GetMockedClientSet(ptpPod),
+				PTPInterface:    "aFakeInterface",
+				PollInterval:    1,
+				DPLLChangesOnly: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			resultsChan := make(chan collectors.PollResult, 1)
+			for range ppsStates {
+				wg := utils.WaitGroupCount{}
+				wg.Add(1)
+				collector.Poll(resultsChan, &wg)
+				Expect((<-resultsChan).Errors).To(BeEmpty())
+			}
+			return callback.Snapshot()
+		}
+		decodeChange := func(line string) devices.DPLLStateChange {
+			change := devices.DPLLStateChange{}
+			_, body, _ := strings.Cut(line, ", ")
+			Expect(json.Unmarshal([]byte(body), &change)).To(Succeed())
+			return change
+		}
+
+		It("should only emit the initial states when they do not change", func() {
+			outputs := pollStates([]string{"3", "3", "3", "3"})
+			Expect(outputs[collectors.DPLLInfo]).To(BeEmpty())
+			Expect(outputs[collectors.DPLLStateChangeKey]).To(HaveLen(1))
+			change := decodeChange(outputs[collectors.DPLLStateChangeKey][0])
+			Expect(change.Interface).To(Equal("aFakeInterface"))
+			Expect(change.PreviousPPSState).To(BeEmpty())
+			Expect(change.PPSState).To(Equal("3"))
+		})
+		It("should emit the old and new states on each transition", func() {
+			outputs := pollStates([]string{"3", "3", "1", "1", "3"})
+			changes := outputs[collectors.DPLLStateChangeKey]
+			Expect(changes).To(HaveLen(3))
+			lost := decodeChange(changes[1])
+			Expect(lost.PreviousPPSState).To(Equal("3"))
+			Expect(lost.PPSState).To(Equal("1"))
+			Expect(lost.PreviousEECState).To(Equal("2"))
+			Expect(lost.EECState).To(Equal("2"))
+			Expect(lost.PreviousTimestamp).To(Equal("2023-06-16T11:49:47.1Z"))
+			Expect(lost.Timestamp).To(Equal("2023-06-16T11:49:47.2Z"))
+			regained := decodeChange(changes[2])
+			Expect(regained.PreviousPPSState).To(Equal("1"))
+			Expect(regained.PPSState).To(Equal("3"))
+		})
+	})
+})
+
+var _ = Describe("DevInfoCollector", func() {
+	var constructor *collectors.CollectionConstructor
+	BeforeEach(func() {
+		constructor = &collectors.CollectionConstructor{
+			Callback:               callbacks.NewFileCallback(&bufferCloser{}, callbacks.Raw),
+			Clientset:              testutils.GetMockedClientSet(ptpPod),
+			PTPInterface:           "aLabInterface",
+			PollInterval:           1,
+			DevInfoAnnouceInterval: 1,
+		}
+		// An Intel X710 rather than an E810
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			return []byte(strings.Join([]string{
+				"<date>",
+				"1686916187.0584",
+				"</date>",
+				"<gnss>",
+				"gnss0",
+				"</gnss>",
+				"<devID>",
+				"0x1572",
+				"</devID>",
+				"<vendorID>",
+				"0x8086",
+				"</vendorID>",
+				"<macAddress>",
+				"b4:96:91:b4:9d:e8",
+				"</macAddress>",
+				"<operState>",
+				"up",
+				"</operState>",
+				"<ethtoolOut>",
+				"driver: ice",
+				"version: 1.11.20.7",
+				"firmware-version: 4.20 0x8001778b 1.3346.0",
+				"expansion-rom-version:",
+				"bus-info: 0000:86:00.0",
+				"supports-statistics: yes",
+				"supports-test: yes",
+				"supports-eeprom-access: yes",
+				"supports-register-dump: yes",
+				"supports-priv-flags: yes",
+				"</ethtoolOut>",
+				"<uevent>",
+				"PCI_SLOT_NAME=0000:86:00.0",
+				"</uevent>",
+			}, "\n")), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	When("the NIC is not an E810", func() {
+		It("should fail the device check", func() {
+			_, err := collectors.NewDevInfoCollector(constructor)
+			Expect(err).To(HaveOccurred())
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("not based on E810"))
+		})
+		It("should proceed when the device check is skipped", func() {
+			constructor.SkipDeviceCheck = true
+			collector, err := collectors.NewDevInfoCollector(constructor)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := collector.(collectors.OnDemandCollector).PollOnce()
+			Expect(err).NotTo(HaveOccurred())
+			devInfo, ok := data[collectors.DeviceInfo].(*devices.PTPDeviceInfo)
+			Expect(ok).To(BeTrue())
+			Expect(devInfo.DeviceID).To(Equal("0x1572"))
+		})
+	})
+})
+
+var _ = Describe("PollOnce", func() {
+	var (
+		output      *bufferCloser
+		constructor *collectors.CollectionConstructor
+	)
+	BeforeEach(func() {
+		output = &bufferCloser{}
+		constructor = &collectors.CollectionConstructor{
+			Callback:     callbacks.NewFileCallback(output, callbacks.Raw),
+			Clientset:    testutils.GetMockedClientSet(ptpPod),
+			PollInterval: 1,
+		}
+	})
+
+	When("a PMCCollector is polled once", func() {
+		It("should return the data rather than calling the callback", func() {
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte(strings.Join([]string{
+					"<date>",
+					"1686916187.0584",
+					"</date>",
+					"<PMC>",
+					"sending: GET GRANDMASTER_SETTINGS_NP",
+					"	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT GRANDMASTER_SETTINGS_NP",
+					"		clockClass              248",
+					"		clockAccuracy           0xfe",
+					"		offsetScaledLogVariance 0xffff",
+					"		currentUtcOffset        37",
+					"		leap61                  0",
+					"		leap59                  0",
+					"		currentUtcOffsetValid   0",
+					"		ptpTimescale            1",
+					"		timeTraceable           0",
+					"		frequencyTraceable      0",
+					"		timeSource              0xa0",
+					"sending: GET PARENT_DATA_SET",
+					"	507c6f.fffe.30fbe8-0 seq 1 RESPONSE MANAGEMENT PARENT_DATA_SET",
+					"		parentPortIdentity                    507c6f.fffe.30fbe8-0",
+					"		parentStats                           0",
+					"		observedParentOffsetScaledLogVariance 0xffff",
+					"		observedParentClockPhaseChangeRate    0x7fffffff",
+					"		grandmasterPriority1                  128",
+					"		gm.ClockClass                         248",
+					"		gm.ClockAccuracy                      0xfe",
+					"		gm.OffsetScaledLogVariance            0xffff",
+					"		grandmasterPriority2                  127",
+					"		grandmasterIdentity                   507c6f.fffe.30fbe8",
+					"</PMC>",
+				}, "\n")), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+
+			collector, err := collectors.NewPMCCollector(constructor)
+			Expect(err).NotTo(HaveOccurred())
+			onDemand, ok := collector.(collectors.OnDemandCollector)
+			Expect(ok).To(BeTrue())
+
+			data, err := onDemand.PollOnce()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data).To(HaveKey(collectors.PMCInfo))
+			pmcInfo, ok := data[collectors.PMCInfo].(*devices.PMCInfo)
+			Expect(ok).To(BeTrue())
+			Expect(pmcInfo.ClockClass).To(Equal(248))
+			Expect(pmcInfo.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(output.Len()).To(BeZero())
+		})
+		It("should return the fetch error", func() {
+			responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+				return []byte("unexpected output"), []byte(""), nil
+			}
+			clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+
+			collector, err := collectors.NewPMCCollector(constructor)
+			Expect(err).NotTo(HaveOccurred())
+
+			data, err := collector.(collectors.OnDemandCollector).PollOnce()
+			Expect(err).To(HaveOccurred())
+			var fetchErr *fetcher.FetchError
+			Expect(errors.As(err, &fetchErr)).To(BeTrue())
+			Expect(fetchErr.Collector).To(Equal(collectors.PMCCollectorName))
+			Expect(data).To(BeEmpty())
+		})
+	})
+
+	When("a MockCollector is polled once", func() {
+		It("should return the data for each poll and any injected failure", func() {
+			mock := collectors.NewMockCollector(time.Second, func(poll int64) bool { return poll == 2 })
+
+			data, err := mock.PollOnce()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(data[collectors.MockDataKey]).To(BeAssignableToTypeOf(&collectors.MockData{}))
+			Expect(data[collectors.MockDataKey].(*collectors.MockData).Poll).To(Equal(int64(1)))
+
+			data, err = mock.PollOnce()
+			Expect(err).To(MatchError(ContainSubstring("failed poll 2")))
+			Expect(data).To(BeEmpty())
+			Expect(mock.GetPollCount()).To(Equal(int64(2)))
+		})
+	})
+})
+
+var _ = Describe("SysfsCollector", func() {
+	var constructor *collectors.CollectionConstructor
+	BeforeEach(func() {
+		constructor = &collectors.CollectionConstructor{
+			Clientset:    testutils.GetMockedClientSet(ptpPod),
+			PollInterval: 1,
+			SysfsAttributes: map[string]string{
+				"carrier_changes": "/sys/class/net/ens7f0/carrier_changes",
+				"operstate":       "/sys/class/net/ens7f0/operstate",
+			},
+		}
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			return []byte(strings.Join([]string{
+				"<date>", "1686916187.0584", "</date>",
+				"<sysfs-carrier_changes>", "4", "</sysfs-carrier_changes>",
+				"<sysfs-operstate>", "up", "</sysfs-operstate>",
+			}, "\n")), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	When("attributes are configured", func() {
+		It("should emit each value under its name", func() {
+			callback, err := callbacks.NewRingBufferCallback(1, callbacks.Raw)
+			Expect(err).NotTo(HaveOccurred())
+			constructor.Callback = callback
+			collector, err := collectors.NewSysfsCollector(constructor)
+			Expect(err).NotTo(HaveOccurred())
+
+			resultsChan := make(chan collectors.PollResult, 1)
+			wg := utils.WaitGroupCount{}
+			wg.Add(1)
+			collector.Poll(resultsChan, &wg)
+			Expect((<-resultsChan).Errors).To(BeEmpty())
+
+			outputs := callback.Snapshot()[collectors.SysfsAttributesInfo]
+			Expect(outputs).To(HaveLen(1))
+			Expect(outputs[0]).To(ContainSubstring(`"values":{"carrier_changes":"4","operstate":"up"}`))
+		})
+	})
+
+	When("no attributes are configured", func() {
+		It("should return a MissingInputError", func() {
+			constructor.SysfsAttributes = nil
+			_, err := collectors.NewSysfsCollector(constructor)
+			var missingInput *utils.MissingInputError
+			Expect(errors.As(err, &missingInput)).To(BeTrue())
+		})
+	})
+})
+
+func TestCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Collectors Suite")
+}
@@ -4,8 +4,12 @@ package contexts
 
 import (
 	"fmt"
+	"path"
+	"strings"
 
+	log "github.com/sirupsen/logrus"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 )
@@ -18,49 +22,174 @@ const (
 	NetlinkDebugPod            = "ptp-dpll-netlink-debug-pod"
 	NetlinkDebugContainer      = "ptp-dpll-netlink-debug-container"
 	NetlinkDebugContainerImage = "quay.io/redhat-partner-solutions/dpll-debug:0.1"
+	// RunIDLabel identifies the run which created a pod
+	RunIDLabel = "vse-sync-collection-tools/run-id"
+	// InterfaceLabel is the PTP interface a pod was created to collect from
+	InterfaceLabel = "vse-sync-collection-tools/interface"
 )
 
-func GetPTPDaemonContext(clientset *clients.Clientset) (clients.ExecContext, error) {
-	ctx, err := clients.NewContainerContext(clientset, PTPNamespace, PTPPodNamePrefix, PTPContainer)
+// GetPTPDaemonContext returns the context for the PTP daemon container on the node,
+// the container is found by the PTPContainer prefix in case it has been renamed
+func GetPTPDaemonContext(clientset *clients.Clientset, nodeName string) (clients.ExecContext, error) {
+	ctx, err := clients.NewContainerContextFromPrefixes(clientset, PTPNamespace, PTPPodNamePrefix, PTPContainer, nodeName)
 	if err != nil {
 		return ctx, fmt.Errorf("could not create container context %w", err)
 	}
 	return ctx, nil
 }
 
-func GetNetlinkContext(clientset *clients.Clientset) (*clients.ContainerCreationExecContext, error) {
+// DefaultPodCapabilities are the capabilities the netlink debug pod needs.
+// Requires NET_ADMIN: having (NET_RAW + NET_BIND_SERVICE + NET_BROADCAST) does not work,
+// without NET_ADMIN it will not connect to the netlink interface.
+// Requires SYS_AMDIN: having every other permission does not work, without SYS_ADMIN
+// lspci does not include the Serial number in the comments thefore can not calculate the clockID.
+var DefaultPodCapabilities = []string{"SYS_ADMIN", "NET_ADMIN"}
+
+// PodOptions allows the user to adjust the pods created by the collectors
+// so that they are admitted on clusters with restricted security policies
+type PodOptions struct {
+	RunAsUser      *int64
+	Privileged     *bool
+	Labels         map[string]string
+	ServiceAccount string
+	RunID          string
+	Capabilities   []string
+	Volumes        []*clients.Volume
+}
+
+// PodLabels returns the user's labels merged with the labels identifying
+// the run and interface, the identifying labels take precedence
+func (opts *PodOptions) PodLabels(ptpInterface string) map[string]string {
+	labels := make(map[string]string)
+	if opts != nil {
+		for key, value := range opts.Labels {
+			labels[key] = value
+		}
+		if opts.RunID != "" {
+			labels[RunIDLabel] = opts.RunID
+		}
+	}
+	if ptpInterface != "" {
+		labels[InterfaceLabel] = ptpInterface
+	}
+	return labels
+}
+
+// ParseLabels converts key=value specs into the labels for the created pods
+func ParseLabels(specs []string) (map[string]string, error) {
+	labels := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		key, value, found := strings.Cut(spec, "=")
+		if !found {
+			return labels, fmt.Errorf("label %q is not in the form key=value", spec)
+		}
+		if errs := validation.IsQualifiedName(key); len(errs) > 0 {
+			return labels, fmt.Errorf("label %q has an invalid key: %s", spec, strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(value); len(errs) > 0 {
+			return labels, fmt.Errorf("label %q has an invalid value: %s", spec, strings.Join(errs, ", "))
+		}
+		labels[key] = value
+	}
+	return labels, nil
+}
+
+// unsafeHostPaths are paths which would give the created pod control of the node if mounted
+var unsafeHostPaths = map[string]bool{
+	"/":                       true,
+	"/etc":                    true,
+	"/proc":                   true,
+	"/root":                   true,
+	"/var/lib/kubelet":        true,
+	"/var/run/crio/crio.sock": true,
+	"/run/crio/crio.sock":     true,
+	"/var/run/docker.sock":    true,
+}
+
+// ParseVolumes converts host:container specs into host path volumes for the created pods.
+// Both paths must be absolute, mounts of obviously unsafe host paths are skipped with a warning.
+func ParseVolumes(specs []string) ([]*clients.Volume, error) {
+	volumes := make([]*clients.Volume, 0, len(specs))
+	for _, spec := range specs {
+		hostPath, mountPath, found := strings.Cut(spec, ":")
+		if !found || hostPath == "" || mountPath == "" {
+			return volumes, fmt.Errorf("volume %q is not in the form host:container", spec)
+		}
+		if !path.IsAbs(hostPath) || !path.IsAbs(mountPath) {
+			return volumes, fmt.Errorf("volume %q must use absolute paths", spec)
+		}
+		hostPath = path.Clean(hostPath)
+		mountPath = path.Clean(mountPath)
+		if unsafeHostPaths[hostPath] || mountPath == "/" {
+			log.Warningf("not mounting volume %q as it is unsafe", spec)
+			continue
+		}
+		volumes = append(volumes, &clients.Volume{
+			Name:         fmt.Sprintf("user-volume-%d", len(volumes)),
+			MountPath:    mountPath,
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: hostPath}},
+		})
+	}
+	return volumes, nil
+}
+
+// SecurityContext returns the container security context described by the options,
+// if no capabilities are given DefaultPodCapabilities are used
+func (opts *PodOptions) SecurityContext() *corev1.SecurityContext {
+	capabilities := DefaultPodCapabilities
+	if opts != nil && len(opts.Capabilities) > 0 {
+		capabilities = opts.Capabilities
+	}
+	securityContext := &corev1.SecurityContext{
+		Capabilities: &corev1.Capabilities{
+			Add: make([]corev1.Capability, 0, len(capabilities)),
+		},
+	}
+	for _, capability := range capabilities {
+		securityContext.Capabilities.Add = append(securityContext.Capabilities.Add, corev1.Capability(capability))
+	}
+	if opts != nil {
+		securityContext.RunAsUser = opts.RunAsUser
+		securityContext.Privileged = opts.Privileged
+	}
+	return securityContext
+}
+
+func GetNetlinkContext(
+	clientset *clients.Clientset,
+	nodeName string,
+	ptpInterface string,
+	podOptions *PodOptions,
+) (*clients.ContainerCreationExecContext, error) {
 	hpt := corev1.HostPathDirectory
+	volumes := []*clients.Volume{
+		{
+			Name:         "modules",
+			MountPath:    "/lib/modules",
+			VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/lib/modules", Type: &hpt}},
+		},
+	}
+	if podOptions != nil {
+		volumes = append(volumes, podOptions.Volumes...)
+	}
 	ctx, err := clients.NewContainerCreationExecContext(
 		clientset,
 		PTPNamespace,
 		NetlinkDebugPod,
 		NetlinkDebugContainer,
 		NetlinkDebugContainerImage,
-		map[string]string{},
+		nodeName,
+		podOptions.PodLabels(ptpInterface),
 		[]string{"sleep", "inf"},
-		&corev1.SecurityContext{
-			Capabilities: &corev1.Capabilities{
-				// Requires NET_ADMIN: having (NET_RAW + NET_BIND_SERVICE + NET_BROADCAST) does not work
-				//     Without NET_ADMIN it will not connect to the netlink interface
-				// Requires SYS_AMDIN: having every other permission does not work.
-				//     Without SYS_ADMIN lspci does not include the Serial number in the comments thefore can not calculate the clockID
-				Add: []corev1.Capability{
-					"SYS_ADMIN",
-					"NET_ADMIN",
-				},
-			},
-		},
+		podOptions.SecurityContext(),
 		true,
-		[]*clients.Volume{
-			{
-				Name:         "modules",
-				MountPath:    "/lib/modules",
-				VolumeSource: corev1.VolumeSource{HostPath: &corev1.HostPathVolumeSource{Path: "/lib/modules", Type: &hpt}},
-			},
-		},
+		volumes,
 	)
 	if err != nil {
 		return ctx, fmt.Errorf("failed to create netlink context: %w", err)
 	}
+	if podOptions != nil {
+		ctx.SetServiceAccountName(podOptions.ServiceAccount)
+	}
 	return ctx, nil
 }
@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package contexts_test
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	fakeK8s "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+// getCreatedPod returns the first pod created through the fake clientset
+func getCreatedPod(fakeK8sClient *fakeK8s.Clientset) *corev1.Pod {
+	for _, action := range fakeK8sClient.Actions() {
+		if createAction, ok := action.(k8stesting.CreateAction); ok {
+			if pod, ok := createAction.GetObject().(*corev1.Pod); ok {
+				return pod
+			}
+		}
+	}
+	return nil
+}
+
+var _ = Describe("GetNetlinkContext", func() {
+	BeforeEach(func() {
+		// The fake pod never starts so don't wait long for it
+		Expect(os.Setenv("COLLECTOR_POD_START_TIMEOUT", "10ms")).To(Succeed())
+		DeferCleanup(os.Unsetenv, "COLLECTOR_POD_START_TIMEOUT")
+	})
+
+	When("no pod options are given", func() {
+		It("should create the pod with the default capabilities", func() {
+			clientset := testutils.GetMockedClientSet()
+			ctx, err := contexts.GetNetlinkContext(clientset, "TestNode", "ens7f0", nil)
+			Expect(err).NotTo(HaveOccurred())
+			_ = ctx.CreatePodAndWait()
+
+			pod := getCreatedPod(clientset.K8sClient.(*fakeK8s.Clientset))
+			Expect(pod).NotTo(BeNil())
+			securityContext := pod.Spec.Containers[0].SecurityContext
+			Expect(securityContext.Capabilities.Add).To(Equal([]corev1.Capability{"SYS_ADMIN", "NET_ADMIN"}))
+			Expect(securityContext.RunAsUser).To(BeNil())
+			Expect(securityContext.Privileged).To(BeNil())
+			Expect(pod.Spec.ServiceAccountName).To(BeEmpty())
+			Expect(pod.Labels).To(Equal(map[string]string{
+				clients.ManagedByLabel:  clients.ManagedByValue,
+				contexts.InterfaceLabel: "ens7f0",
+			}))
+		})
+	})
+
+	When("pod options are given", func() {
+		It("should create the pod with the requested security context and service account", func() {
+			runAsUser := int64(1000)
+			privileged := true
+			volumes, err := contexts.ParseVolumes([]string{"/dev:/dev"})
+			Expect(err).NotTo(HaveOccurred())
+			clientset := testutils.GetMockedClientSet()
+			ctx, err := contexts.GetNetlinkContext(clientset, "TestNode", "ens7f0", &contexts.PodOptions{
+				RunAsUser:      &runAsUser,
+				Labels:         map[string]string{"team": "ptp", contexts.InterfaceLabel: "ens1f0"},
+				RunID:          "20230616-114947",
+				Privileged:     &privileged,
+				Capabilities:   []string{"NET_ADMIN", "SYS_TIME"},
+				ServiceAccount: "ptp-collector",
+				Volumes:        volumes,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			_ = ctx.CreatePodAndWait()
+
+			pod := getCreatedPod(clientset.K8sClient.(*fakeK8s.Clientset))
+			Expect(pod).NotTo(BeNil())
+			securityContext := pod.Spec.Containers[0].SecurityContext
+			Expect(securityContext.Capabilities.Add).To(Equal([]corev1.Capability{"NET_ADMIN", "SYS_TIME"}))
+			Expect(*securityContext.RunAsUser).To(Equal(runAsUser))
+			Expect(*securityContext.Privileged).To(BeTrue())
+			Expect(pod.Spec.ServiceAccountName).To(Equal("ptp-collector"))
+			Expect(pod.Spec.NodeName).To(Equal("TestNode"))
+			Expect(pod.Labels).To(Equal(map[string]string{
+				"team":                  "ptp",
+				clients.ManagedByLabel:  clients.ManagedByValue,
+				contexts.RunIDLabel:     "20230616-114947",
+				contexts.InterfaceLabel: "ens7f0",
+			}))
+			Expect(pod.Spec.Volumes).To(HaveLen(2))
+			Expect(pod.Spec.Volumes[1].HostPath.Path).To(Equal("/dev"))
+			Expect(pod.Spec.Containers[0].VolumeMounts).To(ContainElement(corev1.VolumeMount{
+				Name:      pod.Spec.Volumes[1].Name,
+				MountPath: "/dev",
+			}))
+		})
+	})
+})
+
+var _ = Describe("ParseVolumes", func() {
+	When("given valid volumes", func() {
+		It("should return host path volumes", func() {
+			volumes, err := contexts.ParseVolumes([]string{"/dev:/dev", "/sys/class/net/:/host/net"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(HaveLen(2))
+			Expect(volumes[0].MountPath).To(Equal("/dev"))
+			Expect(volumes[0].VolumeSource.HostPath.Path).To(Equal("/dev"))
+			Expect(volumes[1].MountPath).To(Equal("/host/net"))
+			Expect(volumes[1].VolumeSource.HostPath.Path).To(Equal("/sys/class/net"))
+			Expect(volumes[0].Name).NotTo(Equal(volumes[1].Name))
+		})
+	})
+	When("given an unsafe volume", func() {
+		It("should skip it", func() {
+			volumes, err := contexts.ParseVolumes([]string{"/:/host", "/etc/:/etc", "/dev:/"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(volumes).To(BeEmpty())
+		})
+	})
+	When("given an invalid volume", func() {
+		It("should return an error", func() {
+			for _, spec := range []string{"/dev", "/dev:", ":/dev", "dev:/dev", "/dev:dev"} {
+				_, err := contexts.ParseVolumes([]string{spec})
+				Expect(err).To(HaveOccurred(), spec)
+			}
+		})
+	})
+})
+
+var _ = Describe("ParseLabels", func() {
+	When("given valid labels", func() {
+		It("should return them as a map", func() {
+			labels, err := contexts.ParseLabels([]string{"team=ptp", "example.com/site=lab-1", "empty="})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(labels).To(Equal(map[string]string{"team": "ptp", "example.com/site": "lab-1", "empty": ""}))
+		})
+	})
+	When("given an invalid label", func() {
+		It("should return an error", func() {
+			for _, spec := range []string{"team", "=ptp", "team=not valid", "-team=ptp"} {
+				_, err := contexts.ParseLabels([]string{spec})
+				Expect(err).To(HaveOccurred(), spec)
+			}
+		})
+	})
+})
+
+func TestCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Contexts Suite")
+}
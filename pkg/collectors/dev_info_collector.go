@@ -14,6 +14,7 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/validations"
 )
@@ -76,7 +77,7 @@ func (ptpDev *DevInfoCollector) poll() error {
 	case <-ptpDev.requiresFetch:
 		fetchedDevInfo, err := devices.GetPTPDeviceInfo(ptpDev.interfaceName, ptpDev.ctx)
 		if err != nil {
-			return fmt.Errorf("failed to fetch %s %w", DeviceInfo, err)
+			return fetcher.SetCollector(fmt.Errorf("failed to fetch %s %w", DeviceInfo, err), DevInfoCollectorName)
 		}
 		ptpDev.devInfo = &fetchedDevInfo
 		devInfo = &fetchedDevInfo
@@ -84,7 +85,7 @@ func (ptpDev *DevInfoCollector) poll() error {
 		devInfo = ptpDev.devInfo
 	}
 
-	err := ptpDev.callback.Call(devInfo, DeviceInfo)
+	err := ptpDev.call(devInfo, DeviceInfo)
 	if err != nil {
 		return fmt.Errorf("callback failed %w", err)
 	}
@@ -94,6 +95,7 @@ func (ptpDev *DevInfoCollector) poll() error {
 // Poll collects information from the cluster then
 // calls the callback.Call to allow that to persist it
 func (ptpDev *DevInfoCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
 	defer func() {
 		wg.Done()
 	}()
@@ -103,11 +105,18 @@ func (ptpDev *DevInfoCollector) Poll(resultsChan chan PollResult, wg *utils.Wait
 		errorsToReturn = append(errorsToReturn, err)
 	}
 	resultsChan <- PollResult{
-		CollectorName: DPLLCollectorName,
+		CollectorName: DevInfoCollectorName,
 		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
 	}
 }
 
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (ptpDev *DevInfoCollector) PollOnce() (map[string]any, error) {
+	return ptpDev.pollOnce(ptpDev.Poll)
+}
+
 // CleanUp stops a running collector
 func (ptpDev *DevInfoCollector) CleanUp() error {
 	ptpDev.running = false
@@ -128,7 +137,10 @@ func verify(ptpDevInfo *devices.PTPDeviceInfo, constructor *CollectionConstructo
 		err := check.Verify()
 		if err != nil {
 			var invalidEnv *utils.InvalidEnvError
-			if errors.As(err, &invalidEnv) {
+			_, isDeviceCheck := check.(*validations.DeviceDetails)
+			if isDeviceCheck && constructor.SkipDeviceCheck {
+				log.Warningf("ignoring failed device check as it has been skipped: %s", err.Error())
+			} else if errors.As(err, &invalidEnv) {
 				checkErrors = append(checkErrors, err)
 			} else {
 				log.Warningf("failed to verify %s: %s", check.GetDescription(), err.Error())
@@ -151,18 +163,21 @@ func verify(ptpDevInfo *devices.PTPDeviceInfo, constructor *CollectionConstructo
 // Returns a new DevInfoCollector from the CollectionConstuctor Factory
 func NewDevInfoCollector(constructor *CollectionConstructor) (Collector, error) {
 	// Build DPPInfoFetcher ahead of time call to GetPTPDeviceInfo will build the other
-	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset)
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
 	if err != nil {
 		return &DevInfoCollector{}, fmt.Errorf("failed to create DevInfoCollector: %w", err)
 	}
-	err = devices.BuildPTPDeviceInfo(constructor.PTPInterface)
+	err = devices.BuildPTPDeviceInfo(constructor.PTPInterface, constructor.GNSSDeviceIndex)
 	if err != nil {
 		return &DevInfoCollector{}, fmt.Errorf("failed to build fetcher for PTPDeviceInfo %w", err)
 	}
 
 	ptpDevInfo, err := devices.GetPTPDeviceInfo(constructor.PTPInterface, ctx)
 	if err != nil {
-		return &DevInfoCollector{}, fmt.Errorf("failed to fetch initial DeviceInfo %w", err)
+		return &DevInfoCollector{}, fetcher.SetCollector(
+			fmt.Errorf("failed to fetch initial DeviceInfo %w", err),
+			DevInfoCollectorName,
+		)
 	}
 
 	err = verify(&ptpDevInfo, constructor)
@@ -189,4 +204,5 @@ func NewDevInfoCollector(constructor *CollectionConstructor) (Collector, error)
 
 func init() {
 	RegisterCollector(DevInfoCollectorName, NewDevInfoCollector, required)
+	RegisterAnalyserIDs(DevInfoCollectorName, devices.DeviceInfoID)
 }
@@ -0,0 +1,92 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	DeviceSummaryCollectorName = "DeviceSummary"
+	DeviceSummaryInfo          = "device-summary"
+)
+
+// DeviceSummaryCollector announces a single record containing
+// the device info along with the DPLL and GNSS versions
+type DeviceSummaryCollector struct {
+	*baseCollector
+	ctx           clients.ExecContext
+	interfaceName string
+}
+
+// polls for the device summary then passes it to the callback,
+// a partial summary is still passed to the callback
+func (summary *DeviceSummaryCollector) poll() []error {
+	errorsToReturn := make([]error, 0)
+	deviceSummary, err := devices.GetDeviceSummary(summary.ctx, summary.interfaceName)
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, fetcher.SetCollector(
+			fmt.Errorf("failed to fetch %s %w", DeviceSummaryInfo, err),
+			DeviceSummaryCollectorName,
+		))
+	}
+	if deviceSummary.DeviceInfo == nil {
+		return errorsToReturn
+	}
+	err = summary.call(&deviceSummary, DeviceSummaryInfo)
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, fmt.Errorf("callback failed %w", err))
+	}
+	return errorsToReturn
+}
+
+// Poll collects information from the cluster then
+// calls the callback.Call to allow that to persist it
+func (summary *DeviceSummaryCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
+	defer func() {
+		wg.Done()
+	}()
+	resultsChan <- PollResult{
+		CollectorName: DeviceSummaryCollectorName,
+		Errors:        summary.poll(),
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
+	}
+}
+
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (summary *DeviceSummaryCollector) PollOnce() (map[string]any, error) {
+	return summary.pollOnce(summary.Poll)
+}
+
+// Returns a new DeviceSummaryCollector from the CollectionConstuctor Factory
+func NewDeviceSummaryCollector(constructor *CollectionConstructor) (Collector, error) {
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
+	if err != nil {
+		return &DeviceSummaryCollector{}, fmt.Errorf("failed to create DeviceSummaryCollector: %w", err)
+	}
+
+	collector := DeviceSummaryCollector{
+		baseCollector: newBaseCollector(
+			constructor.DevInfoAnnouceInterval,
+			true,
+			constructor.Callback,
+		),
+		ctx:           ctx,
+		interfaceName: constructor.PTPInterface,
+	}
+	return &collector, nil
+}
+
+func init() {
+	RegisterCollector(DeviceSummaryCollectorName, NewDeviceSummaryCollector, optional)
+	RegisterAnalyserIDs(DeviceSummaryCollectorName, devices.DeviceSummaryID)
+}
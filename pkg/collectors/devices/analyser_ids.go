@@ -0,0 +1,23 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+// The IDs of the messages sent to the analysers, consumers match on these
+// so they must not change without updating vse-sync-pp
+const (
+	DeviceInfoID      = "devInfo"
+	DeviceSummaryID   = "device-summary"
+	DPLLTimeErrorID   = "dpll/time-error"
+	DPLLStatesID      = "dpll/states"
+	DPLLStateChangeID = "dpll/state-change"
+	GNSSTimeErrorID   = "gnss/time-error"
+	GNSSRFMonID       = "gnss/rf-mon"
+	GNSSTimePulseID   = "gnss/time-pulse"
+	GNSSUBXMessageID  = "gnss/ubx-message"
+	GNSSEventID       = "gnss/event"
+	PMCGMSettingsID   = "phc/gm-settings"
+	PMCTimeStatusID   = "phc/time-status"
+	PTPConfigID       = "ptp/config"
+	ICEPinConfigID    = "ice/pin-config"
+	SysfsAttributesID = "sysfs/attributes"
+)
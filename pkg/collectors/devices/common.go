@@ -5,9 +5,11 @@ package devices
 import (
 	"fmt"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
@@ -36,3 +38,27 @@ func getDateCommand() *clients.Cmd {
 func init() {
 	getDateCommand()
 }
+
+// fetcherCache holds the fetcher built for each key e.g. an interface or a config file,
+// it is safe to use from the collectors of several interfaces at once
+type fetcherCache struct {
+	fetchers map[string]*fetcher.Fetcher
+	lock     sync.RWMutex
+}
+
+func newFetcherCache() *fetcherCache {
+	return &fetcherCache{fetchers: make(map[string]*fetcher.Fetcher)}
+}
+
+func (cache *fetcherCache) get(interfaceName string) (*fetcher.Fetcher, bool) {
+	cache.lock.RLock()
+	defer cache.lock.RUnlock()
+	fetcherInst, ok := cache.fetchers[interfaceName]
+	return fetcherInst, ok
+}
+
+func (cache *fetcherCache) set(interfaceName string, fetcherInst *fetcher.Fetcher) {
+	cache.lock.Lock()
+	defer cache.lock.Unlock()
+	cache.fetchers[interfaceName] = fetcherInst
+}
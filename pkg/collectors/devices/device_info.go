@@ -17,19 +17,24 @@ import (
 )
 
 type PTPDeviceInfo struct {
-	Timestamp       string        `fetcherKey:"date"            json:"date"`
-	VendorID        string        `fetcherKey:"vendorID"        json:"vendorId"`
-	DeviceID        string        `fetcherKey:"devID"           json:"deviceInfo"`
-	GNSSDev         string        `fetcherKey:"gnss"            json:"GNSSDev"`
-	FirmwareVersion string        `fetcherKey:"firmwareVersion" json:"firmwareVersion"`
-	DriverVersion   string        `fetcherKey:"driverVersion"   json:"driverVersion"`
-	Timeoffset      time.Duration `fetcherKey:"timeOffset"      json:"timeOffset"`
+	Raw             map[string]string `fetcherKey:"raw"             json:"raw,omitempty"`
+	Timestamp       string            `fetcherKey:"date"            json:"date"`
+	VendorID        string            `fetcherKey:"vendorID"        json:"vendorId"`
+	DeviceID        string            `fetcherKey:"devID"           json:"deviceInfo"`
+	GNSSDev         string            `fetcherKey:"gnss"            json:"GNSSDev"`
+	GNSSDevices     []string          `fetcherKey:"gnssDevices"     json:"GNSSDevices,omitempty"`
+	FirmwareVersion string            `fetcherKey:"firmwareVersion" json:"firmwareVersion"`
+	DriverVersion   string            `fetcherKey:"driverVersion"   json:"driverVersion"`
+	PCIAddress      string            `fetcherKey:"pciAddress"      json:"pciAddress,omitempty"`
+	MACAddress      string            `fetcherKey:"macAddress"      json:"macAddress"`
+	OperState       string            `fetcherKey:"operState"       json:"operState"`
+	Timeoffset      time.Duration     `fetcherKey:"timeOffset"      json:"timeOffset"`
 }
 
 // AnalyserJSON returns the json expected by the analysers
 func (ptpDevInfo *PTPDeviceInfo) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
 	formatted := callbacks.AnalyserFormatType{
-		ID: "devInfo",
+		ID: DeviceInfoID,
 		Data: map[string]any{
 			"timestamp":         time.Now().Add(ptpDevInfo.Timeoffset).UTC().Format(time.RFC3339Nano),
 			"fetched_timestamp": ptpDevInfo.Timestamp,
@@ -38,13 +43,16 @@ func (ptpDevInfo *PTPDeviceInfo) GetAnalyserFormat() ([]*callbacks.AnalyserForma
 			"gnss":              ptpDevInfo.GNSSDev,
 			"firmwareVersion":   ptpDevInfo.FirmwareVersion,
 			"driverVersion":     ptpDevInfo.DriverVersion,
+			"pciAddress":        ptpDevInfo.PCIAddress,
+			"macAddress":        ptpDevInfo.MACAddress,
+			"operState":         ptpDevInfo.OperState,
 		},
 	}
 	return []*callbacks.AnalyserFormatType{&formatted}, nil
 }
 
 var (
-	devFetcher   map[string]*fetcher.Fetcher
+	devFetcher   *fetcherCache
 	ethtoolRegex = regexp.MustCompile(`version: (.*)\nfirmware-version: (.*)\n`)
 	// driver: ice
 	// version: 1.11.20.7
@@ -58,8 +66,30 @@ var (
 	// supports-priv-flags: yes
 )
 
+// pciSlotNameKey is the key of the PCI bus address in the uevent file of a PCI device
+const pciSlotNameKey = "PCI_SLOT_NAME"
+
 func init() {
-	devFetcher = make(map[string]*fetcher.Fetcher)
+	devFetcher = newFetcherCache()
+}
+
+// parsePCIAddress returns the PCI_SLOT_NAME from the uevent file of the interface's device,
+// virtual interfaces have no device so the uevent is empty and the address is blank
+//
+//	DRIVER=ice
+//	PCI_CLASS=20000
+//	PCI_ID=8086:1593
+//	PCI_SUBSYS_ID=8086:0002
+//	PCI_SLOT_NAME=0000:86:00.0
+//	MODALIAS=pci:v00008086d00001593sv00008086sd00000002bc02sc00i00
+func parsePCIAddress(uevent string) string {
+	for _, line := range strings.Split(uevent, "\n") {
+		key, value, found := strings.Cut(strings.TrimSpace(line), "=")
+		if found && key == pciSlotNameKey {
+			return value
+		}
+	}
+	return ""
 }
 
 func extractOffsetFromTimestamp(result map[string]string) (map[string]any, error) {
@@ -99,21 +129,81 @@ func devInfoPostProcessor(result map[string]string) (map[string]any, error) {
 	for key, value := range firmwareResult {
 		processedResult[key] = value
 	}
+	processedResult["pciAddress"] = parsePCIAddress(result["uevent"])
 	return processedResult, nil
 }
 
-func processGNSSPath(s string) (string, error) {
-	return "/dev/" + strings.TrimSpace(s), nil
+func gnssSysfsDir(interfaceName string) string {
+	return fmt.Sprintf("/sys/class/net/%s/device/gnss/", interfaceName)
+}
+
+// newGNSSListingProcessor returns a processor which checks that the listing of the gnss directory
+// for interfaceName has at least one device and returns the devices one per line
+func newGNSSListingProcessor(interfaceName string) func(string) (string, error) {
+	return func(s string) (string, error) {
+		entries := strings.Fields(s)
+		if len(entries) == 0 {
+			return "", fmt.Errorf(
+				"no GNSS device found for interface %s, %s is empty or missing",
+				interfaceName, gnssSysfsDir(interfaceName),
+			)
+		}
+		return strings.Join(entries, "\n"), nil
+	}
+}
+
+// selectGNSSDev sets gnss to the path of the device at gnssDeviceIndex in the listing
+// and gnssDevices to the paths of all of the devices
+func selectGNSSDev(interfaceName string, gnssDeviceIndex int, result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+	entries := strings.Fields(result["gnss"])
+	if gnssDeviceIndex < 0 || gnssDeviceIndex >= len(entries) {
+		return processedResult, fmt.Errorf(
+			"GNSS device index %d is out of range for interface %s, %s contains: %s",
+			gnssDeviceIndex, interfaceName, gnssSysfsDir(interfaceName), strings.Join(entries, ", "),
+		)
+	}
+	gnssDevices := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		gnssDevices = append(gnssDevices, "/dev/"+entry)
+	}
+	if len(gnssDevices) > 1 {
+		log.Infof(
+			"interface %s has %d GNSS devices (%s), using %s",
+			interfaceName, len(gnssDevices), strings.Join(gnssDevices, ", "), gnssDevices[gnssDeviceIndex],
+		)
+	}
+	processedResult["gnss"] = gnssDevices[gnssDeviceIndex]
+	processedResult["gnssDevices"] = gnssDevices
+	return processedResult, nil
+}
+
+func newDevInfoPostProcessor(interfaceName string, gnssDeviceIndex int) fetcher.PostProcessFuncType {
+	return func(result map[string]string) (map[string]any, error) {
+		processedResult, err := devInfoPostProcessor(result)
+		if err != nil {
+			return processedResult, err
+		}
+		gnssResult, err := selectGNSSDev(interfaceName, gnssDeviceIndex, result)
+		if err != nil {
+			return processedResult, err
+		}
+		for key, value := range gnssResult {
+			processedResult[key] = value
+		}
+		return processedResult, nil
+	}
 }
 
 // BuildPTPDeviceInfo popluates the fetcher required for
-// collecting the PTPDeviceInfo
-func BuildPTPDeviceInfo(interfaceName string) error { //nolint:dupl // Further dedup risks be too abstract or fragile
-	gnssCmd, err := clients.NewCmd("gnss", fmt.Sprintf("ls /sys/class/net/%s/device/gnss/", interfaceName))
+// collecting the PTPDeviceInfo, if the interface has more than one
+// GNSS device the one at gnssDeviceIndex in the gnss directory is used
+func BuildPTPDeviceInfo(interfaceName string, gnssDeviceIndex int) error { //nolint:dupl // Further dedup risks be too abstract or fragile
+	gnssCmd, err := clients.NewCmd("gnss", "ls "+gnssSysfsDir(interfaceName))
 	if err != nil {
 		return fmt.Errorf("failed to create fetcher for devInfo: failed to create command for %s: %w", "gnss", err)
 	}
-	gnssCmd.SetOutputProcessor(processGNSSPath)
+	gnssCmd.SetOutputProcessor(newGNSSListingProcessor(interfaceName))
 
 	fetcherInst, err := fetcher.FetcherFactory(
 		[]*clients.Cmd{
@@ -131,33 +221,49 @@ func BuildPTPDeviceInfo(interfaceName string) error { //nolint:dupl // Further d
 				Command: fmt.Sprintf("cat /sys/class/net/%s/device/vendor", interfaceName),
 				Trim:    true,
 			},
+			{
+				Key:     "macAddress",
+				Command: fmt.Sprintf("cat /sys/class/net/%s/address", interfaceName),
+				Trim:    true,
+			},
+			{
+				Key:     "operState",
+				Command: fmt.Sprintf("cat /sys/class/net/%s/operstate", interfaceName),
+				Trim:    true,
+			},
 			{
 				Key:     "ethtoolOut",
 				Command: fmt.Sprintf("ethtool -i %s", interfaceName),
 				Trim:    true,
 			},
+			{
+				Key:     "uevent",
+				Command: fmt.Sprintf("cat /sys/class/net/%s/device/uevent 2>/dev/null", interfaceName),
+				Trim:    true,
+			},
 		},
 	)
 	if err != nil {
 		log.Errorf("failed to create fetcher for devInfo: %s", err.Error())
 		return fmt.Errorf("failed to create fetcher for devInfo: %w", err)
 	}
-	devFetcher[interfaceName] = fetcherInst
-	fetcherInst.SetPostProcessor(devInfoPostProcessor)
+	devFetcher.set(interfaceName, fetcherInst)
+	fetcherInst.SetPostProcessor(newDevInfoPostProcessor(interfaceName, gnssDeviceIndex))
 	return nil
 }
 
-// GetPTPDeviceInfo returns the PTPDeviceInfo for an interface
+// GetPTPDeviceInfo returns the PTPDeviceInfo for an interface, if BuildPTPDeviceInfo
+// has not been called for the interface the first GNSS device is used
 func GetPTPDeviceInfo(interfaceName string, ctx clients.ExecContext) (PTPDeviceInfo, error) {
 	devInfo := PTPDeviceInfo{}
 	// Find the dev for the GNSS for this interface
-	fetcherInst, fetchedInstanceOk := devFetcher[interfaceName]
+	fetcherInst, fetchedInstanceOk := devFetcher.get(interfaceName)
 	if !fetchedInstanceOk {
-		err := BuildPTPDeviceInfo(interfaceName)
+		err := BuildPTPDeviceInfo(interfaceName, 0)
 		if err != nil {
 			return devInfo, err
 		}
-		fetcherInst, fetchedInstanceOk = devFetcher[interfaceName]
+		fetcherInst, fetchedInstanceOk = devFetcher.get(interfaceName)
 		if !fetchedInstanceOk {
 			return devInfo, errors.New("failed to create fetcher for PTPDeviceInfo")
 		}
@@ -34,6 +34,21 @@ supports-priv-flags: yes
 </ethtoolOut>
 `
 
+const ueventOutput = `<uevent>
+DRIVER=ice
+PCI_CLASS=20000
+PCI_ID=8086:1593
+PCI_SUBSYS_ID=8086:0002
+PCI_SLOT_NAME=%s
+MODALIAS=pci:v00008086d00001593sv00008086sd00000002bc02sc00i00
+</uevent>
+`
+
+// linkOutput returns the output of reading the address and operstate of an interface
+func linkOutput(macAddress, operState string) string {
+	return fmt.Sprintf("<macAddress>\n%s\n</macAddress>\n<operState>\n%s\n</operState>\n", macAddress, operState)
+}
+
 var testPod = &v1.Pod{
 	ObjectMeta: metav1.ObjectMeta{
 		Name:        "TestPod-8292",
@@ -69,18 +84,26 @@ var _ = Describe("NewContainerContext", func() {
 			gnssDev := "gnss0"
 			firmwareVersion := "4.20 0x8001778b 1.3346.0"
 			driverVersion := "1.11.20.7"
+			pciAddress := "0000:86:00.0"
+			macAddress := "b4:96:91:b4:9d:e8"
 
 			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
 			expectedInput += "echo '<gnss>';ls /sys/class/net/aFakeInterface/device/gnss/;echo '</gnss>';"
 			expectedInput += "echo '<devID>';cat /sys/class/net/aFakeInterface/device/device;echo '</devID>';"
 			expectedInput += "echo '<vendorID>';cat /sys/class/net/aFakeInterface/device/vendor;echo '</vendorID>';"
+			expectedInput += "echo '<macAddress>';cat /sys/class/net/aFakeInterface/address;echo '</macAddress>';"
+			expectedInput += "echo '<operState>';cat /sys/class/net/aFakeInterface/operstate;echo '</operState>';"
 			expectedInput += "echo '<ethtoolOut>';ethtool -i aFakeInterface;echo '</ethtoolOut>';"
+			expectedInput += "echo '<uevent>';cat /sys/class/net/aFakeInterface/device/uevent 2>/dev/null;echo '</uevent>';"
 
 			expectedOutput := "<date>\n1686916187.0584\n</date>\n"
 			expectedOutput += fmt.Sprintf("<gnss>\n%s\n</gnss>\n", gnssDev)
 			expectedOutput += fmt.Sprintf("<devID>\n%s\n</devID>\n", devID)
 			expectedOutput += fmt.Sprintf("<vendorID>\n%s\n</vendorID>\n", vendor)
+			expectedOutput += fmt.Sprintf("<macAddress>\n%s\n</macAddress>\n", macAddress)
+			expectedOutput += "<operState>\nup\n</operState>\n"
 			expectedOutput += fmt.Sprintf(ethtoolOutput, driverVersion, firmwareVersion)
+			expectedOutput += fmt.Sprintf(ueventOutput, pciAddress)
 
 			response[expectedInput] = []byte(expectedOutput)
 
@@ -94,6 +117,220 @@ var _ = Describe("NewContainerContext", func() {
 			Expect(info.GNSSDev).To(Equal("/dev/" + gnssDev))
 			Expect(info.FirmwareVersion).To(Equal(firmwareVersion))
 			Expect(info.DriverVersion).To(Equal(driverVersion))
+			Expect(info.PCIAddress).To(Equal(pciAddress))
+			Expect(info.MACAddress).To(Equal(macAddress))
+			Expect(info.OperState).To(Equal("up"))
+		})
+	})
+	When("GetAnalyserFormat is called on a PTPDeviceInfo", func() {
+		It("should return a devInfo message containing the device fields", func() {
+			info := devices.PTPDeviceInfo{
+				Timestamp:       "2023-06-16T11:49:47.0584Z",
+				VendorID:        "0x8086",
+				DeviceID:        "0x1593",
+				GNSSDev:         "/dev/gnss0",
+				FirmwareVersion: "4.20 0x8001778b 1.3346.0",
+				DriverVersion:   "1.11.20.7",
+				PCIAddress:      "0000:86:00.0",
+				MACAddress:      "b4:96:91:b4:9d:e8",
+				OperState:       "up",
+			}
+			messages, err := info.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0].ID).To(Equal("devInfo"))
+			data, ok := messages[0].Data.(map[string]any)
+			Expect(ok).To(BeTrue())
+			Expect(data).To(HaveKey("timestamp"))
+			Expect(data).To(HaveKeyWithValue("fetched_timestamp", info.Timestamp))
+			Expect(data).To(HaveKeyWithValue("vendorID", info.VendorID))
+			Expect(data).To(HaveKeyWithValue("devID", info.DeviceID))
+			Expect(data).To(HaveKeyWithValue("gnss", info.GNSSDev))
+			Expect(data).To(HaveKeyWithValue("firmwareVersion", info.FirmwareVersion))
+			Expect(data).To(HaveKeyWithValue("driverVersion", info.DriverVersion))
+			Expect(data).To(HaveKeyWithValue("pciAddress", info.PCIAddress))
+			Expect(data).To(HaveKeyWithValue("macAddress", info.MACAddress))
+			Expect(data).To(HaveKeyWithValue("operState", info.OperState))
+		})
+	})
+})
+
+var _ = Describe("GetPTPDeviceInfo GNSS device", func() {
+	var ctx clients.ExecContext
+	BeforeEach(func() {
+		var err error
+		ctx, err = clients.NewContainerContext(testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer")
+		Expect(err).NotTo(HaveOccurred())
+	})
+	respondWithGNSS := func(gnssListing string) {
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			output := "<date>\n1686916187.0584\n</date>\n"
+			output += "<gnss>\n" + gnssListing + "</gnss>\n"
+			output += "<devID>\n0x1593\n</devID>\n"
+			output += "<vendorID>\n0x8086\n</vendorID>\n"
+			output += linkOutput("b4:96:91:b4:9d:e8", "up")
+			output += fmt.Sprintf(ethtoolOutput, "1.11.20.7", "4.20 0x8001778b 1.3346.0")
+			output += fmt.Sprintf(ueventOutput, "0000:86:00.0")
+			return []byte(output), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	}
+
+	When("the gnss directory has one entry", func() {
+		It("should return the device path", func() {
+			respondWithGNSS("gnss0\n")
+			info, err := devices.GetPTPDeviceInfo("oneGNSSInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.GNSSDev).To(Equal("/dev/gnss0"))
+		})
+	})
+	When("the gnss directory is empty", func() {
+		It("should return an error naming the interface and directory", func() {
+			respondWithGNSS("")
+			_, err := devices.GetPTPDeviceInfo("noGNSSInterface", ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(
+				"no GNSS device found for interface noGNSSInterface, " +
+					"/sys/class/net/noGNSSInterface/device/gnss/ is empty or missing",
+			))
+		})
+	})
+	When("the gnss listing has extra whitespace", func() {
+		It("should trim it", func() {
+			respondWithGNSS("  gnss0 \t\n\n")
+			info, err := devices.GetPTPDeviceInfo("paddedGNSSInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.GNSSDev).To(Equal("/dev/gnss0"))
+			Expect(info.GNSSDevices).To(Equal([]string{"/dev/gnss0"}))
+		})
+	})
+	When("the gnss directory has multiple entries", func() {
+		It("should use the first and record all of them", func() {
+			respondWithGNSS("gnss0\ngnss1\n")
+			info, err := devices.GetPTPDeviceInfo("twoGNSSInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.GNSSDev).To(Equal("/dev/gnss0"))
+			Expect(info.GNSSDevices).To(Equal([]string{"/dev/gnss0", "/dev/gnss1"}))
+		})
+		It("should use the device at the requested index", func() {
+			respondWithGNSS("gnss0  gnss1\n")
+			Expect(devices.BuildPTPDeviceInfo("secondGNSSInterface", 1)).To(Succeed())
+			info, err := devices.GetPTPDeviceInfo("secondGNSSInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.GNSSDev).To(Equal("/dev/gnss1"))
+			Expect(info.GNSSDevices).To(Equal([]string{"/dev/gnss0", "/dev/gnss1"}))
+		})
+		It("should return an error listing the entries if the index is out of range", func() {
+			respondWithGNSS("gnss0\ngnss1\n")
+			Expect(devices.BuildPTPDeviceInfo("thirdGNSSInterface", 2)).To(Succeed())
+			_, err := devices.GetPTPDeviceInfo("thirdGNSSInterface", ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(
+				"GNSS device index 2 is out of range for interface thirdGNSSInterface, " +
+					"/sys/class/net/thirdGNSSInterface/device/gnss/ contains: gnss0, gnss1",
+			))
+		})
+	})
+})
+
+var _ = Describe("GetPTPDeviceInfo PCI address", func() {
+	var ctx clients.ExecContext
+	BeforeEach(func() {
+		var err error
+		ctx, err = clients.NewContainerContext(
+			testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer",
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+	respondWithUevent := func(uevent string) {
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			output := "<date>\n1686916187.0584\n</date>\n"
+			output += "<gnss>\ngnss0\n</gnss>\n"
+			output += "<devID>\n0x1593\n</devID>\n"
+			output += "<vendorID>\n0x8086\n</vendorID>\n"
+			output += linkOutput("b4:96:91:b4:9d:e8", "up")
+			output += fmt.Sprintf(ethtoolOutput, "1.11.20.7", "4.20 0x8001778b 1.3346.0")
+			output += uevent
+			return []byte(output), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	}
+
+	When("the uevent has a PCI_SLOT_NAME", func() {
+		It("should return it as the PCI address", func() {
+			respondWithUevent(fmt.Sprintf(ueventOutput, "0000:51:00.1"))
+			info, err := devices.GetPTPDeviceInfo("pciInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.PCIAddress).To(Equal("0000:51:00.1"))
+		})
+	})
+	When("the uevent has padding around the PCI_SLOT_NAME", func() {
+		It("should trim it", func() {
+			respondWithUevent("<uevent>\nDRIVER=ice\n  PCI_SLOT_NAME=0000:51:00.0 \t\n</uevent>\n")
+			info, err := devices.GetPTPDeviceInfo("paddedPCIInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.PCIAddress).To(Equal("0000:51:00.0"))
+		})
+	})
+	When("the device is not a PCI device", func() {
+		It("should leave the PCI address empty", func() {
+			respondWithUevent("<uevent>\nDRIVER=virtio_net\nMODALIAS=virtio:d00000001v00001AF4\n</uevent>\n")
+			info, err := devices.GetPTPDeviceInfo("virtioInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.PCIAddress).To(BeEmpty())
+		})
+	})
+	When("the interface is virtual and has no device", func() {
+		It("should leave the PCI address empty", func() {
+			respondWithUevent("<uevent>\n</uevent>\n")
+			info, err := devices.GetPTPDeviceInfo("virtualInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.PCIAddress).To(BeEmpty())
+			Expect(info.DeviceID).To(Equal("0x1593"))
+		})
+	})
+})
+
+var _ = Describe("GetPTPDeviceInfo link", func() {
+	var ctx clients.ExecContext
+	BeforeEach(func() {
+		var err error
+		ctx, err = clients.NewContainerContext(
+			testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer",
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+	respondWithLink := func(macAddress, operState string) {
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			output := "<date>\n1686916187.0584\n</date>\n"
+			output += "<gnss>\ngnss0\n</gnss>\n"
+			output += "<devID>\n0x1593\n</devID>\n"
+			output += "<vendorID>\n0x8086\n</vendorID>\n"
+			output += linkOutput(macAddress, operState)
+			output += fmt.Sprintf(ethtoolOutput, "1.11.20.7", "4.20 0x8001778b 1.3346.0")
+			output += fmt.Sprintf(ueventOutput, "0000:86:00.0")
+			return []byte(output), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	}
+
+	When("the interface is up", func() {
+		It("should return the MAC address and operstate", func() {
+			respondWithLink("b4:96:91:b4:9d:e8", "up")
+			info, err := devices.GetPTPDeviceInfo("upInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.MACAddress).To(Equal("b4:96:91:b4:9d:e8"))
+			Expect(info.OperState).To(Equal("up"))
+		})
+	})
+	When("the interface is down", func() {
+		It("should still return the device info with the operstate", func() {
+			respondWithLink("b4:96:91:b4:9d:e9", "down")
+			info, err := devices.GetPTPDeviceInfo("downInterface", ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(info.MACAddress).To(Equal("b4:96:91:b4:9d:e9"))
+			Expect(info.OperState).To(Equal("down"))
+			Expect(info.DeviceID).To(Equal("0x1593"))
 		})
 	})
 })
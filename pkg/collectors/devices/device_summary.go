@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// DeviceSummary combines the device, DPLL and GNSS information
+// into a single record so it can be announced as one line.
+type DeviceSummary struct {
+	DeviceInfo *PTPDeviceInfo         `json:"deviceInfo"`
+	DPLL       *DevFilesystemDPLLInfo `json:"dpll,omitempty"`
+	GNSS       *GPSVersions           `json:"gnss,omitempty"`
+}
+
+// GetAnalyserFormat returns the json expected by the analysers
+func (summary *DeviceSummary) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	if summary.DeviceInfo == nil {
+		return []*callbacks.AnalyserFormatType{}, fmt.Errorf("device summary is missing the device info")
+	}
+	devInfo := summary.DeviceInfo
+	data := map[string]any{
+		"timestamp":         time.Now().Add(devInfo.Timeoffset).UTC().Format(time.RFC3339Nano),
+		"fetched_timestamp": devInfo.Timestamp,
+		"vendorID":          devInfo.VendorID,
+		"devID":             devInfo.DeviceID,
+		"gnss":              devInfo.GNSSDev,
+		"firmwareVersion":   devInfo.FirmwareVersion,
+		"driverVersion":     devInfo.DriverVersion,
+	}
+	if summary.DPLL != nil {
+		data["dpll"] = map[string]any{
+			"timestamp": summary.DPLL.Timestamp,
+			"eecstate":  summary.DPLL.EECState,
+			"state":     summary.DPLL.PPSState,
+		}
+	}
+	if summary.GNSS != nil {
+		data["gnssVersions"] = map[string]any{
+			"timestamp":       summary.GNSS.Timestamp,
+			"firmwareVersion": summary.GNSS.FirmwareVersion,
+			"protocolVersion": summary.GNSS.ProtoVersion,
+			"module":          summary.GNSS.Module,
+			"ubxVersion":      summary.GNSS.UBXVersion,
+			"gpsdVersion":     summary.GNSS.GPSDVersion,
+		}
+	}
+	formatted := callbacks.AnalyserFormatType{
+		ID:   DeviceSummaryID,
+		Data: data,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+// getDPLLIfPresent returns the DPLL info for the interface or nil if it does not have the DPLL filesystem
+func getDPLLIfPresent(ctx clients.ExecContext, interfaceName string) (*DevFilesystemDPLLInfo, error) {
+	dpllFSExists, err := IsDPLLFileSystemPresent(ctx, interfaceName)
+	if err != nil {
+		return nil, err
+	}
+	if !dpllFSExists {
+		log.Debugf("DPLL filesystem not present for %s, DPLL omitted", interfaceName)
+		return nil, nil //nolint:nilnil // no DPLL is not an error
+	}
+	dpllInfo, err := GetDevDPLLFilesystemInfo(ctx, interfaceName)
+	if err != nil {
+		return nil, err
+	}
+	return &dpllInfo, nil
+}
+
+// GetDeviceSummary fetches all the parts of the DeviceSummary for an interface.
+// The device info is required, if the DPLL or GNSS information can not be fetched
+// they are left out of the summary and the errors are returned alongside it.
+func GetDeviceSummary(ctx clients.ExecContext, interfaceName string) (DeviceSummary, error) {
+	summary := DeviceSummary{}
+	devInfo, err := GetPTPDeviceInfo(interfaceName, ctx)
+	if err != nil {
+		return summary, err
+	}
+	summary.DeviceInfo = &devInfo
+
+	fetchErrors := make([]error, 0)
+	summary.DPLL, err = getDPLLIfPresent(ctx, interfaceName)
+	if err != nil {
+		fetchErrors = append(fetchErrors, err)
+	}
+
+	gnssVersions, err := GetGPSVersions(ctx)
+	if err != nil {
+		fetchErrors = append(fetchErrors, err)
+	} else {
+		summary.GNSS = &gnssVersions
+	}
+
+	if len(fetchErrors) > 0 {
+		return summary, utils.MakeCompositeError("device summary is incomplete", fetchErrors)
+	}
+	return summary, nil
+}
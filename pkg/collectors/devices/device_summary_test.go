@@ -0,0 +1,86 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+var _ = Describe("DeviceSummary", func() {
+	When("GetAnalyserFormat is called on a complete summary", func() {
+		It("should return a single record containing all the sub fields", func() {
+			summary := devices.DeviceSummary{
+				DeviceInfo: &devices.PTPDeviceInfo{
+					Timestamp:       "2023-06-16T11:49:47.0584Z",
+					VendorID:        "0x8086",
+					DeviceID:        "0x1593",
+					GNSSDev:         "/dev/gnss0",
+					FirmwareVersion: "4.20 0x8001778b 1.3346.0",
+					DriverVersion:   "1.11.20.7",
+				},
+				DPLL: &devices.DevFilesystemDPLLInfo{
+					Timestamp: "2023-06-16T11:49:47.0584Z",
+					EECState:  "2",
+					PPSState:  "3",
+				},
+				GNSS: &devices.GPSVersions{
+					Timestamp:       "2023-07-13T14:58:52.4728Z",
+					FirmwareVersion: "TIM 2.20",
+					ProtoVersion:    "29.20",
+					Module:          "ZED-F9T",
+					UBXVersion:      "3.25.1~dev",
+					GPSDVersion:     "3.25.1~dev (revision release-3.25-109-g1a04cfab8)",
+				},
+			}
+			messages, err := summary.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0].ID).To(Equal("device-summary"))
+
+			data, ok := messages[0].Data.(map[string]any)
+			Expect(ok).To(BeTrue())
+			Expect(data).To(HaveKey("timestamp"))
+			Expect(data).To(HaveKeyWithValue("fetched_timestamp", "2023-06-16T11:49:47.0584Z"))
+			Expect(data).To(HaveKeyWithValue("vendorID", "0x8086"))
+			Expect(data).To(HaveKeyWithValue("devID", "0x1593"))
+			Expect(data).To(HaveKeyWithValue("gnss", "/dev/gnss0"))
+			Expect(data).To(HaveKeyWithValue("firmwareVersion", "4.20 0x8001778b 1.3346.0"))
+			Expect(data).To(HaveKeyWithValue("driverVersion", "1.11.20.7"))
+			Expect(data).To(HaveKeyWithValue("dpll", map[string]any{
+				"timestamp": "2023-06-16T11:49:47.0584Z",
+				"eecstate":  "2",
+				"state":     "3",
+			}))
+			Expect(data).To(HaveKeyWithValue("gnssVersions", map[string]any{
+				"timestamp":       "2023-07-13T14:58:52.4728Z",
+				"firmwareVersion": "TIM 2.20",
+				"protocolVersion": "29.20",
+				"module":          "ZED-F9T",
+				"ubxVersion":      "3.25.1~dev",
+				"gpsdVersion":     "3.25.1~dev (revision release-3.25-109-g1a04cfab8)",
+			}))
+		})
+	})
+	When("GetAnalyserFormat is called on a summary without DPLL or GNSS", func() {
+		It("should leave them out of the record", func() {
+			summary := devices.DeviceSummary{DeviceInfo: &devices.PTPDeviceInfo{VendorID: "0x8086"}}
+			messages, err := summary.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			data, ok := messages[0].Data.(map[string]any)
+			Expect(ok).To(BeTrue())
+			Expect(data).To(HaveKeyWithValue("vendorID", "0x8086"))
+			Expect(data).NotTo(HaveKey("dpll"))
+			Expect(data).NotTo(HaveKey("gnssVersions"))
+		})
+	})
+	When("GetAnalyserFormat is called on a summary without device info", func() {
+		It("should return an error", func() {
+			summary := devices.DeviceSummary{}
+			_, err := summary.GetAnalyserFormat()
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,79 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"sync"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+// DPLLStateChange describes a change in the EEC or PPS DPLL state of an interface
+// between two polls, for the first poll of an interface the previous values are empty
+type DPLLStateChange struct {
+	Timestamp         string `json:"timestamp"`
+	PreviousTimestamp string `json:"previousTimestamp"`
+	Interface         string `json:"interface"`
+	PreviousEECState  string `json:"previousEecstate"`
+	EECState          string `json:"eecstate"`
+	PreviousPPSState  string `json:"previousState"`
+	PPSState          string `json:"state"`
+}
+
+func (change *DPLLStateChange) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   DPLLStateChangeID,
+		Data: change,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+type dpllState struct {
+	timestamp string
+	eecState  string
+	ppsState  string
+}
+
+// DPLLStateTracker remembers the last DPLL states of each interface
+// so that only the transitions between polls need to be reported
+type DPLLStateTracker struct {
+	states map[string]*dpllState
+	lock   sync.Mutex
+}
+
+func NewDPLLStateTracker() *DPLLStateTracker {
+	return &DPLLStateTracker{
+		states: make(map[string]*dpllState),
+	}
+}
+
+// Update records the states of the interface and returns the change since the previous update,
+// the first update of an interface is always returned so the initial states are known.
+// If neither state has changed it returns nil.
+func (tracker *DPLLStateTracker) Update(interfaceName, timestamp, eecState, ppsState string) *DPLLStateChange {
+	tracker.lock.Lock()
+	defer tracker.lock.Unlock()
+	current := &dpllState{timestamp: timestamp, eecState: eecState, ppsState: ppsState}
+	previous, ok := tracker.states[interfaceName]
+	tracker.states[interfaceName] = current
+	if !ok {
+		return &DPLLStateChange{
+			Timestamp: timestamp,
+			Interface: interfaceName,
+			EECState:  eecState,
+			PPSState:  ppsState,
+		}
+	}
+	if previous.eecState == eecState && previous.ppsState == ppsState {
+		return nil
+	}
+	return &DPLLStateChange{
+		Timestamp:         timestamp,
+		PreviousTimestamp: previous.timestamp,
+		Interface:         interfaceName,
+		PreviousEECState:  previous.eecState,
+		EECState:          eecState,
+		PreviousPPSState:  previous.ppsState,
+		PPSState:          ppsState,
+	}
+}
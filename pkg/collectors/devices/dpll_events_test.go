@@ -0,0 +1,61 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+var _ = Describe("DPLLStateTracker", func() {
+	When("the states do not change", func() {
+		It("should only return the initial states", func() {
+			tracker := devices.NewDPLLStateTracker()
+			change := tracker.Update("ens1f0", "t1", "locked", "locked")
+			Expect(change).To(Equal(&devices.DPLLStateChange{
+				Timestamp: "t1",
+				Interface: "ens1f0",
+				EECState:  "locked",
+				PPSState:  "locked",
+			}))
+			Expect(tracker.Update("ens1f0", "t2", "locked", "locked")).To(BeNil())
+			Expect(tracker.Update("ens1f0", "t3", "locked", "locked")).To(BeNil())
+		})
+	})
+
+	When("a state changes", func() {
+		It("should return the previous and new states", func() {
+			tracker := devices.NewDPLLStateTracker()
+			tracker.Update("ens1f0", "t1", "locked", "locked")
+			Expect(tracker.Update("ens1f0", "t2", "locked", "locked")).To(BeNil())
+			Expect(tracker.Update("ens1f0", "t3", "locked", "holdover")).To(Equal(&devices.DPLLStateChange{
+				Timestamp:         "t3",
+				PreviousTimestamp: "t2",
+				Interface:         "ens1f0",
+				PreviousEECState:  "locked",
+				EECState:          "locked",
+				PreviousPPSState:  "locked",
+				PPSState:          "holdover",
+			}))
+			change := tracker.Update("ens1f0", "t4", "holdover", "holdover")
+			Expect(change).NotTo(BeNil())
+			Expect(change.PreviousEECState).To(Equal("locked"))
+			Expect(change.EECState).To(Equal("holdover"))
+		})
+	})
+
+	When("more than one interface is tracked", func() {
+		It("should keep the states of each interface separately", func() {
+			tracker := devices.NewDPLLStateTracker()
+			Expect(tracker.Update("ens1f0", "t1", "locked", "locked")).NotTo(BeNil())
+			Expect(tracker.Update("ens2f0", "t1", "locked", "holdover")).NotTo(BeNil())
+			Expect(tracker.Update("ens1f0", "t2", "locked", "locked")).To(BeNil())
+			change := tracker.Update("ens2f0", "t2", "locked", "locked")
+			Expect(change).NotTo(BeNil())
+			Expect(change.Interface).To(Equal("ens2f0"))
+			Expect(change.PreviousPPSState).To(Equal("holdover"))
+		})
+	})
+})
@@ -13,48 +13,64 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
-const (
-	unitConversionFactor = 100
-)
-
+// DevFilesystemDPLLInfo holds the DPLL state read from the filesystem,
+// PPSOffset and PPSOffsetSmoothed are in the hundredths of a nanosecond reported
+// by the driver and PPSOffsetNS is the offset in nanoseconds.
+// PPSOffsetSmoothed is only set by the collector when smoothing is enabled
 type DevFilesystemDPLLInfo struct {
-	Timestamp string  `fetcherKey:"date"          json:"timestamp"`
-	EECState  string  `fetcherKey:"dpll_0_state"  json:"eecstate"`
-	PPSState  string  `fetcherKey:"dpll_1_state"  json:"state"`
-	PPSOffset float64 `fetcherKey:"dpll_1_offset" json:"terror"`
+	PPSOffsetSmoothed *float64          `json:"terrorSmoothed,omitempty"`
+	Raw               map[string]string `fetcherKey:"raw"               json:"raw,omitempty"`
+	Timestamp         string            `fetcherKey:"date"              json:"timestamp"`
+	EECState          string            `fetcherKey:"dpll_0_state"      json:"eecstate"`
+	PPSState          string            `fetcherKey:"dpll_1_state"      json:"state"`
+	PPSOffsetRaw      string            `fetcherKey:"dpll_1_offset_raw" json:"terrorRaw"`
+	PPSOffset         float64           `fetcherKey:"dpll_1_offset"     json:"terror"`
+	PPSOffsetNS       float64           `fetcherKey:"dpll_1_offset_ns"  json:"terrorNs"`
 }
 
 // AnalyserJSON returns the json expected by the analysers
 func (dpllInfo *DevFilesystemDPLLInfo) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
 	formatted := callbacks.AnalyserFormatType{
-		ID: "dpll/time-error",
+		ID: DPLLTimeErrorID,
 		Data: map[string]any{
 			"timestamp": dpllInfo.Timestamp,
 			"eecstate":  dpllInfo.EECState,
 			"state":     dpllInfo.PPSState,
-			"terror":    dpllInfo.PPSOffset / unitConversionFactor,
+			"terror":    utils.CentinanosecondsToNanoseconds(dpllInfo.PPSOffset),
 		},
 	}
 	return []*callbacks.AnalyserFormatType{&formatted}, nil
 }
 
 var (
-	dpllFSFetcher map[string]*fetcher.Fetcher
+	dpllFSFetcher *fetcherCache
 )
 
 func init() {
-	dpllFSFetcher = make(map[string]*fetcher.Fetcher)
+	dpllFSFetcher = newFetcherCache()
 }
 
+// postProcessDPLLFilesystem converts the offset into a number while keeping the raw value,
+// if the attribute is empty the offset is left as its zero value rather than failing the fetch.
 func postProcessDPLLFilesystem(result map[string]string) (map[string]any, error) {
 	processedResult := make(map[string]any)
-	offset, err := strconv.ParseFloat(result["dpll_1_offset"], 32)
+	rawOffset := result["dpll_1_offset"]
+	processedResult["dpll_1_offset_raw"] = rawOffset
+	if rawOffset == "" {
+		log.Debug("dpll_1_offset is empty")
+		processedResult["dpll_1_offset"] = float64(0)
+		processedResult["dpll_1_offset_ns"] = float64(0)
+		return processedResult, nil
+	}
+	offset, err := strconv.ParseFloat(rawOffset, 32)
 	if err != nil {
-		return processedResult, fmt.Errorf("failed converting dpll_1_offset %w to an int", err)
+		return processedResult, fmt.Errorf("failed converting dpll_1_offset %w to a float", err)
 	}
 	processedResult["dpll_1_offset"] = offset
+	processedResult["dpll_1_offset_ns"] = utils.CentinanosecondsToNanoseconds(offset)
 	return processedResult, nil
 }
 
@@ -85,7 +101,7 @@ func BuildFilesystemDPLLInfoFetcher(interfaceName string) error { //nolint:dupl
 		log.Errorf("failed to create fetcher for dpll: %s", err.Error())
 		return fmt.Errorf("failed to create fetcher for dpll: %w", err)
 	}
-	dpllFSFetcher[interfaceName] = fetcherInst
+	dpllFSFetcher.set(interfaceName, fetcherInst)
 	fetcherInst.SetPostProcessor(postProcessDPLLFilesystem)
 	return nil
 }
@@ -93,13 +109,13 @@ func BuildFilesystemDPLLInfoFetcher(interfaceName string) error { //nolint:dupl
 // GetDevDPLLFilesystemInfo returns the device DPLL info for an interface.
 func GetDevDPLLFilesystemInfo(ctx clients.ExecContext, interfaceName string) (DevFilesystemDPLLInfo, error) {
 	dpllInfo := DevFilesystemDPLLInfo{}
-	fetcherInst, fetchedInstanceOk := dpllFSFetcher[interfaceName]
+	fetcherInst, fetchedInstanceOk := dpllFSFetcher.get(interfaceName)
 	if !fetchedInstanceOk {
 		err := BuildFilesystemDPLLInfoFetcher(interfaceName)
 		if err != nil {
 			return dpllInfo, err
 		}
-		fetcherInst, fetchedInstanceOk = dpllFSFetcher[interfaceName]
+		fetcherInst, fetchedInstanceOk = dpllFSFetcher.get(interfaceName)
 		if !fetchedInstanceOk {
 			return dpllInfo, errors.New("failed to create fetcher for DPLLInfo")
 		}
@@ -62,6 +62,74 @@ var _ = Describe("NewContainerContext", func() {
 			Expect(info.EECState).To(Equal(eecState))
 			Expect(info.PPSState).To(Equal(pssState))
 			Expect(info.PPSOffset).To(Equal(offset))
+			Expect(info.PPSOffsetNS).To(Equal(-0.34))
+		})
+	})
+	When("called GetDevDPLLInfo with different offsets", func() {
+		It("should parse the offset and keep the raw value", func() {
+			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+			expectedInput += "echo '<dpll_0_state>';cat /sys/class/net/aFakeInterface/device/dpll_0_state;echo '</dpll_0_state>';"
+			expectedInput += "echo '<dpll_1_state>';cat /sys/class/net/aFakeInterface/device/dpll_1_state;echo '</dpll_1_state>';"
+			expectedInput += "echo '<dpll_1_offset>';cat /sys/class/net/aFakeInterface/device/dpll_1_offset;echo '</dpll_1_offset>';"
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			for raw, expected := range map[string]float64{
+				"1234": 1234,
+				"-567": -567,
+				"":     0,
+			} {
+				expectedOutput := "<date>\n1686916187.0584\n</date>\n"
+				expectedOutput += "<dpll_0_state>\n2\n</dpll_0_state>\n"
+				expectedOutput += "<dpll_1_state>\n3\n</dpll_1_state>\n"
+				expectedOutput += fmt.Sprintf("<dpll_1_offset>\n%s\n</dpll_1_offset>\n", raw)
+				response[expectedInput] = []byte(expectedOutput)
+
+				info, err := devices.GetDevDPLLFilesystemInfo(ctx, "aFakeInterface")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(info.PPSOffset).To(Equal(expected))
+				Expect(info.PPSOffsetRaw).To(Equal(raw))
+			}
+		})
+	})
+	When("called GetDevDPLLInfo with an offset which is not a number", func() {
+		It("should return an error", func() {
+			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+			expectedInput += "echo '<dpll_0_state>';cat /sys/class/net/aFakeInterface/device/dpll_0_state;echo '</dpll_0_state>';"
+			expectedInput += "echo '<dpll_1_state>';cat /sys/class/net/aFakeInterface/device/dpll_1_state;echo '</dpll_1_state>';"
+			expectedInput += "echo '<dpll_1_offset>';cat /sys/class/net/aFakeInterface/device/dpll_1_offset;echo '</dpll_1_offset>';"
+
+			expectedOutput := "<date>\n1686916187.0584\n</date>\n"
+			expectedOutput += "<dpll_0_state>\n2\n</dpll_0_state>\n"
+			expectedOutput += "<dpll_1_state>\n3\n</dpll_1_state>\n"
+			expectedOutput += "<dpll_1_offset>\nnot-a-number\n</dpll_1_offset>\n"
+			response[expectedInput] = []byte(expectedOutput)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+			_, err = devices.GetDevDPLLFilesystemInfo(ctx, "aFakeInterface")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	When("GetAnalyserFormat is called on a DevFilesystemDPLLInfo", func() {
+		It("should return a dpll/time-error message with a numeric offset", func() {
+			info := devices.DevFilesystemDPLLInfo{
+				Timestamp: "2023-06-16T11:49:47.0584Z",
+				EECState:  "2",
+				PPSState:  "10",
+				PPSOffset: -34,
+			}
+			messages, err := info.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0].ID).To(Equal("dpll/time-error"))
+			Expect(messages[0].Data).To(Equal(map[string]any{
+				"timestamp": "2023-06-16T11:49:47.0584Z",
+				"eecstate":  "2",
+				"state":     "10",
+				"terror":    float64(-0.34),
+			}))
 		})
 	})
 })
@@ -26,15 +26,16 @@ var states = map[string]string{
 }
 
 type DevNetlinkDPLLInfo struct {
-	Timestamp string `fetcherKey:"date" json:"timestamp"`
-	EECState  string `fetcherKey:"eec"  json:"eecstate"`
-	PPSState  string `fetcherKey:"pps"  json:"state"`
+	Raw       map[string]string `fetcherKey:"raw"  json:"raw,omitempty"`
+	Timestamp string            `fetcherKey:"date" json:"timestamp"`
+	EECState  string            `fetcherKey:"eec"  json:"eecstate"`
+	PPSState  string            `fetcherKey:"pps"  json:"state"`
 }
 
 // AnalyserJSON returns the json expected by the analysers
 func (dpllInfo *DevNetlinkDPLLInfo) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
 	formatted := callbacks.AnalyserFormatType{
-		ID: "dpll/states",
+		ID: DPLLStatesID,
 		Data: map[string]any{
 			"timestamp": dpllInfo.Timestamp,
 			"eecstate":  dpllInfo.EECState,
@@ -69,13 +70,13 @@ type NetlinkEntry struct {
 //   'type': 'pps'}]
 
 var (
-	dpllNetlinkFetcher map[int64]*fetcher.Fetcher
-	dpllClockIDFetcher map[string]*fetcher.Fetcher
+	dpllNetlinkFetcher *fetcherCache
+	dpllClockIDFetcher *fetcherCache
 )
 
 func init() {
-	dpllNetlinkFetcher = make(map[int64]*fetcher.Fetcher)
-	dpllClockIDFetcher = make(map[string]*fetcher.Fetcher)
+	dpllNetlinkFetcher = newFetcherCache()
+	dpllClockIDFetcher = newFetcherCache()
 }
 
 func buildPostProcessDPLLNetlink(clockID int64) fetcher.PostProcessFuncType {
@@ -104,6 +105,11 @@ func buildPostProcessDPLLNetlink(clockID int64) fetcher.PostProcessFuncType {
 	}
 }
 
+// netlinkFetcherKey returns the key of the fetcher for the DPLL with clockID
+func netlinkFetcherKey(clockID int64) string {
+	return strconv.FormatInt(clockID, 10)
+}
+
 // BuildDPLLNetlinkInfoFetcher popluates the fetcher required for
 // collecting the DPLLInfo
 func BuildDPLLNetlinkInfoFetcher(clockID int64) error { //nolint:dupl // Further dedup risks be too abstract or fragile
@@ -121,21 +127,21 @@ func BuildDPLLNetlinkInfoFetcher(clockID int64) error { //nolint:dupl // Further
 		log.Errorf("failed to create fetcher for dpll netlink: %s", err.Error())
 		return fmt.Errorf("failed to create fetcher for dpll netlink: %w", err)
 	}
-	dpllNetlinkFetcher[clockID] = fetcherInst
 	fetcherInst.SetPostProcessor(buildPostProcessDPLLNetlink(clockID))
+	dpllNetlinkFetcher.set(netlinkFetcherKey(clockID), fetcherInst)
 	return nil
 }
 
 // GetDevDPLLInfo returns the device DPLL info for an interface.
 func GetDevDPLLNetlinkInfo(ctx clients.ExecContext, clockID int64) (DevNetlinkDPLLInfo, error) {
 	dpllInfo := DevNetlinkDPLLInfo{}
-	fetcherInst, fetchedInstanceOk := dpllNetlinkFetcher[clockID]
+	fetcherInst, fetchedInstanceOk := dpllNetlinkFetcher.get(netlinkFetcherKey(clockID))
 	if !fetchedInstanceOk {
 		err := BuildDPLLNetlinkInfoFetcher(clockID)
 		if err != nil {
 			return dpllInfo, err
 		}
-		fetcherInst, fetchedInstanceOk = dpllNetlinkFetcher[clockID]
+		fetcherInst, fetchedInstanceOk = dpllNetlinkFetcher.get(netlinkFetcherKey(clockID))
 		if !fetchedInstanceOk {
 			return dpllInfo, errors.New("failed to create fetcher for DPLLInfo using netlink interface")
 		}
@@ -168,7 +174,7 @@ func BuildClockIDFetcher(interfaceName string) error {
 		return fmt.Errorf("failed to create fetcher for dpll clock ID: %w", err)
 	}
 	fetcherInst.SetPostProcessor(postProcessDPLLNetlinkClockID)
-	dpllClockIDFetcher[interfaceName] = fetcherInst
+	dpllClockIDFetcher.set(interfaceName, fetcherInst)
 	return nil
 }
 
@@ -189,13 +195,13 @@ type NetlinkClockID struct {
 
 func GetClockID(ctx clients.ExecContext, interfaceName string) (NetlinkClockID, error) {
 	clockID := NetlinkClockID{}
-	fetcherInst, fetchedInstanceOk := dpllClockIDFetcher[interfaceName]
+	fetcherInst, fetchedInstanceOk := dpllClockIDFetcher.get(interfaceName)
 	if !fetchedInstanceOk {
 		err := BuildClockIDFetcher(interfaceName)
 		if err != nil {
 			return clockID, err
 		}
-		fetcherInst, fetchedInstanceOk = dpllClockIDFetcher[interfaceName]
+		fetcherInst, fetchedInstanceOk = dpllClockIDFetcher.get(interfaceName)
 		if !fetchedInstanceOk {
 			return clockID, errors.New("failed to create fetcher for DPLLInfo using netlink interface")
 		}
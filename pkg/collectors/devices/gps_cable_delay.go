@@ -0,0 +1,84 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+// ubxCableDelayKey is the configuration item holding the antenna cable delay in ns
+const ubxCableDelayKey = "CFG-TP-ANT_CABLEDELAY"
+
+// ErrGPSCableDelayUnreadable is reported when the receiver did not return the antenna cable delay
+var ErrGPSCableDelayUnreadable = fmt.Errorf("unable to read %s from the GNSS receiver", ubxCableDelayKey)
+
+// GPSCableDelay is the antenna cable delay configured in the GNSS receiver
+type GPSCableDelay struct {
+	Timestamp  string `fetcherKey:"date"       json:"timestamp"`
+	CableDelay int    `fetcherKey:"cableDelay" json:"cableDelayNs"`
+}
+
+var (
+	gpsCableDelayFetcher *fetcher.Fetcher
+
+	// UBX-CFG-VALGET:
+	//  version 1 layer 0 position 0
+	//   layers (ram)
+	//     item CFG-TP-ANT_CABLEDELAY/0x30050001 val 50
+	ubxCableDelayRegex = regexp.MustCompile(
+		`item ` + ubxCableDelayKey + `/0x[0-9a-fA-F]+ val (-?\d+)`,
+	)
+)
+
+func init() {
+	fetcherInst, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{dateCmd},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "UBXCfgValGet",
+				Command: "ubxtool -g " + ubxCableDelayKey + " -P " + ubxProtocolVersion,
+				Trim:    true,
+			},
+		},
+	)
+	if err != nil {
+		log.Errorf("failed to create fetcher for the GNSS cable delay: %s", err.Error())
+		panic(fmt.Errorf("failed to create fetcher for the GNSS cable delay: %w", err))
+	}
+	fetcherInst.SetPostProcessor(processGPSCableDelay)
+	gpsCableDelayFetcher = fetcherInst
+}
+
+func processGPSCableDelay(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+	match := ubxCableDelayRegex.FindStringSubmatch(result["UBXCfgValGet"])
+	if len(match) == 0 {
+		return processedResult, ErrGPSCableDelayUnreadable
+	}
+	// The regex only matches digits so this only fails if the value overflows
+	cableDelay, err := strconv.Atoi(match[1])
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to parse %s %w", ubxCableDelayKey, err)
+	}
+	processedResult["cableDelay"] = cableDelay
+	return processedResult, nil
+}
+
+// GetGPSCableDelay returns the antenna cable delay configured in the GNSS receiver,
+// if it could not be read the error wraps ErrGPSCableDelayUnreadable
+func GetGPSCableDelay(ctx clients.ExecContext) (GPSCableDelay, error) {
+	cableDelay := GPSCableDelay{}
+	err := gpsCableDelayFetcher.Fetch(ctx, &cableDelay)
+	if err != nil {
+		log.Debugf("failed to fetch the GNSS cable delay %s", err.Error())
+		return cableDelay, fmt.Errorf("failed to fetch the GNSS cable delay %w", err)
+	}
+	return cableDelay, nil
+}
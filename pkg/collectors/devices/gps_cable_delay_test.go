@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var _ = Describe("GetGPSCableDelay", func() {
+	var clientset *clients.Clientset
+	var output []byte
+	BeforeEach(func() {
+		clientset = testutils.GetMockedClientSet(testPod)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			return output, []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	When("the receiver returns the cable delay", func() {
+		It("should return the value in ns", func() {
+			output = []byte(strings.Join([]string{
+				"<date>",
+				"1686916187.0584",
+				"</date>",
+				"<UBXCfgValGet>",
+				"UBX-CFG-VALGET:",
+				" version 1 layer 0 position 0",
+				"  layers (ram)",
+				"    item CFG-TP-ANT_CABLEDELAY/0x30050001 val 50",
+				"</UBXCfgValGet>",
+			}, "\n"))
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			cableDelay, err := devices.GetGPSCableDelay(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cableDelay.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(cableDelay.CableDelay).To(Equal(50))
+		})
+	})
+
+	When("the receiver does not return the cable delay", func() {
+		It("should return an error", func() {
+			output = []byte(strings.Join([]string{
+				"<date>",
+				"1686916187.0584",
+				"</date>",
+				"<UBXCfgValGet>",
+				"</UBXCfgValGet>",
+			}, "\n"))
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = devices.GetGPSCableDelay(ctx)
+			Expect(errors.Is(err, devices.ErrGPSCableDelayUnreadable)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+)
+
+const (
+	GPSFixLost           = "fix-lost"
+	GPSFixAcquired       = "fix-acquired"
+	GPSFixChanged        = "fix-changed"
+	AntennaStatusChanged = "antenna-status-changed"
+
+	// gpsFix values below this do not provide a position (no fix or dead reckoning only)
+	minUsableGPSFix = 2
+)
+
+// GPSEvent describes a change in the GNSS fix or an antenna status between two polls
+type GPSEvent struct {
+	BlockID           *int   `json:"blockId,omitempty"`
+	Timestamp         string `json:"timestamp"`
+	PreviousTimestamp string `json:"previousTimestamp"`
+	Event             string `json:"event"`
+	Previous          int    `json:"previous"`
+	Current           int    `json:"current"`
+}
+
+func (event *GPSEvent) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   GNSSEventID,
+		Data: event,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+// GPSStateTracker remembers the last fix and antenna statuses
+// so that transitions between polls can be reported
+type GPSStateTracker struct {
+	navStatus *GPSNavStatus
+	antennas  map[int]*GPSAntennaDetails
+}
+
+func NewGPSStateTracker() *GPSStateTracker {
+	return &GPSStateTracker{
+		antennas: make(map[int]*GPSAntennaDetails),
+	}
+}
+
+func getFixEvent(previous, current int) string {
+	switch {
+	case previous >= minUsableGPSFix && current < minUsableGPSFix:
+		return GPSFixLost
+	case previous < minUsableGPSFix && current >= minUsableGPSFix:
+		return GPSFixAcquired
+	default:
+		return GPSFixChanged
+	}
+}
+
+// Update records the state in gpsNav and returns an event for each change since the
+// previous update. Sections which failed to parse are ignored and keep their last state.
+func (tracker *GPSStateTracker) Update(gpsNav *GPSDetails) []*GPSEvent {
+	events := make([]*GPSEvent, 0)
+	if gpsNav.HasSection(UBXNavStatus) {
+		current := gpsNav.NavStatus
+		if tracker.navStatus != nil && tracker.navStatus.GPSFix != current.GPSFix {
+			events = append(events, &GPSEvent{
+				Timestamp:         current.Timestamp,
+				PreviousTimestamp: tracker.navStatus.Timestamp,
+				Event:             getFixEvent(tracker.navStatus.GPSFix, current.GPSFix),
+				Previous:          tracker.navStatus.GPSFix,
+				Current:           current.GPSFix,
+			})
+		}
+		tracker.navStatus = &current
+	}
+	if gpsNav.HasSection(UBXMonRF) {
+		for _, antenna := range gpsNav.AntennaDetails {
+			previous, ok := tracker.antennas[antenna.BlockID]
+			if ok && previous.Status != antenna.Status {
+				blockID := antenna.BlockID
+				events = append(events, &GPSEvent{
+					BlockID:           &blockID,
+					Timestamp:         antenna.Timestamp,
+					PreviousTimestamp: previous.Timestamp,
+					Event:             AntennaStatusChanged,
+					Previous:          previous.Status,
+					Current:           antenna.Status,
+				})
+			}
+			tracker.antennas[antenna.BlockID] = antenna
+		}
+	}
+	return events
+}
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+func gpsDetailsWithFix(timestamp string, fix, antennaStatus int) *devices.GPSDetails {
+	return &devices.GPSDetails{
+		NavStatus: devices.GPSNavStatus{Timestamp: timestamp, GPSFix: fix},
+		AntennaDetails: []*devices.GPSAntennaDetails{
+			{Timestamp: timestamp, BlockID: 0, Status: antennaStatus},
+		},
+	}
+}
+
+var _ = Describe("GPSStateTracker", func() {
+	When("the fix goes 3 -> 0 -> 3", func() {
+		It("should emit fix lost then fix acquired with timestamps", func() {
+			tracker := devices.NewGPSStateTracker()
+			Expect(tracker.Update(gpsDetailsWithFix("t1", 3, 2))).To(BeEmpty())
+
+			events := tracker.Update(gpsDetailsWithFix("t2", 0, 2))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Event).To(Equal(devices.GPSFixLost))
+			Expect(events[0].Previous).To(Equal(3))
+			Expect(events[0].Current).To(Equal(0))
+			Expect(events[0].PreviousTimestamp).To(Equal("t1"))
+			Expect(events[0].Timestamp).To(Equal("t2"))
+			Expect(events[0].BlockID).To(BeNil())
+
+			Expect(tracker.Update(gpsDetailsWithFix("t3", 0, 2))).To(BeEmpty())
+
+			events = tracker.Update(gpsDetailsWithFix("t4", 3, 2))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Event).To(Equal(devices.GPSFixAcquired))
+			Expect(events[0].PreviousTimestamp).To(Equal("t3"))
+			Expect(events[0].Timestamp).To(Equal("t4"))
+		})
+	})
+
+	When("the fix changes between usable values", func() {
+		It("should emit fix changed", func() {
+			tracker := devices.NewGPSStateTracker()
+			tracker.Update(gpsDetailsWithFix("t1", 3, 2))
+			events := tracker.Update(gpsDetailsWithFix("t2", 2, 2))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Event).To(Equal(devices.GPSFixChanged))
+		})
+	})
+
+	When("an antenna status changes", func() {
+		It("should emit an event for the block", func() {
+			tracker := devices.NewGPSStateTracker()
+			tracker.Update(gpsDetailsWithFix("t1", 3, 2))
+			events := tracker.Update(gpsDetailsWithFix("t2", 3, 4))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].Event).To(Equal(devices.AntennaStatusChanged))
+			Expect(*events[0].BlockID).To(Equal(0))
+			Expect(events[0].Previous).To(Equal(2))
+			Expect(events[0].Current).To(Equal(4))
+		})
+	})
+
+	When("a section failed to parse", func() {
+		It("should keep the last state for that section", func() {
+			tracker := devices.NewGPSStateTracker()
+			tracker.Update(gpsDetailsWithFix("t1", 3, 2))
+			missing := gpsDetailsWithFix("t2", 0, 2)
+			missing.SectionErrors = map[string]string{devices.UBXNavStatus: "missing"}
+			Expect(tracker.Update(missing)).To(BeEmpty())
+			events := tracker.Update(gpsDetailsWithFix("t3", 0, 2))
+			Expect(events).To(HaveLen(1))
+			Expect(events[0].PreviousTimestamp).To(Equal("t1"))
+		})
+	})
+
+	When("an event is formatted for the analysers", func() {
+		It("should use the gnss/event id", func() {
+			event := devices.GPSEvent{Event: devices.GPSFixLost}
+			formatted, err := event.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(formatted[0].ID).To(Equal("gnss/event"))
+		})
+	})
+})
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	GPSSourceUBXTool = "ubxtool"
+	GPSSourceGPSD    = "gpsd"
+
+	gpsdPort = 2947
+	// gpsd sends VERSION, DEVICES and WATCH before the first TPV and SKY reports
+	gpsdReportLines = 12
+	gpsdTimeout     = 3
+)
+
+// GPSSources are the values accepted to select where the GNSS details are read from
+var GPSSources = []string{GPSSourceUBXTool, GPSSourceGPSD}
+
+// GPSSatellites holds the satellite counts which are only reported by gpsd
+type GPSSatellites struct {
+	Timestamp string `json:"timestamp"`
+	Visible   int    `json:"visible"`
+	Used      int    `json:"used"`
+}
+
+// gpsdTPV is the subset of the gpsd Time-Position-Velocity report which is used
+type gpsdTPV struct {
+	Time string  `json:"time"`
+	Mode int     `json:"mode"`
+	Ept  float64 `json:"ept"`
+}
+
+type gpsdSatellite struct {
+	Used bool `json:"used"`
+}
+
+// gpsdSKY is the subset of the gpsd sky view report which is used
+type gpsdSKY struct {
+	Time       string          `json:"time"`
+	Satellites []gpsdSatellite `json:"satellites"`
+}
+
+type gpsdReport struct {
+	Class string `json:"class"`
+}
+
+var (
+	gpsdFetcher         *fetcher.Fetcher
+	gpsdBuiltinsFetcher *fetcher.Fetcher
+
+	// gpsdCoreutils are the commands other than bash which gpsdCommand needs
+	gpsdCoreutils = []string{"timeout", "head"}
+)
+
+// ErrGPSDBashNotFound is returned by NewGPSDNavGetter when bash is not on the path of the container
+var ErrGPSDBashNotFound = errors.New("bash was not found in the container, it is needed to open the gpsd socket")
+
+// Open the gpsd socket from inside the container, enable JSON watch mode
+// and read enough lines to receive the first TPV and SKY reports
+var (
+	gpsdCommand = fmt.Sprintf(
+		`timeout %d bash -c 'exec 3<>/dev/tcp/127.0.0.1/%d; `+
+			`echo "?WATCH={\"enable\":true,\"json\":true}" >&3; head -n %d <&3'`,
+		gpsdTimeout, gpsdPort, gpsdReportLines,
+	)
+	// gpsdBuiltinsCommand does the same as gpsdCommand using only bash builtins
+	// for containers which do not have timeout or head
+	gpsdBuiltinsCommand = fmt.Sprintf(
+		`bash -c 'exec 3<>/dev/tcp/127.0.0.1/%[2]d; `+
+			`echo "?WATCH={\"enable\":true,\"json\":true}" >&3; n=0; `+
+			`while [ $n -lt %[3]d ] && [ $SECONDS -lt %[1]d ] && IFS= read -r -t %[1]d line <&3; `+
+			`do echo "$line"; n=$((n+1)); done'`,
+		gpsdTimeout, gpsdPort, gpsdReportLines,
+	)
+)
+
+func newGPSDFetcher(command string) *fetcher.Fetcher {
+	fetcherInst := fetcher.NewFetcher()
+	fetcherInst.SetPostProcessor(processGPSD)
+	err := fetcherInst.AddNewCommand("GPSD", command, true)
+	if err != nil {
+		panic(fmt.Errorf("failed to setup gpsd fetcher %w", err))
+	}
+	return fetcherInst
+}
+
+func init() {
+	gpsdFetcher = newGPSDFetcher(gpsdCommand)
+	gpsdBuiltinsFetcher = newGPSDFetcher(gpsdBuiltinsCommand)
+}
+
+// gpsdModeToFix converts the gpsd mode (0 unknown, 1 no fix, 2 2D, 3 3D) to the UBX gpsFix values
+func gpsdModeToFix(mode int) int {
+	if mode < minUsableGPSFix {
+		return 0
+	}
+	return mode
+}
+
+// ParseGPSDOutput maps the latest TPV and SKY reports in the gpsd JSON output onto GPSDetails.
+// gpsd does not report the antenna status so the MON-RF section is always recorded as missing.
+func ParseGPSDOutput(output string) (GPSDetails, error) {
+	var tpv *gpsdTPV
+	var sky *gpsdSKY
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		report := gpsdReport{}
+		if err := json.Unmarshal([]byte(line), &report); err != nil {
+			log.Debugf("skipping gpsd line which is not JSON: %s", line)
+			continue
+		}
+		var err error
+		switch report.Class {
+		case "TPV":
+			tpv = &gpsdTPV{}
+			err = json.Unmarshal([]byte(line), tpv)
+		case "SKY":
+			sky = &gpsdSKY{}
+			err = json.Unmarshal([]byte(line), sky)
+		}
+		if err != nil {
+			return GPSDetails{}, fmt.Errorf("failed to decode gpsd %s report %w", report.Class, err)
+		}
+	}
+	if tpv == nil {
+		return GPSDetails{}, errors.New("no TPV report was received from gpsd")
+	}
+
+	gpsDetails := GPSDetails{
+		NavStatus: GPSNavStatus{
+			Timestamp: tpv.Time,
+			GPSFix:    gpsdModeToFix(tpv.Mode),
+		},
+		NavClock: GPSNavClock{
+			Timestamp: tpv.Time,
+			TimeAcc:   int(math.Round(utils.SecondsToNanoseconds(tpv.Ept))),
+		},
+		AntennaDetails: make([]*GPSAntennaDetails, 0),
+		SectionErrors:  map[string]string{UBXMonRF: "antenna status is not reported by gpsd"},
+	}
+	if sky != nil {
+		used := 0
+		for _, satellite := range sky.Satellites {
+			if satellite.Used {
+				used++
+			}
+		}
+		gpsDetails.Satellites = &GPSSatellites{
+			Timestamp: sky.Time,
+			Visible:   len(sky.Satellites),
+			Used:      used,
+		}
+	}
+	return gpsDetails, nil
+}
+
+func processGPSD(result map[string]string) (map[string]any, error) {
+	gpsDetails, err := ParseGPSDOutput(result["GPSD"])
+	if err != nil {
+		return nil, err
+	}
+	return map[string]any{
+		"navStatus":      gpsDetails.NavStatus,
+		"navClock":       gpsDetails.NavClock,
+		"antennaDetails": gpsDetails.AntennaDetails,
+		"sectionErrors":  gpsDetails.SectionErrors,
+		"satellites":     gpsDetails.Satellites,
+	}, nil
+}
+
+// GetGPSNavFromGPSD returns the GNSS details read from the gpsd socket
+func GetGPSNavFromGPSD(ctx clients.ExecContext) (GPSDetails, error) {
+	return fetchGPSNavFromGPSD(ctx, gpsdFetcher)
+}
+
+// GetGPSNavFromGPSDBuiltins returns the GNSS details read from the gpsd socket
+// without using timeout or head so only bash is needed in the container
+func GetGPSNavFromGPSDBuiltins(ctx clients.ExecContext) (GPSDetails, error) {
+	return fetchGPSNavFromGPSD(ctx, gpsdBuiltinsFetcher)
+}
+
+func fetchGPSNavFromGPSD(ctx clients.ExecContext, fetcherInst *fetcher.Fetcher) (GPSDetails, error) {
+	gpsNav := GPSDetails{}
+	err := fetcherInst.Fetch(ctx, &gpsNav)
+	if err != nil {
+		log.Debugf("failed to fetch gpsNav from gpsd %s", err.Error())
+		return gpsNav, fmt.Errorf("failed to fetch gpsNav from gpsd %w", err)
+	}
+	return gpsNav, nil
+}
+
+// FindMissingCommands returns the names which are not on the path of the container
+func FindMissingCommands(ctx clients.ExecContext, names ...string) ([]string, error) {
+	script := fmt.Sprintf(
+		`for name in %s; do command -v "$name" >/dev/null || echo "$name"; done`,
+		strings.Join(names, " "),
+	)
+	stdout, _, err := ctx.ExecCommand([]string{clients.Shell(), "-c", script})
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for %s: %w", strings.Join(names, ", "), err)
+	}
+	return strings.Fields(stdout), nil
+}
+
+// NewGPSDNavGetter returns the getter used to read gpsd in the container. When useCoreutils is set
+// and timeout or head are missing it falls back to reading with bash builtins, bash itself is required
+func NewGPSDNavGetter(
+	ctx clients.ExecContext,
+	useCoreutils bool,
+) (func(clients.ExecContext) (GPSDetails, error), error) {
+	names := []string{"bash"}
+	if useCoreutils {
+		names = append(names, gpsdCoreutils...)
+	}
+	missing, err := FindMissingCommands(ctx, names...)
+	if err != nil {
+		log.Warningf("could not check for the commands used to read gpsd: %s", err.Error())
+	}
+	for _, name := range missing {
+		if name == "bash" {
+			return nil, ErrGPSDBashNotFound
+		}
+	}
+	if !useCoreutils {
+		return GetGPSNavFromGPSDBuiltins, nil
+	}
+	if len(missing) > 0 {
+		log.Warningf("%s not found in the container, reading gpsd with bash builtins", strings.Join(missing, ", "))
+		return GetGPSNavFromGPSDBuiltins, nil
+	}
+	return GetGPSNavFromGPSD, nil
+}
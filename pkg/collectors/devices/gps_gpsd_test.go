@@ -0,0 +1,159 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var gpsdFrames = []string{
+	`{"class":"VERSION","release":"3.25","rev":"3.25","proto_major":3,"proto_minor":15}`,
+	`{"class":"DEVICES","devices":[{"class":"DEVICE","path":"/dev/gnss0","driver":"u-blox","activated":"2023-06-16T11:49:46.000Z"}]}`,
+	`{"class":"WATCH","enable":true,"json":true,"nmea":false,"raw":0,"scaled":false,"timing":false,"split24":false,"pps":false}`,
+	`{"class":"TPV","device":"/dev/gnss0","mode":3,"time":"2023-06-16T11:49:47.000Z","ept":0.000000005,"lat":53.3,"lon":-6.2}`,
+	`{"class":"SKY","device":"/dev/gnss0","time":"2023-06-16T11:49:47.000Z","satellites":[` +
+		`{"PRN":2,"el":40.0,"az":95.0,"ss":43.0,"used":true},` +
+		`{"PRN":5,"el":12.0,"az":300.0,"ss":21.0,"used":false},` +
+		`{"PRN":7,"el":66.0,"az":180.0,"ss":47.0,"used":true}]}`,
+}
+
+var _ = Describe("ParseGPSDOutput", func() {
+	When("given TPV and SKY reports", func() {
+		It("should map them onto GPSDetails", func() {
+			details, err := devices.ParseGPSDOutput(strings.Join(gpsdFrames, "\n"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(details.NavStatus.Timestamp).To(Equal("2023-06-16T11:49:47.000Z"))
+			Expect(details.NavStatus.GPSFix).To(Equal(3))
+			Expect(details.NavClock.TimeAcc).To(Equal(5))
+			Expect(details.Satellites).NotTo(BeNil())
+			Expect(details.Satellites.Visible).To(Equal(3))
+			Expect(details.Satellites.Used).To(Equal(2))
+			Expect(details.HasSection(devices.UBXMonRF)).To(BeFalse())
+			Expect(details.HasSection(devices.UBXNavClock)).To(BeTrue())
+		})
+	})
+	When("gpsd reports no fix", func() {
+		It("should report a gpsFix of 0", func() {
+			details, err := devices.ParseGPSDOutput(`{"class":"TPV","mode":1,"time":"2023-06-16T11:49:47.000Z"}`)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(details.NavStatus.GPSFix).To(Equal(0))
+			Expect(details.Satellites).To(BeNil())
+		})
+	})
+	When("the output has a partial line", func() {
+		It("should skip it and use the complete reports", func() {
+			output := strings.Join(append(gpsdFrames, `{"class":"TPV","mode":`), "\n")
+			details, err := devices.ParseGPSDOutput(output)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(details.NavStatus.GPSFix).To(Equal(3))
+		})
+	})
+	When("no TPV report is received", func() {
+		It("should return an error", func() {
+			_, err := devices.ParseGPSDOutput(strings.Join(gpsdFrames[:3], "\n"))
+			Expect(err).To(MatchError(ContainSubstring("no TPV report")))
+		})
+	})
+})
+
+var _ = Describe("GetGPSNavFromGPSD", func() {
+	It("should read the reports through the container", func() {
+		clientset := testutils.GetMockedClientSet(testPod)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			output := "<GPSD>\n" + strings.Join(gpsdFrames, "\n") + "\n</GPSD>\n"
+			return []byte(output), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+
+		ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+		Expect(err).NotTo(HaveOccurred())
+		details, err := devices.GetGPSNavFromGPSD(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(details.NavStatus.GPSFix).To(Equal(3))
+		Expect(details.Satellites.Used).To(Equal(2))
+		Expect(details.SectionErrors).To(HaveKey(devices.UBXMonRF))
+	})
+})
+
+var _ = Describe("NewGPSDNavGetter", func() {
+	var ctx clients.ExecContext
+	var missing string
+	var checked []string
+	var script string
+	BeforeEach(func() {
+		missing = ""
+		checked = nil
+		script = ""
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			command := url.Query()["command"]
+			if strings.Contains(strings.Join(command, " "), "command -v") {
+				checked = command
+				return []byte(missing), []byte(""), nil
+			}
+			stdin, err := io.ReadAll(options.Stdin)
+			Expect(err).NotTo(HaveOccurred())
+			script = string(stdin)
+			output := "<GPSD>\n" + strings.Join(gpsdFrames, "\n") + "\n</GPSD>\n"
+			return []byte(output), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+		var err error
+		ctx, err = clients.NewContainerContext(
+			testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer",
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+	getNav := func(useCoreutils bool) devices.GPSDetails {
+		getGPSNav, err := devices.NewGPSDNavGetter(ctx, useCoreutils)
+		Expect(err).NotTo(HaveOccurred())
+		details, err := getGPSNav(ctx)
+		Expect(err).NotTo(HaveOccurred())
+		return details
+	}
+
+	When("bash and the coreutils are available", func() {
+		It("should read gpsd with timeout and head", func() {
+			details := getNav(true)
+			Expect(details.NavStatus.GPSFix).To(Equal(3))
+			Expect(checked[len(checked)-1]).To(ContainSubstring("bash timeout head"))
+			Expect(script).To(ContainSubstring("timeout 3 bash"))
+			Expect(script).To(ContainSubstring("head -n 12"))
+		})
+	})
+	When("a coreutil is missing", func() {
+		It("should fall back to reading gpsd with bash builtins", func() {
+			missing = "timeout\n"
+			details := getNav(true)
+			Expect(details.NavStatus.GPSFix).To(Equal(3))
+			Expect(script).NotTo(ContainSubstring("timeout "))
+			Expect(script).NotTo(ContainSubstring("head "))
+			Expect(script).To(ContainSubstring("read -r -t 3"))
+		})
+	})
+	When("coreutils are not to be used", func() {
+		It("should only check for bash and read gpsd with bash builtins", func() {
+			details := getNav(false)
+			Expect(details.NavStatus.GPSFix).To(Equal(3))
+			Expect(checked[len(checked)-1]).NotTo(ContainSubstring("timeout"))
+			Expect(script).To(ContainSubstring("read -r -t 3"))
+		})
+	})
+	When("bash is missing", func() {
+		It("should return ErrGPSDBashNotFound", func() {
+			missing = "bash\nhead\n"
+			_, err := devices.NewGPSDNavGetter(ctx, true)
+			Expect(err).To(MatchError(devices.ErrGPSDBashNotFound))
+		})
+	})
+})
@@ -3,6 +3,7 @@
 package devices
 
 import (
+	"errors"
 	"fmt"
 	"regexp"
 	"strconv"
@@ -16,10 +17,18 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
+// GPSDetails holds the values parsed from the ubxtool output, if a section
+// failed to parse its error is held in SectionErrors keyed by the UBX message
 type GPSDetails struct {
-	NavStatus      GPSNavStatus         `fetcherKey:"navStatus"      json:"navStatus"`
-	AntennaDetails []*GPSAntennaDetails `fetcherKey:"antennaDetails" json:"antennaDetails"`
-	NavClock       GPSNavClock          `fetcherKey:"navClock"       json:"navClock"`
+	Raw            map[string]string      `fetcherKey:"raw"            json:"raw,omitempty"`
+	NavStatus      GPSNavStatus           `fetcherKey:"navStatus"      json:"navStatus"`
+	AntennaDetails []*GPSAntennaDetails   `fetcherKey:"antennaDetails" json:"antennaDetails"`
+	NavClock       GPSNavClock            `fetcherKey:"navClock"       json:"navClock"`
+	SectionErrors  map[string]string      `fetcherKey:"sectionErrors"  json:"sectionErrors,omitempty"`
+	Satellites     *GPSSatellites         `fetcherKey:"satellites"     json:"satellites,omitempty"`
+	RFBlocks       int                    `fetcherKey:"rfBlocks"       json:"rfBlocks"`
+	ExtraMessages  map[string]*UBXMessage `fetcherKey:"extraMessages"  json:"extraMessages,omitempty"`
+	TimePulse      *GPSTimePulse          `fetcherKey:"timePulse"      json:"timePulse,omitempty"`
 }
 
 type GPSNavStatus struct {
@@ -28,12 +37,25 @@ type GPSNavStatus struct {
 	GPSFix    int    `json:"GPSFix"`
 }
 
+// GPSNavClock holds the UBX NAV-CLOCK accuracy estimates,
+// TimeAcc is in nanoseconds and FreqAcc in picoseconds per second
 type GPSNavClock struct {
 	Timestamp string `json:"timestamp"`
 	TimeAcc   int    `json:"timeAcc"`
 	FreqAcc   int    `json:"freqAcc"`
 }
 
+// GPSTimePulse holds the UBX TIM-TP values for the next time pulse,
+// QErr is the quantization error of the pulse in picoseconds and QErrNS is it in nanoseconds
+type GPSTimePulse struct {
+	Timestamp string  `json:"timestamp"`
+	TowMS     int64   `json:"towMS"`
+	TowSubMS  int64   `json:"towSubMS"`
+	QErr      int     `json:"qErr"`
+	QErrNS    float64 `json:"qErrNs"`
+	Week      int     `json:"week"`
+}
+
 type GPSAntennaDetails struct {
 	Timestamp string `json:"timestamp"`
 	BlockID   int    `json:"blockId"`
@@ -41,28 +63,67 @@ type GPSAntennaDetails struct {
 	Power     int    `json:"power"`
 }
 
+// HasSection returns true if the UBX message was parsed
+func (gpsNav *GPSDetails) HasSection(section string) bool {
+	_, failed := gpsNav.SectionErrors[section]
+	return !failed
+}
+
+// CheckRFBlocks returns an error if the number of MON-RF blocks reported differs
+// from expected, an expected value of 0 or less disables the check
+func (gpsNav *GPSDetails) CheckRFBlocks(expected int) error {
+	if expected <= 0 || !gpsNav.HasSection(UBXMonRF) {
+		return nil
+	}
+	if gpsNav.RFBlocks != expected {
+		return fmt.Errorf(
+			"expected %d UBX MON-RF blocks but %d were reported, an antenna may be disconnected",
+			expected,
+			gpsNav.RFBlocks,
+		)
+	}
+	return nil
+}
+
 func (gpsNav *GPSDetails) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
 	messages := []*callbacks.AnalyserFormatType{}
-	messages = append(messages, &callbacks.AnalyserFormatType{
-		ID: "gnss/time-error",
-		Data: map[string]any{
-			"timestamp": gpsNav.NavClock.Timestamp,
-			"terror":    gpsNav.NavClock.TimeAcc,
-			"ferror":    gpsNav.NavClock.FreqAcc,
-			"state":     gpsNav.NavStatus.GPSFix,
-			"flags":     gpsNav.NavStatus.Flags,
-		},
-	})
+	// The time error is made from both NAV messages so is only sent when both were parsed
+	if gpsNav.HasSection(UBXNavClock) && gpsNav.HasSection(UBXNavStatus) {
+		messages = append(messages, &callbacks.AnalyserFormatType{
+			ID: GNSSTimeErrorID,
+			Data: map[string]any{
+				"timestamp": gpsNav.NavClock.Timestamp,
+				"terror":    gpsNav.NavClock.TimeAcc,
+				"ferror":    gpsNav.NavClock.FreqAcc,
+				"state":     gpsNav.NavStatus.GPSFix,
+				"flags":     gpsNav.NavStatus.Flags,
+			},
+		})
+	}
 
 	for _, ant := range gpsNav.AntennaDetails {
 		messages = append(messages, &callbacks.AnalyserFormatType{
-			ID:   "gnss/rf-mon",
+			ID:   GNSSRFMonID,
 			Data: ant,
 		})
 	}
+	if gpsNav.TimePulse != nil && gpsNav.HasSection(UBXTimTP) {
+		messages = append(messages, &callbacks.AnalyserFormatType{
+			ID:   GNSSTimePulseID,
+			Data: gpsNav.TimePulse,
+		})
+	}
+	messages = append(messages, gpsNav.getExtraMessagesAnalyserFormat()...)
 	return messages, nil
 }
 
+const (
+	UBXNavStatus = "NAV-STATUS"
+	UBXNavClock  = "NAV-CLOCK"
+	UBXMonRF     = "MON-RF"
+	UBXTimTP     = "TIM-TP"
+)
+
 var (
 	timeStampPattern  = `(\d+.\d+)`
 	ubxNavStatusRegex = regexp.MustCompile(
@@ -83,6 +144,15 @@ var (
 		//   iTOW 474605000 clkB 61594 clkD 56 tAcc 5 fAcc 164
 	)
 
+	ubxTimTPRegex = regexp.MustCompile(
+		timeStampPattern +
+			`\nUBX-TIM-TP:\n\s+towMS (\d+) towSubMS (\d+) qErr (-?\d+) week (\d+)`,
+		// 1686916187.0585
+		// UBX-TIM-TP:
+		//   towMS 474606000 towSubMS 0 qErr -1234 week 2266
+		//   flags 0x1b refInfo 0x0
+	)
+
 	ubxAntFullBlockRegex = regexp.MustCompile(
 		timeStampPattern +
 			`\nUBX-MON-RF:\n` +
@@ -113,15 +183,10 @@ var (
 )
 
 func init() {
-	gpsFetcher = fetcher.NewFetcher()
-	gpsFetcher.SetPostProcessor(processUBX)
-	err := gpsFetcher.AddNewCommand(
-		"GPS",
-		"ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF -P 29.20",
-		true,
-	)
+	var err error
+	gpsFetcher, err = newGPSFetcher(nil)
 	if err != nil {
-		panic(fmt.Errorf("failed to setup GPS fetcher %w", err))
+		panic(err)
 	}
 }
 
@@ -183,6 +248,44 @@ func processUBXNavClock(result map[string]string) (map[string]any, error) {
 	return processedResult, nil
 }
 
+// processUBXTimTP parses the time pulse quantization error from the ubxtool output
+func processUBXTimTP(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+	match := ubxTimTPRegex.FindStringSubmatch(result["GPS"])
+	if len(match) == 0 {
+		return processedResult, fmt.Errorf("unable to parse UBX TIM-TP from %s", result["GPS"])
+	}
+	timestamp, err := utils.ParseTimestamp(match[1])
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to parse timePulseTimestamp %w", err)
+	}
+	towMS, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to parse towMS %w", err)
+	}
+	towSubMS, err := strconv.ParseInt(match[3], 10, 64)
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to parse towSubMS %w", err)
+	}
+	qErr, err := strconv.Atoi(match[4])
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to parse qErr %w", err)
+	}
+	week, err := strconv.Atoi(match[5])
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to parse week %w", err)
+	}
+	processedResult["timePulse"] = &GPSTimePulse{
+		Timestamp: timestamp.Format(time.RFC3339Nano),
+		TowMS:     towMS,
+		TowSubMS:  towSubMS,
+		QErr:      qErr,
+		QErrNS:    utils.PicosecondsToNanoseconds(float64(qErr)),
+		Week:      week,
+	}
+	return processedResult, nil
+}
+
 func processUBXMonRF(result map[string]string) (map[string]any, error) { //nolint:funlen // allow for a slightly long function
 	processedResult := make(map[string]any)
 
@@ -228,53 +331,79 @@ func processUBXMonRF(result map[string]string) (map[string]any, error) { //nolin
 	}
 
 	processedResult["antennaDetails"] = antennaDetails
+	processedResult["rfBlocks"] = nBlocks
 	return processedResult, nil
 }
 
-func processUBX(result map[string]string) (map[string]any, error) { //nolint:funlen // allow slightly long function
+type ubxSectionProcessor struct {
+	section string
+	process func(map[string]string) (map[string]any, error)
+}
+
+var ubxSectionProcessors = []ubxSectionProcessor{
+	{section: UBXNavStatus, process: processUBXNavStatus},
+	{section: UBXNavClock, process: processUBXNavClock},
+	{section: UBXMonRF, process: processUBXMonRF},
+	{section: UBXTimTP, process: processUBXTimTP},
+}
+
+// processUBX parses each section of the ubxtool output. Sections which fail to parse
+// are recorded in sectionErrors so the ones which did parse can still be reported,
+// an error is only returned if none of the sections could be parsed
+func processUBX(result map[string]string) (map[string]any, error) {
 	processedResult := make(map[string]any)
+	sectionErrors := make(map[string]string)
 	errors := make([]error, 0)
 
-	processedUBXNavStatus, err := processUBXNavStatus(result)
-	if err != nil {
-		log.Debugf("processUBXNav Failed: %s", err.Error())
-		errors = append(errors, err)
-	}
-	for key, value := range processedUBXNavStatus {
-		processedResult[key] = value
-	}
-	processedUBXNavClock, err := processUBXNavClock(result)
-	if err != nil {
-		log.Debugf("processUBXNav Failed: %s", err.Error())
-		errors = append(errors, err)
-	}
-	for key, value := range processedUBXNavClock {
-		processedResult[key] = value
-	}
-
-	processedUBXMonRF, err := processUBXMonRF(result)
-	if err != nil {
-		log.Debugf("processUBXMon Failed: %s", err.Error())
-		errors = append(errors, err)
-	}
-	for key, value := range processedUBXMonRF {
-		processedResult[key] = value
+	for _, processor := range ubxSectionProcessors {
+		processedSection, err := processor.process(result)
+		if err != nil {
+			log.Debugf("processUBX %s Failed: %s", processor.section, err.Error())
+			errors = append(errors, err)
+			sectionErrors[processor.section] = err.Error()
+			continue
+		}
+		for key, value := range processedSection {
+			processedResult[key] = value
+		}
 	}
 
-	if len(errors) > 0 {
+	if len(errors) == len(ubxSectionProcessors) {
 		return processedResult,
 			fmt.Errorf(
 				"the following errors occurred fetching the GNSS values: %w",
 				utils.MakeCompositeError("", errors),
 			)
 	}
+	if len(sectionErrors) > 0 {
+		processedResult["sectionErrors"] = sectionErrors
+	}
 	return processedResult, nil
 }
 
+// ErrUBXToolNotFound is returned by CheckUBXTool when ubxtool is not on the path of the container
+var ErrUBXToolNotFound = errors.New("ubxtool was not found in the container")
+
+// CheckUBXTool returns ErrUBXToolNotFound if ubxtool can not be run in the container
+func CheckUBXTool(ctx clients.ExecContext) error {
+	_, _, err := ctx.ExecCommand([]string{clients.Shell(), "-c", "command -v ubxtool"})
+	if err == nil {
+		return nil
+	}
+	if _, exited := clients.GetExitCode(err); exited {
+		return ErrUBXToolNotFound
+	}
+	return fmt.Errorf("failed to check for ubxtool: %w", err)
+}
+
 // GetGPSNav returns GPSNav of the host
 func GetGPSNav(ctx clients.ExecContext) (GPSDetails, error) {
+	return fetchGPSNav(ctx, gpsFetcher)
+}
+
+func fetchGPSNav(ctx clients.ExecContext, ubxFetcher *fetcher.Fetcher) (GPSDetails, error) {
 	gpsNav := GPSDetails{}
-	err := gpsFetcher.Fetch(ctx, &gpsNav)
+	err := ubxFetcher.Fetch(ctx, &gpsNav)
 	if err != nil {
 		log.Debugf("failed to fetch gpsNav %s", err.Error())
 		return gpsNav, fmt.Errorf("failed to fetch gpsNav %w", err)
@@ -0,0 +1,203 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const ubxProtocolVersion = "29.20"
+
+// UBXMessageParser decodes the lines ubxtool printed for a UBX message,
+// the lines do not include the timestamp or the UBX-<message>: header
+type UBXMessageParser func(block string) (any, error)
+
+// UBXMessage holds an extra UBX message requested by the user. Parsed is set when
+// a parser is registered for the message and succeeded otherwise Raw holds the block.
+type UBXMessage struct {
+	Timestamp string `json:"timestamp"`
+	Raw       string `json:"raw,omitempty"`
+	Parsed    any    `json:"parsed,omitempty"`
+}
+
+var (
+	ubxDefaultMessages  = []string{UBXNavStatus, UBXNavClock, UBXMonRF, UBXTimTP}
+	ubxMessageNameRegex = regexp.MustCompile(`^[A-Z0-9]+-[A-Z0-9]+$`)
+	ubxMessageParsers   = make(map[string]UBXMessageParser)
+	// ubxMessageParsersLock guards ubxMessageParsers as parsers can be registered while collectors are polling
+	ubxMessageParsersLock sync.RWMutex
+)
+
+// RegisterUBXMessageParser sets the parser used for an extra UBX message e.g. TIM-TP
+func RegisterUBXMessageParser(message string, parser UBXMessageParser) {
+	ubxMessageParsersLock.Lock()
+	defer ubxMessageParsersLock.Unlock()
+	ubxMessageParsers[message] = parser
+}
+
+// UnregisterUBXMessageParser removes the parser for an extra UBX message so its raw block is output
+func UnregisterUBXMessageParser(message string) {
+	ubxMessageParsersLock.Lock()
+	defer ubxMessageParsersLock.Unlock()
+	delete(ubxMessageParsers, message)
+}
+
+func getUBXMessageParser(message string) (UBXMessageParser, bool) {
+	ubxMessageParsersLock.RLock()
+	defer ubxMessageParsersLock.RUnlock()
+	parser, ok := ubxMessageParsers[message]
+	return parser, ok
+}
+
+// UBXCommand returns the ubxtool command which polls the default messages and extraMessages,
+// extra messages which are already polled are ignored
+func UBXCommand(extraMessages []string) (string, error) {
+	messages := append([]string{}, ubxDefaultMessages...)
+	for _, message := range extraMessages {
+		if !ubxMessageNameRegex.MatchString(message) {
+			return "", fmt.Errorf("%q is not a UBX message name such as TIM-TP", message)
+		}
+		if !isInList(message, messages) {
+			messages = append(messages, message)
+		}
+	}
+	command := "ubxtool -t"
+	for _, message := range messages {
+		command += " -p " + message
+	}
+	return command + " -P " + ubxProtocolVersion, nil
+}
+
+func isInList(value string, values []string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// processUBXMessage finds the block for message in the ubxtool output
+// and decodes it if a parser has been registered
+func processUBXMessage(message, output string) (*UBXMessage, error) {
+	blockRegex := regexp.MustCompile(
+		timeStampPattern + `\nUBX-` + regexp.QuoteMeta(message) + `:\n((?:[ \t]+.*(?:\n|$))*)`,
+	)
+	match := blockRegex.FindStringSubmatch(output)
+	if len(match) == 0 {
+		return nil, fmt.Errorf("UBX %s not found in output", message)
+	}
+	timestamp, err := utils.ParseTimestamp(match[1])
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s timestamp %w", message, err)
+	}
+	ubxMessage := &UBXMessage{
+		Timestamp: timestamp.Format(time.RFC3339Nano),
+		Raw:       strings.TrimRight(match[2], "\n"),
+	}
+	parser, ok := getUBXMessageParser(message)
+	if !ok {
+		return ubxMessage, nil
+	}
+	parsed, err := parser(ubxMessage.Raw)
+	if err != nil {
+		return ubxMessage, fmt.Errorf("failed to parse UBX %s %w", message, err)
+	}
+	ubxMessage.Raw = ""
+	ubxMessage.Parsed = parsed
+	return ubxMessage, nil
+}
+
+// newUBXProcessor returns a post processor which also handles extraMessages,
+// a failure to parse an extra message is recorded in sectionErrors
+func newUBXProcessor(extraMessages []string) func(map[string]string) (map[string]any, error) {
+	return func(result map[string]string) (map[string]any, error) {
+		processedResult, err := processUBX(result)
+		if err != nil || len(extraMessages) == 0 {
+			return processedResult, err
+		}
+		sectionErrors, ok := processedResult["sectionErrors"].(map[string]string)
+		if !ok {
+			sectionErrors = make(map[string]string)
+		}
+		messages := make(map[string]*UBXMessage)
+		for _, message := range extraMessages {
+			if isInList(message, ubxDefaultMessages) {
+				continue
+			}
+			ubxMessage, msgErr := processUBXMessage(message, result["GPS"])
+			if ubxMessage != nil {
+				messages[message] = ubxMessage
+			}
+			if msgErr != nil {
+				log.Debugf("processUBX %s Failed: %s", message, msgErr.Error())
+				sectionErrors[message] = msgErr.Error()
+			}
+		}
+		if len(messages) > 0 {
+			processedResult["extraMessages"] = messages
+		}
+		if len(sectionErrors) > 0 {
+			processedResult["sectionErrors"] = sectionErrors
+		}
+		return processedResult, nil
+	}
+}
+
+func newGPSFetcher(extraMessages []string) (*fetcher.Fetcher, error) {
+	command, err := UBXCommand(extraMessages)
+	if err != nil {
+		return nil, err
+	}
+	ubxFetcher := fetcher.NewFetcher()
+	ubxFetcher.SetPostProcessor(newUBXProcessor(extraMessages))
+	err = ubxFetcher.AddNewCommand("GPS", command, true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup GPS fetcher %w", err)
+	}
+	return ubxFetcher, nil
+}
+
+// NewGPSNavGetter returns a function like GetGPSNav which also polls extraMessages
+func NewGPSNavGetter(extraMessages []string) (func(clients.ExecContext) (GPSDetails, error), error) {
+	ubxFetcher, err := newGPSFetcher(extraMessages)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx clients.ExecContext) (GPSDetails, error) {
+		return fetchGPSNav(ctx, ubxFetcher)
+	}, nil
+}
+
+func (gpsNav *GPSDetails) getExtraMessagesAnalyserFormat() []*callbacks.AnalyserFormatType {
+	names := make([]string, 0, len(gpsNav.ExtraMessages))
+	for name := range gpsNav.ExtraMessages {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	messages := make([]*callbacks.AnalyserFormatType, 0, len(names))
+	for _, name := range names {
+		messages = append(messages, &callbacks.AnalyserFormatType{
+			ID: GNSSUBXMessageID,
+			Data: map[string]any{
+				"message":   name,
+				"timestamp": gpsNav.ExtraMessages[name].Timestamp,
+				"raw":       gpsNav.ExtraMessages[name].Raw,
+				"parsed":    gpsNav.ExtraMessages[name].Parsed,
+			},
+		})
+	}
+	return messages
+}
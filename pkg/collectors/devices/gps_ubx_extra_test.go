@@ -0,0 +1,154 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"errors"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var _ = Describe("UBXCommand", func() {
+	When("no extra messages are given", func() {
+		It("should only poll the default messages", func() {
+			command, err := devices.UBXCommand(nil)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command).To(Equal("ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF -p TIM-TP -P 29.20"))
+		})
+	})
+	When("extra messages are given", func() {
+		It("should add a -p flag for each new message", func() {
+			command, err := devices.UBXCommand([]string{"NAV-TIMEGPS", "NAV-CLOCK", "NAV-TIMEUTC"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(command).To(Equal(
+				"ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF -p TIM-TP -p NAV-TIMEGPS -p NAV-TIMEUTC -P 29.20",
+			))
+		})
+	})
+	When("an extra message is not a UBX message name", func() {
+		It("should return an error", func() {
+			_, err := devices.UBXCommand([]string{"NAV-TIMEGPS; reboot"})
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("NewGPSNavGetter", func() {
+	var clientset *clients.Clientset
+	var response map[string][]byte
+	BeforeEach(func() { //nolint:dupl // this is test setup code
+		clientset = testutils.GetMockedClientSet(testPod)
+		response = make(map[string][]byte)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmd += string(line)
+			}
+			return response[cmd], []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	extraMessages := []string{"NAV-TIMEGPS", "NAV-TIMEUTC", "NAV-SAT"}
+	expectedInput := "echo '<GPS>';" +
+		"ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF -p TIM-TP -p NAV-TIMEGPS -p NAV-TIMEUTC -p NAV-SAT -P 29.20;" +
+		"echo '</GPS>';"
+	output := strings.Join([]string{
+		"<GPS>",
+		"1686916187.0584",
+		"UBX-NAV-STATUS:",
+		"  iTOW 474605000 gpsFix 3 flags 0xdd fixStat 0x0 flags2 0x8",
+		"  ttff 25030, msss 4294967295",
+		"",
+		"1686916187.0585",
+		"UBX-NAV-TIMEGPS:",
+		"  iTOW 474605000 fTOW 123 week 2266 leapS 18 valid x7 tAcc 21",
+		"",
+		"1686916187.0586",
+		"UBX-NAV-CLOCK:",
+		"  iTOW 474605000 clkB -61594 clkD -56 tAcc 5 fAcc 164",
+		"",
+		"1686916187.0587",
+		"UBX-NAV-TIMEUTC:",
+		"  iTOW 474605000 tAcc 21 nano -72 Time 2023/6/16 11:49:47",
+		"  valid x37 utcStd 3",
+		"</GPS>",
+	}, "\n")
+
+	When("extra messages are requested", func() {
+		BeforeEach(func() {
+			devices.RegisterUBXMessageParser("NAV-TIMEGPS", func(block string) (any, error) {
+				if !strings.Contains(block, "leapS 18") {
+					return nil, errors.New("leapS not found")
+				}
+				return map[string]any{"leapS": 18}, nil
+			})
+			DeferCleanup(devices.UnregisterUBXMessageParser, "NAV-TIMEGPS")
+			response[expectedInput] = []byte(output)
+		})
+
+		It("should use the registered parser", func() {
+			getGPSNav, err := devices.NewGPSNavGetter(extraMessages)
+			Expect(err).NotTo(HaveOccurred())
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := getGPSNav(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.NavClock.TimeAcc).To(Equal(5))
+			Expect(gpsInfo.ExtraMessages).To(HaveKey("NAV-TIMEGPS"))
+			Expect(gpsInfo.ExtraMessages["NAV-TIMEGPS"].Timestamp).To(Equal("2023-06-16T11:49:47.0585Z"))
+			Expect(gpsInfo.ExtraMessages["NAV-TIMEGPS"].Parsed).To(Equal(map[string]any{"leapS": 18}))
+			Expect(gpsInfo.ExtraMessages["NAV-TIMEGPS"].Raw).To(BeEmpty())
+		})
+		It("should keep the raw block of messages without a parser", func() {
+			getGPSNav, err := devices.NewGPSNavGetter(extraMessages)
+			Expect(err).NotTo(HaveOccurred())
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := getGPSNav(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.ExtraMessages).To(HaveKey("NAV-TIMEUTC"))
+			Expect(gpsInfo.ExtraMessages["NAV-TIMEUTC"].Raw).To(Equal(
+				"  iTOW 474605000 tAcc 21 nano -72 Time 2023/6/16 11:49:47\n  valid x37 utcStd 3",
+			))
+			Expect(gpsInfo.ExtraMessages["NAV-TIMEUTC"].Parsed).To(BeNil())
+		})
+		It("should record the messages which were not in the output", func() {
+			getGPSNav, err := devices.NewGPSNavGetter(extraMessages)
+			Expect(err).NotTo(HaveOccurred())
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := getGPSNav(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.ExtraMessages).NotTo(HaveKey("NAV-SAT"))
+			Expect(gpsInfo.SectionErrors).To(HaveKey("NAV-SAT"))
+			Expect(gpsInfo.SectionErrors).To(HaveKey(devices.UBXMonRF))
+			Expect(gpsInfo.SectionErrors).To(HaveKey(devices.UBXTimTP))
+
+			messages, err := gpsInfo.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			ids := make([]string, 0)
+			for _, message := range messages {
+				ids = append(ids, message.ID)
+			}
+			Expect(ids).To(Equal([]string{"gnss/time-error", "gnss/ubx-message", "gnss/ubx-message"}))
+		})
+	})
+})
@@ -37,10 +37,167 @@ var _ = Describe("GetGPSNav", func() {
 		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
 	})
 
+	expectedInput := "echo '<GPS>';ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF -p TIM-TP -P 29.20;echo '</GPS>';"
+	navStatusLines := []string{
+		"1686916187.0584",
+		"UBX-NAV-STATUS:",
+		"  iTOW 474605000 gpsFix 3 flags 0xdd fixStat 0x0 flags2 0x8",
+		"  ttff 25030, msss 4294967295",
+		"",
+	}
+	navClockLines := []string{
+		"1686916187.0586",
+		"UBX-NAV-CLOCK:",
+		"  iTOW 474605000 clkB -61594 clkD -56 tAcc 5 fAcc 164",
+	}
+	timTPLines := []string{
+		"1686916187.0585",
+		"UBX-TIM-TP:",
+		"  towMS 474606000 towSubMS 2147483648 qErr -1234 week 2266",
+		"  flags 0x1b refInfo 0x0",
+		"",
+	}
+	monRFLines := []string{
+		"1686916187.0584",
+		"UBX-MON-RF:",
+		" version 0 nBlocks 1 reserved1 0 0",
+		"   blockId 0 flags x0 antStatus 2 antPower 1 postStatus 0 reserved2 0 0 0 0",
+		"    noisePerMS 82 agcCnt 6318 jamInd 3 ofsI 15 magI 154 ofsQ 2 magQ 145",
+		"    reserved3 0 0 0",
+		"",
+	}
+	buildOutput := func(sections ...[]string) []byte {
+		lines := []string{"<GPS>"}
+		for _, section := range sections {
+			lines = append(lines, section...)
+		}
+		lines = append(lines, "</GPS>")
+		return []byte(strings.Join(lines, "\n"))
+	}
+
+	When("the MON-RF section is missing", func() {
+		It("should return the NAV sections and record the missing section", func() {
+			response[expectedInput] = buildOutput(navStatusLines, timTPLines, navClockLines)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := devices.GetGPSNav(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.NavClock.TimeAcc).To(Equal(5))
+			Expect(gpsInfo.NavStatus.GPSFix).To(Equal(3))
+			Expect(gpsInfo.AntennaDetails).To(BeEmpty())
+			Expect(gpsInfo.SectionErrors).To(HaveKey(devices.UBXMonRF))
+			Expect(gpsInfo.SectionErrors).To(HaveLen(1))
+
+			messages, err := gpsInfo.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(2))
+			Expect(messages[0].ID).To(Equal("gnss/time-error"))
+			Expect(messages[1].ID).To(Equal("gnss/time-pulse"))
+		})
+	})
+
+	When("the NAV-CLOCK section is missing", func() {
+		It("should return the other sections without a time error message", func() {
+			response[expectedInput] = buildOutput(monRFLines, navStatusLines)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := devices.GetGPSNav(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.SectionErrors).To(HaveKey(devices.UBXNavClock))
+			Expect(gpsInfo.AntennaDetails).To(HaveLen(1))
+
+			messages, err := gpsInfo.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(1))
+			Expect(messages[0].ID).To(Equal("gnss/rf-mon"))
+		})
+	})
+
+	When("the TIM-TP section is present", func() {
+		It("should parse the signed quantization error", func() {
+			response[expectedInput] = buildOutput(timTPLines, navStatusLines, navClockLines)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := devices.GetGPSNav(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.HasSection(devices.UBXTimTP)).To(BeTrue())
+			Expect(gpsInfo.TimePulse).To(Equal(&devices.GPSTimePulse{
+				Timestamp: "2023-06-16T11:49:47.0585Z",
+				TowMS:     474606000,
+				TowSubMS:  2147483648,
+				QErr:      -1234,
+				QErrNS:    -1.234,
+				Week:      2266,
+			}))
+
+			messages, err := gpsInfo.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(2))
+			Expect(messages[1].ID).To(Equal("gnss/time-pulse"))
+			Expect(messages[1].Data).To(Equal(gpsInfo.TimePulse))
+		})
+	})
+
+	When("the TIM-TP section is missing", func() {
+		It("should not output a time pulse message", func() {
+			response[expectedInput] = buildOutput(navStatusLines, navClockLines, monRFLines)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := devices.GetGPSNav(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.TimePulse).To(BeNil())
+			Expect(gpsInfo.SectionErrors).To(HaveKey(devices.UBXTimTP))
+
+			messages, err := gpsInfo.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			for _, message := range messages {
+				Expect(message.ID).NotTo(Equal("gnss/time-pulse"))
+			}
+		})
+	})
+
+	When("an expected number of MON-RF blocks is given", func() {
+		It("should only return an error when the count differs", func() {
+			response[expectedInput] = buildOutput(monRFLines, navStatusLines, navClockLines)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			gpsInfo, err := devices.GetGPSNav(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(gpsInfo.RFBlocks).To(Equal(1))
+			Expect(gpsInfo.CheckRFBlocks(1)).To(Succeed())
+			Expect(gpsInfo.CheckRFBlocks(0)).To(Succeed())
+			Expect(gpsInfo.CheckRFBlocks(2)).To(MatchError(ContainSubstring("expected 2 UBX MON-RF blocks but 1")))
+		})
+		It("should not check the count when MON-RF was not parsed", func() {
+			gpsInfo := devices.GPSDetails{SectionErrors: map[string]string{devices.UBXMonRF: "missing"}}
+			Expect(gpsInfo.CheckRFBlocks(2)).To(Succeed())
+		})
+	})
+
+	When("no sections can be parsed", func() {
+		It("should return an error", func() {
+			response[expectedInput] = buildOutput([]string{"garbage"})
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = devices.GetGPSNav(ctx)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	When("called GetGPSNav", func() {
 		It("should return a valid GPSNav", func() {
-			expectedInput := "echo '<GPS>';ubxtool -t -p NAV-STATUS -p NAV-CLOCK -p MON-RF -P 29.20;echo '</GPS>';"
-
 			expectedOutput := strings.Join([]string{
 				"<GPS>",
 				"1686916187.0584",
@@ -58,6 +215,11 @@ var _ = Describe("GetGPSNav", func() {
 				"  iTOW 474605000 gpsFix 3 flags 0xdd fixStat 0x0 flags2 0x8",
 				"  ttff 25030, msss 4294967295",
 				"",
+				"1686916187.0585",
+				"UBX-TIM-TP:",
+				"  towMS 474606000 towSubMS 0 qErr 845 week 2266",
+				"  flags 0x1b refInfo 0x0",
+				"",
 				"1686916187.0586",
 				"UBX-NAV-CLOCK:",
 				"  iTOW 474605000 clkB -61594 clkD -56 tAcc 5 fAcc 164",
@@ -86,6 +248,9 @@ var _ = Describe("GetGPSNav", func() {
 			Expect(gpsInfo.AntennaDetails[1].BlockID).To(Equal(1))
 			Expect(gpsInfo.AntennaDetails[1].Status).To(Equal(2))
 			Expect(gpsInfo.AntennaDetails[1].Power).To(Equal(1))
+			Expect(gpsInfo.TimePulse.QErr).To(Equal(845))
+			Expect(gpsInfo.SectionErrors).To(BeEmpty())
+			Expect(gpsInfo.RFBlocks).To(Equal(2))
 
 		})
 	})
@@ -29,7 +29,7 @@ type GPSVersions struct {
 func (gpsVer *GPSVersions) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
 	messages := []*callbacks.AnalyserFormatType{
 		{
-			ID:   "gnss/time-error",
+			ID:   GNSSTimeErrorID,
 			Data: gpsVer,
 		},
 	}
@@ -0,0 +1,248 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+const (
+	iceDebugFSDir = "/sys/kernel/debug/ice"
+	// written instead of the CGU status when the ice debugfs directory does not exist
+	iceDebugFSMissing = "debugfs-not-mounted"
+	// cguSection is the key of the errors for the CGU status in ICEPinConfig.SectionErrors
+	cguSection = "cgu"
+)
+
+// ErrICEDebugFSNotMounted is reported when the ice driver's debugfs directory can not be read
+var ErrICEDebugFSNotMounted = fmt.Errorf(
+	"%s does not exist, debugfs must be mounted in the container to read the ice CGU pin config",
+	iceDebugFSDir,
+)
+
+// ptpPinFunctions are the names of the values of enum ptp_pin_function
+var ptpPinFunctions = []string{"none", "extts", "perout", "physync"}
+
+// ICEPin is a pin of the PTP hardware clock exposed in sysfs such as SMA1 or U.FL2,
+// Function is what the pin is configured to do and Channel the channel it is assigned to
+type ICEPin struct {
+	Name     string `json:"name"`
+	Function string `json:"function"`
+	Channel  int    `json:"channel"`
+}
+
+// ICECGUInput is an input of the clock generation unit as reported by the ice debugfs,
+// the priorities are those of the input for the EEC and PPS DPLLs where 0 is the highest
+type ICECGUInput struct {
+	Name        string `json:"name"`
+	State       string `json:"state"`
+	Index       int    `json:"index"`
+	EECPriority int    `json:"eecPriority"`
+	PPSPriority int    `json:"ppsPriority"`
+}
+
+// ICEPinConfig holds which pins the GNSS and 1PPS signals are wired to for an interface.
+// The CGU inputs and current references are read from debugfs, if that is not
+// available they are omitted and the reason is held in SectionErrors
+type ICEPinConfig struct {
+	Raw               map[string]string `fetcherKey:"raw"               json:"raw,omitempty"`
+	CurrentReferences map[string]string `fetcherKey:"currentReferences" json:"currentReferences,omitempty"`
+	SectionErrors     map[string]string `fetcherKey:"sectionErrors"     json:"sectionErrors,omitempty"`
+	Timestamp         string            `fetcherKey:"date"              json:"timestamp"`
+	Interface         string            `json:"interface"`
+	ClockName         string            `fetcherKey:"clockName"         json:"clockName"`
+	Pins              []*ICEPin         `fetcherKey:"pins"              json:"pins"`
+	CGUInputs         []*ICECGUInput    `fetcherKey:"cguInputs"         json:"cguInputs,omitempty"`
+}
+
+// GetAnalyserFormat returns the json expected by the analysers
+func (pinConfig *ICEPinConfig) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   ICEPinConfigID,
+		Data: pinConfig,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+var (
+	icePinsFetcher *fetcherCache
+
+	// input (idx)        | state      | EEC (0)   | PPS (1)   |
+	//   ---------------------------------------------------------
+	//   CVL-SDP22 (0)    | invalid    | 8         | 8         |
+	//   SMA2/U.FL2 (5)   | invalid    | 2         | 2         |
+	//   GNSS-1PPS (6)    | valid      | 0         | 0         |
+	cguInputRegex = regexp.MustCompile(
+		`(?m)^[ \t]*(\S[^|\n]*?) \((\d+)\)[ \t]*\|[ \t]*(\S+)[ \t]*\|[ \t]*(\d+)[ \t]*\|[ \t]*(\d+)[ \t]*\|`,
+	)
+	// EEC DPLL:
+	// Current reference:	GNSS-1PPS
+	cguReferenceRegex = regexp.MustCompile(`(?m)^(\S+) DPLL:[ \t]*\nCurrent reference:[ \t]*(.*?)[ \t]*$`)
+)
+
+func init() {
+	icePinsFetcher = newFetcherCache()
+}
+
+// parsePTPPins parses the output of grep -H over the pins directory of the PTP clock
+// which has a line per pin in the form <path>/<pin name>:<function> <channel>
+func parsePTPPins(output string) ([]*ICEPin, error) {
+	pins := make([]*ICEPin, 0)
+	for _, line := range strings.Split(output, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		path, value, found := strings.Cut(line, ":")
+		fields := strings.Fields(value)
+		if !found || len(fields) != 2 { //nolint:gomnd // the function and channel
+			return pins, fmt.Errorf("failed to parse pin from %q", line)
+		}
+		function, err := strconv.Atoi(fields[0])
+		if err != nil {
+			return pins, fmt.Errorf("failed to parse pin function from %q %w", line, err)
+		}
+		channel, err := strconv.Atoi(fields[1])
+		if err != nil {
+			return pins, fmt.Errorf("failed to parse pin channel from %q %w", line, err)
+		}
+		functionName := fmt.Sprintf("unknown(%d)", function)
+		if function >= 0 && function < len(ptpPinFunctions) {
+			functionName = ptpPinFunctions[function]
+		}
+		pins = append(pins, &ICEPin{Name: filepath.Base(path), Function: functionName, Channel: channel})
+	}
+	return pins, nil
+}
+
+// parseCGUStatus parses the inputs and the current reference of each DPLL
+// from the cgu file in the ice debugfs directory of the device
+func parseCGUStatus(output string) ([]*ICECGUInput, map[string]string, error) {
+	output = strings.TrimSpace(output)
+	switch output {
+	case iceDebugFSMissing:
+		return nil, nil, ErrICEDebugFSNotMounted
+	case "":
+		return nil, nil, errors.New("the ice driver does not expose the CGU status for this device")
+	}
+	matches := cguInputRegex.FindAllStringSubmatch(output, -1)
+	if len(matches) == 0 {
+		return nil, nil, fmt.Errorf("unable to parse the CGU inputs from %s", output)
+	}
+	inputs := make([]*ICECGUInput, 0, len(matches))
+	for _, match := range matches {
+		// The regex only matches digits so these can not fail
+		index, _ := strconv.Atoi(match[2])
+		eecPriority, _ := strconv.Atoi(match[4])
+		ppsPriority, _ := strconv.Atoi(match[5])
+		inputs = append(inputs, &ICECGUInput{
+			Name:        strings.TrimSpace(match[1]),
+			Index:       index,
+			State:       match[3],
+			EECPriority: eecPriority,
+			PPSPriority: ppsPriority,
+		})
+	}
+	references := make(map[string]string)
+	for _, match := range cguReferenceRegex.FindAllStringSubmatch(output, -1) {
+		references[match[1]] = match[2]
+	}
+	return inputs, references, nil
+}
+
+// processICEPins parses the pins and CGU status, failing to read the CGU status
+// is reported in the sectionErrors rather than failing the fetch
+func processICEPins(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+	pins, err := parsePTPPins(result["pins"])
+	if err != nil {
+		return processedResult, err
+	}
+	processedResult["pins"] = pins
+	inputs, references, err := parseCGUStatus(result["cgu"])
+	if err != nil {
+		log.Debugf("failed to read the CGU status: %s", err.Error())
+		processedResult["sectionErrors"] = map[string]string{cguSection: err.Error()}
+		return processedResult, nil
+	}
+	processedResult["cguInputs"] = inputs
+	processedResult["currentReferences"] = references
+	return processedResult, nil
+}
+
+// BuildICEPinsFetcher populates the fetcher required for collecting the ICEPinConfig of the interface
+func BuildICEPinsFetcher(interfaceName string) error {
+	deviceDir := fmt.Sprintf("/sys/class/net/%s/device", interfaceName)
+	fetcherInst, err := fetcher.FetcherFactory(
+		[]*clients.Cmd{dateCmd},
+		[]fetcher.AddCommandArgs{
+			{
+				Key:     "clockName",
+				Command: fmt.Sprintf("cat %s/ptp/ptp*/clock_name", deviceDir),
+				Trim:    true,
+			},
+			{
+				Key:     "pins",
+				Command: fmt.Sprintf("grep -H . %s/ptp/ptp*/pins/*", deviceDir),
+				Trim:    true,
+			},
+			{
+				Key: "cgu",
+				Command: fmt.Sprintf(
+					"if [ -d %[1]s ]; then cat %[1]s/$(basename $(readlink -f %[2]s))/cgu 2>/dev/null; else echo %[3]s; fi",
+					iceDebugFSDir, deviceDir, iceDebugFSMissing,
+				),
+				Trim: true,
+			},
+		},
+	)
+	if err != nil {
+		log.Errorf("failed to create fetcher for ice pins: %s", err.Error())
+		return fmt.Errorf("failed to create fetcher for ice pins: %w", err)
+	}
+	fetcherInst.SetPostProcessor(processICEPins)
+	icePinsFetcher.set(interfaceName, fetcherInst)
+	return nil
+}
+
+// GetICEPinConfig returns the pin config of the interface, if the CGU status could
+// not be read the rest of the config is returned along with the reason
+func GetICEPinConfig(ctx clients.ExecContext, interfaceName string) (ICEPinConfig, error) {
+	pinConfig := ICEPinConfig{Interface: interfaceName}
+	fetcherInst, fetchedInstanceOk := icePinsFetcher.get(interfaceName)
+	if !fetchedInstanceOk {
+		err := BuildICEPinsFetcher(interfaceName)
+		if err != nil {
+			return pinConfig, err
+		}
+		fetcherInst, fetchedInstanceOk = icePinsFetcher.get(interfaceName)
+		if !fetchedInstanceOk {
+			return pinConfig, errors.New("failed to create fetcher for ice pins")
+		}
+	}
+	err := fetcherInst.Fetch(ctx, &pinConfig)
+	if err != nil {
+		log.Debugf("failed to fetch ice pins %s", err.Error())
+		return pinConfig, fmt.Errorf("failed to fetch ice pins %w", err)
+	}
+	reason, ok := pinConfig.SectionErrors[cguSection]
+	switch {
+	case !ok:
+		return pinConfig, nil
+	case reason == ErrICEDebugFSNotMounted.Error():
+		return pinConfig, fmt.Errorf("failed to read the CGU status: %w", ErrICEDebugFSNotMounted)
+	default:
+		return pinConfig, fmt.Errorf("failed to read the CGU status: %s", reason)
+	}
+}
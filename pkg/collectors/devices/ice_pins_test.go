@@ -0,0 +1,155 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var (
+	icePinLines = []string{
+		"<clockName>",
+		"ice-0000:86:00.0-clk",
+		"</clockName>",
+		"<pins>",
+		"/sys/class/net/ens7f0/device/ptp/ptp2/pins/GNSS:1 1",
+		"/sys/class/net/ens7f0/device/ptp/ptp2/pins/SMA1:2 1",
+		"/sys/class/net/ens7f0/device/ptp/ptp2/pins/SMA2:0 2",
+		"/sys/class/net/ens7f0/device/ptp/ptp2/pins/U.FL1:0 1",
+		"/sys/class/net/ens7f0/device/ptp/ptp2/pins/U.FL2:0 2",
+		"</pins>",
+	}
+	cguLines = []string{
+		"<cgu>",
+		"Found ZL80032 CGU",
+		"DPLL Config ver: 1.3.0.1",
+		"FW Config ver: 1.4.0.0",
+		"",
+		"CGU Input status:",
+		"                   |            |      priority         |",
+		"input (idx)        | state      | EEC (0)   | PPS (1)   |",
+		"  ---------------------------------------------------------",
+		"  CVL-SDP22 (0)    | invalid    | 8         | 8         |",
+		"  CVL-SDP20 (1)    | invalid    | 15        | 3         |",
+		"  SMA1 (4)         | invalid    | 1         | 1         |",
+		"  SMA2/U.FL2 (5)   | invalid    | 2         | 2         |",
+		"  GNSS-1PPS (6)    | valid      | 0         | 0         |",
+		"",
+		"EEC DPLL:",
+		"Current reference:	GNSS-1PPS",
+		"Status:			locked_ho_ack",
+		"",
+		"PPS DPLL:",
+		"Current reference:	GNSS-1PPS",
+		"Status:			locked_ho_ack",
+		"Phase offset [ps]:	-57",
+		"</cgu>",
+	}
+)
+
+func buildICEPinsOutput(sections ...[]string) []byte {
+	lines := []string{"<date>", "1686916187.0584", "</date>"}
+	for _, section := range sections {
+		lines = append(lines, section...)
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+var _ = Describe("GetICEPinConfig", func() {
+	var clientset *clients.Clientset
+	var output []byte
+	BeforeEach(func() {
+		clientset = testutils.GetMockedClientSet(testPod)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			return output, []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	When("the pins and CGU status can be read", func() {
+		It("should return the clock name, pin mapping and CGU inputs", func() {
+			output = buildICEPinsOutput(icePinLines, cguLines)
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			pinConfig, err := devices.GetICEPinConfig(ctx, "ens7f0")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pinConfig.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(pinConfig.Interface).To(Equal("ens7f0"))
+			Expect(pinConfig.ClockName).To(Equal("ice-0000:86:00.0-clk"))
+			Expect(pinConfig.Pins).To(Equal([]*devices.ICEPin{
+				{Name: "GNSS", Function: "extts", Channel: 1},
+				{Name: "SMA1", Function: "perout", Channel: 1},
+				{Name: "SMA2", Function: "none", Channel: 2},
+				{Name: "U.FL1", Function: "none", Channel: 1},
+				{Name: "U.FL2", Function: "none", Channel: 2},
+			}))
+			Expect(pinConfig.CGUInputs).To(HaveLen(5))
+			Expect(pinConfig.CGUInputs[1]).To(Equal(&devices.ICECGUInput{
+				Name: "CVL-SDP20", Index: 1, State: "invalid", EECPriority: 15, PPSPriority: 3,
+			}))
+			Expect(pinConfig.CGUInputs[3].Name).To(Equal("SMA2/U.FL2"))
+			Expect(pinConfig.CGUInputs[4]).To(Equal(&devices.ICECGUInput{
+				Name: "GNSS-1PPS", Index: 6, State: "valid", EECPriority: 0, PPSPriority: 0,
+			}))
+			Expect(pinConfig.CurrentReferences).To(Equal(map[string]string{"EEC": "GNSS-1PPS", "PPS": "GNSS-1PPS"}))
+			Expect(pinConfig.SectionErrors).To(BeEmpty())
+		})
+	})
+
+	When("debugfs is not mounted", func() {
+		It("should return the pins along with a clear error", func() {
+			output = buildICEPinsOutput(icePinLines, []string{"<cgu>", "debugfs-not-mounted", "</cgu>"})
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			pinConfig, err := devices.GetICEPinConfig(ctx, "ens7f0")
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, devices.ErrICEDebugFSNotMounted)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("debugfs must be mounted"))
+			Expect(pinConfig.ClockName).To(Equal("ice-0000:86:00.0-clk"))
+			Expect(pinConfig.Pins).To(HaveLen(5))
+			Expect(pinConfig.CGUInputs).To(BeNil())
+			Expect(pinConfig.SectionErrors).To(HaveKey("cgu"))
+		})
+	})
+
+	When("the driver does not expose the CGU status", func() {
+		It("should return the pins along with an error", func() {
+			output = buildICEPinsOutput(icePinLines, []string{"<cgu>", "</cgu>"})
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			pinConfig, err := devices.GetICEPinConfig(ctx, "ens7f0")
+			Expect(err).To(HaveOccurred())
+			Expect(errors.Is(err, devices.ErrICEDebugFSNotMounted)).To(BeFalse())
+			Expect(pinConfig.Pins).To(HaveLen(5))
+		})
+	})
+
+	When("a pin can not be parsed", func() {
+		It("should return an error", func() {
+			output = buildICEPinsOutput(
+				[]string{"<clockName>", "ice-0000:86:00.0-clk", "</clockName>"},
+				[]string{"<pins>", "/sys/class/net/ens7f0/device/ptp/ptp2/pins/SMA1:x 1", "</pins>"},
+				cguLines,
+			)
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = devices.GetICEPinConfig(ctx, "ens7f0")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
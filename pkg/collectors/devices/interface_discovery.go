@@ -0,0 +1,91 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+// InterfaceDiscovery holds the result of DiscoverInterfaces,
+// Skipped maps the name of each interface which was not selected to the reason why
+type InterfaceDiscovery struct {
+	Skipped    map[string]string
+	Discovered []string
+}
+
+type nicListing struct {
+	Listing string `fetcherKey:"nics" json:"nics"`
+}
+
+var (
+	// /sys/class/net/ens7f0/device/vendor:0x8086
+	nicIDRegex          = regexp.MustCompile(`^/sys/class/net/([^/]+)/device/(vendor|device):(.*)$`)
+	nicDiscoveryFetcher *fetcher.Fetcher
+)
+
+func init() {
+	nicDiscoveryFetcher = fetcher.NewFetcher()
+	err := nicDiscoveryFetcher.AddNewCommand(
+		"nics",
+		// Errors are discarded so that interfaces without a device e.g. bridges do not fail the discovery
+		"grep -H . /sys/class/net/*/device/vendor /sys/class/net/*/device/device 2>/dev/null",
+		true,
+	)
+	if err != nil {
+		panic(fmt.Errorf("failed to setup interface discovery fetcher %w", err))
+	}
+}
+
+// parseNICListing returns the vendor and device IDs for each interface in the listing
+func parseNICListing(listing string) map[string]map[string]string {
+	nics := make(map[string]map[string]string)
+	for _, line := range strings.Split(listing, "\n") {
+		match := nicIDRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if len(match) == 0 {
+			continue
+		}
+		if _, ok := nics[match[1]]; !ok {
+			nics[match[1]] = make(map[string]string)
+		}
+		nics[match[1]][match[2]] = strings.TrimSpace(match[3])
+	}
+	return nics
+}
+
+// DiscoverInterfaces reads the vendor and device IDs of the network interfaces from sysfs
+// and returns the interfaces for which isSupported returns true sorted by name
+func DiscoverInterfaces(
+	ctx clients.ExecContext,
+	isSupported func(vendorID, deviceID string) bool,
+) (InterfaceDiscovery, error) {
+	discovery := InterfaceDiscovery{
+		Discovered: make([]string, 0),
+		Skipped:    make(map[string]string),
+	}
+	listing := nicListing{}
+	err := nicDiscoveryFetcher.Fetch(ctx, &listing)
+	if err != nil {
+		return discovery, fmt.Errorf("failed to list network interfaces %w", err)
+	}
+	nics := parseNICListing(listing.Listing)
+	names := make([]string, 0, len(nics))
+	for name := range nics {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		vendorID, deviceID := nics[name]["vendor"], nics[name]["device"]
+		if isSupported(vendorID, deviceID) {
+			discovery.Discovered = append(discovery.Discovered, name)
+		} else {
+			discovery.Skipped[name] = fmt.Sprintf("vendor %s device %s is not a supported NIC", vendorID, deviceID)
+		}
+	}
+	return discovery, nil
+}
@@ -0,0 +1,94 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/validations"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+const sysClassNet = "/sys/class/net/"
+
+// writeFakeNIC adds an interface to the fake sysfs tree, if vendorID is empty the interface has no device
+func writeFakeNIC(sysfsRoot, interfaceName, vendorID, deviceID string) {
+	interfaceDir := filepath.Join(sysfsRoot, interfaceName)
+	Expect(os.MkdirAll(interfaceDir, 0755)).To(Succeed())
+	if vendorID == "" {
+		return
+	}
+	deviceDir := filepath.Join(interfaceDir, "device")
+	Expect(os.MkdirAll(deviceDir, 0755)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(deviceDir, "vendor"), []byte(vendorID+"\n"), 0600)).To(Succeed())
+	Expect(os.WriteFile(filepath.Join(deviceDir, "device"), []byte(deviceID+"\n"), 0600)).To(Succeed())
+}
+
+var _ = Describe("DiscoverInterfaces", func() {
+	var (
+		sysfsRoot string
+		ctx       clients.ExecContext
+	)
+	BeforeEach(func() {
+		sysfsRoot = GinkgoT().TempDir()
+		// Run the commands locally against the fake sysfs tree
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmd += string(line)
+			}
+			cmd = strings.ReplaceAll(cmd, sysClassNet, sysfsRoot+"/")
+			stdout, err := exec.Command("sh", "-c", cmd).Output()
+			return []byte(strings.ReplaceAll(string(stdout), sysfsRoot+"/", sysClassNet)), []byte(""), err
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+		var err error
+		ctx, err = clients.NewContainerContext(testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	When("the node has a mix of NICs", func() {
+		It("should discover the supported NICs and report the others as skipped", func() {
+			writeFakeNIC(sysfsRoot, "ens1f1", validations.VendorIntel, validations.E810WesportChannel)
+			writeFakeNIC(sysfsRoot, "ens1f0", validations.VendorIntel, validations.E810WesportChannel)
+			writeFakeNIC(sysfsRoot, "ens2f0", validations.VendorIntel, validations.E810LoganBeach)
+			writeFakeNIC(sysfsRoot, "eno1", validations.VendorIntel, "0x1572")
+			writeFakeNIC(sysfsRoot, "enp3s0", "0x15b3", "0x1017")
+			writeFakeNIC(sysfsRoot, "br0", "", "")
+
+			discovery, err := devices.DiscoverInterfaces(ctx, validations.IsSupportedNIC)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(discovery.Discovered).To(Equal([]string{"ens1f0", "ens1f1", "ens2f0"}))
+			Expect(discovery.Skipped).To(Equal(map[string]string{
+				"eno1":   "vendor 0x8086 device 0x1572 is not a supported NIC",
+				"enp3s0": "vendor 0x15b3 device 0x1017 is not a supported NIC",
+			}))
+		})
+	})
+	When("the node has no NICs with a device", func() {
+		It("should not discover any interfaces", func() {
+			writeFakeNIC(sysfsRoot, "lo", "", "")
+
+			discovery, err := devices.DiscoverInterfaces(ctx, validations.IsSupportedNIC)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(discovery.Discovered).To(BeEmpty())
+			Expect(discovery.Skipped).To(BeEmpty())
+		})
+	})
+})
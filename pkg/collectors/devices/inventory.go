@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// InventoryNIC is the hardware behind one of the interfaces in an Inventory
+type InventoryNIC struct {
+	DeviceInfo *PTPDeviceInfo         `json:"deviceInfo,omitempty"`
+	DPLL       *DevFilesystemDPLLInfo `json:"dpll,omitempty"`
+	Interface  string                 `json:"interface"`
+}
+
+// Inventory describes the timing hardware of a node,
+// the GNSS versions are for the node so are only fetched once
+type Inventory struct {
+	GNSS *GPSVersions    `json:"gnss,omitempty"`
+	NICs []*InventoryNIC `json:"nics"`
+}
+
+// GetInventory fetches the device and DPLL information for each interface along with the GNSS versions.
+// Anything which can not be fetched is left out of the inventory and the errors are returned alongside it.
+func GetInventory(ctx clients.ExecContext, interfaceNames []string) (Inventory, error) {
+	inventory := Inventory{NICs: make([]*InventoryNIC, 0, len(interfaceNames))}
+	fetchErrors := make([]error, 0)
+	for _, interfaceName := range interfaceNames {
+		nic := &InventoryNIC{Interface: interfaceName}
+		inventory.NICs = append(inventory.NICs, nic)
+
+		devInfo, err := GetPTPDeviceInfo(interfaceName, ctx)
+		if err != nil {
+			fetchErrors = append(fetchErrors, fmt.Errorf("interface %s: %w", interfaceName, err))
+		} else {
+			nic.DeviceInfo = &devInfo
+		}
+		nic.DPLL, err = getDPLLIfPresent(ctx, interfaceName)
+		if err != nil {
+			fetchErrors = append(fetchErrors, fmt.Errorf("interface %s: %w", interfaceName, err))
+		}
+	}
+
+	gnssVersions, err := GetGPSVersions(ctx)
+	if err != nil {
+		fetchErrors = append(fetchErrors, err)
+	} else {
+		inventory.GNSS = &gnssVersions
+	}
+
+	if len(fetchErrors) > 0 {
+		return inventory, utils.MakeCompositeError("inventory is incomplete", fetchErrors)
+	}
+	return inventory, nil
+}
@@ -0,0 +1,128 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"fmt"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+// inventoryNICOutput returns the output of every command run for an interface,
+// the fetchers only extract the keys they asked for so the same output can answer each of them
+func inventoryNICOutput(devID, dpllPaths string) string {
+	output := "<date>\n1686916187.0584\n</date>\n"
+	output += "<gnss>\ngnss0\n</gnss>\n"
+	output += fmt.Sprintf("<devID>\n%s\n</devID>\n", devID)
+	output += "<vendorID>\n0x8086\n</vendorID>\n"
+	output += linkOutput("b4:96:91:b4:9d:e8", "up")
+	output += fmt.Sprintf(ethtoolOutput, "1.11.20.7", "4.20 0x8001778b 1.3346.0")
+	output += fmt.Sprintf(ueventOutput, "0000:86:00.0")
+	output += fmt.Sprintf("<paths>\ndevice\nvendor\n%s</paths>\n", dpllPaths)
+	output += "<dpll_0_state>\n2\n</dpll_0_state>\n"
+	output += "<dpll_1_state>\n3\n</dpll_1_state>\n"
+	output += "<dpll_1_offset>\n-25\n</dpll_1_offset>\n"
+	return output
+}
+
+var inventoryGNSSOutput = strings.Join([]string{
+	"<UBXMonVer>",
+	"1689260332.4728",
+	"UBX-MON-VER:",
+	"  swVersion EXT CORE 1.00 (3fda8e)",
+	"  hwVersion 00190000",
+	"  extension ROM BASE 0x118B2060",
+	"  extension FWVER=TIM 2.20",
+	"  extension PROTVER=29.20",
+	"  extension MOD=ZED-F9T",
+	"",
+	"</UBXMonVer>",
+	"<UBXVersion>",
+	"ubxtool: Version 3.25.1~dev",
+	"</UBXVersion>",
+	"<GPSDVersion>",
+	"gpsd: 3.25.1~dev (revision release-3.25-109-g1a04cfab8)",
+	"</GPSDVersion>",
+	"<GNSSDevices>",
+	"gnss0",
+	"</GNSSDevices>",
+	"",
+}, "\n")
+
+var _ = Describe("GetInventory", func() {
+	var ctx clients.ExecContext
+	BeforeEach(func() {
+		outputs := map[string]string{
+			"inventoryNIC0": inventoryNICOutput("0x1593", "dpll_0_state\ndpll_1_state\ndpll_1_offset\n"),
+			"inventoryNIC1": inventoryNICOutput("0x159b", ""),
+		}
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmd += string(line)
+			}
+			for interfaceName, output := range outputs {
+				if strings.Contains(cmd, interfaceName) {
+					return []byte(output), []byte(""), nil
+				}
+			}
+			return []byte(inventoryGNSSOutput), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+		var err error
+		ctx, err = clients.NewContainerContext(
+			testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer",
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	When("the inventory is requested for several interfaces", func() {
+		It("should combine the device, DPLL and GNSS information", func() {
+			inventory, err := devices.GetInventory(ctx, []string{"inventoryNIC0", "inventoryNIC1"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(inventory.NICs).To(HaveLen(2))
+
+			nic := inventory.NICs[0]
+			Expect(nic.Interface).To(Equal("inventoryNIC0"))
+			Expect(nic.DeviceInfo.VendorID).To(Equal("0x8086"))
+			Expect(nic.DeviceInfo.DeviceID).To(Equal("0x1593"))
+			Expect(nic.DeviceInfo.FirmwareVersion).To(Equal("4.20 0x8001778b 1.3346.0"))
+			Expect(nic.DeviceInfo.GNSSDev).To(Equal("/dev/gnss0"))
+			Expect(nic.DPLL.EECState).To(Equal("2"))
+			Expect(nic.DPLL.PPSState).To(Equal("3"))
+
+			nic = inventory.NICs[1]
+			Expect(nic.Interface).To(Equal("inventoryNIC1"))
+			Expect(nic.DeviceInfo.DeviceID).To(Equal("0x159b"))
+			Expect(nic.DPLL).To(BeNil())
+
+			Expect(inventory.GNSS.ProtoVersion).To(Equal("29.20"))
+			Expect(inventory.GNSS.Module).To(Equal("ZED-F9T"))
+		})
+	})
+	When("part of the inventory can not be fetched", func() {
+		It("should return what was fetched along with the error", func() {
+			inventory, err := devices.GetInventory(ctx, []string{"inventoryNIC0", "missingNIC"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("interface missingNIC"))
+			Expect(inventory.NICs).To(HaveLen(2))
+			Expect(inventory.NICs[0].DeviceInfo).NotTo(BeNil())
+			Expect(inventory.NICs[1].DeviceInfo).To(BeNil())
+			Expect(inventory.GNSS).NotTo(BeNil())
+		})
+	})
+})
@@ -4,6 +4,7 @@ package devices
 
 import (
 	"fmt"
+	"path/filepath"
 	"regexp"
 	"strconv"
 
@@ -15,24 +16,29 @@ import (
 )
 
 type PMCInfo struct {
-	Timestamp               string `fetcherKey:"date"                    json:"timestamp"`
-	TimeSource              string `fetcherKey:"timeSource"              json:"timeSource"`
-	ClockAccuracy           string `fetcherKey:"clockAccuracy"           json:"clockAccuracy"`
-	OffsetScaledLogVariance string `fetcherKey:"offsetScaledLogVariance" json:"offsetScaledLogVariance"`
-	ClockClass              int    `fetcherKey:"clockClass"              json:"clock_class"` //nolint:tagliatelle // needs to match the parser in vse-sync-pp
-	CurrentUtcOffset        int    `fetcherKey:"currentUtcOffset"        json:"currentUtcOffset"`
-	Leap61                  int    `fetcherKey:"leap61"                  json:"leap61"`
-	Leap59                  int    `fetcherKey:"leap59"                  json:"leap59"`
-	CurrentUtcOffsetValid   int    `fetcherKey:"currentUtcOffsetValid"   json:"currentUtcOffsetValid"`
-	PtpTimescale            int    `fetcherKey:"ptpTimescale"            json:"ptpTimescale"`
-	TimeTraceable           int    `fetcherKey:"timeTraceable"           json:"timeTraceable"`
-	FrequencyTraceable      int    `fetcherKey:"frequencyTraceable"      json:"frequencyTraceable"`
+	Raw                     map[string]string `fetcherKey:"raw"                     json:"raw,omitempty"`
+	Timestamp               string            `fetcherKey:"date"                    json:"timestamp"`
+	TimeSource              string            `fetcherKey:"timeSource"              json:"timeSource"`
+	ClockAccuracy           string            `fetcherKey:"clockAccuracy"           json:"clockAccuracy"`
+	OffsetScaledLogVariance string            `fetcherKey:"offsetScaledLogVariance" json:"offsetScaledLogVariance"`
+	ClockClass              int               `fetcherKey:"clockClass"              json:"clock_class"` //nolint:tagliatelle // needs to match the parser in vse-sync-pp
+	CurrentUtcOffset        int               `fetcherKey:"currentUtcOffset"        json:"currentUtcOffset"`
+	Leap61                  int               `fetcherKey:"leap61"                  json:"leap61"`
+	Leap59                  int               `fetcherKey:"leap59"                  json:"leap59"`
+	CurrentUtcOffsetValid   int               `fetcherKey:"currentUtcOffsetValid"   json:"currentUtcOffsetValid"`
+	PtpTimescale            int               `fetcherKey:"ptpTimescale"            json:"ptpTimescale"`
+	TimeTraceable           int               `fetcherKey:"timeTraceable"           json:"timeTraceable"`
+	FrequencyTraceable      int               `fetcherKey:"frequencyTraceable"      json:"frequencyTraceable"`
+	GrandmasterIdentity     string            `fetcherKey:"grandmasterIdentity"     json:"grandmasterIdentity"`
+	GrandmasterPriority1    int               `fetcherKey:"grandmasterPriority1"    json:"grandmasterPriority1"`
+	GrandmasterPriority2    int               `fetcherKey:"grandmasterPriority2"    json:"grandmasterPriority2"`
+	ConfigName              string            `json:"configName,omitempty"`
 }
 
 // GetAnalyserFormat returns the json expected by the analysers
 func (gmSetting *PMCInfo) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
 	formatted := callbacks.AnalyserFormatType{
-		ID:   "phc/gm-settings",
+		ID:   PMCGMSettingsID,
 		Data: gmSetting,
 	}
 	return []*callbacks.AnalyserFormatType{&formatted}, nil
@@ -53,9 +59,12 @@ func MapStringToInt(inputMap map[string]string) (map[string]int, error) {
 	return convertedMap, nil
 }
 
+// DefaultPMCConfigFile is the ptp4l config used by pmc in the linuxptp daemon pods
+const DefaultPMCConfigFile = "/var/run/ptp4l.0.config"
+
 var (
-	pmcFetcher *fetcher.Fetcher
-	pmcRegEx   = regexp.MustCompile(
+	pmcFetchers = newFetcherCache()
+	pmcRegEx    = regexp.MustCompile(
 		`\sclockClass\s+(\d+)` +
 			`\s*clockAccuracy\s+(.+)\n` +
 			`\s*offsetScaledLogVariance\s+(.+)\n` +
@@ -81,19 +90,58 @@ var (
 	// 		frequencyTraceable      0
 	// 		timeSource              0xa0
 	)
+	pmcParentRegEx = regexp.MustCompile(
+		`\sgrandmasterPriority1\s+(\d+)\n` +
+			`\s*gm\.ClockClass\s+\d+\n` +
+			`\s*gm\.ClockAccuracy\s+.+\n` +
+			`\s*gm\.OffsetScaledLogVariance\s+.+\n` +
+			`\s*grandmasterPriority2\s+(\d+)\n` +
+			`\s*grandmasterIdentity\s+(\S+)`,
+	// sending: GET PARENT_DATA_SET
+	// 	507c6f.fffe.30fbe8-0 seq 1 RESPONSE MANAGEMENT PARENT_DATA_SET
+	// 		parentPortIdentity                    507c6f.fffe.30fbe8-0
+	// 		parentStats                           0
+	// 		observedParentOffsetScaledLogVariance 0xffff
+	// 		observedParentClockPhaseChangeRate    0x7fffffff
+	// 		grandmasterPriority1                  128
+	// 		gm.ClockClass                         248
+	// 		gm.ClockAccuracy                      0xfe
+	// 		gm.OffsetScaledLogVariance            0xffff
+	// 		grandmasterPriority2                  128
+	// 		grandmasterIdentity                   507c6f.fffe.30fbe8
+	)
 )
 
-func init() {
-	pmcFetcher = fetcher.NewFetcher()
-	pmcFetcher.SetPostProcessor(processPMC)
-	pmcFetcher.AddCommand(getDateCommand())
-	err := pmcFetcher.AddNewCommand(
+// BuildPMCFetcher builds the fetcher which runs pmc against configFile,
+// each config file has its own fetcher so they can be used at the same time
+func BuildPMCFetcher(configFile string) (*fetcher.Fetcher, error) {
+	fetcherInst := fetcher.NewFetcher()
+	fetcherInst.SetPostProcessor(processPMC)
+	fetcherInst.AddCommand(getDateCommand())
+	err := fetcherInst.AddNewCommand(
 		"PMC",
-		"pmc -u -f /var/run/ptp4l.0.config  'GET GRANDMASTER_SETTINGS_NP'",
+		fmt.Sprintf("pmc -u -f %s  'GET GRANDMASTER_SETTINGS_NP' 'GET PARENT_DATA_SET'", configFile),
 		true,
 	)
 	if err != nil {
-		panic(fmt.Errorf("failed to setup PMC fetcher %w", err))
+		return nil, fmt.Errorf("failed to setup PMC fetcher %w", err)
+	}
+	pmcFetchers.set(configFile, fetcherInst)
+	return fetcherInst, nil
+}
+
+// getPMCFetcher returns the fetcher for configFile building it if needed
+func getPMCFetcher(configFile string) (*fetcher.Fetcher, error) {
+	if fetcherInst, ok := pmcFetchers.get(configFile); ok {
+		return fetcherInst, nil
+	}
+	return BuildPMCFetcher(configFile)
+}
+
+func init() {
+	_, err := BuildPMCFetcher(DefaultPMCConfigFile)
+	if err != nil {
+		panic(err)
 	}
 }
 
@@ -104,6 +152,10 @@ func processPMC(result map[string]string) (map[string]any, error) { //nolint:fun
 	if len(match) == 0 {
 		return processedResult, fmt.Errorf("unable to parse pmc output: %s", result["PMC"])
 	}
+	parentMatch := pmcParentRegEx.FindStringSubmatch(result["PMC"])
+	if len(parentMatch) == 0 {
+		return processedResult, fmt.Errorf("unable to parse pmc PARENT_DATA_SET output: %s", result["PMC"])
+	}
 
 	valuesToConvert := map[string]string{
 		"clockClass":            match[1],
@@ -114,6 +166,8 @@ func processPMC(result map[string]string) (map[string]any, error) { //nolint:fun
 		"ptpTimescale":          match[8],
 		"timeTraceable":         match[9],
 		"frequencyTraceable":    match[10],
+		"grandmasterPriority1":  parentMatch[1],
+		"grandmasterPriority2":  parentMatch[2],
 	}
 
 	convertedMap, err := MapStringToInt(valuesToConvert)
@@ -132,17 +186,31 @@ func processPMC(result map[string]string) (map[string]any, error) { //nolint:fun
 	processedResult["ptpTimescale"] = convertedMap["ptpTimescale"]
 	processedResult["timeTraceable"] = convertedMap["timeTraceable"]
 	processedResult["frequencyTraceable"] = convertedMap["frequencyTraceable"]
+	processedResult["grandmasterIdentity"] = parentMatch[3]
+	processedResult["grandmasterPriority1"] = convertedMap["grandmasterPriority1"]
+	processedResult["grandmasterPriority2"] = convertedMap["grandmasterPriority2"]
 
 	return processedResult, nil
 }
 
-// GetPMC returns PMCInfo
+// GetPMC returns PMCInfo using the DefaultPMCConfigFile
 func GetPMC(ctx clients.ExecContext) (PMCInfo, error) {
+	return GetPMCForConfig(ctx, DefaultPMCConfigFile)
+}
+
+// GetPMCForConfig returns PMCInfo from the ptp4l instance using configFile
+// tagged with the name of the config file
+func GetPMCForConfig(ctx clients.ExecContext, configFile string) (PMCInfo, error) {
 	gmSetting := PMCInfo{}
-	err := pmcFetcher.Fetch(ctx, &gmSetting)
+	pmcFetcher, err := getPMCFetcher(configFile)
+	if err != nil {
+		return gmSetting, err
+	}
+	err = pmcFetcher.Fetch(ctx, &gmSetting)
 	if err != nil {
 		log.Debugf("failed to fetch gmSetting %s", err.Error())
 		return gmSetting, fmt.Errorf("failed to fetch gmSetting %w", err)
 	}
+	gmSetting.ConfigName = filepath.Base(configFile)
 	return gmSetting, nil
 }
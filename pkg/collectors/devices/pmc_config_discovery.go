@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+type pmcConfigListing struct {
+	Listing string `fetcherKey:"configs" json:"configs"`
+}
+
+var (
+	// /var/run/ptp4l.1.config
+	pmcConfigRegex            = regexp.MustCompile(`^(/var/run/ptp4l\.(\d+)\.config)$`)
+	pmcConfigDiscoveryFetcher *fetcher.Fetcher
+)
+
+func init() {
+	pmcConfigDiscoveryFetcher = fetcher.NewFetcher()
+	err := pmcConfigDiscoveryFetcher.AddNewCommand(
+		"configs",
+		// Errors are discarded so that a node without any ptp4l config returns an empty listing
+		"ls /var/run/ptp4l.*.config 2>/dev/null",
+		true,
+	)
+	if err != nil {
+		panic(fmt.Errorf("failed to setup ptp4l config discovery fetcher %w", err))
+	}
+}
+
+// parsePMCConfigListing returns the ptp4l config files in the listing ordered by their instance number
+func parsePMCConfigListing(listing string) []string {
+	instances := make(map[string]int)
+	configFiles := make([]string, 0)
+	for _, line := range strings.Split(listing, "\n") {
+		match := pmcConfigRegex.FindStringSubmatch(strings.TrimSpace(line))
+		if len(match) == 0 {
+			continue
+		}
+		instance, err := strconv.Atoi(match[2])
+		if err != nil {
+			continue
+		}
+		instances[match[1]] = instance
+		configFiles = append(configFiles, match[1])
+	}
+	sort.Slice(configFiles, func(i, j int) bool {
+		return instances[configFiles[i]] < instances[configFiles[j]]
+	})
+	return configFiles
+}
+
+// DiscoverPMCConfigFiles lists the ptp4l config files (ptp4l.0.config, ptp4l.1.config, ...)
+// in the run directory so that pmc can be run against each ptp4l instance
+func DiscoverPMCConfigFiles(ctx clients.ExecContext) ([]string, error) {
+	listing := pmcConfigListing{}
+	err := pmcConfigDiscoveryFetcher.Fetch(ctx, &listing)
+	if err != nil {
+		return []string{}, fmt.Errorf("failed to list ptp4l config files %w", err)
+	}
+	return parsePMCConfigListing(listing.Listing), nil
+}
@@ -40,7 +40,7 @@ var _ = Describe("GetPMC", func() {
 	When("called GetPMC", func() {
 		It("should return a valid GMSettings", func() {
 			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
-			expectedInput += "echo '<PMC>';pmc -u -f /var/run/ptp4l.0.config  'GET GRANDMASTER_SETTINGS_NP';echo '</PMC>';"
+			expectedInput += "echo '<PMC>';pmc -u -f /var/run/ptp4l.0.config  'GET GRANDMASTER_SETTINGS_NP' 'GET PARENT_DATA_SET';echo '</PMC>';"
 
 			expectedOutput := strings.Join([]string{
 				"<date>",
@@ -60,6 +60,18 @@ var _ = Describe("GetPMC", func() {
 				"		timeTraceable           0",
 				"		frequencyTraceable      0",
 				"		timeSource              0xa0",
+				"sending: GET PARENT_DATA_SET",
+				"	507c6f.fffe.30fbe8-0 seq 1 RESPONSE MANAGEMENT PARENT_DATA_SET",
+				"		parentPortIdentity                    507c6f.fffe.30fbe8-0",
+				"		parentStats                           0",
+				"		observedParentOffsetScaledLogVariance 0xffff",
+				"		observedParentClockPhaseChangeRate    0x7fffffff",
+				"		grandmasterPriority1                  128",
+				"		gm.ClockClass                         248",
+				"		gm.ClockAccuracy                      0xfe",
+				"		gm.OffsetScaledLogVariance            0xffff",
+				"		grandmasterPriority2                  127",
+				"		grandmasterIdentity                   507c6f.fffe.30fbe8",
 				"</PMC>",
 			}, "\n")
 			response[expectedInput] = []byte(expectedOutput)
@@ -81,7 +93,159 @@ var _ = Describe("GetPMC", func() {
 			Expect(pmcInfo.TimeTraceable).To(Equal(0))
 			Expect(pmcInfo.FrequencyTraceable).To(Equal(0))
 			Expect(pmcInfo.TimeSource).To(Equal("0xa0"))
+			Expect(pmcInfo.GrandmasterIdentity).To(Equal("507c6f.fffe.30fbe8"))
+			Expect(pmcInfo.GrandmasterPriority1).To(Equal(128))
+			Expect(pmcInfo.GrandmasterPriority2).To(Equal(127))
+			Expect(pmcInfo.ConfigName).To(Equal("ptp4l.0.config"))
+		})
+		It("should fail when the PARENT_DATA_SET reply is missing", func() {
+			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+			expectedInput += "echo '<PMC>';pmc -u -f /var/run/ptp4l.0.config  'GET GRANDMASTER_SETTINGS_NP' 'GET PARENT_DATA_SET';echo '</PMC>';"
+
+			response[expectedInput] = []byte(strings.Join([]string{
+				"<date>",
+				"1686916187.0584",
+				"</date>",
+				"<PMC>",
+				"sending: GET GRANDMASTER_SETTINGS_NP",
+				"	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT GRANDMASTER_SETTINGS_NP",
+				"		clockClass              248",
+				"		clockAccuracy           0xfe",
+				"		offsetScaledLogVariance 0xffff",
+				"		currentUtcOffset        37",
+				"		leap61                  0",
+				"		leap59                  0",
+				"		currentUtcOffsetValid   0",
+				"		ptpTimescale            1",
+				"		timeTraceable           0",
+				"		frequencyTraceable      0",
+				"		timeSource              0xa0",
+				"sending: GET PARENT_DATA_SET",
+				"</PMC>",
+			}, "\n"))
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = devices.GetPMC(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("PARENT_DATA_SET"))
+		})
+	})
+
+	When("pmc is run against different config files", func() {
+		It("should use an independent fetcher for each", func() {
+			defaultFetcher, err := devices.BuildPMCFetcher(devices.DefaultPMCConfigFile)
+			Expect(err).NotTo(HaveOccurred())
+			localFetcher, err := devices.BuildPMCFetcher("/env/ptp4l.config")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(localFetcher).NotTo(BeIdenticalTo(defaultFetcher))
+
+			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+			expectedInput += "echo '<PMC>';pmc -u -f /env/ptp4l.config  'GET GRANDMASTER_SETTINGS_NP' 'GET PARENT_DATA_SET';echo '</PMC>';"
+			response[expectedInput] = []byte(strings.Join([]string{
+				"<date>",
+				"1686916187.0584",
+				"</date>",
+				"<PMC>",
+				"sending: GET GRANDMASTER_SETTINGS_NP",
+				"	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT GRANDMASTER_SETTINGS_NP",
+				"		clockClass              6",
+				"		clockAccuracy           0x21",
+				"		offsetScaledLogVariance 0x4e5d",
+				"		currentUtcOffset        37",
+				"		leap61                  0",
+				"		leap59                  0",
+				"		currentUtcOffsetValid   1",
+				"		ptpTimescale            1",
+				"		timeTraceable           1",
+				"		frequencyTraceable      1",
+				"		timeSource              0x20",
+				"sending: GET PARENT_DATA_SET",
+				"	507c6f.fffe.30fbe8-0 seq 1 RESPONSE MANAGEMENT PARENT_DATA_SET",
+				"		parentPortIdentity                    507c6f.fffe.30fbe8-0",
+				"		parentStats                           0",
+				"		observedParentOffsetScaledLogVariance 0xffff",
+				"		observedParentClockPhaseChangeRate    0x7fffffff",
+				"		grandmasterPriority1                  128",
+				"		gm.ClockClass                         6",
+				"		gm.ClockAccuracy                      0x21",
+				"		gm.OffsetScaledLogVariance            0x4e5d",
+				"		grandmasterPriority2                  128",
+				"		grandmasterIdentity                   507c6f.fffe.30fbe8",
+				"</PMC>",
+			}, "\n"))
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			pmcInfo, err := devices.GetPMCForConfig(ctx, "/env/ptp4l.config")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pmcInfo.ClockClass).To(Equal(6))
+			Expect(pmcInfo.ConfigName).To(Equal("ptp4l.config"))
+			// The default config file is not answered so fails without affecting the other fetcher
+			_, err = devices.GetPMC(ctx)
+			Expect(err).To(HaveOccurred())
+			pmcInfo, err = devices.GetPMCForConfig(ctx, "/env/ptp4l.config")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(pmcInfo.ClockAccuracy).To(Equal("0x21"))
+		})
+	})
+})
 
+var _ = Describe("DiscoverPMCConfigFiles", func() {
+	var response map[string][]byte
+	BeforeEach(func() {
+		response = make(map[string][]byte)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmd += string(line)
+			}
+			return response[cmd], []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	const expectedInput = "echo '<configs>';ls /var/run/ptp4l.*.config 2>/dev/null;echo '</configs>';"
+
+	When("there are several ptp4l instances", func() {
+		It("should return every config ordered by instance", func() {
+			response[expectedInput] = []byte(strings.Join([]string{
+				"<configs>",
+				"/var/run/ptp4l.0.config",
+				"/var/run/ptp4l.1.config",
+				"/var/run/ptp4l.10.config",
+				"/var/run/ptp4l.2.config",
+				"</configs>",
+			}, "\n"))
+			ctx, err := clients.NewContainerContext(testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			configFiles, err := devices.DiscoverPMCConfigFiles(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configFiles).To(Equal([]string{
+				"/var/run/ptp4l.0.config",
+				"/var/run/ptp4l.1.config",
+				"/var/run/ptp4l.2.config",
+				"/var/run/ptp4l.10.config",
+			}))
+		})
+	})
+
+	When("there are no ptp4l instances", func() {
+		It("should return an empty list", func() {
+			response[expectedInput] = []byte("<configs>\n</configs>")
+			ctx, err := clients.NewContainerContext(testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+
+			configFiles, err := devices.DiscoverPMCConfigFiles(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(configFiles).To(BeEmpty())
 		})
 	})
 })
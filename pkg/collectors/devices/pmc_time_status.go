@@ -0,0 +1,129 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+// PMCTimeStatus holds the offset from the master reported by ptp4l in nanoseconds
+type PMCTimeStatus struct {
+	Raw          map[string]string `fetcherKey:"raw"          json:"raw,omitempty"`
+	Timestamp    string            `fetcherKey:"date"         json:"timestamp"`
+	GMIdentity   string            `fetcherKey:"gmIdentity"   json:"gmIdentity"`
+	MasterOffset int64             `fetcherKey:"masterOffset" json:"masterOffset"`
+	IngressTime  int64             `fetcherKey:"ingressTime"  json:"ingressTime"`
+	GMPresent    bool              `fetcherKey:"gmPresent"    json:"gmPresent"`
+}
+
+// GetAnalyserFormat returns the json expected by the analysers
+func (timeStatus *PMCTimeStatus) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   PMCTimeStatusID,
+		Data: timeStatus,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+var (
+	pmcTimeStatusFetchers = newFetcherCache()
+	pmcTimeStatusRegEx    = regexp.MustCompile(
+		`\smaster_offset\s+(-?\d+)\n` +
+			`\s*ingress_time\s+(-?\d+)\n` +
+			`(?s:.*?)` +
+			`\s*gmPresent\s+(true|false)\n` +
+			`\s*gmIdentity\s+(\S+)`,
+	// sending: GET TIME_STATUS_NP
+	// 	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT TIME_STATUS_NP
+	// 		master_offset              -12
+	// 		ingress_time               1686916187058400000
+	// 		cumulativeScaledRateOffset +0.000000000
+	// 		scaledLastGmPhaseChange    0
+	// 		gmTimeBaseIndicator        0
+	// 		lastGmPhaseChange          0x0000'0000000000000000.0000
+	// 		gmPresent                  true
+	// 		gmIdentity                 507c6f.fffe.30fbe8
+	)
+)
+
+// BuildPMCTimeStatusFetcher builds the fetcher which runs pmc against configFile,
+// each config file has its own fetcher so they can be used at the same time
+func BuildPMCTimeStatusFetcher(configFile string) (*fetcher.Fetcher, error) {
+	fetcherInst := fetcher.NewFetcher()
+	fetcherInst.SetPostProcessor(processPMCTimeStatus)
+	fetcherInst.AddCommand(getDateCommand())
+	err := fetcherInst.AddNewCommand(
+		"TimeStatus",
+		fmt.Sprintf("pmc -u -f %s  'GET TIME_STATUS_NP'", configFile),
+		true,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to setup PMC time status fetcher %w", err)
+	}
+	pmcTimeStatusFetchers.set(configFile, fetcherInst)
+	return fetcherInst, nil
+}
+
+// getPMCTimeStatusFetcher returns the fetcher for configFile building it if needed
+func getPMCTimeStatusFetcher(configFile string) (*fetcher.Fetcher, error) {
+	if fetcherInst, ok := pmcTimeStatusFetchers.get(configFile); ok {
+		return fetcherInst, nil
+	}
+	return BuildPMCTimeStatusFetcher(configFile)
+}
+
+func init() {
+	_, err := BuildPMCTimeStatusFetcher(DefaultPMCConfigFile)
+	if err != nil {
+		panic(err)
+	}
+}
+
+func processPMCTimeStatus(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+	match := pmcTimeStatusRegEx.FindStringSubmatch(result["TimeStatus"])
+	if len(match) == 0 {
+		return processedResult, fmt.Errorf("unable to parse pmc TIME_STATUS_NP output: %s", result["TimeStatus"])
+	}
+	masterOffset, err := strconv.ParseInt(match[1], 10, 64)
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to parse master_offset %s: %w", match[1], err)
+	}
+	ingressTime, err := strconv.ParseInt(match[2], 10, 64)
+	if err != nil {
+		return processedResult, fmt.Errorf("failed to parse ingress_time %s: %w", match[2], err)
+	}
+	processedResult["masterOffset"] = masterOffset
+	processedResult["ingressTime"] = ingressTime
+	processedResult["gmPresent"] = match[3] == "true"
+	processedResult["gmIdentity"] = match[4]
+	return processedResult, nil
+}
+
+// GetPMCTimeStatus returns the PMCTimeStatus using the DefaultPMCConfigFile
+func GetPMCTimeStatus(ctx clients.ExecContext) (PMCTimeStatus, error) {
+	return GetPMCTimeStatusForConfig(ctx, DefaultPMCConfigFile)
+}
+
+// GetPMCTimeStatusForConfig returns the PMCTimeStatus from the ptp4l instance using configFile
+func GetPMCTimeStatusForConfig(ctx clients.ExecContext, configFile string) (PMCTimeStatus, error) {
+	timeStatus := PMCTimeStatus{}
+	fetcherInst, err := getPMCTimeStatusFetcher(configFile)
+	if err != nil {
+		return timeStatus, err
+	}
+	err = fetcherInst.Fetch(ctx, &timeStatus)
+	if err != nil {
+		log.Debugf("failed to fetch time status %s", err.Error())
+		return timeStatus, fmt.Errorf("failed to fetch time status %w", err)
+	}
+	return timeStatus, nil
+}
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+// timeStatusReply returns the output for a pmc TIME_STATUS_NP query
+func timeStatusReply(masterOffset, gmPresent string) []byte {
+	return []byte(strings.Join([]string{
+		"<date>",
+		"1686916187.0584",
+		"</date>",
+		"<TimeStatus>",
+		"sending: GET TIME_STATUS_NP",
+		"	507c6f.fffe.30fbe8-0 seq 0 RESPONSE MANAGEMENT TIME_STATUS_NP",
+		"		master_offset              " + masterOffset,
+		"		ingress_time               1686916187058400000",
+		"		cumulativeScaledRateOffset +0.000000000",
+		"		scaledLastGmPhaseChange    0",
+		"		gmTimeBaseIndicator        0",
+		"		lastGmPhaseChange          0x0000'0000000000000000.0000",
+		"		gmPresent                  " + gmPresent,
+		"		gmIdentity                 507c6f.fffe.30fbe8",
+		"</TimeStatus>",
+	}, "\n"))
+}
+
+var _ = Describe("GetPMCTimeStatus", func() {
+	var (
+		ctx      clients.ExecContext
+		response map[string][]byte
+	)
+	const expectedInput = "echo '<date>';date +%s.%N;echo '</date>';" +
+		"echo '<TimeStatus>';pmc -u -f /var/run/ptp4l.0.config  'GET TIME_STATUS_NP';echo '</TimeStatus>';"
+
+	BeforeEach(func() { //nolint:dupl // this is test setup code
+		response = make(map[string][]byte)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmd += string(line)
+			}
+			return response[cmd], []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+		var err error
+		ctx, err = clients.NewContainerContext(
+			testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer",
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	When("the master offset is positive", func() {
+		It("should parse the time status", func() {
+			response[expectedInput] = timeStatusReply("12", "true")
+
+			timeStatus, err := devices.GetPMCTimeStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeStatus.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(timeStatus.MasterOffset).To(Equal(int64(12)))
+			Expect(timeStatus.IngressTime).To(Equal(int64(1686916187058400000)))
+			Expect(timeStatus.GMPresent).To(BeTrue())
+			Expect(timeStatus.GMIdentity).To(Equal("507c6f.fffe.30fbe8"))
+		})
+	})
+	When("the master offset is negative and there is no grand master", func() {
+		It("should parse the time status", func() {
+			response[expectedInput] = timeStatusReply("-1234", "false")
+
+			timeStatus, err := devices.GetPMCTimeStatus(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(timeStatus.MasterOffset).To(Equal(int64(-1234)))
+			Expect(timeStatus.GMPresent).To(BeFalse())
+		})
+	})
+	When("the reply can not be parsed", func() {
+		It("should return an error", func() {
+			response[expectedInput] = timeStatusReply("unknown", "true")
+
+			_, err := devices.GetPMCTimeStatus(ctx)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("unable to parse pmc TIME_STATUS_NP output"))
+		})
+	})
+})
@@ -0,0 +1,74 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+const (
+	PTP4lConfigPath = "/var/run/ptp4l.0.config"
+	phc2sysArgsCmd  = "ps -C phc2sys -o args="
+)
+
+type PTPConfig struct {
+	Timestamp   string `fetcherKey:"date"        json:"timestamp"`
+	PTP4lConfig string `fetcherKey:"ptp4lConfig" json:"ptp4lConfig"`
+	Phc2sysArgs string `fetcherKey:"phc2sysArgs" json:"phc2sysArgs"`
+}
+
+// GetAnalyserFormat returns the json expected by the analysers
+func (ptpConfig *PTPConfig) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   PTPConfigID,
+		Data: ptpConfig,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+var (
+	ptpConfigFetcher *fetcher.Fetcher
+)
+
+func init() {
+	ptpConfigFetcher = fetcher.NewFetcher()
+	ptpConfigFetcher.SetPostProcessor(processPTPConfig)
+	ptpConfigFetcher.AddCommand(getDateCommand())
+	err := ptpConfigFetcher.AddNewCommand("ptp4lConfig", "cat "+PTP4lConfigPath, false)
+	if err != nil {
+		panic(fmt.Errorf("failed to setup ptp config fetcher %w", err))
+	}
+	err = ptpConfigFetcher.AddNewCommand("phc2sysArgs", phc2sysArgsCmd, true)
+	if err != nil {
+		panic(fmt.Errorf("failed to setup ptp config fetcher %w", err))
+	}
+}
+
+func normaliseLineEndings(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "\r\n", "\n"), "\r", "\n")
+}
+
+func processPTPConfig(result map[string]string) (map[string]any, error) {
+	processedResult := make(map[string]any)
+	processedResult["ptp4lConfig"] = normaliseLineEndings(result["ptp4lConfig"])
+	processedResult["phc2sysArgs"] = normaliseLineEndings(result["phc2sysArgs"])
+	return processedResult, nil
+}
+
+// GetPTPConfig returns the config the ptp4l and phc2sys daemons are running with
+func GetPTPConfig(ctx clients.ExecContext) (PTPConfig, error) {
+	ptpConfig := PTPConfig{}
+	err := ptpConfigFetcher.Fetch(ctx, &ptpConfig)
+	if err != nil {
+		log.Debugf("failed to fetch ptpConfig %s", err.Error())
+		return ptpConfig, fmt.Errorf("failed to fetch ptpConfig %w", err)
+	}
+	return ptpConfig, nil
+}
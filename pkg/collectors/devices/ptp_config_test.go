@@ -0,0 +1,75 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"bufio"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var _ = Describe("GetPTPConfig", func() {
+	var clientset *clients.Clientset
+	var response map[string][]byte
+	BeforeEach(func() { //nolint:dupl // this is test setup code
+		clientset = testutils.GetMockedClientSet(testPod)
+		response = make(map[string][]byte)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			reader := bufio.NewReader(options.Stdin)
+			cmd := ""
+			keepReading := true
+			for keepReading {
+				line, prefix, _ := reader.ReadLine()
+				keepReading = prefix
+				cmd += string(line)
+			}
+			return response[cmd], []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	})
+
+	When("called GetPTPConfig", func() {
+		It("should read the daemon config and normalise the line endings", func() {
+			expectedInput := "echo '<date>';date +%s.%N;echo '</date>';"
+			expectedInput += "echo '<ptp4lConfig>';cat /var/run/ptp4l.0.config;echo '</ptp4lConfig>';"
+			expectedInput += "echo '<phc2sysArgs>';ps -C phc2sys -o args=;echo '</phc2sysArgs>';"
+
+			expectedOutput := strings.Join([]string{
+				"<date>",
+				"1686916187.0584",
+				"</date>",
+				"<ptp4lConfig>",
+				"[global]\r",
+				"domainNumber 24\r",
+				"[ens7f0]\r",
+				"masterOnly 1",
+				"</ptp4lConfig>",
+				"<phc2sysArgs>",
+				"phc2sys -a -r -r -n 24",
+				"</phc2sysArgs>",
+			}, "\n")
+			response[expectedInput] = []byte(expectedOutput)
+
+			ctx, err := clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+			Expect(err).NotTo(HaveOccurred())
+			config, err := devices.GetPTPConfig(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(config.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(config.PTP4lConfig).To(Equal("[global]\ndomainNumber 24\n[ens7f0]\nmasterOnly 1"))
+			Expect(config.Phc2sysArgs).To(Equal("phc2sys -a -r -r -n 24"))
+
+			messages, err := config.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages[0].ID).To(Equal("ptp/config"))
+		})
+	})
+})
@@ -0,0 +1,148 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"sort"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+)
+
+const (
+	// written instead of the value when an attribute can not be read
+	sysfsAttributeUnreadable = "sysfs-attribute-unreadable"
+	// sysfsKeyPrefix keeps the fetcher keys of the attributes apart from the date
+	sysfsKeyPrefix = "sysfs-"
+)
+
+var (
+	// The name is used in the fetcher key so it is limited to characters which are not special in a regex
+	sysfsNameRegex = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9_-]*$`)
+	// The path is run by the shell so it is limited to characters which do not need quoting
+	sysfsPathRegex = regexp.MustCompile(`^/sys/[A-Za-z0-9_.:@+/-]+$`)
+)
+
+// SysfsAttributes holds the values of the sysfs attributes configured by the user keyed by their name,
+// the attributes which could not be read are omitted from Values and the reason is held in Errors
+type SysfsAttributes struct {
+	Raw       map[string]string `fetcherKey:"raw"    json:"raw,omitempty"`
+	Values    map[string]string `fetcherKey:"values" json:"values"`
+	Errors    map[string]string `fetcherKey:"errors" json:"errors,omitempty"`
+	Timestamp string            `fetcherKey:"date"   json:"timestamp"`
+}
+
+// GetAnalyserFormat returns the json expected by the analysers
+func (attributes *SysfsAttributes) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   SysfsAttributesID,
+		Data: attributes,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+// ParseSysfsAttributes converts name=/sys/path specs into a map of the path keyed by name,
+// the paths must be clean absolute paths under /sys
+func ParseSysfsAttributes(specs []string) (map[string]string, error) {
+	attributes := make(map[string]string, len(specs))
+	for _, spec := range specs {
+		name, attributePath, found := strings.Cut(spec, "=")
+		if !found {
+			return attributes, fmt.Errorf("sysfs attribute %q is not in the form name=/sys/path", spec)
+		}
+		if !sysfsNameRegex.MatchString(name) {
+			return attributes, fmt.Errorf(
+				"sysfs attribute %q has an invalid name, it must start with a letter "+
+					"and only contain letters, digits, '_' and '-'", spec,
+			)
+		}
+		if !sysfsPathRegex.MatchString(attributePath) || path.Clean(attributePath) != attributePath {
+			return attributes, fmt.Errorf("sysfs attribute %q must have a clean path starting with /sys/", spec)
+		}
+		if _, ok := attributes[name]; ok {
+			return attributes, fmt.Errorf("sysfs attribute name %s is given more than once", name)
+		}
+		attributes[name] = attributePath
+	}
+	return attributes, nil
+}
+
+// newSysfsPostProcessor returns a processor which collects the value of each attribute
+// into values or the reason it could not be read into errors
+func newSysfsPostProcessor(attributes map[string]string) fetcher.PostProcessFuncType {
+	return func(result map[string]string) (map[string]any, error) {
+		values := make(map[string]string, len(attributes))
+		errs := make(map[string]string)
+		for name, attributePath := range attributes {
+			value := result[sysfsKeyPrefix+name]
+			if value == sysfsAttributeUnreadable {
+				errs[name] = fmt.Sprintf("unable to read %s", attributePath)
+				continue
+			}
+			values[name] = value
+		}
+		processedResult := map[string]any{"values": values}
+		if len(errs) > 0 {
+			processedResult["errors"] = errs
+		}
+		return processedResult, nil
+	}
+}
+
+func newSysfsFetcher(attributes map[string]string) (*fetcher.Fetcher, error) {
+	names := make([]string, 0, len(attributes))
+	for name := range attributes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	commands := make([]fetcher.AddCommandArgs, 0, len(names))
+	for _, name := range names {
+		commands = append(commands, fetcher.AddCommandArgs{
+			Key:     sysfsKeyPrefix + name,
+			Command: fmt.Sprintf("cat %s 2>/dev/null || echo %s", attributes[name], sysfsAttributeUnreadable),
+			Trim:    true,
+		})
+	}
+	fetcherInst, err := fetcher.FetcherFactory([]*clients.Cmd{dateCmd}, commands)
+	if err != nil {
+		log.Errorf("failed to create fetcher for sysfs attributes: %s", err.Error())
+		return nil, fmt.Errorf("failed to create fetcher for sysfs attributes: %w", err)
+	}
+	fetcherInst.SetPostProcessor(newSysfsPostProcessor(attributes))
+	return fetcherInst, nil
+}
+
+// NewSysfsAttributesGetter returns a function which reads the attributes, which are keyed by name,
+// if some attributes could not be read the others are returned along with an error naming them
+func NewSysfsAttributesGetter(
+	attributes map[string]string,
+) (func(clients.ExecContext) (SysfsAttributes, error), error) {
+	fetcherInst, err := newSysfsFetcher(attributes)
+	if err != nil {
+		return nil, err
+	}
+	return func(ctx clients.ExecContext) (SysfsAttributes, error) {
+		sysfsAttributes := SysfsAttributes{}
+		err := fetcherInst.Fetch(ctx, &sysfsAttributes)
+		if err != nil {
+			log.Debugf("failed to fetch sysfs attributes %s", err.Error())
+			return sysfsAttributes, fmt.Errorf("failed to fetch sysfs attributes %w", err)
+		}
+		if len(sysfsAttributes.Errors) > 0 {
+			names := make([]string, 0, len(sysfsAttributes.Errors))
+			for name := range sysfsAttributes.Errors {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return sysfsAttributes, fmt.Errorf("failed to read sysfs attributes: %s", strings.Join(names, ", "))
+		}
+		return sysfsAttributes, nil
+	}, nil
+}
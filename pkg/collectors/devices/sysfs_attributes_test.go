@@ -0,0 +1,118 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package devices_test
+
+import (
+	"io"
+	"net/url"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var _ = Describe("ParseSysfsAttributes", func() {
+	It("should return the paths keyed by name", func() {
+		attributes, err := devices.ParseSysfsAttributes([]string{
+			"carrier_changes=/sys/class/net/ens7f0/carrier_changes",
+			"speed=/sys/class/net/ens7f0/speed",
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attributes).To(Equal(map[string]string{
+			"carrier_changes": "/sys/class/net/ens7f0/carrier_changes",
+			"speed":           "/sys/class/net/ens7f0/speed",
+		}))
+	})
+	It("should return an empty map when there are no specs", func() {
+		attributes, err := devices.ParseSysfsAttributes(nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(attributes).To(BeEmpty())
+	})
+	DescribeTable("should reject invalid specs",
+		func(spec, message string) {
+			_, err := devices.ParseSysfsAttributes([]string{spec})
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		Entry("no name", "/sys/class/net/ens7f0/speed", "not in the form name=/sys/path"),
+		Entry("an invalid name", "link.speed=/sys/class/net/ens7f0/speed", "invalid name"),
+		Entry("a path outside of sys", "hosts=/etc/hosts", "starting with /sys/"),
+		Entry("a path escaping sys", "hosts=/sys/../etc/hosts", "starting with /sys/"),
+		Entry("a path with shell characters", "speed=/sys/class/net/ens7f0/speed;reboot", "starting with /sys/"),
+		Entry("a relative path", "speed=sys/class/net/ens7f0/speed", "starting with /sys/"),
+	)
+	It("should reject a name given more than once", func() {
+		_, err := devices.ParseSysfsAttributes([]string{
+			"speed=/sys/class/net/ens7f0/speed",
+			"speed=/sys/class/net/ens7f1/speed",
+		})
+		Expect(err).To(MatchError(ContainSubstring("more than once")))
+	})
+})
+
+var _ = Describe("NewSysfsAttributesGetter", func() {
+	var ctx clients.ExecContext
+	var script string
+	attributes := map[string]string{
+		"carrier_changes": "/sys/class/net/ens7f0/carrier_changes",
+		"speed":           "/sys/class/net/ens7f0/speed",
+	}
+	respondWith := func(output string) {
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			stdin, err := io.ReadAll(options.Stdin)
+			Expect(err).NotTo(HaveOccurred())
+			script = string(stdin)
+			return []byte(output), []byte(""), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+	}
+	BeforeEach(func() {
+		var err error
+		ctx, err = clients.NewContainerContext(
+			testutils.GetMockedClientSet(testPod), "TestNamespace", "Test", "TestContainer",
+		)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	When("the attributes can be read", func() {
+		It("should return the values keyed by name", func() {
+			respondWith(strings.Join([]string{
+				"<date>", "1686916187.0584", "</date>",
+				"<sysfs-carrier_changes>", "4", "</sysfs-carrier_changes>",
+				"<sysfs-speed>", "25000", "</sysfs-speed>",
+			}, "\n"))
+			getSysfsAttributes, err := devices.NewSysfsAttributesGetter(attributes)
+			Expect(err).NotTo(HaveOccurred())
+
+			sysfsAttributes, err := getSysfsAttributes(ctx)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(script).To(ContainSubstring("cat /sys/class/net/ens7f0/carrier_changes"))
+			Expect(script).To(ContainSubstring("cat /sys/class/net/ens7f0/speed"))
+			Expect(sysfsAttributes.Timestamp).To(Equal("2023-06-16T11:49:47.0584Z"))
+			Expect(sysfsAttributes.Values).To(Equal(map[string]string{"carrier_changes": "4", "speed": "25000"}))
+			Expect(sysfsAttributes.Errors).To(BeEmpty())
+		})
+	})
+
+	When("an attribute can not be read", func() {
+		It("should return the others along with an error naming it", func() {
+			respondWith(strings.Join([]string{
+				"<date>", "1686916187.0584", "</date>",
+				"<sysfs-carrier_changes>", "4", "</sysfs-carrier_changes>",
+				"<sysfs-speed>", "sysfs-attribute-unreadable", "</sysfs-speed>",
+			}, "\n"))
+			getSysfsAttributes, err := devices.NewSysfsAttributesGetter(attributes)
+			Expect(err).NotTo(HaveOccurred())
+
+			sysfsAttributes, err := getSysfsAttributes(ctx)
+			Expect(err).To(MatchError(ContainSubstring("failed to read sysfs attributes: speed")))
+			Expect(sysfsAttributes.Values).To(Equal(map[string]string{"carrier_changes": "4"}))
+			Expect(sysfsAttributes.Errors).To(HaveKeyWithValue("speed", "unable to read /sys/class/net/ens7f0/speed"))
+		})
+	})
+})
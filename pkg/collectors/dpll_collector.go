@@ -12,12 +12,31 @@ import (
 )
 
 const (
-	DPLLCollectorName = "DPLL"
+	DPLLCollectorName  = "DPLL"
+	DPLLStateChangeKey = "dpll-state-change"
 )
 
+// dpllStateChanges emits the change in the DPLL states since the previous poll if there is one,
+// it is used in place of the DPLL info by the DPLL collectors when only changes are requested
+func dpllStateChanges(
+	base *baseCollector,
+	tracker *devices.DPLLStateTracker,
+	interfaceName, timestamp, eecState, ppsState string,
+) error {
+	change := tracker.Update(interfaceName, timestamp, eecState, ppsState)
+	if change == nil {
+		return nil
+	}
+	err := base.call(change, DPLLStateChangeKey)
+	if err != nil {
+		return fmt.Errorf("callback failed %w", err)
+	}
+	return nil
+}
+
 // Returns a new DPLLCollector from the CollectionConstuctor Factory
 func NewDPLLCollector(constructor *CollectionConstructor) (Collector, error) {
-	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset)
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
 	if err != nil {
 		return &DPLLNetlinkCollector{}, fmt.Errorf("failed to create DPLLCollector: %w", err)
 	}
@@ -31,5 +50,6 @@ func NewDPLLCollector(constructor *CollectionConstructor) (Collector, error) {
 }
 
 func init() {
-	RegisterCollector(DPLLCollectorName, NewDPLLCollector, optional)
+	RegisterCollector(DPLLCollectorName, NewDPLLCollector, optional, DevInfoCollectorName)
+	RegisterAnalyserIDs(DPLLCollectorName, devices.DPLLTimeErrorID, devices.DPLLStatesID, devices.DPLLStateChangeID)
 }
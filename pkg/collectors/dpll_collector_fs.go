@@ -4,16 +4,22 @@ package collectors
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
+// DPLLFilesystemCollector emits the DPLL info of an interface on each poll,
+// when stateTracker is set only the changes in the DPLL states are emitted instead
 type DPLLFilesystemCollector struct {
 	*baseCollector
 	ctx           clients.ExecContext
+	offsetAverage *utils.EWMA
+	stateTracker  *devices.DPLLStateTracker
 	interfaceName string
 }
 
@@ -33,9 +39,23 @@ func (dpll *DPLLFilesystemCollector) poll() error {
 	dpllInfo, err := devices.GetDevDPLLFilesystemInfo(dpll.ctx, dpll.interfaceName)
 
 	if err != nil {
-		return fmt.Errorf("failed to fetch %s %w", DPLLInfo, err)
+		return fetcher.SetCollector(fmt.Errorf("failed to fetch %s %w", DPLLInfo, err), DPLLFilesystemCollectorName)
 	}
-	err = dpll.callback.Call(&dpllInfo, DPLLInfo)
+	if dpll.stateTracker != nil {
+		return dpllStateChanges(
+			dpll.baseCollector,
+			dpll.stateTracker,
+			dpll.interfaceName,
+			dpllInfo.Timestamp,
+			dpllInfo.EECState,
+			dpllInfo.PPSState,
+		)
+	}
+	if dpll.offsetAverage != nil {
+		smoothed := dpll.offsetAverage.Update(dpllInfo.PPSOffset)
+		dpllInfo.PPSOffsetSmoothed = &smoothed
+	}
+	err = dpll.call(&dpllInfo, DPLLInfo)
 	if err != nil {
 		return fmt.Errorf("callback failed %w", err)
 	}
@@ -45,6 +65,7 @@ func (dpll *DPLLFilesystemCollector) poll() error {
 // Poll collects information from the cluster then
 // calls the callback.Call to allow that to persist it
 func (dpll *DPLLFilesystemCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
 	defer func() {
 		wg.Done()
 	}()
@@ -56,9 +77,16 @@ func (dpll *DPLLFilesystemCollector) Poll(resultsChan chan PollResult, wg *utils
 	resultsChan <- PollResult{
 		CollectorName: DPLLFilesystemCollectorName,
 		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
 	}
 }
 
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (dpll *DPLLFilesystemCollector) PollOnce() (map[string]any, error) {
+	return dpll.pollOnce(dpll.Poll)
+}
+
 // CleanUp stops a running collector
 func (dpll *DPLLFilesystemCollector) CleanUp() error {
 	dpll.running = false
@@ -67,7 +95,7 @@ func (dpll *DPLLFilesystemCollector) CleanUp() error {
 
 // Returns a new DPLLFilesystemCollector from the CollectionConstuctor Factory
 func NewDPLLFilesystemCollector(constructor *CollectionConstructor) (Collector, error) {
-	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset)
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
 	if err != nil {
 		return &DPLLFilesystemCollector{}, fmt.Errorf("failed to create DPLLFilesystemCollector: %w", err)
 	}
@@ -85,5 +113,15 @@ func NewDPLLFilesystemCollector(constructor *CollectionConstructor) (Collector,
 		interfaceName: constructor.PTPInterface,
 		ctx:           ctx,
 	}
+	if constructor.DPLLChangesOnly {
+		collector.stateTracker = devices.NewDPLLStateTracker()
+	}
+	// Each collector reads a single interface so holding the average here keeps it per interface
+	if constructor.DPLLSmoothingAlpha > 0 {
+		collector.offsetAverage, err = utils.NewEWMA(constructor.DPLLSmoothingAlpha)
+		if err != nil {
+			return &DPLLFilesystemCollector{}, fmt.Errorf("failed to create DPLLFilesystemCollector: %w", err)
+		}
+	}
 	return &collector, nil
 }
@@ -4,18 +4,23 @@ package collectors
 
 import (
 	"fmt"
+	"time"
 
 	log "github.com/sirupsen/logrus"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
+// DPLLNetlinkCollector emits the DPLL info of an interface on each poll,
+// when stateTracker is set only the changes in the DPLL states are emitted instead
 type DPLLNetlinkCollector struct {
 	*baseCollector
 	ctx           *clients.ContainerCreationExecContext
+	stateTracker  *devices.DPLLStateTracker
 	interfaceName string
 	clockID       int64
 }
@@ -27,16 +32,33 @@ const (
 
 // Start sets up the collector so it is ready to be polled
 func (dpll *DPLLNetlinkCollector) Start() error {
-	dpll.running = true
 	err := dpll.ctx.CreatePodAndWait()
 	if err != nil {
 		return fmt.Errorf("dpll netlink collector failed to start pod: %w", err)
 	}
+	err = dpll.setup()
+	if err != nil {
+		// The collector will not be cleaned up as it failed to start so remove the pod now
+		deleteErr := dpll.ctx.DeletePodAndWait()
+		if deleteErr != nil {
+			log.Errorf("dpll netlink collector failed to delete pod: %s", deleteErr.Error())
+		}
+		return err
+	}
+	dpll.running = true
+	return nil
+}
+
+// setup finds the clock ID in the created pod and builds the fetcher for it
+func (dpll *DPLLNetlinkCollector) setup() error {
 	log.Debug("dpll.interfaceName: ", dpll.interfaceName)
 	log.Debug("dpll.ctx: ", dpll.ctx)
 	clockIDStuct, err := devices.GetClockID(dpll.ctx, dpll.interfaceName)
 	if err != nil {
-		return fmt.Errorf("dpll netlink collector failed to find clock id: %w", err)
+		return fetcher.SetCollector(
+			fmt.Errorf("dpll netlink collector failed to find clock id: %w", err),
+			DPLLNetlinkCollectorName,
+		)
 	}
 	log.Debug("clockIDStuct.ClockID: ", clockIDStuct.ClockID)
 	err = devices.BuildDPLLNetlinkInfoFetcher(clockIDStuct.ClockID)
@@ -52,9 +74,19 @@ func (dpll *DPLLNetlinkCollector) poll() error {
 	dpllInfo, err := devices.GetDevDPLLNetlinkInfo(dpll.ctx, dpll.clockID)
 
 	if err != nil {
-		return fmt.Errorf("failed to fetch %s %w", DPLLNetlinkInfo, err)
+		return fetcher.SetCollector(fmt.Errorf("failed to fetch %s %w", DPLLNetlinkInfo, err), DPLLNetlinkCollectorName)
 	}
-	err = dpll.callback.Call(&dpllInfo, DPLLNetlinkInfo)
+	if dpll.stateTracker != nil {
+		return dpllStateChanges(
+			dpll.baseCollector,
+			dpll.stateTracker,
+			dpll.interfaceName,
+			dpllInfo.Timestamp,
+			dpllInfo.EECState,
+			dpllInfo.PPSState,
+		)
+	}
+	err = dpll.call(&dpllInfo, DPLLNetlinkInfo)
 	if err != nil {
 		return fmt.Errorf("callback failed %w", err)
 	}
@@ -64,6 +96,7 @@ func (dpll *DPLLNetlinkCollector) poll() error {
 // Poll collects information from the cluster then
 // calls the callback.Call to allow that to persist it
 func (dpll *DPLLNetlinkCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
 	defer func() {
 		wg.Done()
 	}()
@@ -75,9 +108,16 @@ func (dpll *DPLLNetlinkCollector) Poll(resultsChan chan PollResult, wg *utils.Wa
 	resultsChan <- PollResult{
 		CollectorName: DPLLNetlinkCollectorName,
 		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
 	}
 }
 
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (dpll *DPLLNetlinkCollector) PollOnce() (map[string]any, error) {
+	return dpll.pollOnce(dpll.Poll)
+}
+
 // CleanUp stops a running collector
 func (dpll *DPLLNetlinkCollector) CleanUp() error {
 	dpll.running = false
@@ -90,7 +130,12 @@ func (dpll *DPLLNetlinkCollector) CleanUp() error {
 
 // Returns a new DPLLNetlinkCollector from the CollectionConstuctor Factory
 func NewDPLLNetlinkCollector(constructor *CollectionConstructor) (Collector, error) {
-	ctx, err := contexts.GetNetlinkContext(constructor.Clientset)
+	ctx, err := contexts.GetNetlinkContext(
+		constructor.Clientset,
+		constructor.NodeName,
+		constructor.PTPInterface,
+		constructor.PodOptions,
+	)
 	if err != nil {
 		return &DPLLNetlinkCollector{}, fmt.Errorf("failed to create DPLLNetlinkCollector: %w", err)
 	}
@@ -104,6 +149,15 @@ func NewDPLLNetlinkCollector(constructor *CollectionConstructor) (Collector, err
 		interfaceName: constructor.PTPInterface,
 		ctx:           ctx,
 	}
-
+	if constructor.DPLLChangesOnly {
+		collector.stateTracker = devices.NewDPLLStateTracker()
+	}
+	// The netlink DPLL info only has the states so there is no offset to smooth
+	if constructor.DPLLSmoothingAlpha > 0 {
+		log.Warnf(
+			"DPLL offset smoothing is not applied to %s as its DPLL info is read over netlink which has no offset",
+			constructor.PTPInterface,
+		)
+	}
 	return &collector, nil
 }
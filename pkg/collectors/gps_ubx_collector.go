@@ -3,40 +3,105 @@
 package collectors //nolint:dupl // new collector
 
 import (
+	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
 var (
 	GPSCollectorName = "GNSS"
 	gpsNavKey        = "gpsNav"
+	gpsEventKey      = "gnss-event"
 )
 
 type GPSCollector struct {
 	*baseCollector
-	ctx           clients.ExecContext
-	interfaceName string
+	ctx              clients.ExecContext
+	stateTracker     *devices.GPSStateTracker
+	getGPSNav        func(clients.ExecContext) (devices.GPSDetails, error)
+	missingSections  map[string]bool
+	interfaceName    string
+	expectedRFBlocks int
+	strictRFBlocks   bool
+	strictParse      bool
 }
 
 func (gps *GPSCollector) poll() error {
-	gpsNav, err := devices.GetGPSNav(gps.ctx)
+	gpsNav, err := gps.getGPSNav(gps.ctx)
 	if err != nil {
-		return fmt.Errorf("failed to fetch  %s %w", gpsNavKey, err)
+		return fetcher.SetCollector(fmt.Errorf("failed to fetch  %s %w", gpsNavKey, err), GPSCollectorName)
+	}
+	gps.logMissingSections(&gpsNav)
+	if gps.strictParse && len(gpsNav.SectionErrors) > 0 {
+		return sectionsParseError(&gpsNav)
 	}
-	err = gps.callback.Call(&gpsNav, gpsNavKey)
+	err = gps.call(&gpsNav, gpsNavKey)
 	if err != nil {
 		return fmt.Errorf("callback failed %w", err)
 	}
+	for _, event := range gps.stateTracker.Update(&gpsNav) {
+		err = gps.call(event, gpsEventKey)
+		if err != nil {
+			return fmt.Errorf("callback failed %w", err)
+		}
+	}
+	err = gpsNav.CheckRFBlocks(gps.expectedRFBlocks)
+	if err != nil {
+		if gps.strictRFBlocks {
+			return err
+		}
+		log.Warningf("GNSS collector: %s", err.Error())
+	}
 	return nil
 }
 
+// sectionsParseError returns a FetchError naming the sections which failed to parse
+// so that the partial data is treated as a parse failure in strict mode
+func sectionsParseError(gpsNav *devices.GPSDetails) error {
+	sections := make([]string, 0, len(gpsNav.SectionErrors))
+	for section := range gpsNav.SectionErrors {
+		sections = append(sections, section)
+	}
+	sort.Strings(sections)
+	return &fetcher.FetchError{
+		Err:       fmt.Errorf("failed to parse %s", strings.Join(sections, ", ")),
+		Stage:     fetcher.PostProcessStage,
+		Collector: GPSCollectorName,
+		Key:       gpsNavKey,
+	}
+}
+
+// logMissingSections warns when a section starts or stops failing to parse
+// rather than on every poll
+func (gps *GPSCollector) logMissingSections(gpsNav *devices.GPSDetails) {
+	for section, sectionErr := range gpsNav.SectionErrors {
+		if !gps.missingSections[section] {
+			log.Warningf("GNSS collector failed to parse %s, reporting the other sections: %s", section, sectionErr)
+			gps.missingSections[section] = true
+		}
+	}
+	for section := range gps.missingSections {
+		if gpsNav.HasSection(section) {
+			log.Infof("GNSS collector parsed %s again", section)
+			delete(gps.missingSections, section)
+		}
+	}
+}
+
 // Poll collects information from the cluster then
 // calls the callback.Call to allow that to persist it
 func (gps *GPSCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
 	defer func() {
 		wg.Done()
 	}()
@@ -49,29 +114,103 @@ func (gps *GPSCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCo
 	resultsChan <- PollResult{
 		CollectorName: GPSCollectorName,
 		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
+	}
+}
+
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (gps *GPSCollector) PollOnce() (map[string]any, error) {
+	return gps.pollOnce(gps.Poll)
+}
+
+// newGPSNavGetter returns the getter for the GNSS source, checking that
+// the commands the source needs are available in the container
+func newGPSNavGetter(
+	ctx clients.ExecContext,
+	constructor *CollectionConstructor,
+) (func(clients.ExecContext) (devices.GPSDetails, error), error) {
+	var getGPSNav func(clients.ExecContext) (devices.GPSDetails, error)
+	var err error
+	switch constructor.GNSSSource {
+	case "", devices.GPSSourceUBXTool:
+		err = devices.CheckUBXTool(ctx)
+		if errors.Is(err, devices.ErrUBXToolNotFound) {
+			return nil, utils.NewRequirementsNotMetError(
+				fmt.Errorf(
+					"GNSS collector: %w, install it in the container or use --gnss-source %s",
+					err, devices.GPSSourceGPSD,
+				),
+			)
+		}
+		if err != nil {
+			log.Warningf("could not check for ubxtool: %s", err.Error())
+		}
+		getGPSNav = devices.GetGPSNav
+		if len(constructor.GNSSExtraMessages) > 0 {
+			getGPSNav, err = devices.NewGPSNavGetter(constructor.GNSSExtraMessages)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create GPSCollector: %w", err)
+			}
+		}
+	case devices.GPSSourceGPSD:
+		getGPSNav, err = devices.NewGPSDNavGetter(ctx, !constructor.NoCoreutils)
+		if errors.Is(err, devices.ErrGPSDBashNotFound) {
+			return nil, utils.NewRequirementsNotMetError(
+				fmt.Errorf(
+					"GNSS collector: %w, install it in the container or use --gnss-source %s",
+					err, devices.GPSSourceUBXTool,
+				),
+			)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GPSCollector: %w", err)
+		}
+	default:
+		return nil, fmt.Errorf("unknown GNSS source %s", constructor.GNSSSource)
 	}
+	return getGPSNav, nil
 }
 
 // Returns a new GPSCollector based on values in the CollectionConstructor
 func NewGPSCollector(constructor *CollectionConstructor) (Collector, error) {
-	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset)
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
 	if err != nil {
 		return &GPSCollector{}, fmt.Errorf("failed to create DPLLCollector: %w", err)
 	}
 
+	getGPSNav, err := newGPSNavGetter(ctx, constructor)
+	if err != nil {
+		return &GPSCollector{}, err
+	}
+
 	collector := GPSCollector{
 		baseCollector: newBaseCollector(
 			constructor.PollInterval,
 			false,
 			constructor.Callback,
 		),
-		ctx:           ctx,
-		interfaceName: constructor.PTPInterface,
+		ctx:              ctx,
+		stateTracker:     devices.NewGPSStateTracker(),
+		getGPSNav:        getGPSNav,
+		missingSections:  make(map[string]bool),
+		interfaceName:    constructor.PTPInterface,
+		expectedRFBlocks: constructor.ExpectedRFBlocks,
+		strictRFBlocks:   constructor.StrictRFBlocks,
+		strictParse:      constructor.StrictParse,
 	}
 
 	return &collector, nil
 }
 
 func init() {
-	RegisterCollector(GPSCollectorName, NewGPSCollector, optional)
+	RegisterCollector(GPSCollectorName, NewGPSCollector, optional, DevInfoCollectorName)
+	RegisterAnalyserIDs(
+		GPSCollectorName,
+		devices.GNSSTimeErrorID,
+		devices.GNSSRFMonID,
+		devices.GNSSTimePulseID,
+		devices.GNSSUBXMessageID,
+		devices.GNSSEventID,
+	)
 }
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	ICEPinsCollectorName = "ICEPins"
+	ICEPinConfigInfo     = "ice-pin-config"
+)
+
+// ICEPinsCollector announces the clock name and pin config of the interface
+// so the physical wiring of the GNSS and 1PPS signals is known
+type ICEPinsCollector struct {
+	*baseCollector
+	ctx           clients.ExecContext
+	interfaceName string
+}
+
+// polls for the pin config then passes it to the callback,
+// the config is still passed to the callback if only the CGU status could not be read
+func (pins *ICEPinsCollector) poll() []error {
+	errorsToReturn := make([]error, 0)
+	pinConfig, err := devices.GetICEPinConfig(pins.ctx, pins.interfaceName)
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, fetcher.SetCollector(
+			fmt.Errorf("failed to fetch %s %w", ICEPinConfigInfo, err),
+			ICEPinsCollectorName,
+		))
+	}
+	if pinConfig.Timestamp == "" {
+		return errorsToReturn
+	}
+	err = pins.call(&pinConfig, ICEPinConfigInfo)
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, fmt.Errorf("callback failed %w", err))
+	}
+	return errorsToReturn
+}
+
+// Poll collects information from the cluster then
+// calls the callback.Call to allow that to persist it
+func (pins *ICEPinsCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
+	defer func() {
+		wg.Done()
+	}()
+	resultsChan <- PollResult{
+		CollectorName: ICEPinsCollectorName,
+		Errors:        pins.poll(),
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
+	}
+}
+
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (pins *ICEPinsCollector) PollOnce() (map[string]any, error) {
+	return pins.pollOnce(pins.Poll)
+}
+
+// Returns a new ICEPinsCollector from the CollectionConstuctor Factory
+func NewICEPinsCollector(constructor *CollectionConstructor) (Collector, error) {
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
+	if err != nil {
+		return &ICEPinsCollector{}, fmt.Errorf("failed to create ICEPinsCollector: %w", err)
+	}
+	err = devices.BuildICEPinsFetcher(constructor.PTPInterface)
+	if err != nil {
+		return &ICEPinsCollector{}, fmt.Errorf("failed to build fetcher for ICEPinConfig %w", err)
+	}
+
+	collector := ICEPinsCollector{
+		baseCollector: newBaseCollector(
+			constructor.DevInfoAnnouceInterval,
+			true,
+			constructor.Callback,
+		),
+		ctx:           ctx,
+		interfaceName: constructor.PTPInterface,
+	}
+	return &collector, nil
+}
+
+func init() {
+	RegisterCollector(ICEPinsCollectorName, NewICEPinsCollector, optional)
+	RegisterAnalyserIDs(ICEPinsCollectorName, devices.ICEPinConfigID)
+}
@@ -63,6 +63,7 @@ type LogsCollector struct {
 	client             *clients.Clientset
 	sliceQuit          chan os.Signal
 	logsOutputFileName string
+	nodeName           string
 	lastPoll           loglines.GenerationalLockedTime
 	wg                 sync.WaitGroup
 	withTimeStamps     bool
@@ -200,7 +201,11 @@ func processStream(stream io.ReadCloser, expectedEndtime time.Time) ([]*loglines
 }
 
 func (logs *LogsCollector) poll() error {
-	podName, err := logs.client.FindPodNameFromPrefix(contexts.PTPNamespace, contexts.PTPPodNamePrefix)
+	podName, err := logs.client.FindPodNameFromPrefixOnNode(
+		contexts.PTPNamespace,
+		contexts.PTPPodNamePrefix,
+		logs.nodeName,
+	)
 	if err != nil {
 		return fmt.Errorf("failed to poll: %w", err)
 	}
@@ -211,7 +216,7 @@ func (logs *LogsCollector) poll() error {
 		Previous:   false,
 		Timestamps: true,
 	}
-	podLogRequest := logs.client.K8sClient.CoreV1().
+	podLogRequest := logs.client.GetK8sClient().CoreV1().
 		Pods(contexts.PTPNamespace).
 		GetLogs(podName, &podLogOptions).
 		Timeout(followTimeout)
@@ -237,6 +242,7 @@ func (logs *LogsCollector) poll() error {
 
 // Poll collects log lines
 func (logs *LogsCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
 	defer func() {
 		wg.Done()
 	}()
@@ -248,6 +254,8 @@ func (logs *LogsCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroup
 	resultsChan <- PollResult{
 		CollectorName: LogsCollectorName,
 		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
 	}
 }
 
@@ -278,6 +286,7 @@ func NewLogsCollector(constructor *CollectionConstructor) (Collector, error) {
 		lastPoll:           loglines.NewGenerationalLockedTime(time.Now().Add(-time.Second)), // Stop initial since seconds from being 0 as its invalid
 		withTimeStamps:     constructor.IncludeLogTimestamps,
 		logsOutputFileName: constructor.LogsOutputFile,
+		nodeName:           constructor.NodeName,
 		generations: loglines.Generations{
 			Store:  make(map[uint32][]*loglines.LineSlice),
 			Dumper: loglines.NewGenerationDumper(constructor.TempDir, constructor.KeepDebugFiles),
@@ -0,0 +1,111 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	MockCollectorName = "Mock"
+	MockDataKey       = "mock-data"
+	MockDataID        = "mock/data"
+)
+
+// MockData is the synthetic output of the MockCollector,
+// the values only depend on the poll number so are deterministic
+type MockData struct {
+	Timestamp string  `json:"timestamp"`
+	Poll      int64   `json:"poll"`
+	Value     float64 `json:"value"`
+}
+
+func (data *MockData) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	formatted := callbacks.AnalyserFormatType{
+		ID:   MockDataID,
+		Data: data,
+	}
+	return []*callbacks.AnalyserFormatType{&formatted}, nil
+}
+
+// MockCollector emits MockData on each poll without needing a cluster so that the
+// runner and callbacks can be tested end to end. It is not registered by default,
+// use RegisterMockCollector to make it available.
+type MockCollector struct {
+	*baseCollector
+	failPoll  func(poll int64) bool
+	polls     int64
+	cleanedUp int64
+}
+
+// NewMockCollector returns a MockCollector which polls at pollInterval,
+// if failPoll is not nil polls for which it returns true report an error instead of emitting data
+func NewMockCollector(pollInterval time.Duration, failPoll func(poll int64) bool) *MockCollector {
+	return &MockCollector{
+		baseCollector: &baseCollector{pollInterval: pollInterval},
+		failPoll:      failPoll,
+	}
+}
+
+// Poll emits the data for the next poll or an error if one was requested for it
+func (mock *MockCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
+	defer wg.Done()
+	poll := atomic.AddInt64(&mock.polls, 1)
+	errorsToReturn := make([]error, 0)
+	if mock.failPoll != nil && mock.failPoll(poll) {
+		errorsToReturn = append(errorsToReturn, fmt.Errorf("mock collector failed poll %d", poll))
+	} else {
+		data := MockData{
+			Timestamp: utils.Epoch.Add(time.Duration(poll) * mock.pollInterval).Format(time.RFC3339Nano),
+			Poll:      poll,
+			Value:     float64(poll),
+		}
+		err := mock.call(&data, MockDataKey)
+		if err != nil {
+			errorsToReturn = append(errorsToReturn, fmt.Errorf("callback failed %w", err))
+		}
+	}
+	resultsChan <- PollResult{
+		CollectorName: MockCollectorName,
+		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
+	}
+}
+
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (mock *MockCollector) PollOnce() (map[string]any, error) {
+	return mock.pollOnce(mock.Poll)
+}
+
+// CleanUp stops the collector and records that it was cleaned up
+func (mock *MockCollector) CleanUp() error {
+	atomic.AddInt64(&mock.cleanedUp, 1)
+	return mock.baseCollector.CleanUp()
+}
+
+// GetPollCount returns the number of times the collector has been polled
+func (mock *MockCollector) GetPollCount() int64 {
+	return atomic.LoadInt64(&mock.polls)
+}
+
+// GetCleanUpCount returns the number of times the collector has been cleaned up
+func (mock *MockCollector) GetCleanUpCount() int64 {
+	return atomic.LoadInt64(&mock.cleanedUp)
+}
+
+// RegisterMockCollector registers mock as the optional collector named MockCollectorName,
+// it will use the callback from the CollectionConstructor when built
+func RegisterMockCollector(mock *MockCollector) {
+	RegisterCollector(MockCollectorName, func(constructor *CollectionConstructor) (Collector, error) {
+		mock.callback = constructor.Callback
+		return mock, nil
+	}, optional)
+	RegisterAnalyserIDs(MockCollectorName, MockDataID)
+}
@@ -4,10 +4,14 @@ package collectors //nolint:dupl // new collector
 
 import (
 	"fmt"
+	"time"
+
+	log "github.com/sirupsen/logrus"
 
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
@@ -16,55 +20,78 @@ const (
 	PMCInfo          = "pmc-info"
 )
 
+// PMCCollector collects the grandmaster settings from each ptp4l instance,
+// the outputs are tagged with the name of the config file of the instance
 type PMCCollector struct {
 	*baseCollector
-	ctx clients.ExecContext
+	ctx         clients.ExecContext
+	configFiles []string
 }
 
-func (pmc *PMCCollector) poll() error {
-	gmSetting, err := devices.GetPMC(pmc.ctx)
-	if err != nil {
-		return fmt.Errorf("failed to fetch  %s %w", PMCInfo, err)
-	}
-	err = pmc.callback.Call(&gmSetting, PMCInfo)
-	if err != nil {
-		return fmt.Errorf("callback failed %w", err)
+func (pmc *PMCCollector) poll() []error {
+	errorsToReturn := make([]error, 0)
+	for _, configFile := range pmc.configFiles {
+		gmSetting, err := devices.GetPMCForConfig(pmc.ctx, configFile)
+		if err != nil {
+			errorsToReturn = append(errorsToReturn, fetcher.SetCollector(
+				fmt.Errorf("failed to fetch  %s for %s %w", PMCInfo, configFile, err),
+				PMCCollectorName,
+			))
+			continue
+		}
+		err = pmc.call(&gmSetting, PMCInfo)
+		if err != nil {
+			errorsToReturn = append(errorsToReturn, fmt.Errorf("callback failed %w", err))
+		}
 	}
-	return nil
+	return errorsToReturn
 }
 
 // Poll collects information from the cluster then
 // calls the callback.Call to allow that to persist it
 func (pmc *PMCCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
 	defer func() {
 		wg.Done()
 	}()
 
-	errorsToReturn := make([]error, 0)
-	err := pmc.poll()
-	if err != nil {
-		errorsToReturn = append(errorsToReturn, err)
-	}
+	errorsToReturn := pmc.poll()
 	resultsChan <- PollResult{
 		CollectorName: PMCCollectorName,
 		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
 	}
 }
 
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (pmc *PMCCollector) PollOnce() (map[string]any, error) {
+	return pmc.pollOnce(pmc.Poll)
+}
+
 // Returns a new PMCCollector based on values in the CollectionConstructor
 func NewPMCCollector(constructor *CollectionConstructor) (Collector, error) {
-	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset)
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
 	if err != nil {
 		return &PMCCollector{}, fmt.Errorf("failed to create PMCCollector: %w", err)
 	}
 
+	configFiles, err := devices.DiscoverPMCConfigFiles(ctx)
+	if err != nil {
+		log.Warningf("PMC collector: %s, falling back to %s", err.Error(), devices.DefaultPMCConfigFile)
+	}
+	if len(configFiles) == 0 {
+		configFiles = []string{devices.DefaultPMCConfigFile}
+	}
+
 	collector := PMCCollector{
 		baseCollector: newBaseCollector(
 			constructor.PollInterval,
 			false,
 			constructor.Callback,
 		),
-		ctx: ctx,
+		ctx:         ctx,
+		configFiles: configFiles,
 	}
 
 	return &collector, nil
@@ -72,4 +99,5 @@ func NewPMCCollector(constructor *CollectionConstructor) (Collector, error) {
 
 func init() {
 	RegisterCollector(PMCCollectorName, NewPMCCollector, optional)
+	RegisterAnalyserIDs(PMCCollectorName, devices.PMCGMSettingsID)
 }
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors //nolint:dupl // new collector
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	PMCTimeStatusCollectorName = "PMCTimeStatus"
+	PMCTimeStatusInfo          = "pmc-time-status"
+)
+
+// PMCTimeStatusCollector collects the offset from the master reported by ptp4l
+type PMCTimeStatusCollector struct {
+	*baseCollector
+	ctx clients.ExecContext
+}
+
+func (pmc *PMCTimeStatusCollector) poll() error {
+	timeStatus, err := devices.GetPMCTimeStatus(pmc.ctx)
+	if err != nil {
+		return fetcher.SetCollector(
+			fmt.Errorf("failed to fetch  %s %w", PMCTimeStatusInfo, err),
+			PMCTimeStatusCollectorName,
+		)
+	}
+	err = pmc.call(&timeStatus, PMCTimeStatusInfo)
+	if err != nil {
+		return fmt.Errorf("callback failed %w", err)
+	}
+	return nil
+}
+
+// Poll collects information from the cluster then
+// calls the callback.Call to allow that to persist it
+func (pmc *PMCTimeStatusCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
+	defer func() {
+		wg.Done()
+	}()
+
+	errorsToReturn := make([]error, 0)
+	err := pmc.poll()
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, err)
+	}
+	resultsChan <- PollResult{
+		CollectorName: PMCTimeStatusCollectorName,
+		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
+	}
+}
+
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (pmc *PMCTimeStatusCollector) PollOnce() (map[string]any, error) {
+	return pmc.pollOnce(pmc.Poll)
+}
+
+// Returns a new PMCTimeStatusCollector based on values in the CollectionConstructor
+func NewPMCTimeStatusCollector(constructor *CollectionConstructor) (Collector, error) {
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
+	if err != nil {
+		return &PMCTimeStatusCollector{}, fmt.Errorf("failed to create PMCTimeStatusCollector: %w", err)
+	}
+
+	collector := PMCTimeStatusCollector{
+		baseCollector: newBaseCollector(
+			constructor.PollInterval,
+			false,
+			constructor.Callback,
+		),
+		ctx: ctx,
+	}
+
+	return &collector, nil
+}
+
+func init() {
+	RegisterCollector(PMCTimeStatusCollectorName, NewPMCTimeStatusCollector, optional)
+	RegisterAnalyserIDs(PMCTimeStatusCollectorName, devices.PMCTimeStatusID)
+}
@@ -0,0 +1,97 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	PTPConfigCollectorName = "PTPConfig"
+	PTPConfigInfo          = "ptp-config"
+)
+
+// PTPConfigCollector announces the ptp4l and phc2sys config once
+// so that the setup the data was collected with can be reproduced
+type PTPConfigCollector struct {
+	*baseCollector
+	ctx       clients.ExecContext
+	lock      sync.Mutex
+	collected bool
+}
+
+func (ptpConfig *PTPConfigCollector) poll() error {
+	ptpConfig.lock.Lock()
+	defer ptpConfig.lock.Unlock()
+	if ptpConfig.collected {
+		return nil
+	}
+	config, err := devices.GetPTPConfig(ptpConfig.ctx)
+	if err != nil {
+		return fetcher.SetCollector(fmt.Errorf("failed to fetch %s %w", PTPConfigInfo, err), PTPConfigCollectorName)
+	}
+	err = ptpConfig.call(&config, PTPConfigInfo)
+	if err != nil {
+		return fmt.Errorf("callback failed %w", err)
+	}
+	ptpConfig.collected = true
+	return nil
+}
+
+// Poll collects the config from the cluster on the first
+// successful poll then calls the callback.Call to allow that to persist it
+func (ptpConfig *PTPConfigCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
+	defer func() {
+		wg.Done()
+	}()
+
+	errorsToReturn := make([]error, 0)
+	err := ptpConfig.poll()
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, err)
+	}
+	resultsChan <- PollResult{
+		CollectorName: PTPConfigCollectorName,
+		Errors:        errorsToReturn,
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
+	}
+}
+
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (ptpConfig *PTPConfigCollector) PollOnce() (map[string]any, error) {
+	return ptpConfig.pollOnce(ptpConfig.Poll)
+}
+
+// Returns a new PTPConfigCollector based on values in the CollectionConstructor
+func NewPTPConfigCollector(constructor *CollectionConstructor) (Collector, error) {
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
+	if err != nil {
+		return &PTPConfigCollector{}, fmt.Errorf("failed to create PTPConfigCollector: %w", err)
+	}
+
+	collector := PTPConfigCollector{
+		baseCollector: newBaseCollector(
+			constructor.DevInfoAnnouceInterval,
+			true,
+			constructor.Callback,
+		),
+		ctx: ctx,
+	}
+
+	return &collector, nil
+}
+
+func init() {
+	RegisterCollector(PTPConfigCollectorName, NewPTPConfigCollector, optional)
+	RegisterAnalyserIDs(PTPConfigCollectorName, devices.PTPConfigID)
+}
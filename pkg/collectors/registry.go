@@ -5,6 +5,7 @@ package collectors
 import (
 	"fmt"
 	"log"
+	"strings"
 )
 
 type collectonBuilderFunc func(*CollectionConstructor) (Collector, error)
@@ -16,9 +17,11 @@ const (
 )
 
 type CollectorRegistry struct {
-	registry map[string]collectonBuilderFunc
-	required []string
-	optional []string
+	registry     map[string]collectonBuilderFunc
+	dependencies map[string][]string
+	analyserIDs  map[string][]string
+	required     []string
+	optional     []string
 }
 
 var registry *CollectorRegistry
@@ -31,8 +34,15 @@ func (reg *CollectorRegistry) register(
 	collectorName string,
 	builderFunc collectonBuilderFunc,
 	inclusionType collectorInclusionType,
+	dependsOn []string,
 ) {
+	_, alreadyRegistered := reg.registry[collectorName]
 	reg.registry[collectorName] = builderFunc
+	reg.dependencies[collectorName] = dependsOn
+	if alreadyRegistered {
+		// Registering again replaces the builder without listing the name twice
+		return
+	}
 	switch inclusionType {
 	case required:
 		reg.required = append(reg.required, collectorName)
@@ -59,13 +69,91 @@ func (reg *CollectorRegistry) GetOptionalNames() []string {
 	return reg.optional
 }
 
-func RegisterCollector(collectorName string, builderFunc collectonBuilderFunc, inclusionType collectorInclusionType) {
+// GetDependencies returns the names of the collectors which must be
+// constructed and started before the named collector
+func (reg *CollectorRegistry) GetDependencies(collectorName string) []string {
+	return reg.dependencies[collectorName]
+}
+
+// GetAnalyserIDs returns the IDs of the analyser messages the named collector can emit
+func (reg *CollectorRegistry) GetAnalyserIDs(collectorName string) []string {
+	return reg.analyserIDs[collectorName]
+}
+
+// ResolveDependencies adds the dependencies of the named collectors
+// and orders them so that each collector comes after its dependencies
+func (reg *CollectorRegistry) ResolveDependencies(collectorNames []string) ([]string, error) {
+	return ResolveDependencies(collectorNames, reg.dependencies)
+}
+
+// ResolveDependencies returns collectorNames along with all of their dependencies
+// ordered so that each name comes after the names it depends on. Where there is
+// no dependency between names the input order is kept. An error is returned
+// if the dependencies contain a cycle.
+func ResolveDependencies(collectorNames []string, dependencies map[string][]string) ([]string, error) {
+	const (
+		visiting = iota + 1
+		visited
+	)
+	state := make(map[string]int)
+	ordered := make([]string, 0, len(collectorNames))
+
+	var visit func(name string, path []string) error
+	visit = func(name string, path []string) error {
+		switch state[name] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("collector dependency cycle: %s -> %s", strings.Join(path, " -> "), name)
+		}
+		state[name] = visiting
+		for _, dependency := range dependencies[name] {
+			err := visit(dependency, append(path, name))
+			if err != nil {
+				return err
+			}
+		}
+		state[name] = visited
+		ordered = append(ordered, name)
+		return nil
+	}
+
+	for _, name := range collectorNames {
+		err := visit(name, []string{})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ordered, nil
+}
+
+// RegisterCollector adds a collector to the registry, dependsOn lists the
+// collectors which will be added to the run and started before this one
+func RegisterCollector(
+	collectorName string,
+	builderFunc collectonBuilderFunc,
+	inclusionType collectorInclusionType,
+	dependsOn ...string,
+) {
+	initRegistry()
+	registry.register(collectorName, builderFunc, inclusionType, dependsOn)
+}
+
+// RegisterAnalyserIDs records the IDs of the analyser messages the named collector can emit
+// so they can be advertised and the output checked for completeness
+func RegisterAnalyserIDs(collectorName string, ids ...string) {
+	initRegistry()
+	registry.analyserIDs[collectorName] = ids
+}
+
+func initRegistry() {
 	if registry == nil {
 		registry = &CollectorRegistry{
-			registry: make(map[string]collectonBuilderFunc, 0),
-			required: make([]string, 0),
-			optional: make([]string, 0),
+			registry:     make(map[string]collectonBuilderFunc, 0),
+			dependencies: make(map[string][]string, 0),
+			analyserIDs:  make(map[string][]string, 0),
+			required:     make([]string, 0),
+			optional:     make([]string, 0),
 		}
 	}
-	registry.register(collectorName, builderFunc, inclusionType)
 }
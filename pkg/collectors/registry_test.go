@@ -0,0 +1,76 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+var _ = Describe("Registry", func() {
+	When("the GNSS collector is resolved", func() {
+		It("should imply the DevInfo collector", func() {
+			registry := collectors.GetRegistry()
+			Expect(registry.GetDependencies(collectors.GPSCollectorName)).To(ContainElement(collectors.DevInfoCollectorName))
+			names, err := registry.ResolveDependencies([]string{collectors.GPSCollectorName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{collectors.DevInfoCollectorName, collectors.GPSCollectorName}))
+		})
+	})
+
+	When("the analyser ids of the GNSS collector are listed", func() {
+		It("should return every id the GPSDetails and events can be formatted as", func() {
+			registry := collectors.GetRegistry()
+			Expect(registry.GetAnalyserIDs(collectors.GPSCollectorName)).To(ConsistOf(
+				"gnss/time-error",
+				"gnss/rf-mon",
+				"gnss/time-pulse",
+				"gnss/ubx-message",
+				"gnss/event",
+			))
+		})
+		It("should cover the ids of a formatted GPSDetails", func() {
+			gpsNav := &devices.GPSDetails{
+				AntennaDetails: []*devices.GPSAntennaDetails{{}},
+				TimePulse:      &devices.GPSTimePulse{},
+				ExtraMessages:  map[string]*devices.UBXMessage{"MON-HW": {}},
+			}
+			messages, err := gpsNav.GetAnalyserFormat()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(messages).To(HaveLen(4))
+			for _, message := range messages {
+				Expect(collectors.GetRegistry().GetAnalyserIDs(collectors.GPSCollectorName)).To(ContainElement(message.ID))
+			}
+		})
+	})
+
+	When("dependencies are resolved", func() {
+		It("should order every collector after its dependencies", func() {
+			dependencies := map[string][]string{
+				"c": {"b"},
+				"b": {"a"},
+				"d": {"a"},
+			}
+			names, err := collectors.ResolveDependencies([]string{"d", "c"}, dependencies)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"a", "d", "b", "c"}))
+		})
+		It("should not repeat names which are already present", func() {
+			dependencies := map[string][]string{"b": {"a"}}
+			names, err := collectors.ResolveDependencies([]string{"a", "b", "a"}, dependencies)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal([]string{"a", "b"}))
+		})
+		It("should return an error for a cycle", func() {
+			dependencies := map[string][]string{
+				"a": {"b"},
+				"b": {"a"},
+			}
+			_, err := collectors.ResolveDependencies([]string{"a"}, dependencies)
+			Expect(err).To(MatchError(ContainSubstring("a -> b -> a")))
+		})
+	})
+})
@@ -0,0 +1,102 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package collectors
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	SysfsCollectorName  = "Sysfs"
+	SysfsAttributesInfo = "sysfs-attributes"
+)
+
+// SysfsCollector outputs the sysfs attributes given by the user on each poll
+// so new kernel attributes can be captured without code changes
+type SysfsCollector struct {
+	*baseCollector
+	ctx                clients.ExecContext
+	getSysfsAttributes func(clients.ExecContext) (devices.SysfsAttributes, error)
+}
+
+// polls for the attributes then passes them to the callback,
+// the attributes are still passed to the callback if only some of them could not be read
+func (sysfs *SysfsCollector) poll() []error {
+	errorsToReturn := make([]error, 0)
+	attributes, err := sysfs.getSysfsAttributes(sysfs.ctx)
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, fetcher.SetCollector(
+			fmt.Errorf("failed to fetch %s %w", SysfsAttributesInfo, err),
+			SysfsCollectorName,
+		))
+	}
+	if attributes.Timestamp == "" {
+		return errorsToReturn
+	}
+	err = sysfs.call(&attributes, SysfsAttributesInfo)
+	if err != nil {
+		errorsToReturn = append(errorsToReturn, fmt.Errorf("callback failed %w", err))
+	}
+	return errorsToReturn
+}
+
+// Poll collects information from the cluster then
+// calls the callback.Call to allow that to persist it
+func (sysfs *SysfsCollector) Poll(resultsChan chan PollResult, wg *utils.WaitGroupCount) {
+	startedAt := time.Now()
+	defer func() {
+		wg.Done()
+	}()
+	resultsChan <- PollResult{
+		CollectorName: SysfsCollectorName,
+		Errors:        sysfs.poll(),
+		StartedAt:     startedAt,
+		Duration:      time.Since(startedAt),
+	}
+}
+
+// PollOnce polls synchronously returning the outputs keyed by tag rather than calling the callback
+func (sysfs *SysfsCollector) PollOnce() (map[string]any, error) {
+	return sysfs.pollOnce(sysfs.Poll)
+}
+
+// Returns a new SysfsCollector from the CollectionConstuctor Factory
+func NewSysfsCollector(constructor *CollectionConstructor) (Collector, error) {
+	if len(constructor.SysfsAttributes) == 0 {
+		return &SysfsCollector{}, utils.NewMissingInputError(
+			errors.New("the Sysfs collector needs at least one --sysfs-attribute"),
+		)
+	}
+	ctx, err := contexts.GetPTPDaemonContext(constructor.Clientset, constructor.NodeName)
+	if err != nil {
+		return &SysfsCollector{}, fmt.Errorf("failed to create SysfsCollector: %w", err)
+	}
+	getSysfsAttributes, err := devices.NewSysfsAttributesGetter(constructor.SysfsAttributes)
+	if err != nil {
+		return &SysfsCollector{}, fmt.Errorf("failed to create SysfsCollector: %w", err)
+	}
+
+	collector := SysfsCollector{
+		baseCollector: newBaseCollector(
+			constructor.PollInterval,
+			false,
+			constructor.Callback,
+		),
+		ctx:                ctx,
+		getSysfsAttributes: getSysfsAttributes,
+	}
+	return &collector, nil
+}
+
+func init() {
+	RegisterCollector(SysfsCollectorName, NewSysfsCollector, optional)
+	RegisterAnalyserIDs(SysfsCollectorName, devices.SysfsAttributesID)
+}
@@ -0,0 +1,108 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package diff
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/replay"
+)
+
+const maxLineSize = 1 << 24
+
+// record is a single output read back from a capture
+type record struct {
+	output    callbacks.OutputType
+	timestamp time.Time
+	datatype  string
+}
+
+// Capture holds the records read from a file previously written by collect
+type Capture struct {
+	start   time.Time
+	records map[string][]*record
+}
+
+// parseLine reconstructs an output from a line in either the NDJSON or the raw format
+func parseLine(line string) (callbacks.OutputType, string, error) {
+	if strings.HasPrefix(line, "{") {
+		return replay.ParseNDJSONLine(line) //nolint:wrapcheck // the error is wrapped by the caller
+	}
+	return replay.ParseLine(line) //nolint:wrapcheck // the error is wrapped by the caller
+}
+
+// recordTimestamp returns the time the output was collected, outputs without a
+// timestamp or with one which can not be parsed return the zero time
+func recordTimestamp(output callbacks.OutputType) time.Time {
+	var timestamp string
+	switch typed := output.(type) {
+	case *devices.PTPDeviceInfo:
+		timestamp = typed.Timestamp
+	case *devices.DevFilesystemDPLLInfo:
+		timestamp = typed.Timestamp
+	case *devices.DevNetlinkDPLLInfo:
+		timestamp = typed.Timestamp
+	case *devices.GPSDetails:
+		timestamp = typed.NavClock.Timestamp
+	case *devices.PMCInfo:
+		timestamp = typed.Timestamp
+	case *devices.PMCTimeStatus:
+		timestamp = typed.Timestamp
+	case *devices.DeviceSummary:
+		if typed.DeviceInfo != nil {
+			timestamp = typed.DeviceInfo.Timestamp
+		}
+	}
+	parsed, err := time.Parse(time.RFC3339Nano, timestamp)
+	if err != nil {
+		return time.Time{}
+	}
+	return parsed
+}
+
+// ReadCapture reads the lines written by collect in the raw or NDJSON format from input,
+// lines for types which can not be reconstructed are skipped
+func ReadCapture(input io.Reader) (*Capture, error) {
+	capture := &Capture{records: make(map[string][]*record)}
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		output, _, err := parseLine(line)
+		if err != nil {
+			var unknownType *replay.UnknownTypeError
+			if errors.As(err, &unknownType) {
+				log.Debugf("skipping line %d: %s", lineNumber, err.Error())
+				continue
+			}
+			return nil, fmt.Errorf("failed to parse line %d: %w", lineNumber, err)
+		}
+		rec := &record{
+			output:    output,
+			timestamp: recordTimestamp(output),
+			datatype:  fmt.Sprintf("%T", output),
+		}
+		if !rec.timestamp.IsZero() && (capture.start.IsZero() || rec.timestamp.Before(capture.start)) {
+			capture.start = rec.timestamp
+		}
+		capture.records[rec.datatype] = append(capture.records[rec.datatype], rec)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read capture: %w", err)
+	}
+	return capture, nil
+}
@@ -0,0 +1,232 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package diff
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// DefaultWindow is the length of the windows the offsets are compared over
+const DefaultWindow = time.Minute
+
+// FieldDiff is a device info or version field which differs between the captures
+type FieldDiff struct {
+	Datatype string
+	Field    string
+	Baseline string
+	Compared string
+}
+
+// OffsetStats summarises the offsets of a datatype in nanoseconds, Max is the largest absolute offset
+type OffsetStats struct {
+	Count  int
+	Mean   float64
+	Max    float64
+	StdDev float64
+}
+
+// OffsetDiff holds the offset statistics of a datatype from both captures over the same window,
+// the window starts at Start after the first record of each capture and the overall
+// statistics cover the whole of each capture
+type OffsetDiff struct {
+	Datatype string
+	Baseline OffsetStats
+	Compared OffsetStats
+	Start    time.Duration
+	Overall  bool
+}
+
+// Report holds the differences between a baseline capture and a compared capture
+type Report struct {
+	OnlyInBaseline []string
+	OnlyInCompared []string
+	DeviceInfo     []FieldDiff
+	Offsets        []OffsetDiff
+	Window         time.Duration
+}
+
+// infoFields returns the device information and versions held by the output keyed by their json name,
+// outputs which do not hold device information return nil
+func infoFields(output callbacks.OutputType) map[string]string {
+	switch typed := output.(type) {
+	case *devices.PTPDeviceInfo:
+		return deviceInfoFields(typed, "")
+	case *devices.DeviceSummary:
+		fields := make(map[string]string)
+		if typed.DeviceInfo != nil {
+			fields = deviceInfoFields(typed.DeviceInfo, "deviceInfo.")
+		}
+		if typed.GNSS != nil {
+			fields["gnss.firmwareVersion"] = typed.GNSS.FirmwareVersion
+			fields["gnss.protocolVersion"] = typed.GNSS.ProtoVersion
+			fields["gnss.module"] = typed.GNSS.Module
+			fields["gnss.ubxVersion"] = typed.GNSS.UBXVersion
+			fields["gnss.gpsdVersion"] = typed.GNSS.GPSDVersion
+		}
+		return fields
+	}
+	return nil
+}
+
+func deviceInfoFields(devInfo *devices.PTPDeviceInfo, prefix string) map[string]string {
+	return map[string]string{
+		prefix + "vendorId":        devInfo.VendorID,
+		prefix + "deviceInfo":      devInfo.DeviceID,
+		prefix + "GNSSDev":         devInfo.GNSSDev,
+		prefix + "firmwareVersion": devInfo.FirmwareVersion,
+		prefix + "driverVersion":   devInfo.DriverVersion,
+	}
+}
+
+// recordOffset returns the time error held by the output in nanoseconds,
+// the second value is false if the output does not hold one
+func recordOffset(output callbacks.OutputType) (float64, bool) {
+	switch typed := output.(type) {
+	case *devices.DevFilesystemDPLLInfo:
+		return utils.CentinanosecondsToNanoseconds(typed.PPSOffset), true
+	case *devices.GPSDetails:
+		if typed.HasSection(devices.UBXNavClock) {
+			return float64(typed.NavClock.TimeAcc), true
+		}
+	case *devices.PMCTimeStatus:
+		return float64(typed.MasterOffset), true
+	}
+	return 0, false
+}
+
+func calculateStats(offsets []float64) OffsetStats {
+	stats := OffsetStats{Count: len(offsets)}
+	if stats.Count == 0 {
+		return stats
+	}
+	sum := 0.0
+	for _, offset := range offsets {
+		sum += offset
+		stats.Max = math.Max(stats.Max, math.Abs(offset))
+	}
+	stats.Mean = sum / float64(stats.Count)
+	squares := 0.0
+	for _, offset := range offsets {
+		squares += (offset - stats.Mean) * (offset - stats.Mean)
+	}
+	stats.StdDev = math.Sqrt(squares / float64(stats.Count))
+	return stats
+}
+
+// windowedOffsets returns all the offsets of the records along with the offsets
+// grouped by the window they fall in, records without a timestamp are only in the former
+func windowedOffsets(records []*record, start time.Time, window time.Duration) ([]float64, map[int][]float64) {
+	all := make([]float64, 0, len(records))
+	windows := make(map[int][]float64)
+	for _, rec := range records {
+		offset, ok := recordOffset(rec.output)
+		if !ok {
+			continue
+		}
+		all = append(all, offset)
+		if rec.timestamp.IsZero() {
+			continue
+		}
+		index := int(rec.timestamp.Sub(start) / window)
+		windows[index] = append(windows[index], offset)
+	}
+	return all, windows
+}
+
+func (report *Report) compareOffsets(datatype string, baseline, compared *Capture) {
+	baselineAll, baselineWindows := windowedOffsets(baseline.records[datatype], baseline.start, report.Window)
+	comparedAll, comparedWindows := windowedOffsets(compared.records[datatype], compared.start, report.Window)
+	if len(baselineAll) == 0 && len(comparedAll) == 0 {
+		return
+	}
+	report.Offsets = append(report.Offsets, OffsetDiff{
+		Datatype: datatype,
+		Overall:  true,
+		Baseline: calculateStats(baselineAll),
+		Compared: calculateStats(comparedAll),
+	})
+
+	indexes := make([]int, 0, len(baselineWindows))
+	for index := range baselineWindows {
+		indexes = append(indexes, index)
+	}
+	for index := range comparedWindows {
+		if _, ok := baselineWindows[index]; !ok {
+			indexes = append(indexes, index)
+		}
+	}
+	sort.Ints(indexes)
+	for _, index := range indexes {
+		report.Offsets = append(report.Offsets, OffsetDiff{
+			Datatype: datatype,
+			Start:    time.Duration(index) * report.Window,
+			Baseline: calculateStats(baselineWindows[index]),
+			Compared: calculateStats(comparedWindows[index]),
+		})
+	}
+}
+
+// compareInfo compares the device information in the last record of the datatype from each capture
+func (report *Report) compareInfo(datatype string, baseline, compared *Capture) {
+	baselineRecords := baseline.records[datatype]
+	comparedRecords := compared.records[datatype]
+	baselineFields := infoFields(baselineRecords[len(baselineRecords)-1].output)
+	comparedFields := infoFields(comparedRecords[len(comparedRecords)-1].output)
+	if baselineFields == nil && comparedFields == nil {
+		return
+	}
+	names := make([]string, 0, len(baselineFields))
+	for name := range baselineFields {
+		names = append(names, name)
+	}
+	for name := range comparedFields {
+		if _, ok := baselineFields[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if baselineFields[name] != comparedFields[name] {
+			report.DeviceInfo = append(report.DeviceInfo, FieldDiff{
+				Datatype: datatype,
+				Field:    name,
+				Baseline: baselineFields[name],
+				Compared: comparedFields[name],
+			})
+		}
+	}
+}
+
+// Compare returns the differences between the baseline and compared captures. Records are
+// aligned by datatype and by windows of the given length from the start of each capture.
+func Compare(baseline, compared *Capture, window time.Duration) *Report {
+	report := &Report{Window: window}
+	datatypes := make([]string, 0, len(baseline.records))
+	for datatype := range baseline.records {
+		if _, ok := compared.records[datatype]; ok {
+			datatypes = append(datatypes, datatype)
+		} else {
+			report.OnlyInBaseline = append(report.OnlyInBaseline, datatype)
+		}
+	}
+	for datatype := range compared.records {
+		if _, ok := baseline.records[datatype]; !ok {
+			report.OnlyInCompared = append(report.OnlyInCompared, datatype)
+		}
+	}
+	sort.Strings(datatypes)
+	sort.Strings(report.OnlyInBaseline)
+	sort.Strings(report.OnlyInCompared)
+
+	for _, datatype := range datatypes {
+		report.compareInfo(datatype, baseline, compared)
+		report.compareOffsets(datatype, baseline, compared)
+	}
+	return report
+}
@@ -0,0 +1,110 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package diff_test
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/diff"
+)
+
+const dpllDatatype = "*devices.DevFilesystemDPLLInfo"
+
+func readCapture(path string) *diff.Capture {
+	input, err := os.Open(path)
+	Expect(err).NotTo(HaveOccurred())
+	defer input.Close()
+	capture, err := diff.ReadCapture(input)
+	Expect(err).NotTo(HaveOccurred())
+	return capture
+}
+
+var _ = Describe("Compare", func() {
+	var report *diff.Report
+	BeforeEach(func() {
+		report = diff.Compare(
+			readCapture("test_files/baseline.ndjson"),
+			readCapture("test_files/compared.log"),
+			time.Minute,
+		)
+	})
+
+	It("should report the datatypes only in one of the captures", func() {
+		Expect(report.OnlyInBaseline).To(Equal([]string{"*devices.PMCInfo"}))
+		Expect(report.OnlyInCompared).To(Equal([]string{"*devices.PMCTimeStatus"}))
+	})
+	It("should report the device info which differs", func() {
+		Expect(report.DeviceInfo).To(Equal([]diff.FieldDiff{{
+			Datatype: "*devices.PTPDeviceInfo",
+			Field:    "firmwareVersion",
+			Baseline: "4.20 0x8001778b 1.3346.0",
+			Compared: "4.30 0x8001778b 1.3346.0",
+		}}))
+	})
+	It("should compare the offsets overall and in windows from the start of each capture", func() {
+		Expect(report.Offsets).To(Equal([]diff.OffsetDiff{
+			{
+				Datatype: dpllDatatype,
+				Overall:  true,
+				Baseline: diff.OffsetStats{Count: 4, Mean: 0, Max: 4, StdDev: math.Sqrt(10)},
+				Compared: diff.OffsetStats{Count: 4, Mean: 0, Max: 20, StdDev: math.Sqrt(250)},
+			},
+			{
+				Datatype: dpllDatatype,
+				Start:    0,
+				Baseline: diff.OffsetStats{Count: 2, Mean: 0, Max: 2, StdDev: 2},
+				Compared: diff.OffsetStats{Count: 2, Mean: 0, Max: 10, StdDev: 10},
+			},
+			{
+				Datatype: dpllDatatype,
+				Start:    time.Minute,
+				Baseline: diff.OffsetStats{Count: 2, Mean: 0, Max: 4, StdDev: 4},
+				Compared: diff.OffsetStats{Count: 2, Mean: 0, Max: 20, StdDev: 20},
+			},
+		}))
+	})
+	It("should write the differences for the user", func() {
+		output := &bytes.Buffer{}
+		Expect(report.Write(output)).To(Succeed())
+		lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+		Expect(lines).To(ContainElements(
+			"Only in baseline: *devices.PMCInfo",
+			"Only in compared: *devices.PMCTimeStatus",
+			`  *devices.PTPDeviceInfo firmwareVersion: "4.20 0x8001778b 1.3346.0" -> "4.30 0x8001778b 1.3346.0"`,
+			"  *devices.DevFilesystemDPLLInfo +1m0s/1m0s: count 2 -> 2, mean 0.000 -> 0.000 (+0.000), "+
+				"max 4.000 -> 20.000 (+16.000), stddev 4.000 -> 20.000 (+16.000)",
+		))
+	})
+})
+
+var _ = Describe("ReadCapture", func() {
+	When("given a line which is not in either format", func() {
+		It("should return an error", func() {
+			_, err := diff.ReadCapture(strings.NewReader("not a capture\n"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+	When("the captures have nothing in common", func() {
+		It("should report no differences", func() {
+			empty, err := diff.ReadCapture(strings.NewReader(""))
+			Expect(err).NotTo(HaveOccurred())
+			report := diff.Compare(empty, empty, time.Minute)
+			output := &bytes.Buffer{}
+			Expect(report.Write(output)).To(Succeed())
+			Expect(output.String()).To(Equal("Device info: no differences\nOffsets: none in common\n"))
+		})
+	})
+})
+
+func TestDiff(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Diff Suite")
+}
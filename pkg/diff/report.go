@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package diff
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+func formatStat(baseline, compared float64) string {
+	return fmt.Sprintf("%.3f -> %.3f (%+.3f)", baseline, compared, compared-baseline)
+}
+
+func (offsetDiff *OffsetDiff) window(length time.Duration) string {
+	if offsetDiff.Overall {
+		return "overall"
+	}
+	return fmt.Sprintf("+%s/%s", offsetDiff.Start, length)
+}
+
+// Write writes the report in a form to be read by the user
+func (report *Report) Write(w io.Writer) error {
+	var builder strings.Builder
+	if len(report.OnlyInBaseline) > 0 {
+		fmt.Fprintf(&builder, "Only in baseline: %s\n", strings.Join(report.OnlyInBaseline, ", "))
+	}
+	if len(report.OnlyInCompared) > 0 {
+		fmt.Fprintf(&builder, "Only in compared: %s\n", strings.Join(report.OnlyInCompared, ", "))
+	}
+
+	if len(report.DeviceInfo) == 0 {
+		builder.WriteString("Device info: no differences\n")
+	} else {
+		builder.WriteString("Device info:\n")
+	}
+	for _, field := range report.DeviceInfo {
+		fmt.Fprintf(&builder, "  %s %s: %q -> %q\n", field.Datatype, field.Field, field.Baseline, field.Compared)
+	}
+
+	if len(report.Offsets) == 0 {
+		builder.WriteString("Offsets: none in common\n")
+	} else {
+		builder.WriteString("Offsets:\n")
+	}
+	for i := range report.Offsets {
+		offsetDiff := &report.Offsets[i]
+		fmt.Fprintf(
+			&builder, "  %s %s: count %d -> %d, mean %s, max %s, stddev %s\n",
+			offsetDiff.Datatype, offsetDiff.window(report.Window),
+			offsetDiff.Baseline.Count, offsetDiff.Compared.Count,
+			formatStat(offsetDiff.Baseline.Mean, offsetDiff.Compared.Mean),
+			formatStat(offsetDiff.Baseline.Max, offsetDiff.Compared.Max),
+			formatStat(offsetDiff.Baseline.StdDev, offsetDiff.Compared.StdDev),
+		)
+	}
+	_, err := io.WriteString(w, builder.String())
+	if err != nil {
+		return fmt.Errorf("failed to write diff report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,70 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package fetcher
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// FetchStage is the step of a fetch at which an error occurred
+type FetchStage string
+
+const (
+	ExecStage        FetchStage = "exec"
+	ExtractStage     FetchStage = "extract"
+	PostProcessStage FetchStage = "post-process"
+	UnmarshalStage   FetchStage = "unmarshal"
+)
+
+// FetchError carries the context of a failed fetch so that it can be
+// logged uniformly and failures can be classified by their stage
+type FetchError struct {
+	Err       error
+	Stage     FetchStage
+	Collector string
+	Key       string
+	Command   string
+	RawOutput string
+}
+
+func (err *FetchError) Error() string {
+	context := make([]string, 0)
+	if err.Collector != "" {
+		context = append(context, fmt.Sprintf("collector %s", err.Collector))
+	}
+	if err.Key != "" {
+		context = append(context, fmt.Sprintf("key %s", err.Key))
+	}
+	msg := fmt.Sprintf("fetch failed at %s stage", err.Stage)
+	if len(context) > 0 {
+		msg += fmt.Sprintf(" (%s)", strings.Join(context, ", "))
+	}
+	return fmt.Sprintf("%s: %s", msg, err.Err.Error())
+}
+
+func (err *FetchError) Unwrap() error {
+	return err.Err
+}
+
+// SetCollector records the name of the collector on the FetchError wrapped by err.
+// As wrapping errors fixes their message the name is only seen through the FetchError,
+// it returns err so that it can be used when returning the error
+func SetCollector(err error, collectorName string) error {
+	var fetchErr *FetchError
+	if errors.As(err, &fetchErr) {
+		fetchErr.Collector = collectorName
+	}
+	return err
+}
+
+// IsParseError returns true if err wraps a FetchError from after the commands ran,
+// i.e. the output was received but could not be extracted, processed or unpacked
+func IsParseError(err error) bool {
+	var fetchErr *FetchError
+	if !errors.As(err, &fetchErr) {
+		return false
+	}
+	return fetchErr.Stage != ExecStage
+}
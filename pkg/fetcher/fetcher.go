@@ -4,8 +4,10 @@ package fetcher
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"strings"
+	"sync"
 
 	log "github.com/sirupsen/logrus"
 
@@ -14,9 +16,34 @@ import (
 
 type PostProcessFuncType func(map[string]string) (map[string]any, error)
 
+// RawKey is the key the output of each command is passed under when raw output is included,
+// the structs which should carry it have a map[string]string field with this fetcherKey
+const RawKey = "raw"
+
+var (
+	includeRaw     bool
+	includeRawLock sync.RWMutex
+)
+
+// SetIncludeRaw sets whether the output of each command is passed to the fetched struct
+// alongside the parsed values, this is off by default as it greatly increases the output
+func SetIncludeRaw(include bool) {
+	includeRawLock.Lock()
+	defer includeRawLock.Unlock()
+	includeRaw = include
+}
+
+// IncludeRaw returns true if the output of each command is passed to the fetched struct
+func IncludeRaw() bool {
+	includeRawLock.RLock()
+	defer includeRawLock.RUnlock()
+	return includeRaw
+}
+
 type Fetcher struct {
 	cmdGrp        *clients.CmdGroup
 	postProcessor PostProcessFuncType
+	env           map[string]string
 }
 
 func NewFetcher() *Fetcher {
@@ -33,6 +60,15 @@ func (inst *Fetcher) SetPostProcessor(ppFunc PostProcessFuncType) {
 	inst.postProcessor = ppFunc
 }
 
+// SetEnv sets the environment variables the commands are run with
+func (inst *Fetcher) SetEnv(env map[string]string) error {
+	if _, err := clients.CommandWithEnv([]string{}, env); err != nil {
+		return fmt.Errorf("set fetcher env failed %w", err)
+	}
+	inst.env = env
+	return nil
+}
+
 // AddNewCommand creates a new command from a string
 // then adds it to the fetcher
 func (inst *Fetcher) AddNewCommand(key, cmd string, trim bool) error {
@@ -53,9 +89,9 @@ func (inst *Fetcher) AddCommand(cmdInst *clients.Cmd) {
 }
 
 // Fetch executes the commands on the container passed as the ctx and
-// use the results to populate pack
+// use the results to populate pack. Errors are returned as a FetchError
 func (inst *Fetcher) Fetch(ctx clients.ExecContext, pack any) error {
-	runResult, err := runCommands(ctx, inst.cmdGrp)
+	runResult, stdout, err := runCommands(ctx, inst.cmdGrp, inst.env)
 	if err != nil {
 		return err
 	}
@@ -66,24 +102,48 @@ func (inst *Fetcher) Fetch(ctx clients.ExecContext, pack any) error {
 	if inst.postProcessor != nil {
 		updatedResults, ppErr := inst.postProcessor(runResult)
 		if ppErr != nil {
-			return fmt.Errorf("feching failed post process the data %w", ppErr)
+			return &FetchError{
+				Err:       fmt.Errorf("feching failed post process the data %w", ppErr),
+				Stage:     PostProcessStage,
+				Command:   inst.cmdGrp.GetCommand(),
+				RawOutput: stdout,
+			}
 		}
 		for key, value := range updatedResults {
 			result[key] = value
 		}
 	}
+	if IncludeRaw() {
+		result[RawKey] = runResult
+	}
 	err = unmarshal(result, pack)
 	if err != nil {
-		return fmt.Errorf("feching failed to unpack data %w", err)
+		return &FetchError{
+			Err:       fmt.Errorf("feching failed to unpack data %w", err),
+			Stage:     UnmarshalStage,
+			Command:   inst.cmdGrp.GetCommand(),
+			RawOutput: stdout,
+		}
 	}
 	return nil
 }
 
-// runCommands executes the commands on the container passed as the ctx
-// and extracts the results from the stdout
-func runCommands(ctx clients.ExecContext, cmdGrp clients.Cmder) (result map[string]string, err error) { //nolint:lll // allow slightly long function definition
+// runCommands executes the commands with env set on the container passed as the ctx
+// and extracts the results from the stdout which is also returned
+func runCommands(
+	ctx clients.ExecContext,
+	cmdGrp clients.Cmder,
+	env map[string]string,
+) (map[string]string, string, error) {
 	cmd := cmdGrp.GetCommand()
-	command := []string{"/usr/bin/sh"}
+	command, err := clients.CommandWithEnv([]string{clients.Shell()}, env)
+	if err != nil {
+		return nil, "", &FetchError{
+			Err:     fmt.Errorf("runCommands failed %w", err),
+			Stage:   ExecStage,
+			Command: cmd,
+		}
+	}
 	var buffIn bytes.Buffer
 	buffIn.WriteString(cmd)
 
@@ -93,13 +153,28 @@ func runCommands(ctx clients.ExecContext, cmdGrp clients.Cmder) (result map[stri
 			"command in container failed unexpectedly:\n\tcontext: %v\n\tcommand: %v\n\terror: %v",
 			ctx, command, err,
 		)
-		return result, fmt.Errorf("runCommands failed %w", err)
+		return nil, stdout, &FetchError{
+			Err:       fmt.Errorf("runCommands failed %w", err),
+			Stage:     ExecStage,
+			Command:   cmd,
+			RawOutput: stdout,
+		}
 	}
-	result, err = cmdGrp.ExtractResult(stdout)
+	result, err := cmdGrp.ExtractResult(stdout)
 	if err != nil {
 		log.Debugf("extraction failed %s", err.Error())
 		log.Debugf("output was %s", stdout)
-		return result, fmt.Errorf("runCommands failed %w", err)
+		fetchErr := &FetchError{
+			Err:       fmt.Errorf("runCommands failed %w", err),
+			Stage:     ExtractStage,
+			Command:   cmd,
+			RawOutput: stdout,
+		}
+		var extractErr *clients.ExtractError
+		if errors.As(err, &extractErr) {
+			fetchErr.Key = extractErr.Key
+		}
+		return result, stdout, fetchErr
 	}
-	return result, nil
+	return result, stdout, nil
 }
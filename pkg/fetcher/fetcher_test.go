@@ -0,0 +1,185 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package fetcher //nolint:testpackage // testing internal functions
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeExecContext struct {
+	err     error
+	stdout  string
+	command []string
+}
+
+func (ctx *fakeExecContext) ExecCommand(command []string) (stdout, stderr string, err error) {
+	return ctx.stdout, "", ctx.err
+}
+
+func (ctx *fakeExecContext) ExecCommandStdIn(command []string, buffIn bytes.Buffer) (stdout, stderr string, err error) {
+	ctx.command = command
+	return ctx.stdout, "", ctx.err
+}
+
+func (ctx *fakeExecContext) ExecCommandEnv(command []string, env map[string]string) (stdout, stderr string, err error) {
+	return ctx.stdout, "", ctx.err
+}
+
+type fetchTarget struct {
+	Value string `fetcherKey:"value"`
+}
+
+type rawFetchTarget struct {
+	Raw   map[string]string `fetcherKey:"raw"`
+	Value string            `fetcherKey:"value"`
+}
+
+type intFetchTarget struct {
+	Value int `fetcherKey:"value"`
+}
+
+func newTestFetcher() *Fetcher {
+	inst := NewFetcher()
+	err := inst.AddNewCommand("value", "echo 1", true)
+	Expect(err).NotTo(HaveOccurred())
+	return inst
+}
+
+func expectFetchError(err error) *FetchError {
+	var fetchErr *FetchError
+	Expect(errors.As(err, &fetchErr)).To(BeTrue())
+	Expect(fetchErr.Command).To(Equal(newTestFetcher().cmdGrp.GetCommand()))
+	return fetchErr
+}
+
+var _ = Describe("Fetch", func() {
+	When("the command fails to run", func() {
+		It("should return a FetchError for the exec stage", func() {
+			execErr := errors.New("exec failed")
+			err := newTestFetcher().Fetch(&fakeExecContext{err: execErr, stdout: "partial"}, &fetchTarget{})
+			fetchErr := expectFetchError(err)
+			Expect(fetchErr.Stage).To(Equal(ExecStage))
+			Expect(fetchErr.RawOutput).To(Equal("partial"))
+			Expect(errors.Is(err, execErr)).To(BeTrue())
+		})
+	})
+	When("the result can not be found in the output", func() {
+		It("should return a FetchError with the key and raw output", func() {
+			err := newTestFetcher().Fetch(&fakeExecContext{stdout: "nothing useful"}, &fetchTarget{})
+			fetchErr := expectFetchError(err)
+			Expect(fetchErr.Stage).To(Equal(ExtractStage))
+			Expect(fetchErr.Key).To(Equal("value"))
+			Expect(fetchErr.RawOutput).To(Equal("nothing useful"))
+		})
+	})
+	When("the post processor fails", func() {
+		It("should return a FetchError for the post-process stage", func() {
+			inst := newTestFetcher()
+			inst.SetPostProcessor(func(map[string]string) (map[string]any, error) {
+				return nil, errors.New("bad value")
+			})
+			stdout := "<value>\n1\n</value>\n"
+			err := inst.Fetch(&fakeExecContext{stdout: stdout}, &fetchTarget{})
+			fetchErr := expectFetchError(err)
+			Expect(fetchErr.Stage).To(Equal(PostProcessStage))
+			Expect(fetchErr.RawOutput).To(Equal(stdout))
+		})
+	})
+	When("the result can not be unmarshalled", func() {
+		It("should return a FetchError for the unmarshal stage", func() {
+			stdout := "<value>\n1\n</value>\n"
+			err := newTestFetcher().Fetch(&fakeExecContext{stdout: stdout}, &intFetchTarget{})
+			fetchErr := expectFetchError(err)
+			Expect(fetchErr.Stage).To(Equal(UnmarshalStage))
+			Expect(fetchErr.RawOutput).To(Equal(stdout))
+		})
+	})
+	When("the fetch succeeds", func() {
+		It("should populate the target", func() {
+			target := &fetchTarget{}
+			err := newTestFetcher().Fetch(&fakeExecContext{stdout: "<value>\n1\n</value>\n"}, target)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target.Value).To(Equal("1"))
+		})
+	})
+	When("raw output is included", func() {
+		AfterEach(func() {
+			SetIncludeRaw(false)
+		})
+		It("should not populate the raw output by default", func() {
+			target := &rawFetchTarget{}
+			err := newTestFetcher().Fetch(&fakeExecContext{stdout: "<value>\n1\n</value>\n"}, target)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target.Value).To(Equal("1"))
+			Expect(target.Raw).To(BeNil())
+		})
+		It("should populate the raw output of each command when enabled", func() {
+			SetIncludeRaw(true)
+			target := &rawFetchTarget{}
+			err := newTestFetcher().Fetch(&fakeExecContext{stdout: "<value>\n1\n</value>\n"}, target)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target.Value).To(Equal("1"))
+			Expect(target.Raw).To(Equal(map[string]string{"value": "1"}))
+		})
+		It("should keep the raw output alongside the post processed values", func() {
+			SetIncludeRaw(true)
+			inst := newTestFetcher()
+			inst.SetPostProcessor(func(map[string]string) (map[string]any, error) {
+				return map[string]any{"value": "processed"}, nil
+			})
+			target := &rawFetchTarget{}
+			err := inst.Fetch(&fakeExecContext{stdout: "<value>\n1\n</value>\n"}, target)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(target.Value).To(Equal("processed"))
+			Expect(target.Raw).To(Equal(map[string]string{"value": "1"}))
+		})
+	})
+	When("an env is set", func() {
+		It("should run the commands with the env", func() {
+			inst := newTestFetcher()
+			Expect(inst.SetEnv(map[string]string{"TZ": "UTC", "LC_ALL": "C"})).To(Succeed())
+			ctx := &fakeExecContext{stdout: "<value>\n1\n</value>\n"}
+			Expect(inst.Fetch(ctx, &fetchTarget{})).To(Succeed())
+			Expect(ctx.command).To(Equal([]string{"env", "LC_ALL=C", "TZ=UTC", "/usr/bin/sh"}))
+		})
+		It("should reject an invalid variable name", func() {
+			inst := newTestFetcher()
+			Expect(inst.SetEnv(map[string]string{"NOT=VALID": "1"})).NotTo(Succeed())
+			ctx := &fakeExecContext{stdout: "<value>\n1\n</value>\n"}
+			Expect(inst.Fetch(ctx, &fetchTarget{})).To(Succeed())
+			Expect(ctx.command).To(Equal([]string{"/usr/bin/sh"}))
+		})
+	})
+})
+
+var _ = Describe("SetCollector", func() {
+	It("should set the collector on a wrapped FetchError", func() {
+		fetchErr := &FetchError{Err: errors.New("failed"), Stage: ExtractStage, Key: "value"}
+		err := SetCollector(fmt.Errorf("failed to fetch %w", fetchErr), "GNSS")
+		Expect(fetchErr.Collector).To(Equal("GNSS"))
+		Expect(errors.Is(err, fetchErr)).To(BeTrue())
+		Expect(fetchErr.Error()).To(Equal("fetch failed at extract stage (collector GNSS, key value): failed"))
+	})
+	It("should return other errors unchanged", func() {
+		otherErr := errors.New("other")
+		Expect(SetCollector(otherErr, "GNSS")).To(Equal(otherErr))
+	})
+})
+
+var _ = Describe("IsParseError", func() {
+	It("should be true for a wrapped FetchError after the commands ran", func() {
+		for _, stage := range []FetchStage{ExtractStage, PostProcessStage, UnmarshalStage} {
+			err := fmt.Errorf("failed to fetch %w", &FetchError{Err: errors.New("failed"), Stage: stage})
+			Expect(IsParseError(err)).To(BeTrue())
+		}
+	})
+	It("should be false for exec failures and other errors", func() {
+		Expect(IsParseError(&FetchError{Err: errors.New("failed"), Stage: ExecStage})).To(BeFalse())
+		Expect(IsParseError(errors.New("other"))).To(BeFalse())
+	})
+})
@@ -0,0 +1,130 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package replay
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+)
+
+const maxLineSize = 1 << 24
+
+var outputTypes = make(map[string]func() callbacks.OutputType)
+
+// registerOutputType allows lines written for the type returned by
+// newOutput to be reconstructed, it is keyed by the name the raw callback writes
+func registerOutputType(newOutput func() callbacks.OutputType) {
+	outputTypes[fmt.Sprintf("%T", newOutput())] = newOutput
+}
+
+func init() {
+	registerOutputType(func() callbacks.OutputType { return &clients.ClusterInfo{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.PTPDeviceInfo{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.DevFilesystemDPLLInfo{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.DevNetlinkDPLLInfo{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.DPLLStateChange{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.GPSDetails{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.GPSEvent{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.PMCInfo{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.PMCTimeStatus{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.PTPConfig{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.DeviceSummary{} })
+	registerOutputType(func() callbacks.OutputType { return &devices.ICEPinConfig{} })
+}
+
+// UnknownTypeError is returned when a line was written for a type which can not be reconstructed
+type UnknownTypeError struct {
+	TypeName string
+}
+
+func (err *UnknownTypeError) Error() string {
+	return fmt.Sprintf("unknown output type %s", err.TypeName)
+}
+
+// ParseLine reconstructs the output and tag from a line written by the raw callback
+// which are in the form "<type>:<tag>, <json>"
+func ParseLine(line string) (callbacks.OutputType, string, error) {
+	header, body, found := strings.Cut(line, ", ")
+	if !found {
+		return nil, "", fmt.Errorf("line is not in the form <type>:<tag>, <json>: %s", line)
+	}
+	typeName, tag, found := strings.Cut(header, ":")
+	if !found {
+		return nil, "", fmt.Errorf("line header is not in the form <type>:<tag>: %s", header)
+	}
+	newOutput, ok := outputTypes[typeName]
+	if !ok {
+		return nil, tag, &UnknownTypeError{TypeName: typeName}
+	}
+	output := newOutput()
+	err := json.Unmarshal([]byte(body), output)
+	if err != nil {
+		return nil, tag, fmt.Errorf("failed to unmarshal %s: %w", typeName, err)
+	}
+	return output, tag, nil
+}
+
+// ParseNDJSONLine reconstructs the output and tag from a line written by the NDJSON callback
+func ParseNDJSONLine(line string) (callbacks.OutputType, string, error) {
+	ndjsonLine := struct {
+		Type string          `json:"type"`
+		Tag  string          `json:"tag"`
+		Data json.RawMessage `json:"data"`
+	}{}
+	err := json.Unmarshal([]byte(line), &ndjsonLine)
+	if err != nil {
+		return nil, "", fmt.Errorf("line is not in the NDJSON format: %w", err)
+	}
+	newOutput, ok := outputTypes[ndjsonLine.Type]
+	if !ok {
+		return nil, ndjsonLine.Tag, &UnknownTypeError{TypeName: ndjsonLine.Type}
+	}
+	output := newOutput()
+	err = json.Unmarshal(ndjsonLine.Data, output)
+	if err != nil {
+		return nil, ndjsonLine.Tag, fmt.Errorf("failed to unmarshal %s: %w", ndjsonLine.Type, err)
+	}
+	return output, ndjsonLine.Tag, nil
+}
+
+// Replay reads the lines written by the raw callback from input and passes the
+// reconstructed outputs to the callback. Lines for unknown types are skipped.
+func Replay(input io.Reader, callback callbacks.Callback) error {
+	scanner := bufio.NewScanner(input)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	lineNumber := 0
+	for scanner.Scan() {
+		lineNumber++
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		output, tag, err := ParseLine(line)
+		if err != nil {
+			var unknownType *UnknownTypeError
+			if errors.As(err, &unknownType) {
+				log.Warningf("skipping line %d: %s", lineNumber, err.Error())
+				continue
+			}
+			return fmt.Errorf("failed to parse line %d: %w", lineNumber, err)
+		}
+		err = callback.Call(output, tag)
+		if err != nil {
+			return fmt.Errorf("callback failed for line %d: %w", lineNumber, err)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+	return nil
+}
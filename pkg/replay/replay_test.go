@@ -0,0 +1,140 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package replay_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/replay"
+)
+
+type bufferCloser struct {
+	bytes.Buffer
+}
+
+func (b *bufferCloser) Close() error {
+	return nil
+}
+
+var _ = Describe("Replay", func() {
+	When("given a previously collected file", func() {
+		It("should write the analyser format for each known line", func() {
+			input, err := os.Open("test_files/collected.log")
+			Expect(err).NotTo(HaveOccurred())
+			defer input.Close()
+
+			output := &bufferCloser{}
+			err = replay.Replay(input, callbacks.NewFileCallback(output, callbacks.AnalyserJSON))
+			Expect(err).NotTo(HaveOccurred())
+
+			ids := make([]string, 0)
+			messages := make(map[string]map[string]any)
+			for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+				message := callbacks.AnalyserFormatType{}
+				Expect(json.Unmarshal([]byte(line), &message)).To(Succeed())
+				ids = append(ids, message.ID)
+				if data, ok := message.Data.(map[string]any); ok {
+					messages[message.ID] = data
+				}
+			}
+			Expect(ids).To(Equal([]string{
+				"devInfo",
+				"dpll/time-error",
+				"gnss/time-error",
+				"gnss/rf-mon",
+				"phc/gm-settings",
+			}))
+			Expect(messages["devInfo"]).To(HaveKeyWithValue("vendorID", "0x8086"))
+			Expect(messages["devInfo"]).To(HaveKeyWithValue("fetched_timestamp", "2023-06-16T11:49:47.0584Z"))
+			Expect(messages["dpll/time-error"]).To(HaveKeyWithValue("terror", -0.34))
+			Expect(messages["dpll/time-error"]).To(HaveKeyWithValue("timestamp", "2023-06-16T11:49:48.0584Z"))
+			Expect(messages["phc/gm-settings"]).To(HaveKeyWithValue("clock_class", float64(6)))
+		})
+	})
+})
+
+var _ = Describe("ParseLine", func() {
+	When("given a line for a known type", func() {
+		It("should reconstruct the typed output", func() {
+			output, tag, err := replay.ParseLine(
+				`*devices.DevFilesystemDPLLInfo:dpll-info-fs, {"timestamp":"2023-06-16T11:49:48.0584Z","eecstate":"2","state":"3","terror":-34}`,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tag).To(Equal("dpll-info-fs"))
+			Expect(output).To(Equal(&devices.DevFilesystemDPLLInfo{
+				Timestamp: "2023-06-16T11:49:48.0584Z",
+				EECState:  "2",
+				PPSState:  "3",
+				PPSOffset: -34,
+			}))
+		})
+	})
+	When("given a line for the cluster info", func() {
+		It("should reconstruct the cluster info", func() {
+			output, tag, err := replay.ParseLine(
+				`*clients.ClusterInfo:cluster-info, {"context":"admin","cluster":"cormorant","server":"https://api.cormorant.wpc.test:6443"}`,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tag).To(Equal("cluster-info"))
+			Expect(output).To(Equal(&clients.ClusterInfo{
+				Context: "admin",
+				Cluster: "cormorant",
+				Server:  "https://api.cormorant.wpc.test:6443",
+			}))
+		})
+	})
+	When("given a line for an unknown type", func() {
+		It("should return an UnknownTypeError", func() {
+			_, _, err := replay.ParseLine(`*verify.Result:env-check, {}`)
+			var unknownType *replay.UnknownTypeError
+			Expect(err).To(BeAssignableToTypeOf(unknownType))
+		})
+	})
+	When("given a line which is not in the raw format", func() {
+		It("should return an error", func() {
+			_, _, err := replay.ParseLine(`{"id":"devInfo"}`)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+var _ = Describe("ParseNDJSONLine", func() {
+	When("given a line for a known type", func() {
+		It("should reconstruct the typed output", func() {
+			output, tag, err := replay.ParseNDJSONLine(
+				`{"data":{"timestamp":"2023-06-16T11:49:48.0584Z","eecstate":"2","state":"3","terror":-34},` +
+					`"type":"*devices.DevFilesystemDPLLInfo","tag":"dpll-info-fs"}`,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(tag).To(Equal("dpll-info-fs"))
+			Expect(output).To(Equal(&devices.DevFilesystemDPLLInfo{
+				Timestamp: "2023-06-16T11:49:48.0584Z",
+				EECState:  "2",
+				PPSState:  "3",
+				PPSOffset: -34,
+			}))
+		})
+	})
+	When("given a line for an unknown type", func() {
+		It("should return an UnknownTypeError", func() {
+			_, _, err := replay.ParseNDJSONLine(`{"data":{},"type":"*verify.Result","tag":"env-check"}`)
+			var unknownType *replay.UnknownTypeError
+			Expect(err).To(BeAssignableToTypeOf(unknownType))
+		})
+	})
+})
+
+func TestCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Replay Suite")
+}
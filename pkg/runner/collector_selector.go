@@ -3,6 +3,7 @@
 package runner
 
 import (
+	"fmt"
 	"strings"
 
 	log "github.com/sirupsen/logrus"
@@ -16,6 +17,9 @@ var (
 	All                    string = "all"
 )
 
+// excludePrefix marks a selected collector name as one to be removed from the run
+const excludePrefix = "-"
+
 func init() {
 	registry := collectors.GetRegistry()
 	OptionalCollectorNames = registry.GetOptionalNames()
@@ -31,6 +35,16 @@ func isIn(name string, arr []string) bool {
 	return false
 }
 
+// canonicalName returns the entry of arr which matches name ignoring case
+func canonicalName(name string, arr []string) (string, bool) {
+	for _, arrVal := range arr {
+		if strings.EqualFold(name, arrVal) {
+			return arrVal, true
+		}
+	}
+	return "", false
+}
+
 func removeDuplicates(arr []string) []string {
 	res := make([]string, 0)
 	for _, name := range arr {
@@ -41,26 +55,117 @@ func removeDuplicates(arr []string) []string {
 	return res
 }
 
+func removeExcluded(arr, excluded []string) []string {
+	res := make([]string, 0)
+	for _, name := range arr {
+		if !isIn(name, excluded) {
+			res = append(res, name)
+		}
+	}
+	return res
+}
+
+// UnknownCollectorsError is returned by GetCollectorsToRun in strict mode
+// when any of the selected collectors are not known
+type UnknownCollectorsError struct {
+	Names []string
+}
+
+func (err *UnknownCollectorsError) Error() string {
+	return fmt.Sprintf(
+		"unknown collectors: %s. Valid collectors are: %s",
+		strings.Join(err.Names, ", "),
+		strings.Join(append([]string{All, "defaults"}, OptionalCollectorNames...), ", "),
+	)
+}
+
+// collectorSelection accumulates the result of parsing the selected collector names
+type collectorSelection struct {
+	names    []string
+	excluded []string
+	unknown  []string
+	strict   bool
+}
+
+func (sel *collectorSelection) addUnknown(name string) {
+	if sel.strict {
+		sel.unknown = append(sel.unknown, name)
+	} else {
+		log.Errorf("Unknown collector %s. Ignored", name)
+	}
+}
+
+func (sel *collectorSelection) exclude(name string) {
+	excludedName := strings.TrimSpace(strings.TrimPrefix(name, excludePrefix))
+	requiredName, isRequired := canonicalName(excludedName, RequiredCollectorNames)
+	optionalName, isOptional := canonicalName(excludedName, OptionalCollectorNames)
+	switch {
+	case isRequired:
+		log.Warningf("Collector %s is required and can not be excluded. Ignored", requiredName)
+	case isOptional:
+		sel.excluded = append(sel.excluded, optionalName)
+	default:
+		sel.addUnknown(name)
+	}
+}
+
+func (sel *collectorSelection) include(name string) {
+	optionalName, isOptional := canonicalName(name, OptionalCollectorNames)
+	_, isRequired := canonicalName(name, RequiredCollectorNames)
+	switch {
+	case strings.EqualFold(name, "all"):
+		sel.names = append(sel.names, OptionalCollectorNames...)
+	case strings.EqualFold(name, "defaults"):
+		sel.names = append(sel.names, OptionalCollectorNames...)
+	case isRequired:
+		return
+	case isOptional:
+		sel.names = append(sel.names, optionalName)
+	default:
+		sel.addUnknown(name)
+	}
+}
+
 // GetCollectorsToRun returns a slice containing the names of the
 // collectors to be run it will enfore that required colletors
-// are returned
-func GetCollectorsToRun(selectedCollectors []string) []string {
-	collectorNames := make([]string, 0)
-	collectorNames = append(collectorNames, RequiredCollectorNames...)
+// are returned. Names are matched ignoring case and surrounding
+// whitespace and the registered names are returned. Names prefixed with
+// "-" are removed after "all" has been expanded, regardless of where they
+// appear, required collectors can not be excluded. In strict mode
+// unknown names cause an UnknownCollectorsError to be returned
+// otherwise they are logged and ignored. The dependencies of the selected
+// collectors are added and the names are ordered so that each collector
+// comes after its dependencies
+func GetCollectorsToRun(selectedCollectors []string, strict bool) ([]string, error) {
+	sel := &collectorSelection{
+		names:    append([]string{}, RequiredCollectorNames...),
+		excluded: make([]string, 0),
+		unknown:  make([]string, 0),
+		strict:   strict,
+	}
 	for _, name := range selectedCollectors {
+		name = strings.TrimSpace(name)
 		switch {
-		case strings.EqualFold(name, "all"):
-			collectorNames = append(collectorNames, OptionalCollectorNames...)
-		case strings.EqualFold(name, "defaults"):
-			collectorNames = append(collectorNames, OptionalCollectorNames...)
-		case isIn(name, collectorNames):
+		case name == "":
 			continue
-		case isIn(name, OptionalCollectorNames):
-			collectorNames = append(collectorNames, name)
+		case strings.HasPrefix(name, excludePrefix):
+			sel.exclude(name)
 		default:
-			log.Errorf("Unknown collector %s. Ignored", name)
+			sel.include(name)
+		}
+	}
+	if len(sel.unknown) > 0 {
+		return nil, &UnknownCollectorsError{Names: removeDuplicates(sel.unknown)}
+	}
+	collectorNames := removeExcluded(removeDuplicates(sel.names), sel.excluded)
+	collectorNames, err := collectors.GetRegistry().ResolveDependencies(collectorNames)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve collector dependencies: %w", err)
+	}
+	for _, name := range sel.excluded {
+		if isIn(name, collectorNames) {
+			log.Warningf("Collector %s was excluded but another selected collector depends on it so it will be run", name)
 		}
 	}
-	collectorNames = removeDuplicates(collectorNames)
-	return collectorNames
+	return collectorNames, nil
 }
@@ -0,0 +1,142 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+)
+
+var _ = Describe("GetCollectorsToRun", func() {
+	for _, strict := range []bool{true, false} {
+		strict := strict
+		When("only known collectors are selected", func() {
+			It("should return them with the required collectors", func() {
+				names, err := GetCollectorsToRun([]string{collectors.GPSCollectorName}, strict)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(names).To(ContainElements(RequiredCollectorNames))
+				Expect(names).To(ContainElement(collectors.GPSCollectorName))
+				Expect(names).To(HaveLen(len(RequiredCollectorNames) + 1))
+			})
+			It("should expand all to every optional collector", func() {
+				names, err := GetCollectorsToRun([]string{All}, strict)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(names).To(ContainElements(OptionalCollectorNames))
+			})
+		})
+	}
+
+	When("names use different casing or surrounding whitespace", func() {
+		It("should return the registered names", func() {
+			names, err := GetCollectorsToRun([]string{
+				" " + strings.ToLower(collectors.GPSCollectorName),
+				strings.ToUpper(collectors.DPLLCollectorName) + "\t",
+				strings.ToUpper(collectors.DevInfoCollectorName),
+			}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(ContainElements(collectors.GPSCollectorName, collectors.DPLLCollectorName))
+			Expect(names).To(HaveLen(len(RequiredCollectorNames) + 2))
+		})
+		It("should de-duplicate names which only differ in case", func() {
+			names, err := GetCollectorsToRun([]string{
+				collectors.GPSCollectorName,
+				strings.ToLower(collectors.GPSCollectorName),
+				" " + strings.ToUpper(collectors.GPSCollectorName) + " ",
+			}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(HaveLen(len(RequiredCollectorNames) + 1))
+		})
+		It("should match all ignoring case and whitespace", func() {
+			names, err := GetCollectorsToRun([]string{" ALL "}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(ContainElements(OptionalCollectorNames))
+		})
+	})
+
+	When("a collector with dependencies is selected", func() {
+		It("should include and order the dependencies first", func() {
+			names, err := GetCollectorsToRun([]string{collectors.GPSCollectorName}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(ContainElement(collectors.DevInfoCollectorName))
+			Expect(indexOf(names, collectors.DevInfoCollectorName)).To(
+				BeNumerically("<", indexOf(names, collectors.GPSCollectorName)),
+			)
+		})
+	})
+
+	When("a collector is excluded from all", func() {
+		It("should run every other collector", func() {
+			names, err := GetCollectorsToRun([]string{All, "-" + strings.ToLower(collectors.GPSCollectorName)}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).NotTo(ContainElement(collectors.GPSCollectorName))
+			Expect(names).To(ContainElements(RequiredCollectorNames))
+			Expect(names).To(HaveLen(len(RequiredCollectorNames) + len(OptionalCollectorNames) - 1))
+		})
+		It("should exclude the collector even when it is also included", func() {
+			names, err := GetCollectorsToRun([]string{
+				"-" + collectors.GPSCollectorName,
+				collectors.GPSCollectorName,
+				collectors.PMCCollectorName,
+			}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).NotTo(ContainElement(collectors.GPSCollectorName))
+			Expect(names).To(ContainElement(collectors.PMCCollectorName))
+		})
+		It("should ignore the exclusion of a required collector", func() {
+			names, err := GetCollectorsToRun([]string{All, "-" + collectors.DevInfoCollectorName}, true)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(ContainElement(collectors.DevInfoCollectorName))
+		})
+		It("should treat the exclusion of an unknown collector as unknown in strict mode", func() {
+			_, err := GetCollectorsToRun([]string{All, "-dpl"}, true)
+			var unknownErr *UnknownCollectorsError
+			Expect(errors.As(err, &unknownErr)).To(BeTrue())
+			Expect(unknownErr.Names).To(Equal([]string{"-dpl"}))
+		})
+	})
+
+	When("in strict mode", func() {
+		It("should return an error listing the unknown collectors", func() {
+			names, err := GetCollectorsToRun([]string{"dpl", "gpss"}, true)
+			Expect(names).To(BeNil())
+			var unknownErr *UnknownCollectorsError
+			Expect(errors.As(err, &unknownErr)).To(BeTrue())
+			Expect(unknownErr.Names).To(Equal([]string{"dpl", "gpss"}))
+			Expect(err.Error()).To(ContainSubstring(collectors.DPLLCollectorName))
+		})
+		It("should return an error when known and unknown collectors are mixed", func() {
+			_, err := GetCollectorsToRun([]string{collectors.GPSCollectorName, "dpl", "dpl"}, true)
+			var unknownErr *UnknownCollectorsError
+			Expect(errors.As(err, &unknownErr)).To(BeTrue())
+			Expect(unknownErr.Names).To(Equal([]string{"dpl"}))
+		})
+	})
+
+	When("in lenient mode", func() {
+		It("should ignore unknown collectors", func() {
+			names, err := GetCollectorsToRun([]string{"dpl"}, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(Equal(RequiredCollectorNames))
+		})
+		It("should keep the known collectors when mixed with unknown ones", func() {
+			names, err := GetCollectorsToRun([]string{"dpl", collectors.GPSCollectorName}, false)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(names).To(ContainElement(collectors.GPSCollectorName))
+			Expect(names).NotTo(ContainElement("dpl"))
+		})
+	})
+})
+
+func indexOf(names []string, name string) int {
+	for i, n := range names {
+		if n == name {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,221 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+)
+
+const (
+	// A collector is unhealthy once it has missed this many polls in a row
+	livenessMissedPolls = 3
+	// Give collectors with short poll intervals at least this long to succeed
+	livenessMinimumWindow = time.Minute
+	healthReadTimeout     = 5 * time.Second
+	healthShutdownTimeout = 5 * time.Second
+	metricsPrefix         = "vse_sync_collector"
+)
+
+type collectorStats struct {
+	addedAt      time.Time
+	lastSuccess  time.Time
+	pollInterval time.Duration
+	polls        int64
+	errors       int64
+}
+
+// livenessDeadline returns the time after which the collector is unhealthy
+// if it has not had a successful poll
+func (stats *collectorStats) livenessDeadline() time.Time {
+	window := livenessMissedPolls * stats.pollInterval
+	if window < livenessMinimumWindow {
+		window = livenessMinimumWindow
+	}
+	last := stats.addedAt
+	if stats.lastSuccess.After(last) {
+		last = stats.lastSuccess
+	}
+	return last.Add(window)
+}
+
+// pollStats records the poll results of the started collectors
+// so they can be reported by the health server
+type pollStats struct {
+	collectors map[string]*collectorStats
+	now        func() time.Time
+	lock       sync.RWMutex
+}
+
+func newPollStats() *pollStats {
+	return &pollStats{
+		collectors: make(map[string]*collectorStats),
+		now:        time.Now,
+	}
+}
+
+func (s *pollStats) addCollector(collectorName string, pollInterval time.Duration) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	s.collectors[collectorName] = &collectorStats{
+		addedAt:      s.now(),
+		pollInterval: pollInterval,
+	}
+}
+
+func (s *pollStats) record(pollRes collectors.PollResult) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+	stats, ok := s.collectors[pollRes.CollectorName]
+	if !ok {
+		return
+	}
+	stats.polls++
+	if len(pollRes.Errors) > 0 {
+		stats.errors++
+	} else {
+		stats.lastSuccess = s.now()
+	}
+}
+
+func (s *pollStats) sortedNames() []string {
+	names := make([]string, 0, len(s.collectors))
+	for name := range s.collectors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// unhealthy returns the names of the collectors which have not had a successful poll recently
+func (s *pollStats) unhealthy() []string {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	now := s.now()
+	unhealthy := make([]string, 0)
+	for _, name := range s.sortedNames() {
+		if now.After(s.collectors[name].livenessDeadline()) {
+			unhealthy = append(unhealthy, name)
+		}
+	}
+	return unhealthy
+}
+
+func (s *pollStats) healthzHandler(w http.ResponseWriter, r *http.Request) {
+	unhealthy := s.unhealthy()
+	if len(unhealthy) > 0 {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		fmt.Fprintf(w, "collectors without a recent successful poll: %s\n", strings.Join(unhealthy, ", "))
+		return
+	}
+	fmt.Fprintln(w, "ok")
+}
+
+// metricsHandler writes the poll counts in the Prometheus text format
+func (s *pollStats) metricsHandler(w http.ResponseWriter, r *http.Request) {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	names := s.sortedNames()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	writeMetric := func(name, metricType, help string, value func(*collectorStats) string) {
+		fmt.Fprintf(w, "# HELP %s_%s %s\n", metricsPrefix, name, help)
+		fmt.Fprintf(w, "# TYPE %s_%s %s\n", metricsPrefix, name, metricType)
+		for _, collectorName := range names {
+			fmt.Fprintf(w, "%s_%s{collector=%q} %s\n", metricsPrefix, name, collectorName, value(s.collectors[collectorName]))
+		}
+	}
+	writeMetric("polls_total", "counter", "Number of completed polls.", func(stats *collectorStats) string {
+		return fmt.Sprint(stats.polls)
+	})
+	writeMetric("poll_errors_total", "counter", "Number of polls which returned errors.", func(stats *collectorStats) string {
+		return fmt.Sprint(stats.errors)
+	})
+	writeMetric(
+		"last_successful_poll_timestamp_seconds",
+		"gauge",
+		"Unix time of the last successful poll, 0 if there has not been one.",
+		func(stats *collectorStats) string {
+			if stats.lastSuccess.IsZero() {
+				return "0"
+			}
+			return fmt.Sprint(stats.lastSuccess.Unix())
+		},
+	)
+	writeExecMetrics(w, clients.GetExecMetrics())
+}
+
+// writeExecMetrics writes the latency histogram and failure counts of the commands executed in containers
+func writeExecMetrics(w io.Writer, metrics clients.ExecMetrics) {
+	name := metricsPrefix + "_exec_duration_seconds"
+	fmt.Fprintf(w, "# HELP %s Time taken to execute commands in containers including retries.\n", name)
+	fmt.Fprintf(w, "# TYPE %s histogram\n", name)
+	for i, bound := range clients.ExecLatencyBuckets() {
+		fmt.Fprintf(w, "%s_bucket{le=%q} %d\n", name, fmt.Sprint(bound.Seconds()), metrics.BucketCounts[i])
+	}
+	fmt.Fprintf(w, "%s_bucket{le=\"+Inf\"} %d\n", name, metrics.Count)
+	fmt.Fprintf(w, "%s_sum %s\n", name, fmt.Sprint(metrics.Sum.Seconds()))
+	fmt.Fprintf(w, "%s_count %d\n", name, metrics.Count)
+
+	name = metricsPrefix + "_exec_failures_total"
+	fmt.Fprintf(w, "# HELP %s Number of commands executed in containers which failed by type of failure.\n", name)
+	fmt.Fprintf(w, "# TYPE %s counter\n", name)
+	failureTypes := make([]clients.ExecFailureType, 0, len(metrics.Failures))
+	for failureType := range metrics.Failures {
+		failureTypes = append(failureTypes, failureType)
+	}
+	sort.Slice(failureTypes, func(i, j int) bool { return failureTypes[i] < failureTypes[j] })
+	for _, failureType := range failureTypes {
+		fmt.Fprintf(w, "%s{type=%q} %d\n", name, failureType.String(), metrics.Failures[failureType])
+	}
+}
+
+func (s *pollStats) handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/healthz", s.healthzHandler)
+	mux.HandleFunc("/metrics", s.metricsHandler)
+	return mux
+}
+
+// startHealthServer serves /healthz and /metrics for the stats on address,
+// it returns an error if the address can not be listened on
+func startHealthServer(address string, stats *pollStats) (*http.Server, error) {
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	server := &http.Server{
+		Handler:           stats.handler(),
+		ReadHeaderTimeout: healthReadTimeout,
+	}
+	go func() {
+		serveErr := server.Serve(listener)
+		if serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			log.Errorf("health server stopped: %s", serveErr.Error())
+		}
+	}()
+	log.Infof("Serving health and metrics on %s", listener.Addr().String())
+	return server, nil
+}
+
+func stopHealthServer(server *http.Server) {
+	ctx, cancel := context.WithTimeout(context.Background(), healthShutdownTimeout)
+	defer cancel()
+	err := server.Shutdown(ctx)
+	if err != nil {
+		log.Errorf("failed to stop health server: %s", err.Error())
+	}
+}
@@ -0,0 +1,132 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+)
+
+var _ = Describe("pollStats", func() {
+	var (
+		stats *pollStats
+		now   time.Time
+	)
+
+	BeforeEach(func() {
+		now = time.Unix(1700000000, 0)
+		stats = newPollStats()
+		stats.now = func() time.Time { return now }
+		stats.addCollector(collectors.GPSCollectorName, time.Second)
+		stats.addCollector(collectors.DPLLCollectorName, time.Minute)
+	})
+
+	get := func(path string) (int, string) {
+		recorder := httptest.NewRecorder()
+		stats.handler().ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, path, http.NoBody))
+		body, err := io.ReadAll(recorder.Body)
+		Expect(err).NotTo(HaveOccurred())
+		return recorder.Code, string(body)
+	}
+
+	When("the collectors have just started", func() {
+		It("should report healthy", func() {
+			code, body := get("/healthz")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(body).To(Equal("ok\n"))
+		})
+	})
+
+	When("a collector has not had a successful poll recently", func() {
+		It("should report it as unhealthy", func() {
+			now = now.Add(2 * time.Minute)
+			stats.record(collectors.PollResult{CollectorName: collectors.DPLLCollectorName})
+			stats.record(collectors.PollResult{
+				CollectorName: collectors.GPSCollectorName,
+				Errors:        []error{errors.New("poll failed")},
+			})
+
+			code, body := get("/healthz")
+			Expect(code).To(Equal(http.StatusServiceUnavailable))
+			Expect(body).To(ContainSubstring(collectors.GPSCollectorName))
+			Expect(body).NotTo(ContainSubstring(collectors.DPLLCollectorName))
+		})
+		It("should use the poll interval when it is longer than the minimum window", func() {
+			now = now.Add(2 * time.Minute)
+			stats.record(collectors.PollResult{CollectorName: collectors.GPSCollectorName})
+			Expect(stats.unhealthy()).To(BeEmpty())
+
+			now = now.Add(2 * time.Minute)
+			stats.record(collectors.PollResult{CollectorName: collectors.GPSCollectorName})
+			Expect(stats.unhealthy()).To(Equal([]string{collectors.DPLLCollectorName}))
+		})
+	})
+
+	When("metrics are requested", func() {
+		It("should report the poll and error counts for each collector", func() {
+			stats.record(collectors.PollResult{CollectorName: collectors.GPSCollectorName})
+			stats.record(collectors.PollResult{
+				CollectorName: collectors.GPSCollectorName,
+				Errors:        []error{errors.New("poll failed")},
+			})
+			stats.record(collectors.PollResult{CollectorName: "not started"})
+
+			code, body := get("/metrics")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(body).To(ContainSubstring(`vse_sync_collector_polls_total{collector="GNSS"} 2`))
+			Expect(body).To(ContainSubstring(`vse_sync_collector_poll_errors_total{collector="GNSS"} 1`))
+			Expect(body).To(ContainSubstring(`vse_sync_collector_polls_total{collector="DPLL"} 0`))
+			Expect(body).To(ContainSubstring(
+				`vse_sync_collector_last_successful_poll_timestamp_seconds{collector="GNSS"} 1700000000`,
+			))
+			Expect(body).To(ContainSubstring(
+				`vse_sync_collector_last_successful_poll_timestamp_seconds{collector="DPLL"} 0`,
+			))
+			Expect(body).NotTo(ContainSubstring("not started"))
+		})
+		It("should report the exec latency histogram and failures", func() {
+			code, body := get("/metrics")
+			Expect(code).To(Equal(http.StatusOK))
+			Expect(body).To(ContainSubstring("# TYPE vse_sync_collector_exec_duration_seconds histogram"))
+			Expect(body).To(ContainSubstring(`vse_sync_collector_exec_duration_seconds_bucket{le="0.05"}`))
+			Expect(body).To(ContainSubstring(`vse_sync_collector_exec_duration_seconds_bucket{le="+Inf"}`))
+			Expect(body).To(ContainSubstring("vse_sync_collector_exec_duration_seconds_count"))
+			Expect(body).To(ContainSubstring(`vse_sync_collector_exec_failures_total{type="timeout"}`))
+		})
+	})
+})
+
+var _ = Describe("startHealthServer", func() {
+	When("the runner is collecting", func() {
+		It("should serve the stats recorded by the runner", func() {
+			mock := collectors.NewMockCollector(time.Millisecond, nil)
+			callback := callbacks.NewFileCallback(&closeRecorder{}, callbacks.Raw)
+			runner := newMockRunner(mock, callback, 0, 3)
+			server, err := startHealthServer("127.0.0.1:0", runner.stats)
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(stopHealthServer, server)
+
+			Expect(runner.collect(callback)).To(Succeed())
+
+			recorder := httptest.NewRecorder()
+			server.Handler.ServeHTTP(recorder, httptest.NewRequest(http.MethodGet, "/metrics", http.NoBody))
+			Expect(recorder.Body.String()).To(ContainSubstring(`vse_sync_collector_polls_total{collector="Mock"} 3`))
+		})
+	})
+
+	When("the address can not be listened on", func() {
+		It("should return an error", func() {
+			_, err := startHealthServer("not an address", newPollStats())
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
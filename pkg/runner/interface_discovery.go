@@ -0,0 +1,46 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/validations"
+)
+
+// DiscoverInterfaces returns the interfaces on the node which are supported NICs,
+// the discovered and skipped interfaces are logged. An error is returned if none are found.
+func DiscoverInterfaces(kubeConfig, nodeName string) ([]string, error) {
+	clientset, err := clients.GetClientset(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clientset: %w", err)
+	}
+	ctx, err := contexts.GetPTPDaemonContext(clientset, nodeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover interfaces: %w", err)
+	}
+	discovery, err := devices.DiscoverInterfaces(ctx, validations.IsSupportedNIC)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover interfaces: %w", err)
+	}
+	skipped := make([]string, 0, len(discovery.Skipped))
+	for name := range discovery.Skipped {
+		skipped = append(skipped, name)
+	}
+	sort.Strings(skipped)
+	for _, name := range skipped {
+		log.Infof("Skipped interface %s: %s", name, discovery.Skipped[name])
+	}
+	if len(discovery.Discovered) == 0 {
+		return nil, errors.New("no interfaces with a supported NIC were discovered")
+	}
+	log.Infof("Discovered interfaces: %v", discovery.Discovered)
+	return discovery.Discovered, nil
+}
@@ -0,0 +1,126 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	manifestPermissions = 0644
+	manifestSuffix      = ".manifest.json"
+	splitManifestName   = "manifest.json"
+)
+
+// manifestTarget is what the run collected from
+type manifestTarget struct {
+	Cluster   string `json:"cluster"`
+	Node      string `json:"node,omitempty"`
+	Interface string `json:"interface,omitempty"`
+}
+
+// manifestPolls is the number of polls and the number of those which returned errors for a collector
+type manifestPolls struct {
+	Polls  int64 `json:"polls"`
+	Errors int64 `json:"errors"`
+}
+
+// runManifest describes a run so its output can be archived as a self describing bundle,
+// Records is the number of outputs written for each datatype and Statistics
+// summarises the offset and accuracy fields keyed by analyser ID then field
+type runManifest struct {
+	Records     map[string]int64                              `json:"records"`
+	Polls       map[string]manifestPolls                      `json:"polls"`
+	Statistics  map[string]map[string]utils.StatisticsSummary `json:"statistics,omitempty"`
+	StartTime   time.Time                                     `json:"startTime"`
+	EndTime     time.Time                                     `json:"endTime"`
+	Target      manifestTarget                                `json:"target"`
+	Duration    string                                        `json:"duration"`
+	ToolVersion string                                        `json:"toolVersion"`
+	Collectors  []string                                      `json:"collectors"`
+}
+
+// summary returns the poll and error counts of each started collector
+func (s *pollStats) summary() map[string]manifestPolls {
+	s.lock.RLock()
+	defer s.lock.RUnlock()
+	summary := make(map[string]manifestPolls, len(s.collectors))
+	for name, stats := range s.collectors {
+		summary[name] = manifestPolls{Polls: stats.polls, Errors: stats.errors}
+	}
+	return summary
+}
+
+// getManifestPath returns where the manifest is written, next to the output file
+// or in the directory of the split output
+func getManifestPath(outputFile, splitOutputDir string) string {
+	if splitOutputDir != "" {
+		return filepath.Join(splitOutputDir, splitManifestName)
+	}
+	return outputFile + manifestSuffix
+}
+
+// buildManifest returns the manifest of the run from the state of the runner
+func (runner *CollectorRunner) buildManifest(
+	startTime, endTime time.Time,
+	target manifestTarget,
+	records map[string]int64,
+	statistics map[string]map[string]utils.StatisticsSummary,
+) *runManifest {
+	collectorNames := make([]string, 0, len(runner.collectorInstances))
+	for _, name := range runner.collectorNames {
+		if _, ok := runner.collectorInstances[name]; ok {
+			collectorNames = append(collectorNames, name)
+		}
+	}
+	polls := make(map[string]manifestPolls)
+	if runner.stats != nil {
+		polls = runner.stats.summary()
+	}
+	return &runManifest{
+		StartTime:   startTime,
+		EndTime:     endTime,
+		Duration:    endTime.Sub(startTime).String(),
+		Target:      target,
+		Collectors:  collectorNames,
+		Records:     records,
+		Polls:       polls,
+		Statistics:  statistics,
+		ToolVersion: utils.Version(),
+	}
+}
+
+// getManifestTarget returns the target of the run, the cluster is left empty if it is not known
+func getManifestTarget(clientset *clients.Clientset, nodeName, ptpInterface string) manifestTarget {
+	target := manifestTarget{Node: nodeName, Interface: ptpInterface}
+	clusterInfo, err := clientset.GetClusterInfo()
+	if err != nil {
+		log.Warnf("failed to get the cluster info for the manifest: %s", err.Error())
+		return target
+	}
+	target.Cluster = clusterInfo.Cluster
+	return target
+}
+
+// writeManifest writes the manifest as JSON to path
+func writeManifest(path string, manifest *runManifest) error {
+	content, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal the manifest: %w", err)
+	}
+	err = os.WriteFile(path, append(content, '\n'), manifestPermissions)
+	if err != nil {
+		return fmt.Errorf("failed to write the manifest: %w", err)
+	}
+	log.Infof("Wrote the run manifest to %s", path)
+	return nil
+}
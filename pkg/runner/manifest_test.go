@@ -0,0 +1,82 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var _ = Describe("run manifest", func() {
+	It("should reflect the state of the run", func() {
+		runner := &CollectorRunner{
+			collectorNames: []string{collectors.GPSCollectorName, collectors.DPLLCollectorName, "NotStarted"},
+			collectorInstances: map[string]collectors.Collector{
+				collectors.GPSCollectorName:  &cleanupCollector{},
+				collectors.DPLLCollectorName: &cleanupCollector{},
+			},
+			stats: newPollStats(),
+		}
+		runner.stats.addCollector(collectors.GPSCollectorName, time.Second)
+		runner.stats.addCollector(collectors.DPLLCollectorName, time.Second)
+		runner.stats.record(collectors.PollResult{CollectorName: collectors.GPSCollectorName})
+		runner.stats.record(collectors.PollResult{
+			CollectorName: collectors.GPSCollectorName,
+			Errors:        []error{errors.New("poll failed")},
+		})
+		runner.stats.record(collectors.PollResult{CollectorName: collectors.DPLLCollectorName})
+
+		startTime := time.Date(2023, 6, 1, 12, 0, 0, 0, time.UTC)
+		manifest := runner.buildManifest(
+			startTime,
+			startTime.Add(90*time.Second),
+			manifestTarget{Cluster: "test-cluster", Node: "node-1", Interface: "ens7f0"},
+			map[string]int64{"gnss": 2, "dpll": 1},
+			map[string]map[string]utils.StatisticsSummary{
+				"dpll/time-error": {"terror": {Count: 2, Min: -1, Max: 1, Mean: 0, StdDev: 1, AbsP99: 1}},
+			},
+		)
+
+		outputDir, err := os.MkdirTemp("", "manifest")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, outputDir)
+		path := getManifestPath(filepath.Join(outputDir, "output.jsonl"), "")
+		Expect(path).To(Equal(filepath.Join(outputDir, "output.jsonl.manifest.json")))
+		Expect(writeManifest(path, manifest)).To(Succeed())
+
+		content, err := os.ReadFile(path)
+		Expect(err).NotTo(HaveOccurred())
+		written := map[string]any{}
+		Expect(json.Unmarshal(content, &written)).To(Succeed())
+		Expect(written).To(Equal(map[string]any{
+			"startTime":  "2023-06-01T12:00:00Z",
+			"endTime":    "2023-06-01T12:01:30Z",
+			"duration":   "1m30s",
+			"target":     map[string]any{"cluster": "test-cluster", "node": "node-1", "interface": "ens7f0"},
+			"collectors": []any{collectors.GPSCollectorName, collectors.DPLLCollectorName},
+			"records":    map[string]any{"gnss": 2.0, "dpll": 1.0},
+			"polls": map[string]any{
+				collectors.GPSCollectorName:  map[string]any{"polls": 2.0, "errors": 1.0},
+				collectors.DPLLCollectorName: map[string]any{"polls": 1.0, "errors": 0.0},
+			},
+			"statistics": map[string]any{
+				"dpll/time-error": map[string]any{"terror": map[string]any{
+					"count": 2.0, "min": -1.0, "max": 1.0, "mean": 0.0, "stddev": 1.0, "absP99": 1.0,
+				}},
+			},
+			"toolVersion": utils.Version(),
+		}))
+	})
+	It("should be written in the split output directory", func() {
+		Expect(getManifestPath("", "/tmp/split")).To(Equal("/tmp/split/manifest.json"))
+	})
+})
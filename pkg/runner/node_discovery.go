@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"fmt"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+)
+
+// DiscoverNodes returns the nodes which are running the PTP daemon,
+// an error is returned if none are found.
+func DiscoverNodes(kubeConfig string) ([]string, error) {
+	clientset, err := clients.GetClientset(kubeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get clientset: %w", err)
+	}
+	nodes, err := clientset.FindNodesWithPodPrefix(contexts.PTPNamespace, contexts.PTPPodNamePrefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to discover nodes: %w", err)
+	}
+	log.Infof("Discovered nodes running the PTP daemon: %v", nodes)
+	return nodes, nil
+}
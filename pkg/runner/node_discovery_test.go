@@ -0,0 +1,80 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+func newDaemonPod(name, nodeName string) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: contexts.PTPNamespace},
+		Spec:       v1.PodSpec{NodeName: nodeName},
+	}
+}
+
+type nodeTestOutput struct {
+	Msg string `json:"msg"`
+}
+
+func (o *nodeTestOutput) GetAnalyserFormat() ([]*callbacks.AnalyserFormatType, error) {
+	return []*callbacks.AnalyserFormatType{{ID: "nodeTestOutput", Data: o}}, nil
+}
+
+var _ = Describe("DiscoverNodes", func() {
+	When("the daemon is running on several nodes", func() {
+		It("should produce a tagged stream for each node", func() {
+			testutils.GetMockedClientSet(
+				newDaemonPod("linuxptp-daemon-aaaaa", "node-1"),
+				newDaemonPod("linuxptp-daemon-bbbbb", "node-2"),
+				newDaemonPod("linuxptp-daemon-ccccc", "node-3"),
+			)
+			nodes, err := DiscoverNodes("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(nodes).To(Equal([]string{"node-1", "node-2", "node-3"}))
+
+			outputDir, err := os.MkdirTemp("", "node-output")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(os.RemoveAll, outputDir)
+			outputFile := filepath.Join(outputDir, "output.jsonl")
+			fileCallback, err := callbacks.SetupCallback(outputFile, callbacks.NDJSON, false, 0, callbacks.GzipDisabled)
+			Expect(err).NotTo(HaveOccurred())
+			for _, node := range nodes {
+				callback := callbacks.NewNodeTaggingCallback(fileCallback, node)
+				Expect(callback.Call(&nodeTestOutput{Msg: node}, "test")).To(Succeed())
+			}
+			Expect(fileCallback.CleanUp()).To(Succeed())
+
+			content, err := os.ReadFile(outputFile)
+			Expect(err).NotTo(HaveOccurred())
+			lines := strings.Split(strings.TrimSpace(string(content)), "\n")
+			Expect(lines).To(HaveLen(len(nodes)))
+			for i, line := range lines {
+				ndjsonLine := map[string]any{}
+				Expect(json.Unmarshal([]byte(line), &ndjsonLine)).To(Succeed())
+				Expect(ndjsonLine["node"]).To(Equal(nodes[i]))
+				Expect(ndjsonLine["data"]).To(Equal(map[string]any{"msg": nodes[i]}))
+			}
+		})
+	})
+	When("the daemon is not running on any node", func() {
+		It("should return an error", func() {
+			testutils.GetMockedClientSet()
+			_, err := DiscoverNodes("")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"time"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/contexts"
+)
+
+// RunOptions holds the settings of a run, they are set from the flags of the collect command
+// so see its help for what each one does
+type RunOptions struct {
+	PodOptions        *contexts.PodOptions
+	OutputFields      map[string][]string
+	RedactedFields    map[string][]string
+	SysfsAttributes   map[string]string
+	GNSSExtraMessages []string
+	KubeConfig        string
+	OutputFile        string
+	OutputDir         string
+	SplitOutputDir    string
+	PTPInterface      string
+	NodeName          string
+	LogsOutputFile    string
+	TempDir           string
+	GNSSSource        string
+	ListenAddress     string
+
+	OutputFormat           callbacks.OutputFormat
+	AsyncQueuePolicy       callbacks.QueueFullPolicy
+	RequestedDuration      time.Duration
+	Deadline               time.Duration
+	FlushInterval          time.Duration
+	Warmup                 time.Duration
+	DPLLSmoothingAlpha     float64
+	PollCount              int
+	PollInterval           int
+	DevInfoAnnouceInterval int
+	OutputBufferSize       int
+	GzipLevel              int
+	SampleEvery            int
+	AsyncQueueSize         int
+	ExpectedRFBlocks       int
+	GNSSDeviceIndex        int
+
+	AnnounceOnce         bool
+	FlushOnAnnounce      bool
+	TagNode              bool
+	IncludeLogTimestamps bool
+	KeepDebugFiles       bool
+	AppendOutput         bool
+	ValidateOutput       bool
+	StrictRFBlocks       bool
+	DPLLChangesOnly      bool
+	SkipDeviceCheck      bool
+	StrictParse          bool
+	NoCoreutils          bool
+	WriteRunManifest     bool
+}
+
+// collectionConstructor returns the constructor passed to the builder of each collector
+func (options *RunOptions) collectionConstructor(
+	callback callbacks.Callback,
+	clientset *clients.Clientset,
+	erroredPolls chan collectors.PollResult,
+) *collectors.CollectionConstructor {
+	return &collectors.CollectionConstructor{
+		Callback:               callback,
+		PTPInterface:           options.PTPInterface,
+		NodeName:               options.NodeName,
+		Clientset:              clientset,
+		PollInterval:           options.PollInterval,
+		DevInfoAnnouceInterval: options.DevInfoAnnouceInterval,
+		ErroredPolls:           erroredPolls,
+		LogsOutputFile:         options.LogsOutputFile,
+		IncludeLogTimestamps:   options.IncludeLogTimestamps,
+		TempDir:                options.TempDir,
+		KeepDebugFiles:         options.KeepDebugFiles,
+		PodOptions:             options.PodOptions,
+		ExpectedRFBlocks:       options.ExpectedRFBlocks,
+		StrictRFBlocks:         options.StrictRFBlocks,
+		DPLLSmoothingAlpha:     options.DPLLSmoothingAlpha,
+		DPLLChangesOnly:        options.DPLLChangesOnly,
+		GNSSSource:             options.GNSSSource,
+		GNSSExtraMessages:      options.GNSSExtraMessages,
+		GNSSDeviceIndex:        options.GNSSDeviceIndex,
+		SkipDeviceCheck:        options.SkipDeviceCheck,
+		StrictParse:            options.StrictParse,
+		NoCoreutils:            options.NoCoreutils,
+		SysfsAttributes:        options.SysfsAttributes,
+	}
+}
@@ -0,0 +1,58 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/pprof"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func pprofHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return mux
+}
+
+// StartPprofServer serves the Go profiling endpoints under /debug/pprof/ on address so the
+// collection can be profiled while it runs. Nothing is served if address is empty, otherwise
+// an error is returned if the address can not be listened on. The Addr of the returned server
+// is the address which was listened on.
+func StartPprofServer(address string) (*http.Server, error) {
+	if address == "" {
+		return nil, nil //nolint:nilnil // there is no server when profiling is not requested
+	}
+	listener, err := net.Listen("tcp", address)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	server := &http.Server{
+		Addr:              listener.Addr().String(),
+		Handler:           pprofHandler(),
+		ReadHeaderTimeout: healthReadTimeout,
+	}
+	go func() {
+		serveErr := server.Serve(listener)
+		if serveErr != nil && !errors.Is(serveErr, http.ErrServerClosed) {
+			log.Errorf("pprof server stopped: %s", serveErr.Error())
+		}
+	}()
+	log.Infof("Serving pprof on %s", server.Addr)
+	return server, nil
+}
+
+// StopPprofServer stops the server returned by StartPprofServer, it does nothing if there is no server
+func StopPprofServer(server *http.Server) {
+	if server == nil {
+		return
+	}
+	stopHealthServer(server)
+}
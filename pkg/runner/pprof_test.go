@@ -0,0 +1,45 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("StartPprofServer", func() {
+	When("an address is given", func() {
+		It("should serve the profiling endpoints", func() {
+			server, err := StartPprofServer("127.0.0.1:0")
+			Expect(err).NotTo(HaveOccurred())
+			DeferCleanup(StopPprofServer, server)
+
+			request, err := http.NewRequestWithContext(
+				context.Background(), http.MethodGet, fmt.Sprintf("http://%s/debug/pprof/cmdline", server.Addr), http.NoBody,
+			)
+			Expect(err).NotTo(HaveOccurred())
+			response, err := http.DefaultClient.Do(request)
+			Expect(err).NotTo(HaveOccurred())
+			defer response.Body.Close()
+			Expect(response.StatusCode).To(Equal(http.StatusOK))
+		})
+	})
+	When("no address is given", func() {
+		It("should not start a server", func() {
+			server, err := StartPprofServer("")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(server).To(BeNil())
+			StopPprofServer(server)
+		})
+	})
+	When("the address can not be listened on", func() {
+		It("should return an error", func() {
+			_, err := StartPprofServer("not an address")
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
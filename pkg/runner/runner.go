@@ -4,8 +4,11 @@ package runner
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
+	"sync"
 	"syscall"
 	"time"
 
@@ -14,14 +17,66 @@ import (
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
 	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
 )
 
 const (
-	maxRunningPolls      = 3
 	pollResultsQueueSize = 10
+	slowPollFactor       = 2
+	shutdownWaitTimeout  = 10 * time.Second
+	clusterInfoTag       = "cluster-info"
+	outputDirPermissions = 0755
+
+	// UntilDuration is the poll count used to poll until the requested duration has passed
+	UntilDuration = 0
+	// InfinitePolls is the poll count used to poll until a signal is received
+	InfinitePolls = -1
 )
 
+// forcedShutdownTimeout is how long is spent stopping the collectors
+// and cleaning up once the deadline has been reached
+var forcedShutdownTimeout = 10 * time.Second
+
+var (
+	// pollSlots limits the polls running at once across every runner, it is nil when there is no limit
+	pollSlots     chan struct{}
+	pollSlotsLock sync.RWMutex
+)
+
+// SetMaxConcurrency limits the number of polls running at once across all the collectors of every runner
+// so that many collectors and interfaces do not overwhelm the API server with exec streams.
+// A limit of 0 removes the limit. Polls which are waiting for a slot still count as running for
+// their collector so it skips polls rather than queueing them up.
+func SetMaxConcurrency(limit int) {
+	pollSlotsLock.Lock()
+	defer pollSlotsLock.Unlock()
+	if limit <= 0 {
+		pollSlots = nil
+		return
+	}
+	pollSlots = make(chan struct{}, limit)
+}
+
+// acquirePollSlot blocks until a poll may run and returns the function which frees its slot
+func acquirePollSlot() func() {
+	pollSlotsLock.RLock()
+	slots := pollSlots
+	pollSlotsLock.RUnlock()
+	if slots == nil {
+		return func() {}
+	}
+	slots <- struct{}{}
+	return func() { <-slots }
+}
+
+// gatedPoll polls the collector once a slot is available
+func (runner *CollectorRunner) gatedPoll(collector collectors.Collector, runningPolls *utils.WaitGroupCount) {
+	release := acquirePollSlot()
+	defer release()
+	collector.Poll(runner.pollResults, runningPolls)
+}
+
 // getQuitChannel creates and returns a channel for notifying
 // that a exit signal has been received
 func getQuitChannel() chan os.Signal {
@@ -32,62 +87,81 @@ func getQuitChannel() chan os.Signal {
 }
 
 type CollectorRunner struct {
+	parseFailure           error
+	flusher                callbacks.Flusher
 	endTime                time.Time
+	deadline               time.Time
 	quit                   chan os.Signal
 	collectorQuitChannel   map[string]chan os.Signal
 	pollResults            chan collectors.PollResult
 	erroredPolls           chan collectors.PollResult
 	collectorInstances     map[string]collectors.Collector
 	collectorNames         []string
+	startedCollectors      []string
+	stats                  *pollStats
+	statistics             *callbacks.StatisticsCallback
 	runningCollectorsWG    utils.WaitGroupCount
 	runningAnnouncersWG    utils.WaitGroupCount
 	pollInterval           int
+	pollCount              int
 	devInfoAnnouceInterval int
+	announceOnce           bool
+	flushOnAnnounce        bool
 	onlyAnnouncers         bool
+	strictParse            bool
+}
+
+// newPollResultsChannel returns the channel the polls of collectorCount collectors send their results to.
+// A poller only runs one poll at a time so with room for a result from every collector a poll does not
+// block on sending its result while the main loop is busy e.g. writing output. If the main loop falls
+// further behind than that the blocked poll is still counted as running so its poller skips polls
+// rather than starting more goroutines.
+func newPollResultsChannel(collectorCount int) chan collectors.PollResult {
+	size := pollResultsQueueSize
+	if collectorCount > size {
+		size = collectorCount
+	}
+	return make(chan collectors.PollResult, size)
 }
 
-func NewCollectorRunner(selectedCollectors []string) *CollectorRunner {
+// NewCollectorRunner returns a CollectorRunner for the selected collectors,
+// see GetCollectorsToRun for how strictCollectors is used
+func NewCollectorRunner(selectedCollectors []string, strictCollectors bool) (*CollectorRunner, error) {
+	collectorNames, err := GetCollectorsToRun(selectedCollectors, strictCollectors)
+	if err != nil {
+		return nil, err
+	}
 	return &CollectorRunner{
 		collectorInstances:   make(map[string]collectors.Collector),
-		collectorNames:       GetCollectorsToRun(selectedCollectors),
+		collectorNames:       collectorNames,
 		quit:                 getQuitChannel(),
-		pollResults:          make(chan collectors.PollResult, pollResultsQueueSize),
+		pollResults:          newPollResultsChannel(len(collectorNames)),
 		erroredPolls:         make(chan collectors.PollResult, pollResultsQueueSize),
 		collectorQuitChannel: make(map[string]chan os.Signal, 1),
 		onlyAnnouncers:       false,
-	}
+	}, nil
+}
+
+// IsSelected returns true if the named collector will be run
+func (runner *CollectorRunner) IsSelected(collectorName string) bool {
+	return isIn(collectorName, runner.collectorNames)
 }
 
 // initialise will call theconstructor for each
 // value in collector name, it will panic if a collector name is not known.
-func (runner *CollectorRunner) initialise( //nolint:funlen // allow a slightly long function
+func (runner *CollectorRunner) initialise(
 	callback callbacks.Callback,
-	ptpInterface string,
 	clientset *clients.Clientset,
-	pollInterval int,
-	requestedDuration time.Duration,
-	devInfoAnnouceInterval int,
-	logsOutputFile string,
-	includeLogTimestamps bool,
-	tempDir string,
-	keepDebugFiles bool,
-) {
-	runner.pollInterval = pollInterval
-	runner.endTime = time.Now().Add(requestedDuration)
-	runner.devInfoAnnouceInterval = devInfoAnnouceInterval
-
-	constructor := &collectors.CollectionConstructor{
-		Callback:               callback,
-		PTPInterface:           ptpInterface,
-		Clientset:              clientset,
-		PollInterval:           pollInterval,
-		DevInfoAnnouceInterval: devInfoAnnouceInterval,
-		ErroredPolls:           runner.erroredPolls,
-		LogsOutputFile:         logsOutputFile,
-		IncludeLogTimestamps:   includeLogTimestamps,
-		TempDir:                tempDir,
-		KeepDebugFiles:         keepDebugFiles,
-	}
+	options *RunOptions,
+) error {
+	runner.pollInterval = options.PollInterval
+	runner.endTime = time.Now().Add(options.RequestedDuration)
+	runner.pollCount = options.PollCount
+	runner.stats = newPollStats()
+	runner.devInfoAnnouceInterval = options.DevInfoAnnouceInterval
+	runner.strictParse = options.StrictParse
+
+	constructor := options.collectionConstructor(callback, clientset, runner.erroredPolls)
 
 	registry := collectors.GetRegistry()
 
@@ -105,13 +179,16 @@ func (runner *CollectorRunner) initialise( //nolint:funlen // allow a slightly l
 			// so that it doesn't get ran
 			log.Warning(err.Error())
 		} else {
-			utils.IfErrorExitOrPanic(err)
+			if err != nil {
+				return fmt.Errorf("failed to create collector %s: %w", collectorName, err)
+			}
 			runner.collectorInstances[collectorName] = newCollector
 			log.Debugf("Added collector %T, %v", newCollector, newCollector)
 		}
 	}
 	log.Debugf("Collectors %v", runner.collectorInstances)
 	runner.setOnlyAnnouncers()
+	return nil
 }
 
 func (runner *CollectorRunner) setOnlyAnnouncers() {
@@ -125,12 +202,26 @@ func (runner *CollectorRunner) setOnlyAnnouncers() {
 	runner.onlyAnnouncers = onlyAnnouncers
 }
 
+// shouldKeepPolling returns true until the collector has been polled pollCount times,
+// or the requested duration has passed when the poll count is UntilDuration.
+// Announcers are polled for as long as any other collector is running
+// unless announceOnce is set in which case they are only polled at the start.
 func (runner *CollectorRunner) shouldKeepPolling(
 	collector collectors.Collector,
+	polls int,
 ) bool {
+	if collector.IsAnnouncer() && runner.announceOnce {
+		return polls < 1
+	}
 	if collector.IsAnnouncer() && !runner.onlyAnnouncers {
 		return runner.runningCollectorsWG.GetCount() > 0
-	} else {
+	}
+	switch {
+	case runner.pollCount == InfinitePolls:
+		return true
+	case runner.pollCount > 0:
+		return polls < runner.pollCount
+	default:
 		return time.Since(runner.endTime) <= 0
 	}
 }
@@ -143,15 +234,17 @@ func (runner *CollectorRunner) poller(
 ) {
 	defer wg.Done()
 	var lastPoll time.Time
+	skippedPolls := 0
+	polls := 0
 	pollInterval := collector.GetPollInterval()
 	runningPolls := utils.WaitGroupCount{}
 	log.Debugf("Collector with poll interval %f ", pollInterval.Seconds())
-	for runner.shouldKeepPolling(collector) {
-		// If pollResults were to block we do not want to keep spawning polls
-		// so we shouldn't allow too many polls to be running simultaneously
-		if runningPolls.GetCount() >= maxRunningPolls {
-			runningPolls.Wait()
+	defer func() {
+		if skippedPolls > 0 {
+			log.Warnf("Collector %s skipped %d polls as the previous poll was still running", collectorName, skippedPolls)
 		}
+	}()
+	for runner.shouldKeepPolling(collector, polls) {
 		log.Debugf("Collector GoRoutine: %s", collectorName)
 		select {
 		case <-quit:
@@ -170,9 +263,17 @@ func (runner *CollectorRunner) poller(
 			)
 			if lastPoll.IsZero() || time.Since(lastPoll) > pollInterval {
 				lastPoll = time.Now()
+				// If the previous poll is overrunning skip this one rather than
+				// letting polls (and their goroutines) pile up
+				if runningPolls.GetCount() > 0 {
+					skippedPolls++
+					log.Debugf("skipped poll %s as the previous poll is still running", collectorName)
+					continue
+				}
 				log.Debugf("poll %s", collectorName)
+				polls++
 				runningPolls.Add(1)
-				go collector.Poll(runner.pollResults, &runningPolls)
+				go runner.gatedPoll(collector, &runningPolls)
 			}
 			time.Sleep(time.Microsecond)
 		}
@@ -181,16 +282,79 @@ func (runner *CollectorRunner) poller(
 	log.Debugf("Collector finished %s", collectorName)
 }
 
-// start configures all collectors to start collecting all their data keys
-func (runner *CollectorRunner) start() {
-	for collectorName, collector := range runner.collectorInstances {
+// checkPollDuration warns when a poll took much longer than the collectors poll interval
+// as the data it returned will not be evenly spaced with the other polls.
+// Some collectors (e.g. logs) stream for their whole interval so allow some slack.
+func (runner *CollectorRunner) checkPollDuration(pollRes collectors.PollResult) {
+	collector, ok := runner.collectorInstances[pollRes.CollectorName]
+	if !ok {
+		return
+	}
+	if pollRes.Duration > slowPollFactor*collector.GetPollInterval() {
+		log.Warnf(
+			"Poll %s started at %s took %s which is much longer than its poll interval %s",
+			pollRes.CollectorName,
+			pollRes.StartedAt.Format(time.RFC3339Nano),
+			pollRes.Duration,
+			collector.GetPollInterval(),
+		)
+	}
+}
+
+// waitForShutdown waits a bounded time for the collectors to finish, draining poll results
+// so that they are not blocked from finishing. It returns false if any are still running.
+func (runner *CollectorRunner) waitForShutdown(timeout time.Duration) bool {
+	deadline := time.Now().Add(timeout)
+	for (runner.runningCollectorsWG.GetCount() + runner.runningAnnouncersWG.GetCount()) > 0 {
+		if time.Now().After(deadline) {
+			log.Warnf(
+				"%d collectors and %d announcers still running after %s",
+				runner.runningCollectorsWG.GetCount(),
+				runner.runningAnnouncersWG.GetCount(),
+				timeout,
+			)
+			return false
+		}
+		select {
+		case pollRes := <-runner.pollResults:
+			log.Infof("Received %v", pollRes)
+		default:
+			time.Sleep(time.Millisecond)
+		}
+	}
+	return true
+}
+
+// start configures all collectors to start collecting all their data keys.
+// If a collector fails to start (or panics) the collectors which have
+// already started are stopped and cleaned up so that no pods are leaked.
+func (runner *CollectorRunner) start() error {
+	defer func() {
+		if r := recover(); r != nil {
+			runner.stopPollers(os.Interrupt)
+			runner.cleanUpAll()
+			panic(r)
+		}
+	}()
+	for _, collectorName := range runner.collectorNames {
+		collector, ok := runner.collectorInstances[collectorName]
+		if !ok {
+			continue
+		}
 		log.Debugf("start collector %v", collector)
 		err := collector.Start()
-		utils.IfErrorExitOrPanic(err)
+		if err != nil {
+			log.Errorf("failed to start collector %s, cleaning up started collectors", collectorName)
+			runner.stopPollers(os.Interrupt)
+			runner.cleanUpAll()
+			return fmt.Errorf("failed to start collector %s: %w", collectorName, err)
+		}
+		runner.startedCollectors = append(runner.startedCollectors, collectorName)
+		if runner.stats != nil {
+			runner.stats.addCollector(collectorName, collector.GetPollInterval())
+		}
 
 		log.Debugf("Spawning  collector: %v", collector)
-		collectorName := collectorName
-		collector := collector
 		quit := make(chan os.Signal, 1)
 		if collector.IsAnnouncer() {
 			runner.collectorQuitChannel[collectorName] = quit
@@ -202,86 +366,389 @@ func (runner *CollectorRunner) start() {
 			go runner.poller(collectorName, collector, quit, &runner.runningCollectorsWG)
 		}
 	}
+	return nil
+}
+
+// stopPollers forwards the signal to the pollers of the started collectors
+// and waits a bounded time for them to stop
+func (runner *CollectorRunner) stopPollers(sig os.Signal) {
+	runner.stopPollersWithin(sig, shutdownWaitTimeout)
+}
+
+func (runner *CollectorRunner) stopPollersWithin(sig os.Signal, timeout time.Duration) {
+	for collectorName, quit := range runner.collectorQuitChannel {
+		log.Infof("Killed shutting down: %s", collectorName)
+		quit <- sig
+	}
+	runner.collectorQuitChannel = make(map[string]chan os.Signal)
+	runner.waitForShutdown(timeout)
 }
 
-// cleanup calls cleanup on each collector
+// cleanup calls cleanup on each started collector, carrying on if one fails
+// so that the others (e.g. ones which created pods) still get cleaned up
 func (runner *CollectorRunner) cleanUpAll() {
-	for collectorName, collector := range runner.collectorInstances {
+	for _, collectorName := range runner.startedCollectors {
 		log.Debugf("cleanup %s", collectorName)
-		err := collector.CleanUp()
-		utils.IfErrorExitOrPanic(err)
+		err := runner.collectorInstances[collectorName].CleanUp()
+		if err != nil {
+			log.Errorf("failed to cleanup %s: %s", collectorName, err.Error())
+		}
 	}
+	runner.startedCollectors = nil
 }
 
-// Run manages set of collectors.
-// It first initialises them,
-// then polls them on the correct cadence and
-// finally cleans up the collectors when exiting
-func (runner *CollectorRunner) Run( //nolint:funlen // allow a slightly long function
-	kubeConfig string,
-	outputFile string,
-	requestedDuration time.Duration,
-	pollInterval int,
-	devInfoAnnouceInterval int,
-	ptpInterface string,
-	useAnalyserJSON bool,
-	logsOutputFile string,
-	includeLogTimestamps bool,
-	tempDir string,
-	keepDebugFiles bool,
-) {
-	clientset, err := clients.GetClientset(kubeConfig)
-	utils.IfErrorExitOrPanic(err)
-
-	outputFormat := callbacks.Raw
-	if useAnalyserJSON {
-		outputFormat = callbacks.AnalyserJSON
-	}
-
-	callback, err := callbacks.SetupCallback(outputFile, outputFormat)
-	utils.IfErrorExitOrPanic(err)
-	runner.initialise(
-		callback,
-		ptpInterface,
-		clientset,
-		pollInterval,
-		requestedDuration,
-		devInfoAnnouceInterval,
-		logsOutputFile,
-		includeLogTimestamps,
-		tempDir,
-		keepDebugFiles,
-	)
-	runner.start()
+// flushAfterAnnounce flushes the output when flushOnAnnounce is set and the poll was
+// from an announcer so the output is written out on each announce tick
+func (runner *CollectorRunner) flushAfterAnnounce(pollRes collectors.PollResult) {
+	if !runner.flushOnAnnounce || runner.flusher == nil {
+		return
+	}
+	collector, ok := runner.collectorInstances[pollRes.CollectorName]
+	if !ok || !collector.IsAnnouncer() {
+		return
+	}
+	err := runner.flusher.Flush()
+	if err != nil {
+		log.Errorf("failed to flush output after %s announced: %s", pollRes.CollectorName, err.Error())
+	}
+}
+
+func (runner *CollectorRunner) handlePollResult(pollRes collectors.PollResult) {
+	log.Infof("Received %v", pollRes)
+	runner.checkPollDuration(pollRes)
+	if runner.stats != nil {
+		runner.stats.record(pollRes)
+	}
+	if runner.strictParse && runner.parseFailure == nil {
+		for _, err := range pollRes.Errors {
+			if fetcher.IsParseError(err) {
+				runner.parseFailure = fmt.Errorf("poll %s failed to parse in strict parse mode: %w", pollRes.CollectorName, err)
+				return
+			}
+		}
+	}
+	runner.flushAfterAnnounce(pollRes)
+	if len(pollRes.Errors) > 0 {
+		log.Warnf("Poll %s had issues: %v. Will retry next poll", pollRes.CollectorName, pollRes.Errors)
+		// If erroredPolls blocks it could cause pollResults to fill and
+		// block the execution of the collectors. It is only read by the
+		// DevInfo collector so drop the result if nothing is reading it.
+		select {
+		case runner.erroredPolls <- pollRes:
+		default:
+			log.Debugf("erroredPolls is full, not forwarding poll %s", pollRes.CollectorName)
+		}
+	}
+}
 
+// deadlineReached returns a channel which receives when the deadline is reached,
+// if no deadline is set it never receives
+func (runner *CollectorRunner) deadlineReached() <-chan time.Time {
+	if runner.deadline.IsZero() {
+		return nil
+	}
+	return time.After(time.Until(runner.deadline))
+}
+
+// cleanUp cleans up the collectors and the callback. If a deadline is set cleaning up is
+// abandoned forcedShutdownTimeout after it, so waiting e.g. for pods to be deleted can not
+// keep the process running indefinitely.
+func (runner *CollectorRunner) cleanUp(callback callbacks.Callback) error {
+	if runner.deadline.IsZero() {
+		runner.cleanUpAll()
+		return callback.CleanUp() //nolint:wrapcheck // the callbacks wrap their errors
+	}
+	done := make(chan error, 1)
+	go func() {
+		runner.cleanUpAll()
+		done <- callback.CleanUp()
+	}()
+	select {
+	case err := <-done:
+		return err
+	case <-time.After(time.Until(runner.deadline.Add(forcedShutdownTimeout))):
+		log.Errorf(
+			"Clean up did not finish within %s of the deadline, the output may be incomplete "+
+				"and pods created by the collectors may need deleting manually",
+			forcedShutdownTimeout,
+		)
+	}
+	return nil
+}
+
+// collect runs the started collectors until they finish, a signal is received, the deadline
+// is reached or a poll fails to parse in strict parse mode, then cleans up the collectors
+// and the callback so buffered output is flushed. The callback is also cleaned up if a collector
+// fails to start so the output written so far is not lost.
+func (runner *CollectorRunner) collect(callback callbacks.Callback) error {
+	err := runner.start()
+	if err != nil {
+		return cleanUpAfterError(callback, err)
+	}
+	// The warmup is from when the collectors have started so slow starts do not use it up
+	if runner.statistics != nil {
+		runner.statistics.StartWarmup()
+	}
+
+	deadlineReached := runner.deadlineReached()
 	// Use wg count to know if any collectors are running.
-	for (runner.runningCollectorsWG.GetCount() + runner.runningAnnouncersWG.GetCount()) > 0 {
+	killed := false
+	for !killed && (runner.runningCollectorsWG.GetCount()+runner.runningAnnouncersWG.GetCount()) > 0 {
 		log.Debugf("Main Loop ")
 		select {
 		case sig := <-runner.quit:
 			log.Info("Killed shutting down")
-			// Forward signal to collector QuitChannels
-			for collectorName, quit := range runner.collectorQuitChannel {
-				log.Infof("Killed shutting down: %s", collectorName)
-				quit <- sig
-			}
-			runner.runningCollectorsWG.Wait()
-			runner.runningAnnouncersWG.Wait()
+			runner.stopPollers(sig)
+			killed = true
+		case <-deadlineReached:
+			log.Warn("Deadline reached forcing shutdown")
+			// Leave some of the time for cleaning up
+			runner.stopPollersWithin(os.Interrupt, forcedShutdownTimeout/2) //nolint:gomnd // half the timeout
+			killed = true
 		case pollRes := <-runner.pollResults:
-			log.Infof("Received %v", pollRes)
-			if len(pollRes.Errors) > 0 {
-				log.Warnf("Poll %s had issues: %v. Will retry next poll", pollRes.CollectorName, pollRes.Errors)
-				// If erroredPolls blocks it could cause pollResults to fill and
-				// block the execution of the collectors.
-				runner.erroredPolls <- pollRes
+			runner.handlePollResult(pollRes)
+			if runner.parseFailure != nil {
+				log.Error(runner.parseFailure)
+				runner.stopPollers(os.Interrupt)
+				killed = true
 			}
 		default:
 			log.Debug("Sleeping main func")
 			time.Sleep(time.Millisecond)
 		}
 	}
+	// The final polls can finish after the loop last checked for results
+	for !killed && len(runner.pollResults) > 0 {
+		runner.handlePollResult(<-runner.pollResults)
+	}
 	log.Info("Doing Cleanup")
-	runner.cleanUpAll()
-	err = callback.CleanUp()
-	utils.IfErrorExitOrPanic(err)
+	return runner.cleanUp(callback)
+}
+
+// flushPeriodically flushes buffered output every interval until stop is closed
+// so that most of the output is persisted even if the process dies
+func flushPeriodically(flusher callbacks.Flusher, interval time.Duration, stop chan struct{}) {
+	if interval <= 0 {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			err := flusher.Flush()
+			if err != nil {
+				log.Errorf("failed to flush output: %s", err.Error())
+			}
+		}
+	}
+}
+
+// writeClusterInfo records which cluster the output was collected from
+func writeClusterInfo(clientset *clients.Clientset, callback callbacks.Callback) {
+	clusterInfo, err := clientset.GetClusterInfo()
+	if err != nil {
+		log.Warnf("failed to get the cluster info: %s", err.Error())
+		return
+	}
+	err = callback.Call(clusterInfo, clusterInfoTag)
+	if err != nil {
+		log.Errorf("callback failed for the cluster info: %s", err.Error())
+	}
+}
+
+// getOutputDirFile returns the path within outputDir for the output of this run,
+// the name is generated from the start time, the cluster and the collectors
+// and has a .gz suffix if the output is compressed
+func (runner *CollectorRunner) getOutputDirFile(
+	outputDir string,
+	clientset *clients.Clientset,
+	outputFormat callbacks.OutputFormat,
+	compressed bool,
+) (string, error) {
+	err := os.MkdirAll(outputDir, outputDirPermissions)
+	if err != nil {
+		return "", fmt.Errorf("failed to create output directory: %w", err)
+	}
+	cluster := ""
+	clusterInfo, err := clientset.GetClusterInfo()
+	if err != nil {
+		log.Warnf("failed to get the cluster info for the output file name: %s", err.Error())
+	} else {
+		cluster = clusterInfo.Cluster
+	}
+	outputFile := filepath.Join(
+		outputDir,
+		callbacks.GetOutputFileName(time.Now(), cluster, runner.collectorNames, outputFormat),
+	)
+	if compressed {
+		outputFile += ".gz"
+	}
+	log.Infof("Writing output to %s", outputFile)
+	return outputFile, nil
+}
+
+// ConfigureClientset applies the exec and pod lookup settings to the clientset shared by every run,
+// it must be called before any runs start as they use the clientset concurrently
+func ConfigureClientset(
+	kubeConfig string,
+	execTimeout time.Duration,
+	podLookupAttempts int,
+	podLookupBackoff time.Duration,
+) error {
+	clientset, err := clients.GetClientset(kubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get clientset: %w", err)
+	}
+	clientset.ExecTimeout = execTimeout
+	clientset.PodLookupAttempts = podLookupAttempts
+	clientset.PodLookupBackoff = podLookupBackoff
+	return nil
+}
+
+// Run manages set of collectors.
+// It first initialises them,
+// then polls them on the correct cadence and
+// finally cleans up the collectors when exiting.
+// Errors are returned rather than exiting so that the other runs of a
+// multi interface or multi node collection can still clean up.
+func (runner *CollectorRunner) Run(options *RunOptions) error { //nolint:funlen,gocyclo,cyclop // allow a long function
+	startTime := time.Now()
+	outputFile := options.OutputFile
+	if options.Deadline > 0 {
+		runner.deadline = time.Now().Add(options.Deadline)
+	}
+	runner.announceOnce = options.AnnounceOnce
+	runner.flushOnAnnounce = options.FlushOnAnnounce
+	clientset, err := clients.GetClientset(options.KubeConfig)
+	if err != nil {
+		return fmt.Errorf("failed to get clientset: %w", err)
+	}
+
+	if options.OutputDir != "" {
+		outputFile, err = runner.getOutputDirFile(
+			options.OutputDir,
+			clientset,
+			options.OutputFormat,
+			options.GzipLevel != callbacks.GzipDisabled,
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	var callback callbacks.Callback
+	if options.SplitOutputDir != "" {
+		callback, err = callbacks.NewSplitFileCallback(options.SplitOutputDir, options.OutputFormat, options.AppendOutput)
+	} else {
+		callback, err = callbacks.SetupCallback(
+			outputFile,
+			options.OutputFormat,
+			options.AppendOutput,
+			options.OutputBufferSize,
+			options.GzipLevel,
+		)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to set up the output: %w", err)
+	}
+	var countingCallback *callbacks.CountingCallback
+	if options.WriteRunManifest {
+		countingCallback = callbacks.NewCountingCallback(callback)
+		callback = countingCallback
+	}
+	if options.TagNode {
+		callback = callbacks.NewNodeTaggingCallback(callback, options.NodeName)
+	}
+	if len(options.OutputFields) > 0 {
+		callback = callbacks.NewProjectingCallback(callback, options.OutputFields)
+	}
+	if len(options.RedactedFields) > 0 {
+		callback = callbacks.NewRedactingCallback(callback, options.RedactedFields)
+	}
+	// Validation is done before the projection as that removes required fields
+	if options.ValidateOutput {
+		callback = callbacks.NewValidatingCallback(callback)
+	}
+	if options.SampleEvery > 1 {
+		callback = callbacks.NewDecimatingCallback(
+			callback,
+			options.SampleEvery,
+			append(collectors.UndecimatedTags(), clusterInfoTag)...,
+		)
+	}
+	// The statistics are of every output seen during the run so must be outside the decimation
+	statisticsCallback := callbacks.NewStatisticsCallback(callback, statisticsFields, options.Warmup)
+	callback = statisticsCallback
+	runner.statistics = statisticsCallback
+	// The async callback is outermost so the other wrappers run on its dispatch goroutine rather than delaying polls
+	var asyncCallback *callbacks.AsyncCallback
+	if options.AsyncQueueSize > 0 {
+		asyncCallback, err = callbacks.NewAsyncCallback(callback, options.AsyncQueueSize, options.AsyncQueuePolicy)
+		if err != nil {
+			return cleanUpAfterError(callback, err)
+		}
+		callback = asyncCallback
+	}
+	if flusher, ok := callback.(callbacks.Flusher); ok {
+		runner.flusher = flusher
+	}
+	if runner.flusher != nil && (options.OutputBufferSize > 0 || options.GzipLevel != callbacks.GzipDisabled) {
+		flushInterval := options.FlushInterval
+		if flushInterval == 0 {
+			flushInterval = time.Duration(options.DevInfoAnnouceInterval) * time.Second
+		}
+		stopFlushing := make(chan struct{})
+		go flushPeriodically(runner.flusher, flushInterval, stopFlushing)
+		defer close(stopFlushing)
+	}
+	writeClusterInfo(clientset, callback)
+	err = runner.initialise(callback, clientset, options)
+	if err != nil {
+		return cleanUpAfterError(callback, err)
+	}
+	if options.ListenAddress != "" {
+		server, serverErr := startHealthServer(options.ListenAddress, runner.stats)
+		if serverErr != nil {
+			return cleanUpAfterError(callback, serverErr)
+		}
+		defer stopHealthServer(server)
+	}
+	collectErr := runner.collect(callback)
+	if asyncCallback != nil && asyncCallback.Dropped() > 0 {
+		log.Warnf("%d outputs were dropped because the async queue was full", asyncCallback.Dropped())
+	}
+	statistics := statisticsCallback.Summaries()
+	if excluded := statisticsCallback.WarmupExcluded(); excluded > 0 {
+		log.Infof("%d outputs in the %s warmup were excluded from the statistics", excluded, options.Warmup)
+	}
+	logStatistics(statistics)
+	if countingCallback != nil {
+		manifest := runner.buildManifest(
+			startTime,
+			time.Now(),
+			getManifestTarget(clientset, options.NodeName, options.PTPInterface),
+			countingCallback.Counts(),
+			statistics,
+		)
+		err = writeManifest(getManifestPath(outputFile, options.SplitOutputDir), manifest)
+		if err != nil {
+			log.Errorf("failed to write the run manifest: %s", err.Error())
+		}
+	}
+	if runner.parseFailure != nil {
+		return runner.parseFailure
+	}
+	return collectErr
+}
+
+// cleanUpAfterError cleans up the callback when a run fails before collecting
+// so the output written so far is flushed, then returns err
+func cleanUpAfterError(callback callbacks.Callback, err error) error {
+	if cleanUpErr := callback.CleanUp(); cleanUpErr != nil {
+		log.Errorf("failed to clean up the output: %s", cleanUpErr.Error())
+	}
+	return err
 }
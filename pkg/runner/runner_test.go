@@ -0,0 +1,727 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/callbacks"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/fetcher"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// blockingCollector is a collector whose polls do not return until it is released
+type blockingCollector struct {
+	release      chan bool
+	pollInterval time.Duration
+	polls        int64
+}
+
+func (c *blockingCollector) Start() error {
+	return nil
+}
+
+func (c *blockingCollector) CleanUp() error {
+	return nil
+}
+
+func (c *blockingCollector) IsAnnouncer() bool {
+	return false
+}
+
+func (c *blockingCollector) GetPollInterval() time.Duration {
+	return c.pollInterval
+}
+
+func (c *blockingCollector) Poll(resultsChan chan collectors.PollResult, wg *utils.WaitGroupCount) {
+	defer wg.Done()
+	atomic.AddInt64(&c.polls, 1)
+	<-c.release
+	resultsChan <- collectors.PollResult{CollectorName: "blocking"}
+}
+
+// cleanupCollector is a collector which records if it has been cleaned up
+type cleanupCollector struct {
+	cleanedUp int64
+}
+
+func (c *cleanupCollector) Start() error {
+	return nil
+}
+
+func (c *cleanupCollector) CleanUp() error {
+	atomic.StoreInt64(&c.cleanedUp, 1)
+	return nil
+}
+
+func (c *cleanupCollector) IsAnnouncer() bool {
+	return false
+}
+
+func (c *cleanupCollector) GetPollInterval() time.Duration {
+	return time.Millisecond
+}
+
+func (c *cleanupCollector) Poll(resultsChan chan collectors.PollResult, wg *utils.WaitGroupCount) {
+	defer wg.Done()
+	resultsChan <- collectors.PollResult{CollectorName: "cleanup"}
+}
+
+// hangingCleanupCollector is a collector whose clean up does not return until it is released
+type hangingCleanupCollector struct {
+	cleanupCollector
+	release chan bool
+}
+
+func (c *hangingCleanupCollector) CleanUp() error {
+	atomic.StoreInt64(&c.cleanedUp, 1)
+	<-c.release
+	return nil
+}
+
+// failingCollector is a collector which fails to start
+type failingCollector struct {
+	cleanupCollector
+}
+
+func (c *failingCollector) Start() error {
+	return errors.New("failed to start")
+}
+
+// slowStartingCollector is a mock collector which takes startDelay to start
+type slowStartingCollector struct {
+	*collectors.MockCollector
+	startDelay time.Duration
+}
+
+func (c *slowStartingCollector) Start() error {
+	time.Sleep(c.startDelay)
+	return c.MockCollector.Start() //nolint:wrapcheck // the mock does not fail to start
+}
+
+// announcingCollector is an announcer which writes a record on each poll
+type announcingCollector struct {
+	callback callbacks.Callback
+	polls    int64
+}
+
+func (c *announcingCollector) Start() error {
+	return nil
+}
+
+func (c *announcingCollector) CleanUp() error {
+	return nil
+}
+
+func (c *announcingCollector) IsAnnouncer() bool {
+	return true
+}
+
+func (c *announcingCollector) GetPollInterval() time.Duration {
+	return time.Millisecond
+}
+
+func (c *announcingCollector) Poll(resultsChan chan collectors.PollResult, wg *utils.WaitGroupCount) {
+	defer wg.Done()
+	poll := atomic.AddInt64(&c.polls, 1)
+	errs := make([]error, 0)
+	err := c.callback.Call(&collectors.MockData{Poll: poll}, "announce")
+	if err != nil {
+		errs = append(errs, err)
+	}
+	resultsChan <- collectors.PollResult{CollectorName: "announcer", Errors: errs}
+}
+
+// parseFailingCollector is a collector whose polls fail to parse from failFrom onwards
+type parseFailingCollector struct {
+	cleanupCollector
+	polls    int64
+	failFrom int64
+}
+
+func (c *parseFailingCollector) Poll(resultsChan chan collectors.PollResult, wg *utils.WaitGroupCount) {
+	defer wg.Done()
+	errs := make([]error, 0)
+	if atomic.AddInt64(&c.polls, 1) >= c.failFrom {
+		errs = append(errs, &fetcher.FetchError{Err: errors.New("unexpected output"), Stage: fetcher.PostProcessStage})
+	}
+	resultsChan <- collectors.PollResult{CollectorName: "parse", Errors: errs}
+}
+
+// concurrentCollector records the most polls running at once across the collectors sharing its counters
+type concurrentCollector struct {
+	cleanupCollector
+	running    *int64
+	maxRunning *int64
+	polls      int64
+}
+
+func (c *concurrentCollector) Poll(resultsChan chan collectors.PollResult, wg *utils.WaitGroupCount) {
+	defer wg.Done()
+	running := atomic.AddInt64(c.running, 1)
+	for {
+		maxRunning := atomic.LoadInt64(c.maxRunning)
+		if running <= maxRunning || atomic.CompareAndSwapInt64(c.maxRunning, maxRunning, running) {
+			break
+		}
+	}
+	time.Sleep(5 * time.Millisecond)
+	atomic.AddInt64(c.running, -1)
+	atomic.AddInt64(&c.polls, 1)
+	resultsChan <- collectors.PollResult{CollectorName: "concurrent"}
+}
+
+// closeRecorder records the output and whether it was closed,
+// writes are locked as collectors polled concurrently share the output
+type closeRecorder struct {
+	buffer bytes.Buffer
+	closed int64
+	lock   sync.Mutex
+}
+
+func (c *closeRecorder) Write(p []byte) (int, error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.buffer.Write(p) //nolint:wrapcheck // writing to a buffer does not fail
+}
+
+func (c *closeRecorder) String() string {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.buffer.String()
+}
+
+func (c *closeRecorder) Close() error {
+	atomic.StoreInt64(&c.closed, 1)
+	return nil
+}
+
+var _ = Describe("collect", func() {
+	When("a signal is received", func() {
+		It("should clean up the collectors and the callback", func() {
+			collector := &cleanupCollector{}
+			output := &closeRecorder{}
+			runner := &CollectorRunner{
+				endTime:              time.Now().Add(time.Hour),
+				quit:                 make(chan os.Signal, 1),
+				collectorQuitChannel: make(map[string]chan os.Signal),
+				collectorInstances:   map[string]collectors.Collector{"cleanup": collector},
+				collectorNames:       []string{"cleanup"},
+				pollResults:          make(chan collectors.PollResult, pollResultsQueueSize),
+				erroredPolls:         make(chan collectors.PollResult, pollResultsQueueSize),
+			}
+
+			done := make(chan bool)
+			go func() {
+				defer GinkgoRecover()
+				Expect(runner.collect(callbacks.NewFileCallback(output, callbacks.Raw))).To(Succeed())
+				close(done)
+			}()
+			runner.quit <- syscall.SIGINT
+			Eventually(done, time.Second).Should(BeClosed())
+			Expect(atomic.LoadInt64(&collector.cleanedUp)).To(Equal(int64(1)))
+			Expect(atomic.LoadInt64(&output.closed)).To(Equal(int64(1)))
+		})
+	})
+	When("the deadline is reached", func() {
+		It("should force a shutdown even if polls and clean up do not finish", func() {
+			defer func(timeout time.Duration) { forcedShutdownTimeout = timeout }(forcedShutdownTimeout)
+			forcedShutdownTimeout = 100 * time.Millisecond
+
+			blocking := &blockingCollector{release: make(chan bool), pollInterval: time.Millisecond}
+			hanging := &hangingCleanupCollector{release: make(chan bool)}
+			defer close(blocking.release)
+			defer close(hanging.release)
+			runner := &CollectorRunner{
+				endTime:              time.Now().Add(time.Hour),
+				deadline:             time.Now().Add(50 * time.Millisecond),
+				quit:                 make(chan os.Signal, 1),
+				collectorQuitChannel: make(map[string]chan os.Signal),
+				collectorInstances: map[string]collectors.Collector{
+					"blocking": blocking,
+					"hanging":  hanging,
+				},
+				collectorNames: []string{"blocking", "hanging"},
+				pollResults:    make(chan collectors.PollResult, pollResultsQueueSize),
+				erroredPolls:   make(chan collectors.PollResult, pollResultsQueueSize),
+			}
+
+			done := make(chan bool)
+			go func() {
+				defer GinkgoRecover()
+				Expect(runner.collect(callbacks.NewFileCallback(&closeRecorder{}, callbacks.Raw))).To(Succeed())
+				close(done)
+			}()
+			Consistently(done, 40*time.Millisecond).ShouldNot(BeClosed())
+			Eventually(done, time.Second).Should(BeClosed())
+			Expect(atomic.LoadInt64(&hanging.cleanedUp)).To(Equal(int64(1)))
+		})
+	})
+	When("a collector fails to start", func() {
+		It("should return the error after cleaning up the started collectors and the callback", func() {
+			started := &cleanupCollector{}
+			output := &closeRecorder{}
+			runner := &CollectorRunner{
+				endTime:              time.Now().Add(time.Hour),
+				quit:                 make(chan os.Signal, 1),
+				collectorQuitChannel: make(map[string]chan os.Signal),
+				collectorInstances: map[string]collectors.Collector{
+					"started": started,
+					"failing": &failingCollector{},
+				},
+				collectorNames: []string{"started", "failing"},
+				pollResults:    make(chan collectors.PollResult, pollResultsQueueSize),
+				erroredPolls:   make(chan collectors.PollResult, pollResultsQueueSize),
+			}
+			err := runner.collect(callbacks.NewFileCallback(output, callbacks.Raw))
+			Expect(err).To(MatchError(ContainSubstring("failed to start collector failing")))
+			Expect(atomic.LoadInt64(&started.cleanedUp)).To(Equal(int64(1)))
+			Expect(atomic.LoadInt64(&output.closed)).To(Equal(int64(1)))
+		})
+	})
+})
+
+var _ = Describe("start", func() {
+	When("a collector fails to start", func() {
+		It("should clean up the collectors which have already started", func() {
+			started := &cleanupCollector{}
+			failing := &failingCollector{}
+			runner := &CollectorRunner{
+				endTime:              time.Now().Add(time.Hour),
+				collectorQuitChannel: make(map[string]chan os.Signal),
+				collectorInstances: map[string]collectors.Collector{
+					"started": started,
+					"failing": failing,
+				},
+				collectorNames: []string{"started", "failing"},
+				pollResults:    make(chan collectors.PollResult, pollResultsQueueSize),
+			}
+			Expect(runner.start()).To(MatchError(ContainSubstring("failed to start")))
+			Expect(atomic.LoadInt64(&started.cleanedUp)).To(Equal(int64(1)))
+			Expect(atomic.LoadInt64(&failing.cleanedUp)).To(Equal(int64(0)))
+			Expect(runner.runningCollectorsWG.GetCount()).To(Equal(0))
+		})
+	})
+})
+
+var _ = Describe("poller", func() {
+	When("a poll overruns the poll interval", func() {
+		It("should skip the following polls rather than queue them", func() {
+			collector := &blockingCollector{
+				release:      make(chan bool),
+				pollInterval: time.Millisecond,
+			}
+			runner := &CollectorRunner{
+				endTime:     time.Now().Add(50 * time.Millisecond),
+				pollResults: make(chan collectors.PollResult, pollResultsQueueSize),
+			}
+			wg := utils.WaitGroupCount{}
+			wg.Add(1)
+			go runner.poller("blocking", collector, make(chan os.Signal, 1), &wg)
+
+			// Let the collector reach its end time while the first poll is still running
+			time.Sleep(100 * time.Millisecond)
+			Expect(atomic.LoadInt64(&collector.polls)).To(Equal(int64(1)))
+
+			close(collector.release)
+			wg.Wait()
+			Expect(atomic.LoadInt64(&collector.polls)).To(Equal(int64(1)))
+			Expect(runner.pollResults).To(HaveLen(1))
+		})
+	})
+})
+
+var _ = Describe("newPollResultsChannel", func() {
+	When("there are more collectors than the default queue size", func() {
+		It("should not block pollers while nothing reads the results", func() {
+			collectorCount := pollResultsQueueSize + 5
+			runner := &CollectorRunner{
+				pollCount:   1,
+				pollResults: newPollResultsChannel(collectorCount),
+			}
+			Expect(cap(runner.pollResults)).To(Equal(collectorCount))
+
+			wg := utils.WaitGroupCount{}
+			for i := 0; i < collectorCount; i++ {
+				wg.Add(1)
+				go runner.poller("cleanup", &cleanupCollector{}, make(chan os.Signal, 1), &wg)
+			}
+			done := make(chan bool)
+			go func() {
+				wg.Wait()
+				close(done)
+			}()
+			Eventually(done, time.Second).Should(BeClosed())
+			Expect(runner.pollResults).To(HaveLen(collectorCount))
+		})
+	})
+	When("there are fewer collectors than the default queue size", func() {
+		It("should use the default queue size", func() {
+			Expect(cap(newPollResultsChannel(1))).To(Equal(pollResultsQueueSize))
+		})
+	})
+})
+
+// newMockRunner returns a runner which will run mock for duration, or pollCount polls, using the callback
+func newMockRunner(
+	mock *collectors.MockCollector,
+	callback callbacks.Callback,
+	duration time.Duration,
+	pollCount int,
+) *CollectorRunner {
+	collectors.RegisterMockCollector(mock)
+	runner := &CollectorRunner{
+		quit:                 make(chan os.Signal, 1),
+		collectorQuitChannel: make(map[string]chan os.Signal),
+		collectorInstances:   make(map[string]collectors.Collector),
+		collectorNames:       []string{collectors.MockCollectorName},
+		pollResults:          make(chan collectors.PollResult, pollResultsQueueSize),
+		erroredPolls:         make(chan collectors.PollResult, pollResultsQueueSize),
+	}
+	options := &RunOptions{
+		PollInterval:           1,
+		RequestedDuration:      duration,
+		PollCount:              pollCount,
+		DevInfoAnnouceInterval: 1,
+	}
+	Expect(runner.initialise(callback, nil, options)).To(Succeed())
+	return runner
+}
+
+var _ = Describe("MockCollector", func() {
+	const (
+		interval = 20 * time.Millisecond
+		// Polls are at 0, 20, 40, 60, 80 and 100ms
+		duration      = 110 * time.Millisecond
+		expectedPolls = 6
+	)
+
+	When("the runner is run to completion", func() {
+		It("should poll the expected number of times and write each poll", func() {
+			mock := collectors.NewMockCollector(interval, nil)
+			output := &closeRecorder{}
+			callback := callbacks.NewFileCallback(output, callbacks.Raw)
+			runner := newMockRunner(mock, callback, duration, UntilDuration)
+			Expect(runner.collectorInstances).To(HaveKey(collectors.MockCollectorName))
+
+			Expect(runner.collect(callback)).To(Succeed())
+
+			polls := mock.GetPollCount()
+			Expect(polls).To(BeNumerically("~", expectedPolls, 1))
+			lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+			Expect(lines).To(HaveLen(int(polls)))
+			Expect(lines[0]).To(ContainSubstring(`"poll":1`))
+			Expect(mock.GetCleanUpCount()).To(Equal(int64(1)))
+			Expect(atomic.LoadInt64(&output.closed)).To(Equal(int64(1)))
+		})
+	})
+
+	When("the collectors take longer to start than the warmup", func() {
+		It("should start the warmup once the collectors have started", func() {
+			const pollCount = 3
+			warmup := 50 * time.Millisecond
+			mock := collectors.NewMockCollector(time.Millisecond, nil)
+			statistics := callbacks.NewStatisticsCallback(
+				callbacks.NewFileCallback(&closeRecorder{}, callbacks.NDJSON),
+				map[string][]string{collectors.MockDataID: {"value"}},
+				warmup,
+			)
+			runner := newMockRunner(mock, statistics, 0, pollCount)
+			runner.statistics = statistics
+			runner.collectorInstances[collectors.MockCollectorName] = &slowStartingCollector{
+				MockCollector: mock,
+				startDelay:    2 * warmup,
+			}
+
+			Expect(runner.collect(statistics)).To(Succeed())
+
+			Expect(statistics.Summaries()).To(BeEmpty())
+			Expect(statistics.WarmupExcluded()).To(Equal(int64(pollCount)))
+		})
+	})
+
+	When("the output is written as NDJSON", func() {
+		It("should number the outputs of each run from 1", func() {
+			const pollCount = 3
+			mock := collectors.NewMockCollector(time.Millisecond, nil)
+			output := &closeRecorder{}
+			callback := callbacks.NewFileCallback(output, callbacks.NDJSON)
+			runner := newMockRunner(mock, callback, 0, pollCount)
+
+			Expect(runner.collect(callback)).To(Succeed())
+
+			lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+			Expect(lines).To(HaveLen(pollCount))
+			// Polls can overlap so the lines may not be written in order
+			seqs := make([]int, 0, len(lines))
+			for _, line := range lines {
+				decoded := callbacks.NDJSONLine{}
+				Expect(json.Unmarshal([]byte(line), &decoded)).To(Succeed())
+				Expect(decoded.Seq).NotTo(BeNil())
+				seqs = append(seqs, int(*decoded.Seq))
+			}
+			sort.Ints(seqs)
+			Expect(seqs).To(Equal([]int{1, 2, 3}))
+		})
+	})
+
+	When("polls are configured to fail", func() {
+		It("should keep polling and only write the successful polls", func() {
+			mock := collectors.NewMockCollector(interval, func(poll int64) bool {
+				return poll%2 == 0
+			})
+			output := &closeRecorder{}
+			callback := callbacks.NewFileCallback(output, callbacks.AnalyserJSON)
+			runner := newMockRunner(mock, callback, duration, UntilDuration)
+
+			Expect(runner.collect(callback)).To(Succeed())
+
+			polls := mock.GetPollCount()
+			Expect(polls).To(BeNumerically("~", expectedPolls, 1))
+			lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+			Expect(lines).To(HaveLen(int(polls - polls/2)))
+			for _, line := range lines {
+				Expect(line).To(ContainSubstring(`"id":"mock/data"`))
+			}
+			Expect(runner.erroredPolls).To(HaveLen(int(polls / 2)))
+		})
+	})
+
+	When("a poll count is requested", func() {
+		It("should poll exactly that many times regardless of the duration", func() {
+			const pollCount = 4
+			mock := collectors.NewMockCollector(time.Millisecond, nil)
+			output := &closeRecorder{}
+			callback := callbacks.NewFileCallback(output, callbacks.Raw)
+			// The duration has already passed so would stop the runner straight away
+			runner := newMockRunner(mock, callback, 0, pollCount)
+
+			Expect(runner.collect(callback)).To(Succeed())
+
+			Expect(mock.GetPollCount()).To(Equal(int64(pollCount)))
+			lines := strings.Split(strings.TrimSpace(output.String()), "\n")
+			Expect(lines).To(HaveLen(pollCount))
+			Expect(lines[pollCount-1]).To(ContainSubstring(`"poll":4`))
+		})
+		It("should count failed polls", func() {
+			const pollCount = 3
+			mock := collectors.NewMockCollector(time.Millisecond, func(poll int64) bool {
+				return poll == 1
+			})
+			output := &closeRecorder{}
+			callback := callbacks.NewFileCallback(output, callbacks.Raw)
+			runner := newMockRunner(mock, callback, 0, pollCount)
+
+			Expect(runner.collect(callback)).To(Succeed())
+
+			Expect(mock.GetPollCount()).To(Equal(int64(pollCount)))
+			Expect(strings.Split(strings.TrimSpace(output.String()), "\n")).To(HaveLen(pollCount - 1))
+		})
+	})
+
+	When("infinite polls are requested", func() {
+		It("should poll until a signal is received", func() {
+			mock := collectors.NewMockCollector(time.Millisecond, nil)
+			callback := callbacks.NewFileCallback(&closeRecorder{}, callbacks.Raw)
+			runner := newMockRunner(mock, callback, 0, InfinitePolls)
+
+			done := make(chan bool)
+			go func() {
+				defer GinkgoRecover()
+				Expect(runner.collect(callback)).To(Succeed())
+				close(done)
+			}()
+			Eventually(mock.GetPollCount, time.Second).Should(BeNumerically(">", 5))
+			Consistently(done, 20*time.Millisecond).ShouldNot(BeClosed())
+			runner.quit <- syscall.SIGINT
+			Eventually(done, time.Second).Should(BeClosed())
+		})
+	})
+})
+
+var _ = Describe("announcers", func() {
+	const pollCount = 20
+	runWithAnnouncer := func(announceOnce bool) []string {
+		mock := collectors.NewMockCollector(time.Millisecond, nil)
+		output := &closeRecorder{}
+		callback := callbacks.NewFileCallback(output, callbacks.Raw)
+		runner := newMockRunner(mock, callback, 0, pollCount)
+		runner.collectorInstances["announcer"] = &announcingCollector{callback: callback}
+		runner.collectorNames = append(runner.collectorNames, "announcer")
+		runner.announceOnce = announceOnce
+
+		Expect(runner.collect(callback)).To(Succeed())
+
+		announced := make([]string, 0)
+		for _, line := range strings.Split(strings.TrimSpace(output.String()), "\n") {
+			if strings.Contains(line, ":announce,") {
+				announced = append(announced, line)
+			}
+		}
+		return announced
+	}
+
+	When("announce once is set", func() {
+		It("should write exactly one announce record", func() {
+			Expect(runWithAnnouncer(true)).To(HaveLen(1))
+		})
+	})
+	When("announce once is not set", func() {
+		It("should keep announcing while the other collectors run", func() {
+			Expect(len(runWithAnnouncer(false))).To(BeNumerically(">", 1))
+		})
+	})
+})
+
+var _ = Describe("flush on announce", func() {
+	const pollCount = 20
+	runWithAnnouncer := func(flushOnAnnounce bool) (*countingFlusher, *announcingCollector) {
+		mock := collectors.NewMockCollector(time.Millisecond, nil)
+		callback := callbacks.NewFileCallback(&closeRecorder{}, callbacks.Raw)
+		runner := newMockRunner(mock, callback, 0, pollCount)
+		announcer := &announcingCollector{callback: callback}
+		runner.collectorInstances["announcer"] = announcer
+		runner.collectorNames = append(runner.collectorNames, "announcer")
+		flusher := &countingFlusher{}
+		runner.flusher = flusher
+		runner.flushOnAnnounce = flushOnAnnounce
+
+		Expect(runner.collect(callback)).To(Succeed())
+		return flusher, announcer
+	}
+
+	When("flush on announce is set", func() {
+		It("should flush the output on each announce tick", func() {
+			flusher, announcer := runWithAnnouncer(true)
+			Expect(atomic.LoadInt64(&flusher.flushes)).To(BeNumerically(">", 0))
+			Expect(atomic.LoadInt64(&flusher.flushes)).To(Equal(atomic.LoadInt64(&announcer.polls)))
+		})
+	})
+	When("flush on announce is not set", func() {
+		It("should not flush on the announce tick", func() {
+			flusher, _ := runWithAnnouncer(false)
+			Expect(atomic.LoadInt64(&flusher.flushes)).To(Equal(int64(0)))
+		})
+	})
+})
+
+var _ = Describe("strict parse", func() {
+	const pollCount = 50
+	runWithParseFailure := func(strictParse bool) (*CollectorRunner, *parseFailingCollector) {
+		collector := &parseFailingCollector{failFrom: 2}
+		runner := &CollectorRunner{
+			pollCount:            pollCount,
+			quit:                 make(chan os.Signal, 1),
+			collectorQuitChannel: make(map[string]chan os.Signal),
+			collectorInstances:   map[string]collectors.Collector{"parse": collector},
+			collectorNames:       []string{"parse"},
+			pollResults:          make(chan collectors.PollResult, pollResultsQueueSize),
+			erroredPolls:         make(chan collectors.PollResult, pollResultsQueueSize),
+			strictParse:          strictParse,
+		}
+		Expect(runner.collect(callbacks.NewFileCallback(&closeRecorder{}, callbacks.Raw))).To(Succeed())
+		return runner, collector
+	}
+
+	When("strict parse is set", func() {
+		It("should abort the run on the first parse error", func() {
+			runner, collector := runWithParseFailure(true)
+			Expect(runner.parseFailure).To(HaveOccurred())
+			Expect(fetcher.IsParseError(runner.parseFailure)).To(BeTrue())
+			Expect(atomic.LoadInt64(&collector.polls)).To(BeNumerically("<", pollCount))
+			Expect(atomic.LoadInt64(&collector.cleanedUp)).To(Equal(int64(1)))
+		})
+	})
+	When("strict parse is not set", func() {
+		It("should keep polling", func() {
+			runner, collector := runWithParseFailure(false)
+			Expect(runner.parseFailure).NotTo(HaveOccurred())
+			Expect(atomic.LoadInt64(&collector.polls)).To(Equal(int64(pollCount)))
+		})
+	})
+})
+
+type countingFlusher struct {
+	flushes int64
+}
+
+func (f *countingFlusher) Flush() error {
+	atomic.AddInt64(&f.flushes, 1)
+	return nil
+}
+
+var _ = Describe("flushPeriodically", func() {
+	It("should flush on each interval until stopped", func() {
+		flusher := &countingFlusher{}
+		stop := make(chan struct{})
+		done := make(chan bool)
+		go func() {
+			flushPeriodically(flusher, 10*time.Millisecond, stop)
+			done <- true
+		}()
+		Eventually(func() int64 { return atomic.LoadInt64(&flusher.flushes) }).Should(BeNumerically(">=", 2))
+		close(stop)
+		Eventually(done).Should(Receive())
+		flushes := atomic.LoadInt64(&flusher.flushes)
+		Consistently(func() int64 { return atomic.LoadInt64(&flusher.flushes) }, 50*time.Millisecond).Should(Equal(flushes))
+	})
+})
+
+var _ = Describe("SetMaxConcurrency", func() {
+	AfterEach(func() {
+		SetMaxConcurrency(0)
+	})
+	It("should not run more polls at once than the limit", func() {
+		const (
+			limit          = 2
+			collectorCount = 6
+			pollCount      = 3
+		)
+		SetMaxConcurrency(limit)
+		var running, maxRunning int64
+		runner := &CollectorRunner{
+			endTime:              time.Now().Add(time.Hour),
+			pollCount:            pollCount,
+			quit:                 make(chan os.Signal, 1),
+			collectorQuitChannel: make(map[string]chan os.Signal),
+			collectorInstances:   make(map[string]collectors.Collector),
+			pollResults:          make(chan collectors.PollResult, pollResultsQueueSize),
+			erroredPolls:         make(chan collectors.PollResult, pollResultsQueueSize),
+		}
+		concurrentCollectors := make([]*concurrentCollector, 0, collectorCount)
+		for i := 0; i < collectorCount; i++ {
+			collector := &concurrentCollector{running: &running, maxRunning: &maxRunning}
+			name := fmt.Sprintf("concurrent-%d", i)
+			runner.collectorNames = append(runner.collectorNames, name)
+			runner.collectorInstances[name] = collector
+			concurrentCollectors = append(concurrentCollectors, collector)
+		}
+
+		Expect(runner.collect(callbacks.NewFileCallback(&closeRecorder{}, callbacks.Raw))).To(Succeed())
+		Expect(atomic.LoadInt64(&maxRunning)).To(BeNumerically("<=", limit))
+		Expect(atomic.LoadInt64(&maxRunning)).To(BeNumerically(">", 0))
+		for _, collector := range concurrentCollectors {
+			Expect(atomic.LoadInt64(&collector.polls)).To(Equal(int64(pollCount)))
+		}
+	})
+})
+
+func TestCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Runner Suite")
+}
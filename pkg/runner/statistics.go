@@ -0,0 +1,42 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package runner
+
+import (
+	"sort"
+
+	log "github.com/sirupsen/logrus"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+// statisticsFields are the offset and accuracy fields summarised at the end of each run keyed by analyser ID,
+// the DPLL terror is the offset and the GNSS terror and ferror are the tAcc and fAcc
+var statisticsFields = map[string][]string{
+	devices.DPLLTimeErrorID: {"terror"},
+	devices.GNSSTimeErrorID: {"terror", "ferror"},
+}
+
+// logStatistics logs a line summarising each field so the run can be judged at a glance
+func logStatistics(statistics map[string]map[string]utils.StatisticsSummary) {
+	ids := make([]string, 0, len(statistics))
+	for id := range statistics {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		fields := make([]string, 0, len(statistics[id]))
+		for field := range statistics[id] {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		for _, field := range fields {
+			summary := statistics[id][field]
+			log.Infof(
+				"Statistics of %s %s: count=%d min=%g max=%g mean=%g stddev=%g abs_p99=%g",
+				id, field, summary.Count, summary.Min, summary.Max, summary.Mean, summary.StdDev, summary.AbsP99,
+			)
+		}
+	}
+}
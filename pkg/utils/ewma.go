@@ -0,0 +1,38 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package utils
+
+import "fmt"
+
+// EWMA is an exponentially weighted moving average,
+// each new sample is weighted by alpha and the previous average by 1 - alpha
+type EWMA struct {
+	alpha       float64
+	value       float64
+	initialised bool
+}
+
+// NewEWMA returns an EWMA with the given alpha which must be in the range (0, 1]
+func NewEWMA(alpha float64) (*EWMA, error) {
+	if alpha <= 0 || alpha > 1 {
+		return nil, fmt.Errorf("EWMA alpha must be greater than 0 and at most 1 got %v", alpha)
+	}
+	return &EWMA{alpha: alpha}, nil
+}
+
+// Update adds a sample to the average and returns the new value,
+// the first sample is used as the initial value
+func (avg *EWMA) Update(sample float64) float64 {
+	if !avg.initialised {
+		avg.value = sample
+		avg.initialised = true
+	} else {
+		avg.value = avg.alpha*sample + (1-avg.alpha)*avg.value
+	}
+	return avg.value
+}
+
+// Value returns the current average
+func (avg *EWMA) Value() float64 {
+	return avg.value
+}
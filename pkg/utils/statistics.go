@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package utils
+
+import (
+	"math"
+	"math/rand"
+	"sort"
+)
+
+const (
+	// statisticsSampleSize bounds the number of values kept to estimate the percentile,
+	// the percentile is exact until more values than this have been added
+	statisticsSampleSize = 4096
+	statisticsPercentile = 0.99
+)
+
+// StatisticsSummary is the summary of the values added to a StreamingStatistics. AbsP99 is the
+// 99th percentile of the absolute values so large offsets either side of zero are both counted.
+type StatisticsSummary struct {
+	Count  int64   `json:"count"`
+	Min    float64 `json:"min"`
+	Max    float64 `json:"max"`
+	Mean   float64 `json:"mean"`
+	StdDev float64 `json:"stddev"`
+	AbsP99 float64 `json:"absP99"`
+}
+
+// StreamingStatistics accumulates the min, max, mean and standard deviation of values
+// without keeping them, the 99th percentile of the absolute values is estimated from a uniform sample of bounded size
+type StreamingStatistics struct {
+	random *rand.Rand
+	sample []float64
+	count  int64
+	min    float64
+	max    float64
+	mean   float64
+	m2     float64
+}
+
+// NewStreamingStatistics returns an empty StreamingStatistics
+func NewStreamingStatistics() *StreamingStatistics {
+	return &StreamingStatistics{
+		random: rand.New(rand.NewSource(1)), //nolint:gosec // the sample only needs to be uniform not unpredictable
+		sample: make([]float64, 0),
+	}
+}
+
+// Add adds a value, the mean and variance are updated using Welford's algorithm
+// and the sample using reservoir sampling
+func (stats *StreamingStatistics) Add(value float64) {
+	stats.count++
+	if stats.count == 1 || value < stats.min {
+		stats.min = value
+	}
+	if stats.count == 1 || value > stats.max {
+		stats.max = value
+	}
+	delta := value - stats.mean
+	stats.mean += delta / float64(stats.count)
+	stats.m2 += delta * (value - stats.mean)
+
+	if len(stats.sample) < statisticsSampleSize {
+		stats.sample = append(stats.sample, math.Abs(value))
+	} else if index := stats.random.Int63n(stats.count); index < statisticsSampleSize {
+		stats.sample[index] = math.Abs(value)
+	}
+}
+
+// Summary returns the summary of the values added so far, the standard deviation is of the population
+func (stats *StreamingStatistics) Summary() StatisticsSummary {
+	summary := StatisticsSummary{Count: stats.count}
+	if stats.count == 0 {
+		return summary
+	}
+	summary.Min = stats.min
+	summary.Max = stats.max
+	summary.Mean = stats.mean
+	summary.StdDev = math.Sqrt(stats.m2 / float64(stats.count))
+
+	sorted := make([]float64, len(stats.sample))
+	copy(sorted, stats.sample)
+	sort.Float64s(sorted)
+	// Nearest rank
+	rank := int(math.Ceil(statisticsPercentile * float64(len(sorted))))
+	summary.AbsP99 = sorted[rank-1]
+	return summary
+}
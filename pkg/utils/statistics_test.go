@@ -0,0 +1,77 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package utils_test
+
+import (
+	"math"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var _ = Describe("StreamingStatistics", func() {
+	When("no values have been added", func() {
+		It("should return an empty summary", func() {
+			Expect(utils.NewStreamingStatistics().Summary()).To(Equal(utils.StatisticsSummary{}))
+		})
+	})
+	When("given a known series", func() {
+		It("should return its statistics", func() {
+			stats := utils.NewStreamingStatistics()
+			// 1 to 100 in an order which is not sorted
+			for i := 0; i < 100; i++ {
+				stats.Add(float64((i*37)%100 + 1))
+			}
+			summary := stats.Summary()
+			Expect(summary.Count).To(Equal(int64(100)))
+			Expect(summary.Min).To(Equal(1.0))
+			Expect(summary.Max).To(Equal(100.0))
+			Expect(summary.Mean).To(BeNumerically("~", 50.5, 1e-9))
+			Expect(summary.StdDev).To(BeNumerically("~", math.Sqrt(833.25), 1e-9))
+			Expect(summary.AbsP99).To(Equal(99.0))
+		})
+		It("should handle negative offsets", func() {
+			stats := utils.NewStreamingStatistics()
+			for _, value := range []float64{-4, 2, -2, 4} {
+				stats.Add(value)
+			}
+			summary := stats.Summary()
+			Expect(summary.Min).To(Equal(-4.0))
+			Expect(summary.Max).To(Equal(4.0))
+			Expect(summary.Mean).To(BeNumerically("~", 0, 1e-9))
+			Expect(summary.StdDev).To(BeNumerically("~", math.Sqrt(10), 1e-9))
+			Expect(summary.AbsP99).To(Equal(4.0))
+		})
+	})
+	When("most of the offsets are negative", func() {
+		It("should take the p99 of the absolute values", func() {
+			stats := utils.NewStreamingStatistics()
+			// -1 to -98 with two small positive offsets
+			for i := 1; i <= 98; i++ {
+				stats.Add(float64(-i))
+			}
+			stats.Add(1)
+			stats.Add(2)
+			summary := stats.Summary()
+			Expect(summary.Min).To(Equal(-98.0))
+			Expect(summary.Max).To(Equal(2.0))
+			Expect(summary.AbsP99).To(Equal(97.0))
+		})
+	})
+	When("given more values than are sampled", func() {
+		It("should keep the exact statistics and estimate the absolute p99", func() {
+			stats := utils.NewStreamingStatistics()
+			for i := 1; i <= 100000; i++ {
+				stats.Add(float64(i))
+			}
+			summary := stats.Summary()
+			Expect(summary.Count).To(Equal(int64(100000)))
+			Expect(summary.Min).To(Equal(1.0))
+			Expect(summary.Max).To(Equal(100000.0))
+			Expect(summary.Mean).To(BeNumerically("~", 50000.5, 1e-6))
+			Expect(summary.AbsP99).To(BeNumerically("~", 99000, 1000))
+		})
+	})
+})
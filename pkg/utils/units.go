@@ -0,0 +1,27 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package utils
+
+// The devices report offsets and accuracies in different units,
+// these convert them to nanoseconds so they can be compared directly.
+const (
+	picosecondsPerNanosecond      = 1000
+	centinanosecondsPerNanosecond = 100
+	nanosecondsPerSecond          = 1e9
+)
+
+// PicosecondsToNanoseconds converts a value in picoseconds such as the UBX TIM-TP qErr
+func PicosecondsToNanoseconds(picoseconds float64) float64 {
+	return picoseconds / picosecondsPerNanosecond
+}
+
+// CentinanosecondsToNanoseconds converts a value in hundredths of a nanosecond
+// such as the DPLL offset exposed in the filesystem by the ice driver
+func CentinanosecondsToNanoseconds(centinanoseconds float64) float64 {
+	return centinanoseconds / centinanosecondsPerNanosecond
+}
+
+// SecondsToNanoseconds converts a value in seconds such as the gpsd TPV ept
+func SecondsToNanoseconds(seconds float64) float64 {
+	return seconds * nanosecondsPerSecond
+}
@@ -55,6 +55,25 @@ func (wg *WaitGroupCount) GetCount() int {
 	return int(atomic.LoadInt64(&wg.count))
 }
 
+// WaitTimeout waits for the WaitGroupCount to reach zero or for the timeout to expire.
+// It returns true if the wait completed and false if it timed out, in which case
+// the goroutine waiting on the underlying WaitGroup is left to finish on its own.
+func (wg *WaitGroupCount) WaitTimeout(timeout time.Duration) bool {
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		return false
+	}
+}
+
 // ParseTimestamp converts an input number of seconds (including a decimal fraction) into a time.Time
 func ParseTimestamp(timestamp string) (time.Time, error) {
 	duration, err := time.ParseDuration(fmt.Sprintf("%ss", timestamp))
@@ -0,0 +1,90 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package utils_test
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+var _ = Describe("EWMA", func() {
+	When("created with an alpha outside (0, 1]", func() {
+		It("should return an error", func() {
+			for _, alpha := range []float64{-0.1, 0, 1.5} {
+				_, err := utils.NewEWMA(alpha)
+				Expect(err).To(HaveOccurred())
+			}
+		})
+	})
+	When("updated with a constant input", func() {
+		It("should start at the first sample and converge on the input", func() {
+			avg, err := utils.NewEWMA(0.2)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(avg.Update(0)).To(Equal(float64(0)))
+			previousError := float64(100)
+			for i := 0; i < 50; i++ {
+				value := avg.Update(100)
+				Expect(100 - value).To(BeNumerically("<", previousError))
+				previousError = 100 - value
+			}
+			Expect(avg.Value()).To(BeNumerically("~", 100, 0.01))
+		})
+	})
+})
+
+var _ = Describe("Unit conversions", func() {
+	When("converting picoseconds", func() {
+		It("should return nanoseconds", func() {
+			Expect(utils.PicosecondsToNanoseconds(-1234)).To(Equal(-1.234))
+			Expect(utils.PicosecondsToNanoseconds(845)).To(Equal(0.845))
+		})
+	})
+	When("converting hundredths of a nanosecond", func() {
+		It("should return nanoseconds", func() {
+			Expect(utils.CentinanosecondsToNanoseconds(-34)).To(Equal(-0.34))
+			Expect(utils.CentinanosecondsToNanoseconds(1234)).To(Equal(12.34))
+		})
+	})
+	When("converting seconds", func() {
+		It("should return nanoseconds", func() {
+			Expect(utils.SecondsToNanoseconds(0.005)).To(BeNumerically("~", 5e6, 1e-6))
+			Expect(utils.SecondsToNanoseconds(2)).To(Equal(float64(2e9)))
+		})
+	})
+})
+
+var _ = Describe("WaitGroupCount", func() {
+	When("WaitTimeout is called and the group completes in time", func() {
+		It("should return true", func() {
+			wg := utils.WaitGroupCount{}
+			wg.Add(1)
+			go func() {
+				time.Sleep(10 * time.Millisecond)
+				wg.Done()
+			}()
+			Expect(wg.WaitTimeout(time.Second)).To(BeTrue())
+			Expect(wg.GetCount()).To(Equal(0))
+		})
+	})
+	When("WaitTimeout is called and the group does not complete in time", func() {
+		It("should return false once the timeout expires", func() {
+			wg := utils.WaitGroupCount{}
+			wg.Add(1)
+			defer wg.Done()
+			start := time.Now()
+			Expect(wg.WaitTimeout(20 * time.Millisecond)).To(BeFalse())
+			Expect(time.Since(start)).To(BeNumerically(">=", 20*time.Millisecond))
+			Expect(wg.GetCount()).To(Equal(1))
+		})
+	})
+})
+
+func TestCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Utils Suite")
+}
@@ -0,0 +1,21 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package utils
+
+import "runtime/debug"
+
+// version can be set when building e.g.
+// -ldflags "-X github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils.version=v1.2.3"
+var version = ""
+
+// Version returns the version of the tool, this is the version set when building
+// or if that was not set the version of the main module from the build info
+func Version() string {
+	if version != "" {
+		return version
+	}
+	if info, ok := debug.ReadBuildInfo(); ok && info.Main.Version != "" {
+		return info.Main.Version
+	}
+	return "unknown"
+}
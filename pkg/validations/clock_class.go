@@ -0,0 +1,88 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package validations
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	clockClassID          = TGMSyncEnvPath + "/pmc/clock-class/wpc/"
+	clockClassDescription = "PTP clock is locked to a primary reference"
+
+	// ClockClassLocked is the clockClass of a grand master locked to a primary reference e.g. GNSS
+	ClockClassLocked = 6
+	// DefaultMaxClockAccuracy is the clockAccuracy for within 100ns, smaller values are more accurate
+	DefaultMaxClockAccuracy = 0x21
+)
+
+var clockClassNames = map[int]string{
+	ClockClassLocked: "locked",
+	7:                "holdover",
+	248:              "free-run",
+}
+
+type ClockClass struct {
+	Error            error  `json:"fetchError"`
+	ClockAccuracy    string `json:"clockAccuracy"`
+	MaxClockAccuracy string `json:"maxClockAccuracy"`
+	ClockClass       int    `json:"clockClass"`
+	maxAccuracy      uint64 `json:"-"`
+}
+
+func (clockClass *ClockClass) Verify() error {
+	if clockClass.Error != nil {
+		return clockClass.Error
+	}
+	if clockClass.ClockClass != ClockClassLocked {
+		name, ok := clockClassNames[clockClass.ClockClass]
+		if !ok {
+			name = "not locked"
+		}
+		return utils.NewInvalidEnvError(
+			fmt.Errorf("clockClass is %d (%s) rather than %d (locked)", clockClass.ClockClass, name, ClockClassLocked),
+		)
+	}
+	accuracy, err := strconv.ParseUint(clockClass.ClockAccuracy, 0, 8)
+	if err != nil {
+		return fmt.Errorf("could not parse clockAccuracy %s", clockClass.ClockAccuracy)
+	}
+	if accuracy > clockClass.maxAccuracy {
+		return utils.NewInvalidEnvError(
+			fmt.Errorf("clockAccuracy %s is worse than %s", clockClass.ClockAccuracy, clockClass.MaxClockAccuracy),
+		)
+	}
+	return nil
+}
+
+func (clockClass *ClockClass) GetID() string {
+	return clockClassID
+}
+
+func (clockClass *ClockClass) GetDescription() string {
+	return clockClassDescription
+}
+
+func (clockClass *ClockClass) GetData() any { //nolint:ireturn // data will vary for each validation
+	return clockClass
+}
+
+func (clockClass *ClockClass) GetOrder() int {
+	return clockClassOrdering
+}
+
+// NewClockClass returns a validation of the clockClass and clockAccuracy from pmc,
+// fetchErr is the error from fetching pmcInfo if there was one
+func NewClockClass(pmcInfo *devices.PMCInfo, fetchErr error, maxClockAccuracy uint8) *ClockClass {
+	return &ClockClass{
+		Error:            fetchErr,
+		ClockClass:       pmcInfo.ClockClass,
+		ClockAccuracy:    pmcInfo.ClockAccuracy,
+		MaxClockAccuracy: fmt.Sprintf("0x%02x", maxClockAccuracy),
+		maxAccuracy:      uint64(maxClockAccuracy),
+	}
+}
@@ -0,0 +1,71 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package validations_test
+
+import (
+	"errors"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/validations"
+)
+
+var _ = Describe("ClockClass", func() {
+	var pmcInfo *devices.PMCInfo
+
+	BeforeEach(func() {
+		pmcInfo = &devices.PMCInfo{ClockClass: 6, ClockAccuracy: "0x21"}
+	})
+
+	When("the clock is locked to a primary reference", func() {
+		It("should pass", func() {
+			check := validations.NewClockClass(pmcInfo, nil, validations.DefaultMaxClockAccuracy)
+			Expect(check.Verify()).To(Succeed())
+		})
+		It("should fail if the accuracy is worse than the bound", func() {
+			pmcInfo.ClockAccuracy = "0x22"
+			check := validations.NewClockClass(pmcInfo, nil, validations.DefaultMaxClockAccuracy)
+			err := check.Verify()
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("clockAccuracy 0x22 is worse than 0x21"))
+
+			Expect(validations.NewClockClass(pmcInfo, nil, 0x22).Verify()).To(Succeed())
+		})
+	})
+	When("the clock is in holdover", func() {
+		It("should fail", func() {
+			pmcInfo.ClockClass = 7
+			err := validations.NewClockClass(pmcInfo, nil, validations.DefaultMaxClockAccuracy).Verify()
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("clockClass is 7 (holdover)"))
+		})
+	})
+	When("the clock is free running", func() {
+		It("should fail", func() {
+			pmcInfo.ClockClass = 248
+			pmcInfo.ClockAccuracy = "0xfe"
+			err := validations.NewClockClass(pmcInfo, nil, validations.DefaultMaxClockAccuracy).Verify()
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("clockClass is 248 (free-run)"))
+		})
+	})
+	When("pmc could not be fetched", func() {
+		It("should return the fetch error", func() {
+			fetchErr := errors.New("failed to fetch gmSetting")
+			err := validations.NewClockClass(&devices.PMCInfo{}, fetchErr, validations.DefaultMaxClockAccuracy).Verify()
+			Expect(err).To(MatchError(fetchErr))
+		})
+	})
+})
+
+func TestCommand(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Validations Suite")
+}
@@ -29,10 +29,13 @@ const (
 	gnssModuleOrdering
 	gnssVersionOrdering
 	gnssProtOrdering
+	gnssAllowListOrdering
 	hasGNSSDevicesOrdering
 	gnssConnectedToAntOrdering
+	gnssCableDelayOrdering
 	gnssReceivingDataOrdering
 	configuredForGrandMasterOrdering
+	clockClassOrdering
 )
 
 type VersionCheck struct {
@@ -25,8 +25,13 @@ type DeviceDetails struct {
 	DeviceID string `json:"deviceId"`
 }
 
+// IsSupportedNIC returns true if the vendor and device IDs are for a supported timing NIC
+func IsSupportedNIC(vendorID, deviceID string) bool {
+	return vendorID == VendorIntel && (deviceID == E810WesportChannel || deviceID == E810LoganBeach)
+}
+
 func (dev *DeviceDetails) Verify() error {
-	if dev.VendorID != VendorIntel || (dev.DeviceID != E810WesportChannel && dev.DeviceID != E810LoganBeach) {
+	if !IsSupportedNIC(dev.VendorID, dev.DeviceID) {
 		return utils.NewInvalidEnvError(fmt.Errorf("NIC device is not based on E810"))
 	}
 	return nil
@@ -0,0 +1,63 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package validations
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	gnssCableDelayID          = TGMSyncEnvPath + "/gnss/antenna-cable-delay/wpc/"
+	gnssCableDelayDescription = "GNSS Module has the antenna cable delay configured"
+)
+
+type GNSSCableDelay struct {
+	fetchErr   error  `json:"-"`
+	Error      string `json:"fetchError,omitempty"`
+	CableDelay *int   `json:"cableDelayNs"`
+}
+
+// Verify fails when the cable delay is zero, if it could not be read
+// the fetch error is returned so the result is reported as unknown
+func (cableDelay *GNSSCableDelay) Verify() error {
+	if cableDelay.fetchErr != nil {
+		return fmt.Errorf("antenna cable delay is unknown: %w", cableDelay.fetchErr)
+	}
+	if cableDelay.CableDelay == nil {
+		return errors.New("antenna cable delay is unknown")
+	}
+	if *cableDelay.CableDelay == 0 {
+		return utils.NewInvalidEnvError(errors.New("antenna cable delay is not set (0ns)"))
+	}
+	return nil
+}
+
+func (cableDelay *GNSSCableDelay) GetID() string {
+	return gnssCableDelayID
+}
+
+func (cableDelay *GNSSCableDelay) GetDescription() string {
+	return gnssCableDelayDescription
+}
+
+func (cableDelay *GNSSCableDelay) GetData() any { //nolint:ireturn // data will vary for each validation
+	return cableDelay
+}
+
+func (cableDelay *GNSSCableDelay) GetOrder() int {
+	return gnssCableDelayOrdering
+}
+
+// NewGNSSCableDelay returns a validation of the antenna cable delay,
+// fetchErr is the error from fetching gpsCableDelay if there was one
+func NewGNSSCableDelay(gpsCableDelay *devices.GPSCableDelay, fetchErr error) *GNSSCableDelay {
+	if fetchErr != nil {
+		return &GNSSCableDelay{fetchErr: fetchErr, Error: fetchErr.Error()}
+	}
+	value := gpsCableDelay.CableDelay
+	return &GNSSCableDelay{CableDelay: &value}
+}
@@ -0,0 +1,47 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package validations_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/validations"
+)
+
+var _ = Describe("GNSSCableDelay", func() {
+	When("the cable delay is set", func() {
+		It("should pass and report the value", func() {
+			check := validations.NewGNSSCableDelay(&devices.GPSCableDelay{CableDelay: 50}, nil)
+			Expect(check.Verify()).To(Succeed())
+			Expect(*check.CableDelay).To(Equal(50))
+		})
+	})
+	When("the cable delay is not set", func() {
+		It("should fail", func() {
+			check := validations.NewGNSSCableDelay(&devices.GPSCableDelay{CableDelay: 0}, nil)
+			err := check.Verify()
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("0ns"))
+			Expect(*check.CableDelay).To(Equal(0))
+		})
+	})
+	When("the cable delay can not be read", func() {
+		It("should be unknown rather than fail", func() {
+			fetchErr := errors.New("failed to fetch the GNSS cable delay")
+			check := validations.NewGNSSCableDelay(&devices.GPSCableDelay{}, fetchErr)
+			err := check.Verify()
+			Expect(err).To(MatchError(ContainSubstring("unknown")))
+			Expect(errors.Is(err, fetchErr)).To(BeTrue())
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeFalse())
+			Expect(check.CableDelay).To(BeNil())
+			Expect(check.Error).To(Equal(fetchErr.Error()))
+		})
+	})
+})
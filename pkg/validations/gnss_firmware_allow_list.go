@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package validations
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+)
+
+const (
+	gnssAllowListID          = TGMEnvVerPath + "/gnss-firmware-allow-list/"
+	gnssAllowListDescription = "GNSS firmware and protocol versions are in the allow-list"
+)
+
+// FirmwareCombination is a validated combination of GNSS firmware and protocol versions
+// as they are reported by the GNSS module e.g. "TIM 2.20" and "29.20"
+type FirmwareCombination struct {
+	FirmwareVersion string `json:"firmwareVersion"`
+	ProtocolVersion string `json:"protocolVersion"`
+}
+
+// LoadFirmwareAllowList reads the allow-list from a JSON file containing a list of FirmwareCombinations,
+// an error is returned if the file can not be read or parsed or does not contain any combinations
+func LoadFirmwareAllowList(path string) ([]FirmwareCombination, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the firmware allow-list %s: %w", path, err)
+	}
+	if strings.TrimSpace(string(content)) == "" {
+		return nil, fmt.Errorf("the firmware allow-list %s is empty", path)
+	}
+	allowList := make([]FirmwareCombination, 0)
+	err = json.Unmarshal(content, &allowList)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse the firmware allow-list %s: %w", path, err)
+	}
+	if len(allowList) == 0 {
+		return nil, fmt.Errorf("the firmware allow-list %s does not contain any combinations", path)
+	}
+	for i, combination := range allowList {
+		if combination.FirmwareVersion == "" || combination.ProtocolVersion == "" {
+			return nil, fmt.Errorf(
+				"entry %d of the firmware allow-list %s must have a firmwareVersion and a protocolVersion", i, path,
+			)
+		}
+	}
+	return allowList, nil
+}
+
+// GNSSFirmwareAllowList checks the collected GNSS versions against the combinations given by the user
+type GNSSFirmwareAllowList struct {
+	FirmwareVersion string                `json:"firmwareVersion"`
+	ProtocolVersion string                `json:"protocolVersion"`
+	AllowList       []FirmwareCombination `json:"expected"`
+}
+
+// Verify fails when the combination of the collected versions is not in the allow-list
+func (allowList *GNSSFirmwareAllowList) Verify() error {
+	if allowList.FirmwareVersion == "" || allowList.ProtocolVersion == "" {
+		return errors.New("GNSS firmware or protocol version is unknown")
+	}
+	for _, combination := range allowList.AllowList {
+		if strings.TrimSpace(combination.FirmwareVersion) == allowList.FirmwareVersion &&
+			strings.TrimSpace(combination.ProtocolVersion) == allowList.ProtocolVersion {
+			return nil
+		}
+	}
+	return utils.NewInvalidEnvError(fmt.Errorf(
+		"GNSS firmware %s with protocol %s is not in the allow-list",
+		allowList.FirmwareVersion, allowList.ProtocolVersion,
+	))
+}
+
+func (allowList *GNSSFirmwareAllowList) GetID() string {
+	return gnssAllowListID
+}
+
+func (allowList *GNSSFirmwareAllowList) GetDescription() string {
+	return gnssAllowListDescription
+}
+
+func (allowList *GNSSFirmwareAllowList) GetData() any { //nolint:ireturn // data will vary for each validation
+	return allowList
+}
+
+func (allowList *GNSSFirmwareAllowList) GetOrder() int {
+	return gnssAllowListOrdering
+}
+
+// NewGNSSFirmwareAllowList returns a validation that the GNSS firmware and protocol
+// versions are one of the combinations in allowList
+func NewGNSSFirmwareAllowList(gnss *devices.GPSVersions, allowList []FirmwareCombination) *GNSSFirmwareAllowList {
+	return &GNSSFirmwareAllowList{
+		FirmwareVersion: strings.TrimSpace(gnss.FirmwareVersion),
+		ProtocolVersion: strings.TrimSpace(gnss.ProtoVersion),
+		AllowList:       allowList,
+	}
+}
@@ -0,0 +1,96 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package validations_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/collectors/devices"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/utils"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/validations"
+)
+
+var _ = Describe("GNSSFirmwareAllowList", func() {
+	allowList := []validations.FirmwareCombination{
+		{FirmwareVersion: "TIM 2.20", ProtocolVersion: "29.20"},
+		{FirmwareVersion: "TIM 2.22", ProtocolVersion: "29.22"},
+	}
+
+	When("the firmware and protocol are in the allow-list", func() {
+		It("should pass", func() {
+			gnss := &devices.GPSVersions{FirmwareVersion: "TIM 2.22", ProtoVersion: "29.22"}
+			check := validations.NewGNSSFirmwareAllowList(gnss, allowList)
+			Expect(check.Verify()).To(Succeed())
+		})
+	})
+	When("the firmware is not in the allow-list", func() {
+		It("should fail", func() {
+			gnss := &devices.GPSVersions{FirmwareVersion: "TIM 2.01", ProtoVersion: "29.20"}
+			err := validations.NewGNSSFirmwareAllowList(gnss, allowList).Verify()
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeTrue())
+			Expect(err.Error()).To(ContainSubstring("TIM 2.01"))
+		})
+	})
+	When("the firmware is only allowed with a different protocol", func() {
+		It("should fail", func() {
+			gnss := &devices.GPSVersions{FirmwareVersion: "TIM 2.20", ProtoVersion: "29.22"}
+			err := validations.NewGNSSFirmwareAllowList(gnss, allowList).Verify()
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeTrue())
+		})
+	})
+	When("the versions were not collected", func() {
+		It("should be unknown rather than fail", func() {
+			err := validations.NewGNSSFirmwareAllowList(&devices.GPSVersions{}, allowList).Verify()
+			Expect(err).To(MatchError(ContainSubstring("unknown")))
+			var invalidEnv *utils.InvalidEnvError
+			Expect(errors.As(err, &invalidEnv)).To(BeFalse())
+		})
+	})
+})
+
+var _ = Describe("LoadFirmwareAllowList", func() {
+	var dir string
+	BeforeEach(func() {
+		var err error
+		dir, err = os.MkdirTemp("", "allow-list")
+		Expect(err).NotTo(HaveOccurred())
+		DeferCleanup(os.RemoveAll, dir)
+	})
+	writeAllowList := func(content string) string {
+		path := filepath.Join(dir, "allow-list.json")
+		Expect(os.WriteFile(path, []byte(content), 0600)).To(Succeed())
+		return path
+	}
+
+	It("should load the combinations", func() {
+		path := writeAllowList(`[{"firmwareVersion": "TIM 2.20", "protocolVersion": "29.20"}]`)
+		allowList, err := validations.LoadFirmwareAllowList(path)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(allowList).To(Equal([]validations.FirmwareCombination{
+			{FirmwareVersion: "TIM 2.20", ProtocolVersion: "29.20"},
+		}))
+	})
+	It("should return an error for a missing file", func() {
+		_, err := validations.LoadFirmwareAllowList(filepath.Join(dir, "missing.json"))
+		Expect(err).To(MatchError(ContainSubstring("failed to read")))
+	})
+	It("should return an error for an empty file", func() {
+		_, err := validations.LoadFirmwareAllowList(writeAllowList("\n"))
+		Expect(err).To(MatchError(ContainSubstring("is empty")))
+	})
+	It("should return an error for an empty list", func() {
+		_, err := validations.LoadFirmwareAllowList(writeAllowList("[]"))
+		Expect(err).To(MatchError(ContainSubstring("does not contain any combinations")))
+	})
+	It("should return an error for an incomplete entry", func() {
+		_, err := validations.LoadFirmwareAllowList(writeAllowList(`[{"firmwareVersion": "TIM 2.20"}]`))
+		Expect(err).To(MatchError(ContainSubstring("entry 0")))
+	})
+})
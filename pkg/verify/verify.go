@@ -27,8 +27,9 @@ const (
 func getDevInfoValidations(
 	clientset *clients.Clientset,
 	interfaceName string,
+	nodeName string,
 ) []validations.Validation {
-	ctx, err := contexts.GetPTPDaemonContext(clientset)
+	ctx, err := contexts.GetPTPDaemonContext(clientset, nodeName)
 	utils.IfErrorExitOrPanic(err)
 	devInfo, err := devices.GetPTPDeviceInfo(interfaceName, ctx)
 	utils.IfErrorExitOrPanic(err)
@@ -38,26 +39,35 @@ func getDevInfoValidations(
 	return []validations.Validation{devDetails, devFirmware, devDriver}
 }
 
+// getGPSVersionValidations returns the validations of the GNSS versions,
+// the allow-list is only checked if it has been given
 func getGPSVersionValidations(
 	clientset *clients.Clientset,
+	nodeName string,
+	firmwareAllowList []validations.FirmwareCombination,
 ) []validations.Validation {
-	ctx, err := contexts.GetPTPDaemonContext(clientset)
+	ctx, err := contexts.GetPTPDaemonContext(clientset, nodeName)
 	utils.IfErrorExitOrPanic(err)
 	gnssVersions, err := devices.GetGPSVersions(ctx)
 	utils.IfErrorExitOrPanic(err)
-	return []validations.Validation{
+	checks := []validations.Validation{
 		validations.NewGNSS(&gnssVersions),
 		validations.NewGPSDVersion(&gnssVersions),
 		validations.NewGNSDevices(&gnssVersions),
 		validations.NewGNSSModule(&gnssVersions),
 		validations.NewGNSSProtocol(&gnssVersions),
 	}
+	if len(firmwareAllowList) > 0 {
+		checks = append(checks, validations.NewGNSSFirmwareAllowList(&gnssVersions, firmwareAllowList))
+	}
+	return checks
 }
 
 func getGPSStatusValidation(
 	clientset *clients.Clientset,
+	nodeName string,
 ) []validations.Validation {
-	ctx, err := contexts.GetPTPDaemonContext(clientset)
+	ctx, err := contexts.GetPTPDaemonContext(clientset, nodeName)
 	utils.IfErrorExitOrPanic(err)
 
 	// If we need to do this for more validations then consider a generic
@@ -74,19 +84,38 @@ func getGPSStatusValidation(
 		time.Sleep(time.Second)
 	}
 	utils.IfErrorExitOrPanic(err)
+	// A failure to read the cable delay is reported as the result of the validation
+	cableDelay, err := devices.GetGPSCableDelay(ctx)
 	return []validations.Validation{
 		antCheck,
+		validations.NewGNSSCableDelay(&cableDelay, err),
 		validations.NewGNSSNavStatus(&gpsDetails),
 	}
 }
 
-func getValidations(interfaceName, kubeConfig string) []validations.Validation {
-	checks := make([]validations.Validation, 0)
-	clientset, err := clients.GetClientset(kubeConfig)
+func getPMCValidations(
+	clientset *clients.Clientset,
+	nodeName string,
+	maxClockAccuracy uint8,
+) []validations.Validation {
+	ctx, err := contexts.GetPTPDaemonContext(clientset, nodeName)
 	utils.IfErrorExitOrPanic(err)
-	checks = append(checks, getDevInfoValidations(clientset, interfaceName)...)
-	checks = append(checks, getGPSVersionValidations(clientset)...)
-	checks = append(checks, getGPSStatusValidation(clientset)...)
+	// A failure to fetch is reported as the result of the validation as ptp4l may not be running yet
+	pmcInfo, err := devices.GetPMC(ctx)
+	return []validations.Validation{validations.NewClockClass(&pmcInfo, err, maxClockAccuracy)}
+}
+
+func getValidations(
+	clientset *clients.Clientset,
+	interfaceName, nodeName string,
+	maxClockAccuracy uint8,
+	firmwareAllowList []validations.FirmwareCombination,
+) []validations.Validation {
+	checks := make([]validations.Validation, 0)
+	checks = append(checks, getDevInfoValidations(clientset, interfaceName, nodeName)...)
+	checks = append(checks, getGPSVersionValidations(clientset, nodeName, firmwareAllowList)...)
+	checks = append(checks, getGPSStatusValidation(clientset, nodeName)...)
+	checks = append(checks, getPMCValidations(clientset, nodeName, maxClockAccuracy)...)
 	checks = append(
 		checks,
 		validations.NewIsGrandMaster(clientset),
@@ -96,10 +125,17 @@ func getValidations(interfaceName, kubeConfig string) []validations.Validation {
 	return checks
 }
 
-func reportAnalyserJSON(results []*ValidationResult) {
-	callback, err := callbacks.SetupCallback("-", callbacks.AnalyserJSON)
+func reportAnalyserJSON(results []*ValidationResult, clusterInfo *clients.ClusterInfo) {
+	callback, err := callbacks.SetupCallback("-", callbacks.AnalyserJSON, false, 0, callbacks.GzipDisabled)
 	utils.IfErrorExitOrPanic(err)
 
+	if clusterInfo != nil {
+		err = callback.Call(clusterInfo, "env-check")
+		if err != nil {
+			log.Errorf("callback failed for the cluster info %s", err.Error())
+		}
+	}
+
 	sort.Slice(results, func(i, j int) bool {
 		return results[i].validation.GetOrder() < results[j].validation.GetOrder()
 	})
@@ -121,12 +157,16 @@ func reportAnalyserJSON(results []*ValidationResult) {
 }
 
 //nolint:funlen,cyclop // allow slightly long function
-func report(results []*ValidationResult, useAnalyserJSON bool) {
+func report(results []*ValidationResult, clusterInfo *clients.ClusterInfo, useAnalyserJSON bool) {
 	if useAnalyserJSON {
-		reportAnalyserJSON(results)
+		reportAnalyserJSON(results, clusterInfo)
 		return
 	}
 
+	if clusterInfo != nil {
+		fmt.Printf("Cluster: %s (context %s)\n", clusterInfo.Server, clusterInfo.Context) //nolint:forbidigo // This to print out to the user
+	}
+
 	failures := make([]*ValidationResult, 0)
 	unknown := make([]*ValidationResult, 0)
 
@@ -165,13 +205,27 @@ func report(results []*ValidationResult, useAnalyserJSON bool) {
 	}
 }
 
-func Verify(interfaceName, kubeConfig string, useAnalyserJSON bool) {
-	checks := getValidations(interfaceName, kubeConfig)
+// Verify checks the environment is ready for collection, the clock accuracy reported by pmc
+// must be maxClockAccuracy or better. If firmwareAllowList is not empty the GNSS firmware
+// and protocol versions must be one of its combinations
+func Verify(
+	interfaceName, kubeConfig, nodeName string,
+	useAnalyserJSON bool,
+	maxClockAccuracy uint8,
+	firmwareAllowList []validations.FirmwareCombination,
+) {
+	clientset, err := clients.GetClientset(kubeConfig)
+	utils.IfErrorExitOrPanic(err)
+	clusterInfo, err := clientset.GetClusterInfo()
+	if err != nil {
+		log.Warnf("failed to get the cluster info: %s", err.Error())
+	}
+	checks := getValidations(clientset, interfaceName, nodeName, maxClockAccuracy, firmwareAllowList)
 
 	results := make([]*ValidationResult, 0)
 	for _, check := range checks {
 		results = append(results, NewValidationResult(check))
 	}
 
-	report(results, useAnalyserJSON)
+	report(results, clusterInfo, useAnalyserJSON)
 }
@@ -40,15 +40,32 @@ func (f *fakeExecutor) Stream(options remotecommand.StreamOptions) error {
 	return responseErr
 }
 
+// StreamWithContext calls the responder in the background so that
+// a responder which blocks can be interrupted by the context
 func (f *fakeExecutor) StreamWithContext(ctx context.Context, options remotecommand.StreamOptions) error {
-	stdout, stderr, reponseErr := f.responder(f.method, f.url, options)
-	_, err := options.Stdout.Write(stdout)
-	if err != nil {
-		return fmt.Errorf("failed to write stdout Error: %w", err)
+	type response struct {
+		err    error
+		stdout []byte
+		stderr []byte
 	}
-	_, err = options.Stderr.Write(stderr)
-	if err != nil {
-		return fmt.Errorf("failed to write stderr Error: %w", err)
+	responses := make(chan response, 1)
+	go func() {
+		stdout, stderr, reponseErr := f.responder(f.method, f.url, options)
+		responses <- response{stdout: stdout, stderr: stderr, err: reponseErr}
+	}()
+
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("stream interrupted: %w", ctx.Err())
+	case resp := <-responses:
+		_, err := options.Stdout.Write(resp.stdout)
+		if err != nil {
+			return fmt.Errorf("failed to write stdout Error: %w", err)
+		}
+		_, err = options.Stderr.Write(resp.stderr)
+		if err != nil {
+			return fmt.Errorf("failed to write stderr Error: %w", err)
+		}
+		return resp.err
 	}
-	return reponseErr
 }
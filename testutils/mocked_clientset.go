@@ -33,5 +33,7 @@ func GetMockedClientSet(k8APIObjects ...runtime.Object) *clients.Clientset {
 	}
 	clientset.K8sClient = fakeK8sClient
 	clientset.K8sRestClient = fakeRestClient
+	// missing pods are expected in tests so they should not be waited for
+	clientset.PodLookupAttempts = 1
 	return clientset
 }
@@ -0,0 +1,194 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package testutils
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+)
+
+const recordingFilePermissions = 0666
+
+// RecordedExec is a single command run through a RecordingExecContext
+type RecordedExec struct {
+	Command []string `json:"command"`
+	Stdin   string   `json:"stdin"`
+	Stdout  string   `json:"stdout"`
+	Stderr  string   `json:"stderr"`
+	Err     string   `json:"error"`
+}
+
+func (rec *RecordedExec) key() string {
+	return recordKey(rec.Command, rec.Stdin)
+}
+
+func (rec *RecordedExec) result() (stdout, stderr string, err error) {
+	if rec.Err != "" {
+		err = errors.New(rec.Err)
+	}
+	return rec.Stdout, rec.Stderr, err
+}
+
+func recordKey(command []string, stdin string) string {
+	return strings.Join(command, " ") + "\n" + stdin
+}
+
+// RecordingExecContext wraps an ExecContext and writes every command
+// along with its output to a file so that it can be replayed later
+type RecordingExecContext struct {
+	ctx        clients.ExecContext
+	fileHandle io.WriteCloser
+	lock       sync.Mutex
+}
+
+func (c *RecordingExecContext) record(rec *RecordedExec) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return fmt.Errorf("failed to marshal recorded command %w", err)
+	}
+	line = append(line, []byte("\n")...)
+
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	_, err = c.fileHandle.Write(line)
+	if err != nil {
+		return fmt.Errorf("failed to write recorded command %w", err)
+	}
+	return nil
+}
+
+func (c *RecordingExecContext) execCommand(command []string, buffIn *bytes.Buffer) (stdout, stderr string, err error) {
+	rec := &RecordedExec{Command: command}
+	if buffIn != nil {
+		rec.Stdin = buffIn.String()
+		stdout, stderr, err = c.ctx.ExecCommandStdIn(command, *buffIn)
+	} else {
+		stdout, stderr, err = c.ctx.ExecCommand(command)
+	}
+	rec.Stdout = stdout
+	rec.Stderr = stderr
+	if err != nil {
+		rec.Err = err.Error()
+	}
+
+	recErr := c.record(rec)
+	if recErr != nil {
+		return stdout, stderr, recErr
+	}
+	return stdout, stderr, err //nolint:wrapcheck // this is the error from the wrapped context
+}
+
+func (c *RecordingExecContext) ExecCommand(command []string) (stdout, stderr string, err error) {
+	return c.execCommand(command, nil)
+}
+
+//nolint:lll // allow slightly long function definition
+func (c *RecordingExecContext) ExecCommandStdIn(command []string, buffIn bytes.Buffer) (stdout, stderr string, err error) {
+	return c.execCommand(command, &buffIn)
+}
+
+// ExecCommandEnv records the command as it is run with the env set so it can be replayed
+//
+//nolint:lll // allow slightly long function definition
+func (c *RecordingExecContext) ExecCommandEnv(command []string, env map[string]string) (stdout, stderr string, err error) {
+	envCommand, err := clients.CommandWithEnv(command, env)
+	if err != nil {
+		return "", "", err //nolint:wrapcheck // the error is already descriptive
+	}
+	return c.execCommand(envCommand, nil)
+}
+
+// CleanUp closes the recording file
+func (c *RecordingExecContext) CleanUp() error {
+	err := c.fileHandle.Close()
+	if err != nil {
+		return fmt.Errorf("failed to close recording file: %w", err)
+	}
+	return nil
+}
+
+// NewRecordingExecContext returns a RecordingExecContext which
+// passes commands through to ctx and records them in filename
+func NewRecordingExecContext(ctx clients.ExecContext, filename string) (*RecordingExecContext, error) {
+	fileHandle, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, recordingFilePermissions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+	return &RecordingExecContext{ctx: ctx, fileHandle: fileHandle}, nil
+}
+
+// ReplayExecContext serves the output of commands recorded by a RecordingExecContext.
+// If a command was recorded more than once the outputs are returned in the order
+// they were recorded, after which the last output is repeated.
+type ReplayExecContext struct {
+	records map[string][]*RecordedExec
+	lock    sync.Mutex
+}
+
+func (c *ReplayExecContext) execCommand(command []string, stdin string) (stdout, stderr string, err error) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	key := recordKey(command, stdin)
+	recs, ok := c.records[key]
+	if !ok || len(recs) == 0 {
+		return "", "", fmt.Errorf("no recording found for command: %s", strings.Join(command, " "))
+	}
+	rec := recs[0]
+	if len(recs) > 1 {
+		c.records[key] = recs[1:]
+	}
+	return rec.result()
+}
+
+func (c *ReplayExecContext) ExecCommand(command []string) (stdout, stderr string, err error) {
+	return c.execCommand(command, "")
+}
+
+//nolint:lll // allow slightly long function definition
+func (c *ReplayExecContext) ExecCommandStdIn(command []string, buffIn bytes.Buffer) (stdout, stderr string, err error) {
+	return c.execCommand(command, buffIn.String())
+}
+
+//nolint:lll // allow slightly long function definition
+func (c *ReplayExecContext) ExecCommandEnv(command []string, env map[string]string) (stdout, stderr string, err error) {
+	envCommand, err := clients.CommandWithEnv(command, env)
+	if err != nil {
+		return "", "", err //nolint:wrapcheck // the error is already descriptive
+	}
+	return c.execCommand(envCommand, "")
+}
+
+// NewReplayExecContext loads the commands recorded in filename
+func NewReplayExecContext(filename string) (*ReplayExecContext, error) {
+	fileHandle, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording file: %w", err)
+	}
+	defer fileHandle.Close()
+
+	records := make(map[string][]*RecordedExec)
+	scanner := bufio.NewScanner(fileHandle)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), 1<<24) //nolint:gomnd // allow for large outputs
+	for scanner.Scan() {
+		rec := &RecordedExec{}
+		err = json.Unmarshal(scanner.Bytes(), rec)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse recorded command %w", err)
+		}
+		records[rec.key()] = append(records[rec.key()], rec)
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read recording file %w", err)
+	}
+	return &ReplayExecContext{records: records}, nil
+}
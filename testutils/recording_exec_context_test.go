@@ -0,0 +1,104 @@
+// SPDX-License-Identifier: GPL-2.0-or-later
+
+package testutils_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/remotecommand"
+
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/pkg/clients"
+	"github.com/redhat-partner-solutions/vse-sync-collection-tools/testutils"
+)
+
+var testPod = &v1.Pod{
+	ObjectMeta: metav1.ObjectMeta{
+		Name:        "TestPod-8292",
+		Namespace:   "TestNamespace",
+		Annotations: map[string]string{},
+	},
+}
+
+var _ = Describe("RecordingExecContext", func() {
+	var ctx *clients.ContainerExecContext
+	var recordingPath string
+	BeforeEach(func() {
+		clientset := testutils.GetMockedClientSet(testPod)
+		responder := func(method string, url *url.URL, options remotecommand.StreamOptions) ([]byte, []byte, error) {
+			if options.Stdin == nil {
+				return []byte("no stdin"), []byte(""), errors.New("command failed")
+			}
+			stdin, _ := io.ReadAll(options.Stdin)
+			return []byte("out:" + string(stdin)), []byte("err:" + string(stdin)), nil
+		}
+		clients.NewSPDYExecutor = testutils.NewFakeNewSPDYExecutor(responder, nil)
+		var err error
+		ctx, err = clients.NewContainerContext(clientset, "TestNamespace", "Test", "TestContainer")
+		Expect(err).NotTo(HaveOccurred())
+		recordingPath = filepath.Join(GinkgoT().TempDir(), "recording.jsonl")
+	})
+
+	When("commands are recorded and then replayed", func() {
+		It("should return the same outputs", func() {
+			recorder, err := testutils.NewRecordingExecContext(ctx, recordingPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			cmd := []string{"/usr/bin/sh"}
+			stdout, stderr, err := recorder.ExecCommandStdIn(cmd, *bytes.NewBufferString("first"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("out:first"))
+			Expect(stderr).To(Equal("err:first"))
+			_, _, err = recorder.ExecCommandStdIn(cmd, *bytes.NewBufferString("second"))
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = recorder.ExecCommand([]string{"my", "test", "command"})
+			Expect(err).To(HaveOccurred())
+			Expect(recorder.CleanUp()).To(Succeed())
+
+			replay, err := testutils.NewReplayExecContext(recordingPath)
+			Expect(err).NotTo(HaveOccurred())
+
+			stdout, stderr, err = replay.ExecCommandStdIn(cmd, *bytes.NewBufferString("second"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("out:second"))
+			Expect(stderr).To(Equal("err:second"))
+
+			stdout, stderr, err = replay.ExecCommandStdIn(cmd, *bytes.NewBufferString("first"))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout).To(Equal("out:first"))
+			Expect(stderr).To(Equal("err:first"))
+
+			stdout, _, err = replay.ExecCommand([]string{"my", "test", "command"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("command failed"))
+			Expect(stdout).To(Equal("no stdin"))
+		})
+	})
+
+	When("a command which was not recorded is replayed", func() {
+		It("should return an error", func() {
+			recorder, err := testutils.NewRecordingExecContext(ctx, recordingPath)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.CleanUp()).To(Succeed())
+
+			replay, err := testutils.NewReplayExecContext(recordingPath)
+			Expect(err).NotTo(HaveOccurred())
+			_, _, err = replay.ExecCommandStdIn([]string{"/usr/bin/sh"}, *bytes.NewBufferString("unknown"))
+			Expect(err).To(HaveOccurred())
+		})
+	})
+})
+
+func TestTestutils(t *testing.T) {
+	RegisterFailHandler(Fail)
+	RunSpecs(t, "Testutils Suite")
+}